@@ -2,36 +2,66 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"flag"
+	"net"
+	"os"
+	"time"
 
 	"github.com/IgorRamosBR/g73-techchallenge-payment/configs"
 	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/api"
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/api/middleware"
 	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/controllers"
 	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/usecases"
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/grpcapi"
 	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/infra/drivers/dynamodb"
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/infra/drivers/dynamodbstreams"
 	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/infra/drivers/http"
 	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/infra/drivers/payment"
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/infra/drivers/sns"
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/infra/drivers/sqs"
 	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/infra/gateways"
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/workers"
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	awsDynamoDb "github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	awsDynamoDBStreams "github.com/aws/aws-sdk-go-v2/service/dynamodbstreams"
+	awsSns "github.com/aws/aws-sdk-go-v2/service/sns"
+	awsSqs "github.com/aws/aws-sdk-go-v2/service/sqs"
+	"google.golang.org/grpc"
+
+	log "github.com/sirupsen/logrus"
 
 	_ "github.com/golang-migrate/migrate/v4/source/file"
 )
 
 func main() {
+	reconcile := flag.Bool("reconcile", false, "run a single payment reconciliation, print the report as JSON and exit")
+	flag.Parse()
+
 	config := configs.NewConfig()
 	appConfig, err := config.ReadConfig()
 	if err != nil {
 		panic(err)
 	}
 
+	// the http clients of the upstream apis
+	httpClient := http.NewHttpClient(appConfig.DefaultTimeout)
+
 	// mercado pago payment broker
-	paymentHttpClient := http.NewMockHttpClient()
+	paymentHttpClient := httpClient
+	if appConfig.PaymentBrokerFake {
+		log.Warn("payment broker is fake, no payment, refund or cancellation reaches Mercado Pago")
+		paymentHttpClient = http.NewMockHttpClient()
+	}
 	paymentBrokerConfig := payment.MercadoPagoBrokerConfig{
 		HttpClient:      paymentHttpClient,
 		BrokerUrl:       appConfig.PaymentBrokerURL,
+		StoreOrderUrl:   appConfig.PaymentStoreOrderURL,
+		ApiUrl:          appConfig.PaymentApiURL,
 		NotificationUrl: appConfig.NotificationURL,
 		SponsorId:       appConfig.SponsorId,
+		AccessToken:     appConfig.PaymentAccessToken,
 	}
 	paymentBroker := payment.NewMercadoPagoBroker(paymentBrokerConfig)
 
@@ -40,24 +70,120 @@ func main() {
 	if err != nil {
 		panic(err)
 	}
-	paymentRepository := gateways.NewPaymentRepositoryGateway(dynamodbClient, appConfig.PaymentTable)
+	paymentRepository := gateways.NewPaymentRepositoryGateway(dynamodbClient, appConfig.PaymentTable, appConfig.PaymentEventTable)
+	paymentEventRepository := gateways.NewPaymentEventRepositoryGateway(dynamodbClient, appConfig.PaymentEventTable)
+	processedNotificationRepository := gateways.NewProcessedNotificationRepositoryGateway(dynamodbClient, appConfig.ProcessedNotificationTable)
+	deadLetterRepository := gateways.NewDeadLetterRepositoryGateway(dynamodbClient, appConfig.DeadLetterTable)
+	notificationQueue := NewNotificationQueue(appConfig, dynamodbClient)
 
 	// order api
-	httpClient := http.NewHttpClient(appConfig.DefaultTimeout)
 	orderClient := gateways.NewOrderClient(httpClient, appConfig.OrderApiUrl)
 
+	// production api
+	productionClient := gateways.NewProductionClient(httpClient, appConfig.ProductionApiUrl)
+
+	// payment domain events
+	eventPublisher, err := NewEventPublisher(appConfig)
+	if err != nil {
+		panic(err)
+	}
+
+	// payment status pushes
+	paymentStatusHub, err := NewPaymentStatusHub(appConfig)
+	if err != nil {
+		panic(err)
+	}
+
 	// payment usecase
 	paymentUseCaseConfig := usecases.PaymentUseCaseConfig{
-		PaymentBroker:     paymentBroker,
-		PaymentRepository: paymentRepository,
-		OrderClient:       orderClient,
+		PaymentBroker:                   paymentBroker,
+		PaymentRepository:               paymentRepository,
+		PaymentEventRepository:          paymentEventRepository,
+		ProcessedNotificationRepository: processedNotificationRepository,
+		DeadLetterRepository:            deadLetterRepository,
+		OrderClient:                     orderClient,
+		ProductionClient:                productionClient,
+		ProductionEnabled:               appConfig.ProductionEnabled,
+		StrictOrderVerification:         appConfig.StrictOrderVerification,
+		PayableOrderStatuses:            appConfig.PayableOrderStatuses,
+		CancelledOrderStatuses:          appConfig.CancelledOrderStatuses,
+		EventPublisher:                  eventPublisher,
+		PaymentStatusHub:                paymentStatusHub,
+		PaymentExpiration:               appConfig.PaymentExpiration,
+		NotificationDeduplicationTTL:    appConfig.ProcessedNotificationTTL,
 	}
 	paymentUseCase := usecases.NewPaymentUseCase(paymentUseCaseConfig)
 
+	// notification usecase
+	notificationUseCaseConfig := usecases.NotificationUseCaseConfig{
+		PaymentUseCase:       paymentUseCase,
+		NotificationQueue:    notificationQueue,
+		DeadLetterRepository: deadLetterRepository,
+		MaxAttempts:          appConfig.NotificationMaxAttempts,
+		RetryDelay:           appConfig.NotificationRetryDelay,
+	}
+	notificationUseCase := usecases.NewNotificationUseCase(notificationUseCaseConfig)
+
+	// dead letter usecase
+	deadLetterUseCaseConfig := usecases.DeadLetterUseCaseConfig{
+		DeadLetterRepository: deadLetterRepository,
+		NotificationQueue:    notificationQueue,
+		PaymentUseCase:       paymentUseCase,
+		ProductionClient:     productionClient,
+	}
+	deadLetterUseCase := usecases.NewDeadLetterUseCase(deadLetterUseCaseConfig)
+
+	if *reconcile {
+		runReconciliation(paymentUseCase, appConfig.ReconciliationThreshold)
+		return
+	}
+
+	// payment expiration sweeper
+	paymentExpirationWorker := workers.NewPaymentExpirationWorker(paymentUseCase, appConfig.PaymentSweepInterval)
+	go paymentExpirationWorker.Start(context.Background())
+
+	// payment reconciliation
+	reconciliationWorker := workers.NewReconciliationWorker(paymentUseCase, appConfig.ReconciliationInterval, appConfig.ReconciliationThreshold)
+	go reconciliationWorker.Start(context.Background())
+
+	// payment notification processing
+	notificationWorker := workers.NewNotificationWorker(notificationUseCase, appConfig.NotificationWorkers, appConfig.NotificationPollInterval)
+	go notificationWorker.Start(context.Background())
+
 	// payment controller
-	paymentController := controllers.NewPaymentController(paymentUseCase)
+	paymentController := controllers.NewPaymentController(paymentUseCase, notificationUseCase)
+	deadLetterController := controllers.NewDeadLetterController(deadLetterUseCase)
+
+	// the http and the grpc apis share the token validator, and so its cache, and the rate limits
+	tokenValidator := NewTokenValidator(appConfig, httpClient)
+	rateLimiter := NewRateLimiter(appConfig, dynamodbClient)
+
+	// grpc api
+	if appConfig.GrpcPort != "" {
+		grpcServerConfig := grpcapi.ServerConfig{
+			PaymentUseCase: paymentUseCase,
+			ExposeErrors:   appConfig.Environment != "prod",
+			TokenValidator: tokenValidator,
+			RateLimiter:    rateLimiter,
+			WatchInterval:  appConfig.GrpcWatchInterval,
+		}
+		go ServeGrpc(grpcapi.NewServer(grpcServerConfig), appConfig.GrpcPort)
+	}
 
-	api := api.NewApi(paymentController)
+	apiConfig := api.ApiConfig{
+		PaymentController:    paymentController,
+		DeadLetterController: deadLetterController,
+		ExposeErrors:         appConfig.Environment != "prod",
+		Authenticator:        NewAuthenticator(tokenValidator),
+		RateLimiter:          rateLimiter,
+		TrustedProxies:       appConfig.TrustedProxies,
+		TrustedPlatform:      appConfig.TrustedPlatform,
+		WebhookSecret:        appConfig.WebhookSecret,
+	}
+	api, err := api.NewApi(apiConfig)
+	if err != nil {
+		panic(err)
+	}
 	api.Run(":" + appConfig.Port)
 }
 
@@ -78,3 +204,141 @@ func NewDynamoDBClient(endpoint string) (dynamodb.DynamoDBClient, error) {
 	return dynamodb.NewDynamoDBClient(client), nil
 
 }
+
+func NewNotificationQueue(appConfig configs.AppConfig, dynamodbClient dynamodb.DynamoDBClient) gateways.NotificationQueue {
+	if appConfig.NotificationQueueType == "memory" {
+		return gateways.NewInMemoryNotificationQueue(appConfig.NotificationVisibilityTimeout)
+	}
+	return gateways.NewDynamoDBNotificationQueue(dynamodbClient, appConfig.NotificationQueueTable, appConfig.NotificationVisibilityTimeout)
+}
+
+func NewEventPublisher(appConfig configs.AppConfig) (gateways.EventPublisher, error) {
+	if appConfig.EventPublisherType == "memory" {
+		return gateways.NewInMemoryEventBus(), nil
+	}
+
+	cfg, err := config.LoadDefaultConfig(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := appConfig.EventPublisherEndpoint
+	if appConfig.EventPublisherType == "sqs" {
+		client := awsSqs.NewFromConfig(cfg, func(o *awsSqs.Options) {
+			if endpoint != "" {
+				o.BaseEndpoint = aws.String(endpoint)
+			}
+		})
+		return gateways.NewSQSEventPublisher(sqs.NewSQSClient(client), appConfig.EventPublisherQueueUrl), nil
+	}
+
+	client := awsSns.NewFromConfig(cfg, func(o *awsSns.Options) {
+		if endpoint != "" {
+			o.BaseEndpoint = aws.String(endpoint)
+		}
+	})
+	return gateways.NewSNSEventPublisher(sns.NewSNSClient(client), appConfig.EventPublisherTopicArn), nil
+}
+
+func NewPaymentStatusHub(appConfig configs.AppConfig) (gateways.PaymentStatusHub, error) {
+	if appConfig.PaymentStatusBroadcasterType != "dynamodb" {
+		return gateways.NewPaymentStatusHub(gateways.NewInMemoryPaymentStatusBroadcaster()), nil
+	}
+
+	cfg, err := config.LoadDefaultConfig(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	// the stream is served by the endpoint of the table
+	endpoint := appConfig.PaymentTableEndpoint
+	client := awsDynamoDBStreams.NewFromConfig(cfg, func(o *awsDynamoDBStreams.Options) {
+		if endpoint != "" {
+			o.BaseEndpoint = aws.String(endpoint)
+		}
+	})
+	broadcaster := gateways.NewDynamoDBStreamPaymentStatusBroadcaster(dynamodbstreams.NewDynamoDBStreamsClient(client), appConfig.PaymentStatusBroadcasterStreamArn, appConfig.PaymentStatusBroadcasterPollInterval)
+	return gateways.NewPaymentStatusHub(broadcaster), nil
+}
+
+// NewTokenValidator returns nil when the authentication is disabled.
+func NewTokenValidator(appConfig configs.AppConfig, httpClient http.HttpClient) gateways.TokenValidator {
+	switch appConfig.AuthType {
+	case "authorizer":
+		return gateways.NewAuthorizerTokenValidator(httpClient, appConfig.AuthorizerUrl, appConfig.AuthCacheTTL)
+	case "jwks":
+		return gateways.NewJWKSTokenValidator(gateways.JWKSTokenValidatorConfig{
+			HttpClient: httpClient,
+			JWKSUrl:    appConfig.AuthJWKSUrl,
+			Issuer:     appConfig.AuthIssuer,
+			Audience:   appConfig.AuthAudience,
+		})
+	default:
+		return nil
+	}
+}
+
+// NewAuthenticator returns nil when the authentication is disabled, leaving the routes public.
+func NewAuthenticator(tokenValidator gateways.TokenValidator) middleware.Authenticator {
+	if tokenValidator == nil {
+		return nil
+	}
+
+	return middleware.NewAuthenticator(middleware.AuthenticatorConfig{
+		TokenValidator: tokenValidator,
+		ActorKey:       controllers.ActorKey,
+	})
+}
+
+// NewRateLimiter returns nil when the rate limiting is disabled.
+func NewRateLimiter(appConfig configs.AppConfig, dynamodbClient dynamodb.DynamoDBClient) middleware.RateLimiter {
+	var rateLimiter gateways.RateLimiter
+	switch appConfig.RateLimitType {
+	case "memory":
+		rateLimiter = gateways.NewInMemoryRateLimiter()
+	case "dynamodb":
+		rateLimiter = gateways.NewDynamoDBRateLimiter(dynamodbClient, appConfig.RateLimitTable)
+	default:
+		return nil
+	}
+
+	policies := map[string]middleware.RateLimitPolicy{}
+	for name, policy := range appConfig.RateLimitPolicies {
+		policies[name] = middleware.RateLimitPolicy{
+			PerClient: gateways.RateLimit{Rate: policy.ClientPerMinute / 60, Burst: policy.ClientBurst},
+			PerIp:     gateways.RateLimit{Rate: policy.IpPerMinute / 60, Burst: policy.IpBurst},
+		}
+	}
+
+	return middleware.NewRateLimiter(middleware.RateLimiterConfig{
+		RateLimiter: rateLimiter,
+		Policies:    policies,
+		ActorKey:    controllers.ActorKey,
+	})
+}
+
+func ServeGrpc(server *grpc.Server, port string) {
+	listener, err := net.Listen("tcp", ":"+port)
+	if err != nil {
+		panic(err)
+	}
+
+	err = server.Serve(listener)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func runReconciliation(paymentUseCase usecases.PaymentUseCase, threshold time.Duration) {
+	report, err := paymentUseCase.ReconcilePayments(threshold)
+	if err != nil {
+		panic(err)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	err = encoder.Encode(report)
+	if err != nil {
+		panic(err)
+	}
+}
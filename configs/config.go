@@ -2,6 +2,7 @@ package configs
 
 import (
 	"fmt"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
@@ -11,21 +12,85 @@ type Config struct {
 	viper *viper.Viper
 }
 
+// RateLimitPolicyConfig holds the limits of a rate limit policy, in requests per minute and the
+// burst of requests accepted at once.
+type RateLimitPolicyConfig struct {
+	ClientPerMinute float64
+	ClientBurst     int
+	IpPerMinute     float64
+	IpBurst         int
+}
+
 type AppConfig struct {
 	Port        string
 	Environment string
 
-	PaymentBrokerURL string
-	NotificationURL  string
-	SponsorId        string
+	TrustedProxies  []string
+	TrustedPlatform string
 
-	PaymentTable         string
-	PaymentTableEndpoint string
+	GrpcPort          string
+	GrpcWatchInterval time.Duration
+
+	PaymentBrokerURL     string
+	PaymentStoreOrderURL string
+	PaymentApiURL        string
+	NotificationURL      string
+	SponsorId            string
+	PaymentAccessToken   string
+	PaymentBrokerFake    bool
 
-	OrderApiUrl      string
-	ProductionApiUrl string
+	PaymentExpiration    time.Duration
+	PaymentSweepInterval time.Duration
 
-	DefaultTimeout int
+	ReconciliationInterval  time.Duration
+	ReconciliationThreshold time.Duration
+
+	PaymentTable         string
+	PaymentTableEndpoint string
+	PaymentEventTable    string
+
+	ProcessedNotificationTable string
+	ProcessedNotificationTTL   time.Duration
+
+	NotificationQueueType         string
+	NotificationQueueTable        string
+	NotificationWorkers           int
+	NotificationPollInterval      time.Duration
+	NotificationVisibilityTimeout time.Duration
+	NotificationMaxAttempts       int
+	NotificationRetryDelay        time.Duration
+	DeadLetterTable               string
+
+	EventPublisherType     string
+	EventPublisherTopicArn string
+	EventPublisherQueueUrl string
+	EventPublisherEndpoint string
+
+	PaymentStatusBroadcasterType         string
+	PaymentStatusBroadcasterStreamArn    string
+	PaymentStatusBroadcasterPollInterval time.Duration
+
+	OrderApiUrl       string
+	ProductionApiUrl  string
+	ProductionEnabled bool
+
+	StrictOrderVerification bool
+	PayableOrderStatuses    []string
+	CancelledOrderStatuses  []string
+
+	AuthType      string
+	AuthorizerUrl string
+	AuthCacheTTL  time.Duration
+	AuthJWKSUrl   string
+	AuthIssuer    string
+	AuthAudience  string
+	WebhookSecret string
+
+	RateLimitType     string
+	RateLimitTable    string
+	RateLimitPolicies map[string]RateLimitPolicyConfig
+
+	DefaultTimeout time.Duration
 }
 
 func NewConfig() *Config {
@@ -83,17 +148,88 @@ func (c *Config) extractConfigVars() (AppConfig, error) {
 	appConfig.Port = c.viper.GetString("PORT")
 	appConfig.Environment = c.viper.GetString("ENVIRONMENT")
 
+	appConfig.TrustedProxies = c.viper.GetStringSlice("api.trustedProxies")
+	appConfig.TrustedPlatform = c.viper.GetString("api.trustedPlatform")
+
+	appConfig.GrpcPort = c.viper.GetString("GRPC_PORT")
+	appConfig.GrpcWatchInterval = c.viper.GetDuration("grpc.watchInterval")
+
 	appConfig.PaymentBrokerURL = c.viper.GetString("paymentBroker.url")
 	appConfig.NotificationURL = c.viper.GetString("paymentBroker.notificationUrl")
 	appConfig.SponsorId = c.viper.GetString("paymentBroker.sponsorId")
+	appConfig.PaymentStoreOrderURL = c.viper.GetString("paymentBroker.storeOrderUrl")
+	appConfig.PaymentApiURL = c.viper.GetString("paymentBroker.apiUrl")
+	appConfig.PaymentAccessToken = c.viper.GetString("MERCADO_PAGO_ACCESS_TOKEN")
+	appConfig.PaymentBrokerFake = c.viper.GetBool("paymentBroker.fake")
+	if appConfig.Environment == "prod" && appConfig.PaymentBrokerFake {
+		return AppConfig{}, fmt.Errorf("paymentBroker.fake is not allowed in prod")
+	}
+	if appConfig.Environment == "prod" && appConfig.PaymentAccessToken == "" {
+		return AppConfig{}, fmt.Errorf("MERCADO_PAGO_ACCESS_TOKEN is required in prod")
+	}
+
+	appConfig.PaymentExpiration = c.viper.GetDuration("paymentExpiration.ttl")
+	appConfig.PaymentSweepInterval = c.viper.GetDuration("paymentExpiration.sweepInterval")
+
+	appConfig.ReconciliationInterval = c.viper.GetDuration("reconciliation.interval")
+	appConfig.ReconciliationThreshold = c.viper.GetDuration("reconciliation.threshold")
 
 	appConfig.PaymentTable = c.viper.GetString("paymentRepository.table")
 	appConfig.PaymentTableEndpoint = c.viper.GetString("paymentRepository.endpoint")
+	appConfig.PaymentEventTable = c.viper.GetString("paymentEventRepository.table")
+
+	appConfig.ProcessedNotificationTable = c.viper.GetString("processedNotificationRepository.table")
+	appConfig.ProcessedNotificationTTL = c.viper.GetDuration("processedNotificationRepository.ttl")
+
+	appConfig.NotificationQueueType = c.viper.GetString("notificationQueue.type")
+	appConfig.NotificationQueueTable = c.viper.GetString("notificationQueue.table")
+	appConfig.NotificationWorkers = c.viper.GetInt("notificationQueue.workers")
+	appConfig.NotificationPollInterval = c.viper.GetDuration("notificationQueue.pollInterval")
+	appConfig.NotificationVisibilityTimeout = c.viper.GetDuration("notificationQueue.visibilityTimeout")
+	appConfig.NotificationMaxAttempts = c.viper.GetInt("notificationQueue.maxAttempts")
+	appConfig.NotificationRetryDelay = c.viper.GetDuration("notificationQueue.retryDelay")
+	appConfig.DeadLetterTable = c.viper.GetString("deadLetterRepository.table")
+
+	appConfig.EventPublisherType = c.viper.GetString("eventPublisher.type")
+	appConfig.EventPublisherTopicArn = c.viper.GetString("eventPublisher.topicArn")
+	appConfig.EventPublisherQueueUrl = c.viper.GetString("eventPublisher.queueUrl")
+	appConfig.EventPublisherEndpoint = c.viper.GetString("eventPublisher.endpoint")
+
+	appConfig.PaymentStatusBroadcasterType = c.viper.GetString("paymentStatusBroadcaster.type")
+	appConfig.PaymentStatusBroadcasterStreamArn = c.viper.GetString("PAYMENT_TABLE_STREAM_ARN")
+	appConfig.PaymentStatusBroadcasterPollInterval = c.viper.GetDuration("paymentStatusBroadcaster.pollInterval")
+	if appConfig.PaymentStatusBroadcasterType == "dynamodb" && appConfig.PaymentStatusBroadcasterStreamArn == "" {
+		return AppConfig{}, fmt.Errorf("PAYMENT_TABLE_STREAM_ARN is required by the dynamodb payment status broadcaster")
+	}
 
 	appConfig.OrderApiUrl = c.viper.GetString("ORDER_API_URL")
 	appConfig.ProductionApiUrl = c.viper.GetString("PRODUCTION_API_URL")
+	appConfig.ProductionEnabled = c.viper.GetBool("production.enabled")
+
+	appConfig.StrictOrderVerification = c.viper.GetBool("orderVerification.strict")
+	appConfig.PayableOrderStatuses = c.viper.GetStringSlice("orderVerification.payableStatuses")
+	appConfig.CancelledOrderStatuses = c.viper.GetStringSlice("orderVerification.cancelledStatuses")
+
+	appConfig.AuthType = c.viper.GetString("auth.type")
+	appConfig.AuthorizerUrl = c.viper.GetString("AUTHORIZER_URL")
+	appConfig.AuthCacheTTL = c.viper.GetDuration("auth.cacheTtl")
+	appConfig.AuthJWKSUrl = c.viper.GetString("auth.jwksUrl")
+	appConfig.AuthIssuer = c.viper.GetString("auth.issuer")
+	appConfig.AuthAudience = c.viper.GetString("auth.audience")
+	appConfig.WebhookSecret = c.viper.GetString("MERCADO_PAGO_WEBHOOK_SECRET")
+	// the webhooks are not authenticated by token, so in prod their signature must be checked
+	if appConfig.Environment == "prod" && appConfig.WebhookSecret == "" {
+		return AppConfig{}, fmt.Errorf("MERCADO_PAGO_WEBHOOK_SECRET is required in prod")
+	}
+
+	appConfig.RateLimitType = c.viper.GetString("rateLimit.type")
+	appConfig.RateLimitTable = c.viper.GetString("rateLimit.table")
+	err := c.viper.UnmarshalKey("rateLimit.policies", &appConfig.RateLimitPolicies)
+	if err != nil {
+		return AppConfig{}, fmt.Errorf("error reading rate limit policies, error: %v", err)
+	}
 
-	appConfig.DefaultTimeout = c.viper.GetInt("DEFAULT_TIMEOUT")
+	appConfig.DefaultTimeout = c.viper.GetDuration("DEFAULT_TIMEOUT")
 
 	return appConfig, nil
 }
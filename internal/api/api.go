@@ -1,17 +1,65 @@
 package api
 
 import (
+	"fmt"
+
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/api/middleware"
 	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/controllers"
 	"github.com/gin-gonic/gin"
 )
 
-func NewApi(paymenteControler controllers.PaymentController) *gin.Engine {
+type ApiConfig struct {
+	PaymentController    controllers.PaymentController
+	DeadLetterController controllers.DeadLetterController
+	ExposeErrors         bool
+	// Authenticator checks the scope of the routes, which are public when it is nil.
+	Authenticator middleware.Authenticator
+	// RateLimiter limits the requests to the routes, which are not limited when it is nil.
+	RateLimiter middleware.RateLimiter
+	// TrustedProxies are the proxies, by IP or CIDR, whose X-Forwarded-For header is trusted to carry
+	// the client IP the rate limits and the payment events rely on. None are trusted when empty.
+	TrustedProxies []string
+	// TrustedPlatform is the header set by the platform in front of the api with the client IP, such
+	// as CF-Connecting-IP, ignored when empty.
+	TrustedPlatform string
+	// WebhookSecret verifies the signature of the Mercado Pago notifications, unchecked when empty,
+	// which the config only allows outside prod.
+	WebhookSecret string
+}
+
+func NewApi(config ApiConfig) (*gin.Engine, error) {
 
 	router := gin.Default()
-	v1 := router.Group("/v1")
-	{
-		v1.POST("/payment/:id/notify", paymenteControler.NotifyPaymentHandler)
-		v1.POST("/paymentOrder", paymenteControler.CreatePaymentOrderHandler)
+	err := router.SetTrustedProxies(config.TrustedProxies)
+	if err != nil {
+		return nil, fmt.Errorf("invalid trusted proxies, error: %v", err)
 	}
-	return router
+	router.TrustedPlatform = config.TrustedPlatform
+	router.Use(middleware.RequestId(), middleware.ErrorHandler(config.ExposeErrors))
+
+	routes := newRoutes(config.PaymentController, config.DeadLetterController)
+	for _, route := range routes {
+		handlers := []gin.HandlerFunc{}
+		if route.Successor != "" {
+			handlers = append(handlers, middleware.Deprecation(route.Successor))
+		}
+		if config.RateLimiter != nil {
+			handlers = append(handlers, config.RateLimiter.LimitIp(route.RateLimit))
+		}
+		if route.Scope != "" && config.Authenticator != nil {
+			handlers = append(handlers, config.Authenticator.Require(route.Scope))
+		}
+		if config.RateLimiter != nil {
+			handlers = append(handlers, config.RateLimiter.LimitClient(route.RateLimit))
+		}
+		if route.Signed && config.WebhookSecret != "" {
+			handlers = append(handlers, middleware.MercadoPagoSignature(config.WebhookSecret))
+		}
+		handlers = append(handlers, route.Handler)
+		router.Handle(route.Method, route.Path, handlers...)
+	}
+
+	router.GET("/openapi.json", openAPIHandler(NewOpenAPI(routes)))
+	router.GET("/swagger", swaggerUIHandler)
+	return router, nil
 }
@@ -0,0 +1,86 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/api/middleware"
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/entities"
+	mock_gateways "github.com/IgorRamosBR/g73-techchallenge-payment/internal/infra/gateways/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestNewApi_Authentication(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	tokenValidator := mock_gateways.NewMockTokenValidator(ctrl)
+
+	tokenValidator.EXPECT().ValidateToken(gomock.Eq("token")).
+		Times(1).
+		Return(entities.Principal{Subject: "client-1", Scopes: []string{middleware.ScopePaymentsRead}}, nil)
+
+	router, err := NewApi(ApiConfig{
+		Authenticator: middleware.NewAuthenticator(middleware.AuthenticatorConfig{TokenValidator: tokenValidator}),
+		WebhookSecret: "secret",
+	})
+	assert.Nil(t, err)
+
+	tests := []struct {
+		name          string
+		method        string
+		path          string
+		authorization string
+		wantStatus    int
+	}{
+		{name: "should refuse payment route without token", method: http.MethodGet, path: "/v2/payments/123", wantStatus: http.StatusUnauthorized},
+		{name: "should refuse admin route without the admin scope", method: http.MethodGet, path: "/v1/admin/deadLetters", authorization: "Bearer token", wantStatus: http.StatusForbidden},
+		{name: "should refuse unsigned webhook", method: http.MethodPost, path: "/v2/webhooks/mercadopago", wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(tt.method, tt.path, nil)
+		if tt.authorization != "" {
+			req.Header.Set("Authorization", tt.authorization)
+		}
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, tt.wantStatus, w.Code, tt.name)
+	}
+}
+
+func TestNewApi_TrustedProxies(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		trustedProxies []string
+		wantClientIp   string
+	}{
+		{name: "should ignore X-Forwarded-For without trusted proxies", wantClientIp: "192.0.2.1"},
+		{name: "should read X-Forwarded-For from a trusted proxy", trustedProxies: []string{"192.0.2.0/24"}, wantClientIp: "198.51.100.7"},
+		{name: "should ignore X-Forwarded-For from an untrusted proxy", trustedProxies: []string{"10.0.0.0/8"}, wantClientIp: "192.0.2.1"},
+	}
+
+	for _, tt := range tests {
+		router, err := NewApi(ApiConfig{TrustedProxies: tt.trustedProxies})
+		assert.Nil(t, err)
+		router.GET("/client-ip", func(c *gin.Context) {
+			c.String(http.StatusOK, c.ClientIP())
+		})
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/client-ip", nil)
+		req.RemoteAddr = "192.0.2.1:1234"
+		req.Header.Set("X-Forwarded-For", "198.51.100.7")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, tt.wantClientIp, w.Body.String(), tt.name)
+	}
+
+	_, err := NewApi(ApiConfig{TrustedProxies: []string{"not-an-ip"}})
+	assert.NotNil(t, err)
+}
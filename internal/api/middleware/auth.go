@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"strings"
+
+	coreErrors "github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/errors"
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/infra/gateways"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	ScopePaymentsWrite = "payments:write"
+	ScopePaymentsRead  = "payments:read"
+	// ScopeAdmin grants every other scope too.
+	ScopeAdmin = "admin"
+)
+
+var (
+	ErrMissingToken      = coreErrors.New(coreErrors.KindUnauthenticated, "bearer token is missing")
+	ErrInsufficientScope = coreErrors.New(coreErrors.KindForbidden, "token does not grant the required scope")
+)
+
+type Authenticator interface {
+	Require(scope string) gin.HandlerFunc
+}
+
+type authenticator struct {
+	tokenValidator gateways.TokenValidator
+	actorKey       string
+}
+
+type AuthenticatorConfig struct {
+	TokenValidator gateways.TokenValidator
+	// ActorKey is the context key receiving the subject of the token.
+	ActorKey string
+}
+
+func NewAuthenticator(config AuthenticatorConfig) Authenticator {
+	return authenticator{
+		tokenValidator: config.TokenValidator,
+		actorKey:       config.ActorKey,
+	}
+}
+
+// Require lets the request through only with a bearer token granting the scope, or the admin
+// scope.
+func (a authenticator) Require(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token, ok := bearerToken(c.GetHeader("Authorization"))
+		if !ok {
+			c.Header("WWW-Authenticate", `Bearer`)
+			_ = c.Error(ErrMissingToken).SetMeta("authentication required")
+			c.Abort()
+			return
+		}
+
+		principal, err := a.tokenValidator.ValidateToken(token)
+		if err != nil {
+			c.Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+			_ = c.Error(err).SetMeta("authentication failed")
+			c.Abort()
+			return
+		}
+
+		if !principal.HasScope(scope) && !principal.HasScope(ScopeAdmin) {
+			c.Header("WWW-Authenticate", `Bearer error="insufficient_scope", scope="`+scope+`"`)
+			_ = c.Error(ErrInsufficientScope).SetMeta("scope [" + scope + "] required")
+			c.Abort()
+			return
+		}
+
+		c.Set(a.actorKey, principal.Subject)
+		c.Next()
+	}
+}
+
+func bearerToken(authorization string) (string, bool) {
+	scheme, token, found := strings.Cut(authorization, " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(token) == "" {
+		return "", false
+	}
+	return strings.TrimSpace(token), true
+}
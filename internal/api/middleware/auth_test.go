@@ -0,0 +1,94 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/entities"
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/infra/gateways"
+	mock_gateways "github.com/IgorRamosBR/g73-techchallenge-payment/internal/infra/gateways/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestAuthenticator_Require(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	tokenValidator := mock_gateways.NewMockTokenValidator(ctrl)
+
+	type validateTokenCall struct {
+		times     int
+		principal entities.Principal
+		err       error
+	}
+	tests := []struct {
+		name              string
+		authorization     string
+		validateTokenCall validateTokenCall
+		wantStatus        int
+		wantAuthenticate  string
+		wantActor         string
+	}{
+		{
+			name:             "should refuse request without bearer token",
+			authorization:    "Basic dXNlcjpwYXNz",
+			wantStatus:       http.StatusUnauthorized,
+			wantAuthenticate: `Bearer`,
+		},
+		{
+			name:              "should refuse request with invalid token",
+			authorization:     "Bearer token",
+			validateTokenCall: validateTokenCall{times: 1, err: gateways.ErrInvalidToken},
+			wantStatus:        http.StatusUnauthorized,
+			wantAuthenticate:  `Bearer error="invalid_token"`,
+		},
+		{
+			name:              "should forbid request when the token does not grant the scope",
+			authorization:     "Bearer token",
+			validateTokenCall: validateTokenCall{times: 1, principal: entities.Principal{Subject: "client-1", Scopes: []string{ScopePaymentsRead}}},
+			wantStatus:        http.StatusForbidden,
+			wantAuthenticate:  `Bearer error="insufficient_scope", scope="payments:write"`,
+		},
+		{
+			name:              "should let request through when the token grants the scope",
+			authorization:     "Bearer token",
+			validateTokenCall: validateTokenCall{times: 1, principal: entities.Principal{Subject: "client-1", Scopes: []string{ScopePaymentsWrite}}},
+			wantStatus:        http.StatusOK,
+			wantActor:         "client-1",
+		},
+		{
+			name:              "should let request through when the token grants the admin scope",
+			authorization:     "bearer token",
+			validateTokenCall: validateTokenCall{times: 1, principal: entities.Principal{Subject: "admin-1", Scopes: []string{ScopeAdmin}}},
+			wantStatus:        http.StatusOK,
+			wantActor:         "admin-1",
+		},
+	}
+
+	for _, tt := range tests {
+		tokenValidator.EXPECT().ValidateToken(gomock.Eq("token")).
+			Times(tt.validateTokenCall.times).
+			Return(tt.validateTokenCall.principal, tt.validateTokenCall.err)
+
+		authenticator := NewAuthenticator(AuthenticatorConfig{TokenValidator: tokenValidator, ActorKey: "actor"})
+
+		router := gin.New()
+		router.Use(ErrorHandler(false))
+		router.POST("/test", authenticator.Require(ScopePaymentsWrite), func(c *gin.Context) {
+			c.String(http.StatusOK, c.GetString("actor"))
+		})
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/test", nil)
+		req.Header.Set("Authorization", tt.authorization)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, tt.wantStatus, w.Code, tt.name)
+		assert.Equal(t, tt.wantAuthenticate, w.Header().Get("WWW-Authenticate"), tt.name)
+		if tt.wantStatus == http.StatusOK {
+			assert.Equal(t, tt.wantActor, w.Body.String(), tt.name)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+
+	coreErrors "github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/errors"
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+// ErrorHandler renders the last error attached to the context with c.Error as a problem, when
+// the handler has not written a response itself. Errors of type gin.ErrorTypeBind are bad
+// requests, any other takes the status code of its kind. The detail starts with the message in
+// the error metadata; the error text is added for the domain kinds, which describe the request,
+// and for every error only when exposeErrors is set, so internal failures stay out of production.
+func ErrorHandler(exposeErrors bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		lastError := c.Errors.Last()
+		if lastError == nil || c.Writer.Written() {
+			return
+		}
+
+		status := http.StatusBadRequest
+		if !lastError.IsType(gin.ErrorTypeBind) {
+			status = StatusCode(lastError.Err)
+		}
+		if status >= http.StatusInternalServerError {
+			log.Errorf("request [%s %s] failed, error: %v", c.Request.Method, c.Request.URL.Path, lastError.Err)
+		}
+
+		problem := NewProblem(status)
+		problem.Instance = c.Request.URL.Path
+		problem.RequestId = c.GetString(RequestIdKey)
+		problem.InvalidParams = invalidParams(lastError.Err)
+
+		message, _ := lastError.Meta.(string)
+		problem.Detail = message
+		if exposeErrors || isClientError(lastError.Err) {
+			problem.Detail = fmt.Sprintf("%s: %v", message, lastError.Err)
+		}
+
+		c.Header("Content-Type", ProblemContentType)
+		c.JSON(status, problem)
+	}
+}
+
+// StatusCode maps the kind of err to an HTTP status code. Errors without a kind are unexpected
+// and become a 500.
+func StatusCode(err error) int {
+	kind, ok := coreErrors.KindOf(err)
+	if !ok {
+		return http.StatusInternalServerError
+	}
+
+	switch kind {
+	case coreErrors.KindNotFound:
+		return http.StatusNotFound
+	case coreErrors.KindConflict:
+		return http.StatusConflict
+	case coreErrors.KindValidation:
+		return http.StatusUnprocessableEntity
+	case coreErrors.KindUpstreamRejected:
+		return http.StatusBadGateway
+	case coreErrors.KindUpstreamUnavailable:
+		return http.StatusServiceUnavailable
+	case coreErrors.KindUnauthenticated:
+		return http.StatusUnauthorized
+	case coreErrors.KindForbidden:
+		return http.StatusForbidden
+	case coreErrors.KindRateLimited:
+		return http.StatusTooManyRequests
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func isClientError(err error) bool {
+	kind, _ := coreErrors.KindOf(err)
+	switch kind {
+	case coreErrors.KindNotFound, coreErrors.KindConflict, coreErrors.KindValidation, coreErrors.KindUnauthenticated, coreErrors.KindForbidden, coreErrors.KindRateLimited:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,215 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	coreErrors "github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/errors"
+	"github.com/asaskevich/govalidator"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	type args struct {
+		err          error
+		errType      gin.ErrorType
+		exposeErrors bool
+	}
+	tests := []struct {
+		name string
+		args
+		wantProblem Problem
+	}{
+		{
+			name: "should return not found with the error text",
+			args: args{err: fmt.Errorf("%w: order [123]", coreErrors.New(coreErrors.KindNotFound, "payment order not found"))},
+			wantProblem: Problem{
+				Type:     problemTypeBaseUrl + "not-found",
+				Title:    "Not Found",
+				Status:   http.StatusNotFound,
+				Detail:   "failed to handle request: payment order not found: order [123]",
+				Instance: "/test",
+			},
+		},
+		{
+			name: "should return conflict with the error text",
+			args: args{err: coreErrors.New(coreErrors.KindConflict, "payment order is not pending")},
+			wantProblem: Problem{
+				Type:     problemTypeBaseUrl + "conflict",
+				Title:    "Conflict",
+				Status:   http.StatusConflict,
+				Detail:   "failed to handle request: payment order is not pending",
+				Instance: "/test",
+			},
+		},
+		{
+			name: "should return unprocessable entity with the error text",
+			args: args{err: coreErrors.New(coreErrors.KindValidation, "payment order is not paid")},
+			wantProblem: Problem{
+				Type:     problemTypeBaseUrl + "validation",
+				Title:    "Unprocessable Entity",
+				Status:   http.StatusUnprocessableEntity,
+				Detail:   "failed to handle request: payment order is not paid",
+				Instance: "/test",
+			},
+		},
+		{
+			name: "should return unauthorized with the error text",
+			args: args{err: coreErrors.New(coreErrors.KindUnauthenticated, "bearer token is missing")},
+			wantProblem: Problem{
+				Type:     problemTypeBaseUrl + "unauthorized",
+				Title:    "Unauthorized",
+				Status:   http.StatusUnauthorized,
+				Detail:   "failed to handle request: bearer token is missing",
+				Instance: "/test",
+			},
+		},
+		{
+			name: "should return forbidden with the error text",
+			args: args{err: coreErrors.New(coreErrors.KindForbidden, "token does not grant the required scope")},
+			wantProblem: Problem{
+				Type:     problemTypeBaseUrl + "forbidden",
+				Title:    "Forbidden",
+				Status:   http.StatusForbidden,
+				Detail:   "failed to handle request: token does not grant the required scope",
+				Instance: "/test",
+			},
+		},
+		{
+			name: "should return too many requests with the error text",
+			args: args{err: coreErrors.New(coreErrors.KindRateLimited, "rate limit exceeded")},
+			wantProblem: Problem{
+				Type:     problemTypeBaseUrl + "too-many-requests",
+				Title:    "Too Many Requests",
+				Status:   http.StatusTooManyRequests,
+				Detail:   "failed to handle request: rate limit exceeded",
+				Instance: "/test",
+			},
+		},
+		{
+			name: "should return bad gateway without the error text",
+			args: args{err: coreErrors.Wrap(coreErrors.KindUpstreamRejected, errors.New("failed to refund mercado pago payment, status [400] non-2xx"))},
+			wantProblem: Problem{
+				Type:     problemTypeBaseUrl + "upstream-rejected",
+				Title:    "Bad Gateway",
+				Status:   http.StatusBadGateway,
+				Detail:   "failed to handle request",
+				Instance: "/test",
+			},
+		},
+		{
+			name: "should return service unavailable with the error text when errors are exposed",
+			args: args{err: coreErrors.Wrap(coreErrors.KindUpstreamUnavailable, errors.New("failed to call mercado pago broker, error: timeout")), exposeErrors: true},
+			wantProblem: Problem{
+				Type:     problemTypeBaseUrl + "upstream-unavailable",
+				Title:    "Service Unavailable",
+				Status:   http.StatusServiceUnavailable,
+				Detail:   "failed to handle request: failed to call mercado pago broker, error: timeout",
+				Instance: "/test",
+			},
+		},
+		{
+			name: "should return internal server error without the error text",
+			args: args{err: errors.New("internal error")},
+			wantProblem: Problem{
+				Type:     problemTypeBaseUrl + "internal",
+				Title:    "Internal Server Error",
+				Status:   http.StatusInternalServerError,
+				Detail:   "failed to handle request",
+				Instance: "/test",
+			},
+		},
+		{
+			name: "should return bad request with the invalid params",
+			args: args{
+				err: govalidator.Errors{
+					govalidator.Error{Name: "customerCpf", Err: errors.New("Customer CPF is required"), CustomErrorMessageExists: true},
+					govalidator.Errors{
+						govalidator.Error{Name: "name", Path: []string{"Product"}, Err: errors.New("Product name is required"), CustomErrorMessageExists: true},
+					},
+				},
+				errType: gin.ErrorTypeBind,
+			},
+			wantProblem: Problem{
+				Type:     problemTypeBaseUrl + "bad-request",
+				Title:    "Bad Request",
+				Status:   http.StatusBadRequest,
+				Detail:   "failed to handle request",
+				Instance: "/test",
+				InvalidParams: []InvalidParam{
+					{Name: "customerCpf", Reason: "Customer CPF is required"},
+					{Name: "Product.name", Reason: "Product name is required"},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		router := gin.New()
+		router.Use(ErrorHandler(tt.args.exposeErrors))
+		router.GET("/test", func(c *gin.Context) {
+			ginError := c.Error(tt.args.err).SetMeta("failed to handle request")
+			if tt.args.errType != 0 {
+				ginError.SetType(tt.args.errType)
+			}
+		})
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/test", nil)
+		router.ServeHTTP(w, req)
+
+		var problem Problem
+		_ = json.Unmarshal(w.Body.Bytes(), &problem)
+
+		assert.Equal(t, tt.wantProblem.Status, w.Code, tt.name)
+		assert.Equal(t, ProblemContentType, w.Header().Get("Content-Type"), tt.name)
+		assert.Equal(t, tt.wantProblem, problem, tt.name)
+	}
+}
+
+func TestErrorHandler_RequestId(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(RequestId(), ErrorHandler(false))
+	router.GET("/test", func(c *gin.Context) {
+		_ = c.Error(errors.New("internal error"))
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/test", nil)
+	req.Header.Set(RequestIdHeader, "a1b2c3")
+	router.ServeHTTP(w, req)
+
+	var problem Problem
+	_ = json.Unmarshal(w.Body.Bytes(), &problem)
+
+	assert.Equal(t, "a1b2c3", problem.RequestId)
+	assert.Equal(t, "a1b2c3", w.Header().Get(RequestIdHeader))
+}
+
+func TestErrorHandler_ResponseWritten(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(ErrorHandler(true))
+	router.GET("/test", func(c *gin.Context) {
+		_ = c.Error(errors.New("internal error"))
+		c.Status(http.StatusAccepted)
+		c.Writer.WriteHeaderNow()
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/test", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Empty(t, w.Body.String())
+}
@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/asaskevich/govalidator"
+)
+
+const (
+	ProblemContentType = "application/problem+json"
+
+	problemTypeBaseUrl = "https://github.com/IgorRamosBR/g73-techchallenge-payment/blob/master/docs/problems.md#"
+)
+
+// Problem is an RFC 7807 problem details object.
+type Problem struct {
+	Type          string         `json:"type"`
+	Title         string         `json:"title"`
+	Status        int            `json:"status"`
+	Detail        string         `json:"detail,omitempty"`
+	Instance      string         `json:"instance,omitempty"`
+	RequestId     string         `json:"requestId,omitempty"`
+	InvalidParams []InvalidParam `json:"invalid-params,omitempty"`
+}
+
+type InvalidParam struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+var problemTypes = map[int]string{
+	http.StatusBadRequest:          "bad-request",
+	http.StatusUnauthorized:        "unauthorized",
+	http.StatusForbidden:           "forbidden",
+	http.StatusNotFound:            "not-found",
+	http.StatusConflict:            "conflict",
+	http.StatusUnprocessableEntity: "validation",
+	http.StatusTooManyRequests:     "too-many-requests",
+	http.StatusInternalServerError: "internal",
+	http.StatusBadGateway:          "upstream-rejected",
+	http.StatusServiceUnavailable:  "upstream-unavailable",
+}
+
+func NewProblem(status int) Problem {
+	problemType, ok := problemTypes[status]
+	if !ok {
+		return Problem{Type: "about:blank", Title: http.StatusText(status), Status: status}
+	}
+
+	return Problem{
+		Type:   problemTypeBaseUrl + problemType,
+		Title:  http.StatusText(status),
+		Status: status,
+	}
+}
+
+// invalidParams lists the fields rejected by govalidator, using the json names of the fields.
+func invalidParams(err error) []InvalidParam {
+	var validationErrors govalidator.Errors
+	if !errors.As(err, &validationErrors) {
+		return nil
+	}
+
+	var params []InvalidParam
+	for _, validationError := range validationErrors.Errors() {
+		var fieldErrors govalidator.Errors
+		if errors.As(validationError, &fieldErrors) {
+			params = append(params, invalidParams(fieldErrors)...)
+			continue
+		}
+
+		var fieldError govalidator.Error
+		if !errors.As(validationError, &fieldError) {
+			continue
+		}
+		params = append(params, InvalidParam{
+			Name:   strings.Join(append(fieldError.Path, fieldError.Name), "."),
+			Reason: fieldError.Error(),
+		})
+	}
+
+	return params
+}
@@ -0,0 +1,120 @@
+package middleware
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	coreErrors "github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/errors"
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/infra/gateways"
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+// DefaultRateLimitPolicy applies to the routes without a policy of their own. The QR code
+// creation calls the payment broker under our sponsor id, so it has a policy of its own, as do
+// the webhooks, which are not authenticated by token.
+const (
+	DefaultRateLimitPolicy       = "default"
+	CreatePaymentRateLimitPolicy = "create-payment"
+	WebhookRateLimitPolicy       = "webhook"
+)
+
+var ErrRateLimited = coreErrors.New(coreErrors.KindRateLimited, "rate limit exceeded")
+
+// RateLimitPolicy limits the requests to a group of routes by caller and by IP. A limit with no
+// rate is not applied.
+type RateLimitPolicy struct {
+	PerClient gateways.RateLimit
+	PerIp     gateways.RateLimit
+}
+
+type RateLimiter interface {
+	// LimitIp limits the requests by client IP, before the caller is authenticated.
+	LimitIp(policy string) gin.HandlerFunc
+	// LimitClient limits the requests by the authenticated caller, so it follows Require.
+	LimitClient(policy string) gin.HandlerFunc
+	// AllowClient checks a call of the client under the same limits as LimitClient, for the calls
+	// not served over HTTP, returning how long to wait when it is not allowed.
+	AllowClient(policy, client string) (bool, time.Duration)
+}
+
+type rateLimiter struct {
+	rateLimiter gateways.RateLimiter
+	policies    map[string]RateLimitPolicy
+	actorKey    string
+}
+
+type RateLimiterConfig struct {
+	RateLimiter gateways.RateLimiter
+	Policies    map[string]RateLimitPolicy
+	// ActorKey is the context key holding the subject of the token.
+	ActorKey string
+}
+
+func NewRateLimiter(config RateLimiterConfig) RateLimiter {
+	return rateLimiter{
+		rateLimiter: config.RateLimiter,
+		policies:    config.Policies,
+		actorKey:    config.ActorKey,
+	}
+}
+
+func (r rateLimiter) LimitIp(policy string) gin.HandlerFunc {
+	policy, rateLimitPolicy := r.policy(policy)
+	return func(c *gin.Context) {
+		r.limit(c, fmt.Sprintf("ip:%s:%s", policy, c.ClientIP()), rateLimitPolicy.PerIp)
+	}
+}
+
+func (r rateLimiter) LimitClient(policy string) gin.HandlerFunc {
+	policy, rateLimitPolicy := r.policy(policy)
+	return func(c *gin.Context) {
+		client := c.GetString(r.actorKey)
+		if client == "" {
+			c.Next()
+			return
+		}
+		r.limit(c, fmt.Sprintf("client:%s:%s", policy, client), rateLimitPolicy.PerClient)
+	}
+}
+
+func (r rateLimiter) AllowClient(policy, client string) (bool, time.Duration) {
+	policy, rateLimitPolicy := r.policy(policy)
+	return r.allow(fmt.Sprintf("client:%s:%s", policy, client), rateLimitPolicy.PerClient)
+}
+
+func (r rateLimiter) policy(name string) (string, RateLimitPolicy) {
+	if rateLimitPolicy, ok := r.policies[name]; ok && name != "" {
+		return name, rateLimitPolicy
+	}
+	return DefaultRateLimitPolicy, r.policies[DefaultRateLimitPolicy]
+}
+
+func (r rateLimiter) limit(c *gin.Context, key string, limit gateways.RateLimit) {
+	allowed, retryAfter := r.allow(key, limit)
+	if !allowed {
+		c.Header("Retry-After", fmt.Sprint(int64(math.Max(1, math.Ceil(retryAfter.Seconds())))))
+		_ = c.Error(ErrRateLimited).SetMeta("too many requests")
+		c.Abort()
+		return
+	}
+
+	c.Next()
+}
+
+// allow lets the request through when the limiter fails, so an unavailable shared limiter does
+// not take the api down with it.
+func (r rateLimiter) allow(key string, limit gateways.RateLimit) (bool, time.Duration) {
+	if limit.Rate <= 0 {
+		return true, 0
+	}
+
+	allowed, retryAfter, err := r.rateLimiter.Allow(key, limit)
+	if err != nil {
+		log.Warnf("failed to check rate limit of [%s], letting request through, error: %v", key, err)
+		return true, 0
+	}
+
+	return allowed, retryAfter
+}
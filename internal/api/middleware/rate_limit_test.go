@@ -0,0 +1,153 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/infra/gateways"
+	mock_gateways "github.com/IgorRamosBR/g73-techchallenge-payment/internal/infra/gateways/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestRateLimiter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	limiter := mock_gateways.NewMockRateLimiter(ctrl)
+
+	policies := map[string]RateLimitPolicy{
+		DefaultRateLimitPolicy: {PerIp: gateways.RateLimit{Rate: 10, Burst: 10}},
+		"create-payment": {
+			PerClient: gateways.RateLimit{Rate: 1, Burst: 5},
+			PerIp:     gateways.RateLimit{Rate: 2, Burst: 10},
+		},
+	}
+
+	type allowCall struct {
+		key        string
+		limit      gateways.RateLimit
+		allowed    bool
+		retryAfter time.Duration
+		err        error
+	}
+	tests := []struct {
+		name           string
+		policy         string
+		actor          string
+		allowCalls     []allowCall
+		wantStatus     int
+		wantRetryAfter string
+	}{
+		{
+			name:   "should let request through within the limits",
+			policy: "create-payment",
+			actor:  "kiosk-1",
+			allowCalls: []allowCall{
+				{key: "ip:create-payment:192.0.2.1", limit: policies["create-payment"].PerIp, allowed: true},
+				{key: "client:create-payment:kiosk-1", limit: policies["create-payment"].PerClient, allowed: true},
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:   "should refuse request over the ip limit",
+			policy: "create-payment",
+			actor:  "kiosk-1",
+			allowCalls: []allowCall{
+				{key: "ip:create-payment:192.0.2.1", limit: policies["create-payment"].PerIp, retryAfter: 1500 * time.Millisecond},
+			},
+			wantStatus:     http.StatusTooManyRequests,
+			wantRetryAfter: "2",
+		},
+		{
+			name:   "should refuse request over the client limit",
+			policy: "create-payment",
+			actor:  "kiosk-1",
+			allowCalls: []allowCall{
+				{key: "ip:create-payment:192.0.2.1", limit: policies["create-payment"].PerIp, allowed: true},
+				{key: "client:create-payment:kiosk-1", limit: policies["create-payment"].PerClient, retryAfter: 10 * time.Millisecond},
+			},
+			wantStatus:     http.StatusTooManyRequests,
+			wantRetryAfter: "1",
+		},
+		{
+			name:   "should apply the default policy to the routes without one",
+			policy: "unknown",
+			actor:  "kiosk-1",
+			allowCalls: []allowCall{
+				{key: "ip:default:192.0.2.1", limit: policies[DefaultRateLimitPolicy].PerIp, allowed: true},
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:   "should not limit anonymous requests by client",
+			policy: "create-payment",
+			allowCalls: []allowCall{
+				{key: "ip:create-payment:192.0.2.1", limit: policies["create-payment"].PerIp, allowed: true},
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:   "should let request through when the limiter fails",
+			policy: "create-payment",
+			allowCalls: []allowCall{
+				{key: "ip:create-payment:192.0.2.1", limit: policies["create-payment"].PerIp, err: errors.New("internal error")},
+			},
+			wantStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		for _, call := range tt.allowCalls {
+			limiter.EXPECT().Allow(gomock.Eq(call.key), gomock.Eq(call.limit)).
+				Times(1).
+				Return(call.allowed, call.retryAfter, call.err)
+		}
+
+		rateLimiter := NewRateLimiter(RateLimiterConfig{RateLimiter: limiter, Policies: policies, ActorKey: "actor"})
+
+		router := gin.New()
+		router.Use(ErrorHandler(false))
+		router.POST("/test", rateLimiter.LimitIp(tt.policy), func(c *gin.Context) {
+			if tt.actor != "" {
+				c.Set("actor", tt.actor)
+			}
+		}, rateLimiter.LimitClient(tt.policy), func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/test", nil)
+		req.RemoteAddr = "192.0.2.1:1234"
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, tt.wantStatus, w.Code, tt.name)
+		assert.Equal(t, tt.wantRetryAfter, w.Header().Get("Retry-After"), tt.name)
+	}
+}
+
+func TestRateLimiter_AllowClient(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	limiter := mock_gateways.NewMockRateLimiter(ctrl)
+
+	policies := map[string]RateLimitPolicy{
+		DefaultRateLimitPolicy:       {PerIp: gateways.RateLimit{Rate: 10, Burst: 10}},
+		CreatePaymentRateLimitPolicy: {PerClient: gateways.RateLimit{Rate: 1, Burst: 5}},
+	}
+	rateLimiter := NewRateLimiter(RateLimiterConfig{RateLimiter: limiter, Policies: policies})
+
+	limiter.EXPECT().Allow(gomock.Eq("client:create-payment:kiosk-1"), gomock.Eq(policies[CreatePaymentRateLimitPolicy].PerClient)).
+		Times(1).
+		Return(false, 2*time.Second, nil)
+
+	allowed, retryAfter := rateLimiter.AllowClient(CreatePaymentRateLimitPolicy, "kiosk-1")
+	assert.False(t, allowed)
+	assert.Equal(t, 2*time.Second, retryAfter)
+
+	// the default policy has no client limit, so the limiter is not called
+	allowed, _ = rateLimiter.AllowClient("", "kiosk-1")
+	assert.True(t, allowed)
+}
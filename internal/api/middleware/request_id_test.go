@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestId(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name          string
+		requestId     string
+		wantRequestId func(t *testing.T, requestId string)
+	}{
+		{
+			name:      "should keep the request id sent by the client",
+			requestId: "a1b2c3",
+			wantRequestId: func(t *testing.T, requestId string) {
+				assert.Equal(t, "a1b2c3", requestId)
+			},
+		},
+		{
+			name:      "should create a request id when the client sends none",
+			requestId: "",
+			wantRequestId: func(t *testing.T, requestId string) {
+				assert.Len(t, requestId, 32)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		var contextRequestId string
+		router := gin.New()
+		router.Use(RequestId())
+		router.GET("/test", func(c *gin.Context) {
+			contextRequestId = c.GetString(RequestIdKey)
+		})
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/test", nil)
+		if tt.requestId != "" {
+			req.Header.Set(RequestIdHeader, tt.requestId)
+		}
+		router.ServeHTTP(w, req)
+
+		tt.wantRequestId(t, contextRequestId)
+		assert.Equal(t, contextRequestId, w.Header().Get(RequestIdHeader), tt.name)
+	}
+}
@@ -0,0 +1,118 @@
+package middleware
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMercadoPagoSignature(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	sign := func(secret, manifest string) string {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte(manifest))
+		return hex.EncodeToString(mac.Sum(nil))
+	}
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	staleTs := strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10)
+
+	tests := []struct {
+		name       string
+		query      string
+		body       string
+		requestId  string
+		signature  string
+		wantStatus int
+	}{
+		{
+			name:       "should refuse notification without signature",
+			query:      "?type=payment&data.id=123",
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "should refuse notification signed with another secret",
+			query:      "?type=payment&data.id=123",
+			requestId:  "request-1",
+			signature:  "ts=" + ts + ",v1=" + sign("other", "id:123;request-id:request-1;ts:"+ts+";"),
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "should refuse notification when the signed resource differs",
+			query:      "?type=payment&data.id=456",
+			requestId:  "request-1",
+			signature:  "ts=" + ts + ",v1=" + sign("secret", "id:123;request-id:request-1;ts:"+ts+";"),
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "should accept notification with a valid signature",
+			query:      "?type=payment&data.id=ABC",
+			requestId:  "request-1",
+			signature:  "ts=" + ts + ",v1=" + sign("secret", "id:abc;request-id:request-1;ts:"+ts+";"),
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "should refuse notification signed too long ago",
+			query:      "?type=payment&data.id=123",
+			requestId:  "request-1",
+			signature:  "ts=" + staleTs + ",v1=" + sign("secret", "id:123;request-id:request-1;ts:"+staleTs+";"),
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "should accept notification signed in milliseconds",
+			query:      "?type=payment&data.id=123",
+			requestId:  "request-1",
+			signature:  "ts=" + ts + "000,v1=" + sign("secret", "id:123;request-id:request-1;ts:"+ts+"000;"),
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "should sign the resource id sent in the body",
+			body:       `{"type":"payment","data":{"id":"123"}}`,
+			requestId:  "request-1",
+			signature:  "ts=" + ts + ",v1=" + sign("secret", "id:123;request-id:request-1;ts:"+ts+";"),
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "should let the unsigned IPN notification through",
+			query:      "?topic=payment&id=123",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "should let the unsigned legacy notification through",
+			body:       `{"payment_id":123}`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "should accept notification signed without the optional values",
+			signature:  "ts=" + ts + ", v1=" + sign("secret", "ts:"+ts+";"),
+			wantStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		router := gin.New()
+		router.Use(ErrorHandler(false))
+		router.POST("/test", MercadoPagoSignature("secret"), func(c *gin.Context) {
+			body, _ := c.GetRawData()
+			assert.Equal(t, tt.body, string(body), tt.name)
+			c.Status(http.StatusOK)
+		})
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/test"+tt.query, strings.NewReader(tt.body))
+		req.Header.Set("x-signature", tt.signature)
+		req.Header.Set("x-request-id", tt.requestId)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, tt.wantStatus, w.Code, tt.name)
+	}
+}
@@ -0,0 +1,274 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/api/middleware"
+)
+
+const (
+	openAPIVersion   = "3.0.3"
+	bearerAuthScheme = "bearerAuth"
+	signatureHeader  = "x-signature"
+	problemSchemaRef = "#/components/schemas/Problem"
+)
+
+// OpenAPI is the subset of the OpenAPI 3 document used to describe the routes.
+type OpenAPI struct {
+	OpenAPI    string                          `json:"openapi"`
+	Info       Info                            `json:"info"`
+	Paths      map[string]map[string]Operation `json:"paths"`
+	Components Components                      `json:"components"`
+}
+
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type Operation struct {
+	Summary     string                    `json:"summary"`
+	Description string                    `json:"description,omitempty"`
+	Tags        []string                  `json:"tags,omitempty"`
+	Deprecated  bool                      `json:"deprecated,omitempty"`
+	Parameters  []Parameter               `json:"parameters,omitempty"`
+	RequestBody *RequestBody              `json:"requestBody,omitempty"`
+	Responses   map[string]ResponseObject `json:"responses"`
+	Security    []map[string][]string     `json:"security,omitempty"`
+}
+
+type Parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required,omitempty"`
+	Schema   *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                 `json:"required,omitempty"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+type ResponseObject struct {
+	Description string               `json:"description"`
+	Headers     map[string]Header    `json:"headers,omitempty"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type Header struct {
+	Description string  `json:"description,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+}
+
+type Schema struct {
+	Ref        string             `json:"$ref,omitempty"`
+	Type       string             `json:"type,omitempty"`
+	Format     string             `json:"format,omitempty"`
+	Properties map[string]*Schema `json:"properties,omitempty"`
+	Required   []string           `json:"required,omitempty"`
+	Items      *Schema            `json:"items,omitempty"`
+}
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+)
+
+// NewOpenAPI describes the routes. The request and response bodies are described from their
+// types: json tags name the properties and govalidator required tags make them required.
+func NewOpenAPI(routes []Route) OpenAPI {
+	document := OpenAPI{
+		OpenAPI: openAPIVersion,
+		Info:    Info{Title: "g73-techchallenge-payment", Version: "2.0.0"},
+		Paths:   map[string]map[string]Operation{},
+		Components: Components{
+			Schemas:         map[string]*Schema{},
+			SecuritySchemes: map[string]SecurityScheme{bearerAuthScheme: {Type: "http", Scheme: "bearer", BearerFormat: "JWT"}},
+		},
+	}
+	document.Components.Schemas["Problem"] = document.schemaOf(reflect.TypeOf(middleware.Problem{}))
+
+	for _, route := range routes {
+		path := openAPIPath(route.Path)
+		if document.Paths[path] == nil {
+			document.Paths[path] = map[string]Operation{}
+		}
+		document.Paths[path][strings.ToLower(route.Method)] = document.operation(route)
+	}
+
+	return document
+}
+
+func (d OpenAPI) operation(route Route) Operation {
+	operation := Operation{
+		Summary:    route.Summary,
+		Tags:       []string{route.Tag},
+		Deprecated: route.Successor != "",
+		Responses:  map[string]ResponseObject{},
+	}
+
+	for _, segment := range strings.Split(route.Path, "/") {
+		if strings.HasPrefix(segment, ":") {
+			operation.Parameters = append(operation.Parameters, Parameter{
+				Name:     strings.TrimPrefix(segment, ":"),
+				In:       "path",
+				Required: true,
+				Schema:   &Schema{Type: "string"},
+			})
+		}
+	}
+	for _, name := range route.Query {
+		operation.Parameters = append(operation.Parameters, Parameter{Name: name, In: "query", Schema: &Schema{Type: "string"}})
+	}
+	// only the Webhooks v2 notifications are signed, the IPN ones are checked with the broker
+	if route.Signed {
+		operation.Parameters = append(operation.Parameters, Parameter{Name: signatureHeader, In: "header", Schema: &Schema{Type: "string"}})
+	}
+
+	if route.Request != nil {
+		operation.RequestBody = &RequestBody{
+			Content: map[string]MediaType{"application/json": {Schema: d.schemaOf(reflect.TypeOf(route.Request))}},
+		}
+	}
+
+	for _, response := range route.Responses {
+		responseObject := ResponseObject{Description: response.Description}
+		switch {
+		case response.Body != nil:
+			contentType := response.ContentType
+			if contentType == "" {
+				contentType = "application/json"
+			}
+			responseObject.Content = map[string]MediaType{contentType: {Schema: d.schemaOf(reflect.TypeOf(response.Body))}}
+		case response.Status >= http.StatusBadRequest:
+			responseObject = problemResponse(response.Description)
+		}
+		operation.Responses[fmt.Sprint(response.Status)] = responseObject
+	}
+
+	// the scopes of bearer schemes are not described by OpenAPI 3.0, so the description names it
+	if route.Scope != "" {
+		operation.Description = fmt.Sprintf("Requires the [%s] scope.", route.Scope)
+		operation.Security = []map[string][]string{{bearerAuthScheme: {}}}
+		operation.Responses[fmt.Sprint(http.StatusUnauthorized)] = problemResponse("Missing or invalid bearer token")
+		operation.Responses[fmt.Sprint(http.StatusForbidden)] = problemResponse("Token does not grant the required scope")
+	}
+	if route.Signed {
+		operation.Responses[fmt.Sprint(http.StatusUnauthorized)] = problemResponse("Notification signature does not match")
+	}
+
+	tooManyRequests := problemResponse("Rate limit exceeded")
+	tooManyRequests.Headers = map[string]Header{"Retry-After": {Description: "Seconds until the request may be repeated", Schema: &Schema{Type: "integer"}}}
+	operation.Responses[fmt.Sprint(http.StatusTooManyRequests)] = tooManyRequests
+
+	return operation
+}
+
+func problemResponse(description string) ResponseObject {
+	return ResponseObject{
+		Description: description,
+		Content:     map[string]MediaType{middleware.ProblemContentType: {Schema: &Schema{Ref: problemSchemaRef}}},
+	}
+}
+
+// schemaOf describes the type, adding the structs to the components so they are described once.
+func (d OpenAPI) schemaOf(t reflect.Type) *Schema {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t == rawMessageType:
+		return &Schema{Type: "object"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: d.schemaOf(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object"}
+	case reflect.Struct:
+		if _, ok := d.Components.Schemas[t.Name()]; !ok {
+			// registered before describing the fields, so recursive types end
+			d.Components.Schemas[t.Name()] = &Schema{}
+			*d.Components.Schemas[t.Name()] = d.structSchema(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + t.Name()}
+	default:
+		return &Schema{}
+	}
+}
+
+func (d OpenAPI) structSchema(t reflect.Type) Schema {
+	schema := Schema{Type: "object", Properties: map[string]*Schema{}}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if field.Anonymous && name == "" {
+			embedded := d.structSchema(field.Type)
+			for property, propertySchema := range embedded.Properties {
+				schema.Properties[property] = propertySchema
+			}
+			schema.Required = append(schema.Required, embedded.Required...)
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		schema.Properties[name] = d.schemaOf(field.Type)
+		if strings.Contains(field.Tag.Get("valid"), "required") {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+
+	return schema
+}
+
+// openAPIPath converts the gin path parameters, e.g. /payments/:orderId, to /payments/{orderId}.
+func openAPIPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") {
+			segments[i] = "{" + strings.TrimPrefix(segment, ":") + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
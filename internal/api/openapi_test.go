@@ -0,0 +1,92 @@
+package api
+
+import (
+	"encoding/json"
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/controllers"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+var update = flag.Bool("update", false, "update docs/openapi.json")
+
+const openAPIDocs = "../../docs/openapi.json"
+
+func TestNewApi_RoutesMatchOpenAPI(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router, err := NewApi(ApiConfig{})
+	assert.Nil(t, err)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/openapi.json", nil)
+	router.ServeHTTP(w, req)
+
+	var document OpenAPI
+	err = json.Unmarshal(w.Body.Bytes(), &document)
+	assert.Nil(t, err)
+
+	registered := map[string]bool{}
+	for _, route := range router.Routes() {
+		if route.Path == "/openapi.json" || route.Path == "/swagger" {
+			continue
+		}
+		operation := strings.ToLower(route.Method) + " " + openAPIPath(route.Path)
+		registered[operation] = true
+		assert.Contains(t, document.Paths[openAPIPath(route.Path)], strings.ToLower(route.Method), "route %s is not documented", operation)
+	}
+
+	for path, operations := range document.Paths {
+		for method := range operations {
+			assert.True(t, registered[method+" "+path], "documented operation %s %s has no route", method, path)
+		}
+	}
+}
+
+func TestNewOpenAPI_Docs(t *testing.T) {
+	document, err := json.MarshalIndent(NewOpenAPI(newRoutes(controllers.PaymentController{}, controllers.DeadLetterController{})), "", "  ")
+	assert.Nil(t, err)
+	document = append(document, '\n')
+
+	if *update {
+		err = os.WriteFile(openAPIDocs, document, 0644)
+		assert.Nil(t, err)
+	}
+
+	docs, err := os.ReadFile(openAPIDocs)
+	assert.Nil(t, err)
+	assert.Equal(t, string(docs), string(document), "docs/openapi.json is outdated, run go test ./internal/api -update")
+}
+
+func TestNewOpenAPI(t *testing.T) {
+	document := NewOpenAPI(newRoutes(controllers.PaymentController{}, controllers.DeadLetterController{}))
+
+	createPayment := document.Paths["/v2/payments"]["post"]
+	assert.Equal(t, "#/components/schemas/PaymentOrderDTO", createPayment.RequestBody.Content["application/json"].Schema.Ref)
+	assert.Equal(t, "#/components/schemas/Problem", createPayment.Responses["404"].Content["application/problem+json"].Schema.Ref)
+	assert.Equal(t, []string{"orderId", "customerCpf", "items", "totalAmount"}, document.Components.Schemas["PaymentOrderDTO"].Required)
+	assert.Equal(t, &Schema{Type: "string", Format: "date-time"}, document.Components.Schemas["PaymentDTO"].Properties["createdAt"])
+
+	getPayment := document.Paths["/v2/payments/{orderId}"]["get"]
+	assert.Equal(t, []Parameter{
+		{Name: "orderId", In: "path", Required: true, Schema: &Schema{Type: "string"}},
+		{Name: "waitFor", In: "query", Schema: &Schema{Type: "string"}},
+		{Name: "timeout", In: "query", Schema: &Schema{Type: "string"}},
+	}, getPayment.Parameters)
+	assert.False(t, getPayment.Deprecated)
+	assert.True(t, document.Paths["/v1/paymentOrder"]["post"].Deprecated)
+
+	assert.Equal(t, []map[string][]string{{"bearerAuth": {}}}, createPayment.Security)
+	assert.Equal(t, "Requires the [payments:write] scope.", createPayment.Description)
+	assert.Equal(t, "#/components/schemas/Problem", createPayment.Responses["403"].Content["application/problem+json"].Schema.Ref)
+
+	webhook := document.Paths["/v2/webhooks/mercadopago"]["post"]
+	assert.Nil(t, webhook.Security)
+	assert.Contains(t, webhook.Parameters, Parameter{Name: "x-signature", In: "header", Schema: &Schema{Type: "string"}})
+	assert.Contains(t, webhook.Responses, "401")
+}
@@ -0,0 +1,365 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/api/middleware"
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/controllers"
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/usecases/dto"
+	"github.com/gin-gonic/gin"
+)
+
+// Route is an endpoint of the api. The router and the OpenAPI document are both built from the
+// routes, so every endpoint is registered together with its description.
+type Route struct {
+	Method    string
+	Path      string
+	Summary   string
+	Tag       string
+	Query     []string
+	Request   any
+	Responses []Response
+	// Scope is the scope the caller token must grant, none for public routes.
+	Scope string
+	// Signed routes receive the Mercado Pago notifications, authenticated by their signature.
+	Signed bool
+	// RateLimit is the rate limit policy of the route, the default policy when empty.
+	RateLimit string
+	// Successor is the route replacing a deprecated one.
+	Successor string
+	Handler   gin.HandlerFunc
+}
+
+type Response struct {
+	Status      int
+	Description string
+	Body        any
+	// ContentType is the media type of the body, JSON when empty.
+	ContentType string
+}
+
+// paymentStatusStreamContentType is the media type of the payment status streams, whose events
+// carry the status as JSON.
+const paymentStatusStreamContentType = "text/event-stream"
+
+// paymentWaitQuery holds the payment order until it reaches one of the comma separated waitFor
+// statuses, or a final one, or the timeout elapses, 30s by default and 1m at most.
+var paymentWaitQuery = []string{"waitFor", "timeout"}
+
+// paymentSearchQuery filters the listed payment orders, created between from and to (RFC 3339),
+// and pages them with limit, 20 by default and 100 at most, and the nextCursor of the last page,
+// which only resumes a search with the same filters. Without status nor customerCpf the payment
+// orders are scanned unsorted, so a page may be short or empty before the last one.
+// The payments carry the customers CPF, so they are listed to admins only.
+var paymentSearchQuery = []string{"status", "customerCpf", "broker", "from", "to", "minAmount", "maxAmount", "limit", "cursor"}
+
+const (
+	paymentsTag    = "payments"
+	webhooksTag    = "webhooks"
+	deadLettersTag = "dead letters"
+)
+
+func newRoutes(paymentController controllers.PaymentController, deadLetterController controllers.DeadLetterController) []Route {
+	return []Route{
+		{
+			Method:  http.MethodPost,
+			Path:    "/v2/payments",
+			Summary: "Create a payment order and its QR code",
+			Tag:     paymentsTag,
+			Request: dto.PaymentOrderDTO{},
+			Responses: []Response{
+				{Status: http.StatusCreated, Description: "Payment order created", Body: dto.PaymentQRCode{}},
+				{Status: http.StatusBadRequest, Description: "Invalid payment order"},
+				{Status: http.StatusNotFound, Description: "Order not found"},
+				{Status: http.StatusUnprocessableEntity, Description: "Payment order does not match the order"},
+				{Status: http.StatusBadGateway, Description: "Payment broker refused the payment order"},
+				{Status: http.StatusServiceUnavailable, Description: "Payment broker unavailable"},
+			},
+			Scope:     middleware.ScopePaymentsWrite,
+			RateLimit: middleware.CreatePaymentRateLimitPolicy,
+			Handler:   paymentController.CreatePaymentHandler,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/v2/payments",
+			Summary: "List the payment orders matching the filters, a page at a time",
+			Tag:     paymentsTag,
+			Query:   paymentSearchQuery,
+			Responses: []Response{
+				{Status: http.StatusOK, Description: "Page of payment orders", Body: dto.PaymentPageDTO{}},
+				{Status: http.StatusBadRequest, Description: "Invalid filters"},
+				{Status: http.StatusUnprocessableEntity, Description: "Invalid cursor, or a cursor of other filters"},
+			},
+			Scope:   middleware.ScopeAdmin,
+			Handler: paymentController.SearchPaymentOrdersHandler,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/v2/payments/:orderId",
+			Summary: "Get a payment order, optionally waiting until it reaches one of the waitFor statuses",
+			Tag:     paymentsTag,
+			Query:   paymentWaitQuery,
+			Responses: []Response{
+				{Status: http.StatusOK, Description: "Payment order", Body: dto.PaymentDTO{}},
+				{Status: http.StatusBadRequest, Description: "Invalid waitFor or timeout"},
+				{Status: http.StatusNotFound, Description: "Payment order not found"},
+			},
+			Scope:   middleware.ScopePaymentsRead,
+			Handler: paymentController.GetPaymentOrderHandler,
+		},
+		{
+			Method:  http.MethodDelete,
+			Path:    "/v2/payments/:orderId",
+			Summary: "Cancel a pending payment order",
+			Tag:     paymentsTag,
+			Responses: []Response{
+				{Status: http.StatusNoContent, Description: "Payment order cancelled"},
+				{Status: http.StatusNotFound, Description: "Payment order not found"},
+				{Status: http.StatusConflict, Description: "Payment order is not pending"},
+			},
+			Scope:   middleware.ScopePaymentsWrite,
+			Handler: paymentController.CancelPaymentOrderHandler,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/v2/payments/:orderId/qrcode",
+			Summary: "Get the QR code of a pending payment order",
+			Tag:     paymentsTag,
+			Responses: []Response{
+				{Status: http.StatusOK, Description: "Payment QR code", Body: dto.PaymentQRCode{}},
+				{Status: http.StatusNotFound, Description: "Payment order not found"},
+				{Status: http.StatusConflict, Description: "Payment order is no longer awaiting payment"},
+			},
+			Scope:   middleware.ScopePaymentsRead,
+			Handler: paymentController.GetPaymentQRCodeHandler,
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/v2/payments/:orderId/refunds",
+			Summary: "Refund a paid payment order, fully when no amount is given",
+			Tag:     paymentsTag,
+			Request: dto.RefundRequestDTO{},
+			Responses: []Response{
+				{Status: http.StatusCreated, Description: "Refund created", Body: dto.RefundDTO{}},
+				{Status: http.StatusNotFound, Description: "Payment order not found"},
+				{Status: http.StatusConflict, Description: "Payment order changed while refunding"},
+				{Status: http.StatusUnprocessableEntity, Description: "Payment order cannot be refunded"},
+			},
+			Scope:   middleware.ScopePaymentsWrite,
+			Handler: paymentController.RefundPaymentHandler,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/v2/payments/:orderId/events",
+			Summary: "List the history of a payment order",
+			Tag:     paymentsTag,
+			Responses: []Response{
+				{Status: http.StatusOK, Description: "Payment events", Body: []dto.PaymentEventDTO{}},
+			},
+			Scope:   middleware.ScopePaymentsRead,
+			Handler: paymentController.GetPaymentEventsHandler,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/v2/payments/:orderId/stream",
+			Summary: "Stream the status of a payment order as server-sent events, until it is final",
+			Tag:     paymentsTag,
+			Responses: []Response{
+				{Status: http.StatusOK, Description: "Payment status events", Body: dto.PaymentStatusDTO{}, ContentType: paymentStatusStreamContentType},
+				{Status: http.StatusNotFound, Description: "Payment order not found"},
+			},
+			Scope:   middleware.ScopePaymentsRead,
+			Handler: paymentController.StreamPaymentStatusHandler,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/v2/payments/:orderId/ws",
+			Summary: "Watch the status of a payment order over a WebSocket, until it is final",
+			Tag:     paymentsTag,
+			Responses: []Response{
+				{Status: http.StatusSwitchingProtocols, Description: "Switched to a WebSocket sending the payment status as JSON messages"},
+				{Status: http.StatusNotFound, Description: "Payment order not found"},
+			},
+			Scope:   middleware.ScopePaymentsRead,
+			Handler: paymentController.WatchPaymentStatusHandler,
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/v2/webhooks/mercadopago",
+			Summary: "Receive a Mercado Pago notification",
+			Tag:     webhooksTag,
+			Query:   []string{"topic", "id", "type", "data.id"},
+			Request: dto.MercadoPagoNotificationDTO{},
+			Responses: []Response{
+				{Status: http.StatusOK, Description: "Notification accepted"},
+				{Status: http.StatusBadRequest, Description: "Invalid notification"},
+			},
+			Signed:    true,
+			RateLimit: middleware.WebhookRateLimitPolicy,
+			Handler:   paymentController.MercadoPagoWebhookHandler,
+		},
+		{
+			Method:    http.MethodPost,
+			Path:      "/v1/paymentOrder",
+			Summary:   "Create a payment order and its QR code",
+			Tag:       paymentsTag,
+			Request:   dto.PaymentOrderDTO{},
+			Responses: []Response{{Status: http.StatusOK, Description: "Payment order created", Body: dto.PaymentQRCode{}}},
+			Successor: "/v2/payments",
+			Scope:     middleware.ScopePaymentsWrite,
+			RateLimit: middleware.CreatePaymentRateLimitPolicy,
+			Handler:   paymentController.CreatePaymentOrderHandler,
+		},
+		{
+			Method:    http.MethodGet,
+			Path:      "/v1/payments",
+			Summary:   "List the payment orders matching the filters, a page at a time",
+			Tag:       paymentsTag,
+			Query:     paymentSearchQuery,
+			Responses: []Response{{Status: http.StatusOK, Description: "Page of payment orders", Body: dto.PaymentPageDTO{}}},
+			Successor: "/v2/payments",
+			Scope:     middleware.ScopeAdmin,
+			Handler:   paymentController.SearchPaymentOrdersHandler,
+		},
+		{
+			Method:    http.MethodDelete,
+			Path:      "/v1/paymentOrder/:orderId",
+			Summary:   "Cancel a pending payment order",
+			Tag:       paymentsTag,
+			Responses: []Response{{Status: http.StatusNoContent, Description: "Payment order cancelled"}},
+			Successor: "/v2/payments/{orderId}",
+			Scope:     middleware.ScopePaymentsWrite,
+			Handler:   paymentController.CancelPaymentOrderHandler,
+		},
+		{
+			Method:    http.MethodPost,
+			Path:      "/v1/payment/:id/notify",
+			Summary:   "Receive a Mercado Pago notification",
+			Tag:       webhooksTag,
+			Query:     []string{"topic", "id", "type", "data.id"},
+			Request:   dto.MercadoPagoNotificationDTO{},
+			Responses: []Response{{Status: http.StatusOK, Description: "Notification accepted"}},
+			Successor: "/v2/webhooks/mercadopago",
+			Signed:    true,
+			RateLimit: middleware.WebhookRateLimitPolicy,
+			Handler:   paymentController.NotifyPaymentHandler,
+		},
+		{
+			Method:    http.MethodPost,
+			Path:      "/v1/payment/:id/refunds",
+			Summary:   "Refund a paid payment order, fully when no amount is given",
+			Tag:       paymentsTag,
+			Request:   dto.RefundRequestDTO{},
+			Responses: []Response{{Status: http.StatusCreated, Description: "Refund created", Body: dto.RefundDTO{}}},
+			Successor: "/v2/payments/{orderId}/refunds",
+			Scope:     middleware.ScopePaymentsWrite,
+			Handler:   paymentController.RefundPaymentHandler,
+		},
+		{
+			Method:    http.MethodGet,
+			Path:      "/v1/payment/:id",
+			Summary:   "Get a payment order, optionally waiting until it reaches one of the waitFor statuses",
+			Tag:       paymentsTag,
+			Query:     paymentWaitQuery,
+			Responses: []Response{{Status: http.StatusOK, Description: "Payment order", Body: dto.PaymentDTO{}}},
+			Successor: "/v2/payments/{orderId}",
+			Scope:     middleware.ScopePaymentsRead,
+			Handler:   paymentController.GetPaymentOrderHandler,
+		},
+		{
+			Method:    http.MethodGet,
+			Path:      "/v1/payment/:id/events",
+			Summary:   "List the history of a payment order",
+			Tag:       paymentsTag,
+			Responses: []Response{{Status: http.StatusOK, Description: "Payment events", Body: []dto.PaymentEventDTO{}}},
+			Successor: "/v2/payments/{orderId}/events",
+			Scope:     middleware.ScopePaymentsRead,
+			Handler:   paymentController.GetPaymentEventsHandler,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/v1/payment/:id/stream",
+			Summary: "Stream the status of a payment order as server-sent events, until it is final",
+			Tag:     paymentsTag,
+			Responses: []Response{
+				{Status: http.StatusOK, Description: "Payment status events", Body: dto.PaymentStatusDTO{}, ContentType: paymentStatusStreamContentType},
+			},
+			Successor: "/v2/payments/{orderId}/stream",
+			Scope:     middleware.ScopePaymentsRead,
+			Handler:   paymentController.StreamPaymentStatusHandler,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/v1/payment/:id/ws",
+			Summary: "Watch the status of a payment order over a WebSocket, until it is final",
+			Tag:     paymentsTag,
+			Responses: []Response{
+				{Status: http.StatusSwitchingProtocols, Description: "Switched to a WebSocket sending the payment status as JSON messages"},
+			},
+			Successor: "/v2/payments/{orderId}/ws",
+			Scope:     middleware.ScopePaymentsRead,
+			Handler:   paymentController.WatchPaymentStatusHandler,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/v1/admin/deadLetters",
+			Summary: "List the dead letters, optionally of one type",
+			Tag:     deadLettersTag,
+			Query:   []string{"type"},
+			Responses: []Response{
+				{Status: http.StatusOK, Description: "Dead letters", Body: []dto.DeadLetterDTO{}},
+			},
+			Scope:   middleware.ScopeAdmin,
+			Handler: deadLetterController.GetDeadLettersHandler,
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/v1/admin/deadLetters/replay",
+			Summary: "Replay the dead letters, optionally of one type",
+			Tag:     deadLettersTag,
+			Query:   []string{"type"},
+			Responses: []Response{
+				{Status: http.StatusOK, Description: "Replay report", Body: dto.DeadLetterReplayReport{}},
+			},
+			Scope:   middleware.ScopeAdmin,
+			Handler: deadLetterController.ReplayDeadLettersHandler,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/v1/admin/deadLetters/:messageId",
+			Summary: "Get a dead letter",
+			Tag:     deadLettersTag,
+			Responses: []Response{
+				{Status: http.StatusOK, Description: "Dead letter", Body: dto.DeadLetterDTO{}},
+				{Status: http.StatusNotFound, Description: "Dead letter not found"},
+			},
+			Scope:   middleware.ScopeAdmin,
+			Handler: deadLetterController.GetDeadLetterHandler,
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/v1/admin/deadLetters/:messageId/replay",
+			Summary: "Replay a dead letter",
+			Tag:     deadLettersTag,
+			Responses: []Response{
+				{Status: http.StatusNoContent, Description: "Dead letter replayed"},
+				{Status: http.StatusNotFound, Description: "Dead letter not found"},
+				{Status: http.StatusUnprocessableEntity, Description: "Dead letter cannot be replayed"},
+			},
+			Scope:   middleware.ScopeAdmin,
+			Handler: deadLetterController.ReplayDeadLetterHandler,
+		},
+		{
+			Method:  http.MethodDelete,
+			Path:    "/v1/admin/deadLetters/:messageId",
+			Summary: "Discard a dead letter",
+			Tag:     deadLettersTag,
+			Responses: []Response{
+				{Status: http.StatusNoContent, Description: "Dead letter discarded"},
+				{Status: http.StatusNotFound, Description: "Dead letter not found"},
+			},
+			Scope:   middleware.ScopeAdmin,
+			Handler: deadLetterController.DiscardDeadLetterHandler,
+		},
+	}
+}
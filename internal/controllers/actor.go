@@ -0,0 +1,14 @@
+package controllers
+
+import "github.com/gin-gonic/gin"
+
+// ActorKey is the gin context key holding the caller identity, used to tell who changed a payment.
+const ActorKey = "actor"
+
+// getActor falls back to the client IP, taken from X-Forwarded-For only behind the trusted proxies.
+func getActor(c *gin.Context) string {
+	if actor := c.GetString(ActorKey); actor != "" {
+		return actor
+	}
+	return c.ClientIP()
+}
@@ -0,0 +1,79 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/entities"
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/usecases"
+	"github.com/gin-gonic/gin"
+)
+
+type DeadLetterController struct {
+	deadLetterUsecase usecases.DeadLetterUseCase
+}
+
+func NewDeadLetterController(deadLetterUsecase usecases.DeadLetterUseCase) DeadLetterController {
+	return DeadLetterController{
+		deadLetterUsecase: deadLetterUsecase,
+	}
+}
+
+func (d DeadLetterController) GetDeadLettersHandler(c *gin.Context) {
+	deadLetterType := entities.DeadLetterType(c.Query("type"))
+
+	deadLetters, err := d.deadLetterUsecase.GetDeadLetters(deadLetterType)
+	if err != nil {
+		handleErrorResponse(c, "failed to get dead letters", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, deadLetters)
+}
+
+func (d DeadLetterController) GetDeadLetterHandler(c *gin.Context) {
+	messageId := c.Param("messageId")
+
+	deadLetter, err := d.deadLetterUsecase.GetDeadLetter(messageId)
+	if err != nil {
+		handleErrorResponse(c, "failed to get dead letter", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, deadLetter)
+}
+
+func (d DeadLetterController) ReplayDeadLetterHandler(c *gin.Context) {
+	messageId := c.Param("messageId")
+
+	err := d.deadLetterUsecase.ReplayDeadLetter(messageId)
+	if err != nil {
+		handleErrorResponse(c, "failed to replay dead letter", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (d DeadLetterController) ReplayDeadLettersHandler(c *gin.Context) {
+	deadLetterType := entities.DeadLetterType(c.Query("type"))
+
+	report, err := d.deadLetterUsecase.ReplayDeadLetters(deadLetterType)
+	if err != nil {
+		handleErrorResponse(c, "failed to replay dead letters", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+func (d DeadLetterController) DiscardDeadLetterHandler(c *gin.Context) {
+	messageId := c.Param("messageId")
+
+	err := d.deadLetterUsecase.DiscardDeadLetter(messageId)
+	if err != nil {
+		handleErrorResponse(c, "failed to discard dead letter", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,174 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/api/middleware"
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/entities"
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/usecases"
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/usecases/dto"
+	mock_usecases "github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/usecases/mocks"
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/infra/gateways"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestDeadLetterController_GetDeadLettersHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	deadLetterUseCase := mock_usecases.NewMockDeadLetterUseCase(ctrl)
+	deadLetterController := NewDeadLetterController(deadLetterUseCase)
+
+	createdAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	deadLetterUseCase.EXPECT().
+		GetDeadLetters(gomock.Eq(entities.DeadLetterTypeOrderNotification)).
+		Times(1).
+		Return([]dto.DeadLetterDTO{
+			{
+				MessageId: "1714564800000000000-a1b2c3d4",
+				Type:      entities.DeadLetterTypeOrderNotification,
+				Payload:   []byte(`{"orderId":123,"status":"PAID"}`),
+				Attempts:  1,
+				LastError: "failed to call order api, status [503] non-2xx",
+				CreatedAt: createdAt,
+				UpdatedAt: createdAt,
+			},
+		}, nil)
+
+	router := createDeadLetterRouter(deadLetterController)
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/v1/admin/deadLetters?type=ORDER_NOTIFICATION", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, `[{"messageId":"1714564800000000000-a1b2c3d4","type":"ORDER_NOTIFICATION","payload":{"orderId":123,"status":"PAID"},"attempts":1,"lastError":"failed to call order api, status [503] non-2xx","createdAt":"2024-05-01T12:00:00Z","updatedAt":"2024-05-01T12:00:00Z"}]`, w.Body.String())
+}
+
+func TestDeadLetterController_ReplayDeadLetterHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	deadLetterUseCase := mock_usecases.NewMockDeadLetterUseCase(ctrl)
+	deadLetterController := NewDeadLetterController(deadLetterUseCase)
+
+	type want struct {
+		statusCode int
+		respBody   string
+	}
+	type deadLetterUseCaseCall struct {
+		err error
+	}
+	tests := []struct {
+		name string
+		want
+		deadLetterUseCaseCall
+	}{
+		{
+			name: "should return not found when the dead letter does not exist",
+			want: want{
+				statusCode: 404,
+				respBody:   `{"type":"https://github.com/IgorRamosBR/g73-techchallenge-payment/blob/master/docs/problems.md#not-found","title":"Not Found","status":404,"detail":"failed to replay dead letter: dead letter not found","instance":"/v1/admin/deadLetters/1714564800000000000-a1b2c3d4/replay"}`,
+			},
+			deadLetterUseCaseCall: deadLetterUseCaseCall{
+				err: gateways.ErrDeadLetterNotFound,
+			},
+		},
+		{
+			name: "should return unprocessable entity when the dead letter cannot be replayed",
+			want: want{
+				statusCode: 422,
+				respBody:   `{"type":"https://github.com/IgorRamosBR/g73-techchallenge-payment/blob/master/docs/problems.md#validation","title":"Unprocessable Entity","status":422,"detail":"failed to replay dead letter: dead letter cannot be replayed","instance":"/v1/admin/deadLetters/1714564800000000000-a1b2c3d4/replay"}`,
+			},
+			deadLetterUseCaseCall: deadLetterUseCaseCall{
+				err: usecases.ErrDeadLetterNotReplayable,
+			},
+		},
+		{
+			name: "should return internal server error when the replay fails",
+			want: want{
+				statusCode: 500,
+				respBody:   `{"type":"https://github.com/IgorRamosBR/g73-techchallenge-payment/blob/master/docs/problems.md#internal","title":"Internal Server Error","status":500,"detail":"failed to replay dead letter: failed to call order api, status [503] non-2xx","instance":"/v1/admin/deadLetters/1714564800000000000-a1b2c3d4/replay"}`,
+			},
+			deadLetterUseCaseCall: deadLetterUseCaseCall{
+				err: errors.New("failed to call order api, status [503] non-2xx"),
+			},
+		},
+		{
+			name: "should return no content when the dead letter is replayed",
+			want: want{
+				statusCode: 204,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		deadLetterUseCase.EXPECT().
+			ReplayDeadLetter(gomock.Eq("1714564800000000000-a1b2c3d4")).
+			Times(1).
+			Return(tt.deadLetterUseCaseCall.err)
+
+		router := createDeadLetterRouter(deadLetterController)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/v1/admin/deadLetters/1714564800000000000-a1b2c3d4/replay", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, tt.want.statusCode, w.Code)
+		assert.Equal(t, tt.want.respBody, w.Body.String())
+	}
+}
+
+func TestDeadLetterController_ReplayDeadLettersHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	deadLetterUseCase := mock_usecases.NewMockDeadLetterUseCase(ctrl)
+	deadLetterController := NewDeadLetterController(deadLetterUseCase)
+
+	deadLetterUseCase.EXPECT().
+		ReplayDeadLetters(gomock.Eq(entities.DeadLetterType(""))).
+		Times(1).
+		Return(dto.DeadLetterReplayReport{
+			Replayed: 1,
+			Failed:   []dto.DeadLetterReplayFailure{{MessageId: "2", Error: "order not found"}},
+		}, nil)
+
+	router := createDeadLetterRouter(deadLetterController)
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/v1/admin/deadLetters/replay", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, `{"replayed":1,"failed":[{"messageId":"2","error":"order not found"}]}`, w.Body.String())
+}
+
+func TestDeadLetterController_DiscardDeadLetterHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	deadLetterUseCase := mock_usecases.NewMockDeadLetterUseCase(ctrl)
+	deadLetterController := NewDeadLetterController(deadLetterUseCase)
+
+	deadLetterUseCase.EXPECT().
+		DiscardDeadLetter(gomock.Eq("1714564800000000000-a1b2c3d4")).
+		Times(1).
+		Return(nil)
+
+	router := createDeadLetterRouter(deadLetterController)
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("DELETE", "/v1/admin/deadLetters/1714564800000000000-a1b2c3d4", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, 204, w.Code)
+}
+
+func createDeadLetterRouter(deadLetterController DeadLetterController) *gin.Engine {
+	router := gin.Default()
+	router.Use(middleware.ErrorHandler(true))
+	admin := router.Group("/v1/admin")
+	{
+		admin.GET("/deadLetters", deadLetterController.GetDeadLettersHandler)
+		admin.POST("/deadLetters/replay", deadLetterController.ReplayDeadLettersHandler)
+		admin.GET("/deadLetters/:messageId", deadLetterController.GetDeadLetterHandler)
+		admin.POST("/deadLetters/:messageId/replay", deadLetterController.ReplayDeadLetterHandler)
+		admin.DELETE("/deadLetters/:messageId", deadLetterController.DiscardDeadLetterHandler)
+	}
+	return router
+}
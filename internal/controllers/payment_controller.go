@@ -2,6 +2,8 @@ package controllers
 
 import (
 	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"strconv"
 
@@ -11,63 +13,261 @@ import (
 )
 
 type PaymentController struct {
-	paymentUsecase usecases.PaymentUseCase
+	paymentUsecase      usecases.PaymentUseCase
+	notificationUsecase usecases.NotificationUseCase
 }
 
-func NewPaymentController(paymentUsecase usecases.PaymentUseCase) PaymentController {
+func NewPaymentController(paymentUsecase usecases.PaymentUseCase, notificationUsecase usecases.NotificationUseCase) PaymentController {
 	return PaymentController{
-		paymentUsecase: paymentUsecase,
+		paymentUsecase:      paymentUsecase,
+		notificationUsecase: notificationUsecase,
 	}
 }
 
 func (p PaymentController) CreatePaymentOrderHandler(c *gin.Context) {
-	var paymentOrder dto.PaymentOrderDTO
-	err := c.ShouldBindJSON(&paymentOrder)
+	paymentOrder, ok := bindPaymentOrder(c)
+	if !ok {
+		return
+	}
+
+	paymentQRCode, err := p.paymentUsecase.CreatePaymentOrder(paymentOrder)
 	if err != nil {
-		handleBadRequestResponse(c, "failed to bind payment order payload", err)
+		handleErrorResponse(c, "failed to create payment order", err)
 		return
 	}
 
-	valid, err := paymentOrder.ValidatePaymentOrder()
-	if !valid {
-		handleBadRequestResponse(c, "invalid payment order payload", err)
+	c.JSON(http.StatusOK, dto.PaymentQRCode{QRCode: paymentQRCode})
+}
+
+// CreatePaymentHandler is the v2 version of CreatePaymentOrderHandler, answering with the
+// location of the created payment.
+func (p PaymentController) CreatePaymentHandler(c *gin.Context) {
+	paymentOrder, ok := bindPaymentOrder(c)
+	if !ok {
 		return
 	}
 
 	paymentQRCode, err := p.paymentUsecase.CreatePaymentOrder(paymentOrder)
 	if err != nil {
-		handleInternalServerResponse(c, "failed to create payment order", err)
+		handleErrorResponse(c, "failed to create payment order", err)
 		return
 	}
 
-	c.JSON(http.StatusOK, dto.PaymentQRCode{QRCode: paymentQRCode})
+	c.Header("Location", fmt.Sprintf("%s/%d", c.Request.URL.Path, paymentOrder.OrderId))
+	c.JSON(http.StatusCreated, dto.PaymentQRCode{QRCode: paymentQRCode})
+}
+
+// GetPaymentOrderHandler answers with the payment order, once it reaches one of the [waitFor]
+// statuses when they are given.
+func (p PaymentController) GetPaymentOrderHandler(c *gin.Context) {
+	orderId, ok := getOrderId(c)
+	if !ok {
+		return
+	}
+
+	if c.Query("waitFor") != "" {
+		p.waitForPaymentStatus(c, orderId)
+		return
+	}
+
+	payment, err := p.paymentUsecase.GetPaymentOrder(orderId)
+	if err != nil {
+		handleErrorResponse(c, "failed to get payment order", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, payment)
+}
+
+func (p PaymentController) SearchPaymentOrdersHandler(c *gin.Context) {
+	var search dto.PaymentSearchDTO
+	err := c.ShouldBindQuery(&search)
+	if err != nil {
+		handleBadRequestResponse(c, "failed to bind payment search", err)
+		return
+	}
+
+	valid, err := search.ValidatePaymentSearch()
+	if !valid {
+		handleBadRequestResponse(c, "invalid payment search", err)
+		return
+	}
+
+	page, err := p.paymentUsecase.SearchPaymentOrders(search)
+	if err != nil {
+		handleErrorResponse(c, "failed to search payment orders", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+func (p PaymentController) GetPaymentQRCodeHandler(c *gin.Context) {
+	orderId, ok := getOrderId(c)
+	if !ok {
+		return
+	}
+
+	paymentQRCode, err := p.paymentUsecase.GetPaymentQRCode(orderId)
+	if err != nil {
+		handleErrorResponse(c, "failed to get payment qrcode", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, paymentQRCode)
 }
 
 func (p PaymentController) NotifyPaymentHandler(c *gin.Context) {
-	id := c.Param("id")
-	if id == "" {
-		handleBadRequestResponse(c, "[id] path parameter is required", errors.New("id is missing"))
+	_, ok := getOrderId(c)
+	if !ok {
 		return
 	}
 
-	orderId, err := strconv.Atoi(id)
+	p.enqueueNotification(c, true)
+}
+
+// MercadoPagoWebhookHandler is the v2 version of NotifyPaymentHandler, which refuses the legacy
+// notifications.
+func (p PaymentController) MercadoPagoWebhookHandler(c *gin.Context) {
+	p.enqueueNotification(c, false)
+}
+
+// enqueueNotification queues the notification to be verified with the broker. The order in the url
+// is not trusted, the payment is matched to its order by the external reference the broker holds.
+func (p PaymentController) enqueueNotification(c *gin.Context, acceptLegacy bool) {
+	payload, err := c.GetRawData()
 	if err != nil {
-		handleBadRequestResponse(c, "[id] path parameter is invalid", err)
+		handleBadRequestResponse(c, "failed to read payment notification payload", err)
 		return
 	}
 
-	var paymentNotification dto.PaymentNotificationDTO
-	err = c.ShouldBindJSON(&paymentNotification)
+	paymentNotification, err := dto.ParseMercadoPagoNotification(c.Request.URL.Query(), payload)
 	if err != nil {
 		handleBadRequestResponse(c, "failed to bind payment notification payload", err)
 		return
 	}
 
-	err = p.paymentUsecase.NotifyPayment(orderId, paymentNotification.PaymentId)
+	if paymentNotification.IsLegacy() && !acceptLegacy {
+		handleBadRequestResponse(c, "invalid payment notification payload", dto.ErrNotificationTopicMissing)
+		return
+	}
+
+	if !paymentNotification.IsRelevant() {
+		c.Status(http.StatusOK)
+		return
+	}
+
+	brokerNotification, err := paymentNotification.ToBrokerNotification(payload, c.GetHeader("x-request-id"))
 	if err != nil {
-		handleInternalServerResponse(c, "failed to notify payment", err)
+		handleBadRequestResponse(c, "invalid payment notification payload", err)
+		return
+	}
+
+	err = p.notificationUsecase.EnqueueBrokerNotification(brokerNotification)
+	if err != nil {
+		handleErrorResponse(c, "failed to enqueue payment notification", err)
 		return
 	}
 
 	c.Status(http.StatusOK)
 }
+
+func (p PaymentController) RefundPaymentHandler(c *gin.Context) {
+	orderId, ok := getOrderId(c)
+	if !ok {
+		return
+	}
+
+	// the body is optional, and its length is unknown when it is chunked, so no body is only
+	// told apart once reading it ends at once
+	var refundRequest dto.RefundRequestDTO
+	err := c.ShouldBindJSON(&refundRequest)
+	if err != nil && !errors.Is(err, io.EOF) {
+		handleBadRequestResponse(c, "failed to bind refund payload", err)
+		return
+	}
+
+	valid, err := refundRequest.ValidateRefundRequest()
+	if !valid {
+		handleBadRequestResponse(c, "invalid refund payload", err)
+		return
+	}
+
+	refund, err := p.paymentUsecase.RefundPayment(orderId, refundRequest, getActor(c))
+	if err != nil {
+		handleErrorResponse(c, "failed to refund payment", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, refund)
+}
+
+func (p PaymentController) CancelPaymentOrderHandler(c *gin.Context) {
+	orderId, ok := getOrderId(c)
+	if !ok {
+		return
+	}
+
+	err := p.paymentUsecase.CancelPaymentOrder(orderId, getActor(c))
+	if err != nil {
+		handleErrorResponse(c, "failed to cancel payment order", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (p PaymentController) GetPaymentEventsHandler(c *gin.Context) {
+	orderId, ok := getOrderId(c)
+	if !ok {
+		return
+	}
+
+	paymentEvents, err := p.paymentUsecase.GetPaymentEvents(orderId)
+	if err != nil {
+		handleErrorResponse(c, "failed to get payment events", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, paymentEvents)
+}
+
+func bindPaymentOrder(c *gin.Context) (dto.PaymentOrderDTO, bool) {
+	var paymentOrder dto.PaymentOrderDTO
+	err := c.ShouldBindJSON(&paymentOrder)
+	if err != nil {
+		handleBadRequestResponse(c, "failed to bind payment order payload", err)
+		return dto.PaymentOrderDTO{}, false
+	}
+
+	valid, err := paymentOrder.ValidatePaymentOrder()
+	if !valid {
+		handleBadRequestResponse(c, "invalid payment order payload", err)
+		return dto.PaymentOrderDTO{}, false
+	}
+
+	return paymentOrder, true
+}
+
+// getOrderId reads the order id from the path, named [orderId] on the v2 routes and on the v1
+// cancel route, and [id] on the other v1 routes.
+func getOrderId(c *gin.Context) (int, bool) {
+	name := "orderId"
+	id, ok := c.Params.Get(name)
+	if !ok {
+		name = "id"
+		id = c.Param(name)
+	}
+	if id == "" {
+		handleBadRequestResponse(c, fmt.Sprintf("[%s] path parameter is required", name), fmt.Errorf("%s is missing", name))
+		return 0, false
+	}
+
+	orderId, err := strconv.Atoi(id)
+	if err != nil {
+		handleBadRequestResponse(c, fmt.Sprintf("[%s] path parameter is invalid", name), err)
+		return 0, false
+	}
+
+	return orderId, true
+}
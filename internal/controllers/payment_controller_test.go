@@ -8,9 +8,15 @@ import (
 	"os"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/api/middleware"
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/entities"
+	coreErrors "github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/errors"
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/usecases"
 	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/usecases/dto"
 	mock_usecases "github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/usecases/mocks"
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/infra/gateways"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/mock/gomock"
@@ -19,10 +25,13 @@ import (
 var paymentRequestValid, _ = os.ReadFile("./testdata/payment_request_valid.json")
 var paymentRequestInvalid, _ = os.ReadFile("./testdata/payment_request_invalid.json")
 
+const problemTypeUrl = "https://github.com/IgorRamosBR/g73-techchallenge-payment/blob/master/docs/problems.md#"
+
 func TestPaymentController_CreatePaymentOrderHandler(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	paymentUseCase := mock_usecases.NewMockPaymentUseCase(ctrl)
-	paymentController := NewPaymentController(paymentUseCase)
+	notificationUseCase := mock_usecases.NewMockNotificationUseCase(ctrl)
+	paymentController := NewPaymentController(paymentUseCase, notificationUseCase)
 
 	type args struct {
 		reqBody string
@@ -50,7 +59,7 @@ func TestPaymentController_CreatePaymentOrderHandler(t *testing.T) {
 			},
 			want: want{
 				statusCode: 400,
-				respBody:   `{"message":"failed to bind payment order payload","error":"invalid character '\u003c' looking for beginning of value"}`,
+				respBody:   `{"type":"https://github.com/IgorRamosBR/g73-techchallenge-payment/blob/master/docs/problems.md#bad-request","title":"Bad Request","status":400,"detail":"failed to bind payment order payload: invalid character '\u003c' looking for beginning of value","instance":"/v1/paymentOrder"}`,
 			},
 		},
 		{
@@ -60,7 +69,7 @@ func TestPaymentController_CreatePaymentOrderHandler(t *testing.T) {
 			},
 			want: want{
 				statusCode: 400,
-				respBody:   `{"message":"invalid payment order payload","error":"Customer CPF is required"}`,
+				respBody:   `{"type":"https://github.com/IgorRamosBR/g73-techchallenge-payment/blob/master/docs/problems.md#bad-request","title":"Bad Request","status":400,"detail":"invalid payment order payload: Customer CPF is required","instance":"/v1/paymentOrder","invalid-params":[{"name":"customerCpf","reason":"Customer CPF is required"}]}`,
 			},
 		},
 		{
@@ -70,7 +79,7 @@ func TestPaymentController_CreatePaymentOrderHandler(t *testing.T) {
 			},
 			want: want{
 				statusCode: 500,
-				respBody:   `{"message":"failed to create payment order","error":"internal server error"}`,
+				respBody:   `{"type":"https://github.com/IgorRamosBR/g73-techchallenge-payment/blob/master/docs/problems.md#internal","title":"Internal Server Error","status":500,"detail":"failed to create payment order: internal server error","instance":"/v1/paymentOrder"}`,
 			},
 			paymentUseCaseCall: paymentUseCaseCall{
 				paymentOrder: createPaymentOrder(),
@@ -79,6 +88,54 @@ func TestPaymentController_CreatePaymentOrderHandler(t *testing.T) {
 				err:          errors.New("internal server error"),
 			},
 		},
+		{
+			name: "should return unprocessable entity when payment order does not match the order",
+			args: args{
+				reqBody: string(paymentRequestValid),
+			},
+			want: want{
+				statusCode: 422,
+				respBody:   `{"type":"https://github.com/IgorRamosBR/g73-techchallenge-payment/blob/master/docs/problems.md#validation","title":"Unprocessable Entity","status":422,"detail":"failed to create payment order: payment order does not match the order: items differ from the order items","instance":"/v1/paymentOrder"}`,
+			},
+			paymentUseCaseCall: paymentUseCaseCall{
+				paymentOrder: createPaymentOrder(),
+				times:        1,
+				qrCode:       "",
+				err:          fmt.Errorf("%w: items differ from the order items", usecases.ErrOrderMismatch),
+			},
+		},
+		{
+			name: "should return service unavailable when the broker cannot be reached",
+			args: args{
+				reqBody: string(paymentRequestValid),
+			},
+			want: want{
+				statusCode: 503,
+				respBody:   `{"type":"https://github.com/IgorRamosBR/g73-techchallenge-payment/blob/master/docs/problems.md#upstream-unavailable","title":"Service Unavailable","status":503,"detail":"failed to create payment order: failed to call mercado pago broker, error: timeout","instance":"/v1/paymentOrder"}`,
+			},
+			paymentUseCaseCall: paymentUseCaseCall{
+				paymentOrder: createPaymentOrder(),
+				times:        1,
+				qrCode:       "",
+				err:          coreErrors.Wrap(coreErrors.KindUpstreamUnavailable, errors.New("failed to call mercado pago broker, error: timeout")),
+			},
+		},
+		{
+			name: "should return bad gateway when the broker rejects the payment order",
+			args: args{
+				reqBody: string(paymentRequestValid),
+			},
+			want: want{
+				statusCode: 502,
+				respBody:   `{"type":"https://github.com/IgorRamosBR/g73-techchallenge-payment/blob/master/docs/problems.md#upstream-rejected","title":"Bad Gateway","status":502,"detail":"failed to create payment order: failed to generate mercado pago qrcode, status [400] non-2xx","instance":"/v1/paymentOrder"}`,
+			},
+			paymentUseCaseCall: paymentUseCaseCall{
+				paymentOrder: createPaymentOrder(),
+				times:        1,
+				qrCode:       "",
+				err:          coreErrors.Wrap(coreErrors.KindUpstreamRejected, errors.New("failed to generate mercado pago qrcode, status [400] non-2xx")),
+			},
+		},
 		{
 			name: "should return ok when creates payment order successfully",
 			args: args{
@@ -116,7 +173,8 @@ func TestPaymentController_CreatePaymentOrderHandler(t *testing.T) {
 func TestPaymentController_NotifyPaymentHandler(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	paymentUseCase := mock_usecases.NewMockPaymentUseCase(ctrl)
-	paymentController := NewPaymentController(paymentUseCase)
+	notificationUseCase := mock_usecases.NewMockNotificationUseCase(ctrl)
+	paymentController := NewPaymentController(paymentUseCase, notificationUseCase)
 
 	type args struct {
 		id      string
@@ -127,10 +185,8 @@ func TestPaymentController_NotifyPaymentHandler(t *testing.T) {
 		respBody   string
 	}
 	type paymentUseCaseCall struct {
-		orderId   int
-		paymentId int
-		times     int
-		err       error
+		times int
+		err   error
 	}
 	tests := []struct {
 		name string
@@ -146,7 +202,7 @@ func TestPaymentController_NotifyPaymentHandler(t *testing.T) {
 			},
 			want: want{
 				statusCode: 400,
-				respBody:   `{"message":"[id] path parameter is required","error":"id is missing"}`,
+				respBody:   `{"type":"https://github.com/IgorRamosBR/g73-techchallenge-payment/blob/master/docs/problems.md#bad-request","title":"Bad Request","status":400,"detail":"[id] path parameter is required: id is missing","instance":"/v1/payment//notify"}`,
 			},
 		},
 		{
@@ -157,7 +213,7 @@ func TestPaymentController_NotifyPaymentHandler(t *testing.T) {
 			},
 			want: want{
 				statusCode: 400,
-				respBody:   `{"message":"[id] path parameter is invalid","error":"strconv.Atoi: parsing \"abc\": invalid syntax"}`,
+				respBody:   `{"type":"https://github.com/IgorRamosBR/g73-techchallenge-payment/blob/master/docs/problems.md#bad-request","title":"Bad Request","status":400,"detail":"[id] path parameter is invalid: strconv.Atoi: parsing \"abc\": invalid syntax","instance":"/v1/payment/abc/notify"}`,
 			},
 		},
 		{
@@ -168,12 +224,12 @@ func TestPaymentController_NotifyPaymentHandler(t *testing.T) {
 			},
 			want: want{
 				statusCode: 400,
-				respBody:   `{"message":"failed to bind payment notification payload","error":"invalid character '\u003c' looking for beginning of value"}`,
+				respBody:   `{"type":"https://github.com/IgorRamosBR/g73-techchallenge-payment/blob/master/docs/problems.md#bad-request","title":"Bad Request","status":400,"detail":"failed to bind payment notification payload: invalid character '\u003c' looking for beginning of value","instance":"/v1/payment/123/notify"}`,
 			},
 		},
 
 		{
-			name: "should return internal server error when payment use case fails to notify payment",
+			name: "should return internal server error when notification use case fails to enqueue the notification",
 			args: args{
 				id: "123",
 				reqBody: `{
@@ -184,17 +240,15 @@ func TestPaymentController_NotifyPaymentHandler(t *testing.T) {
 			},
 			want: want{
 				statusCode: 500,
-				respBody:   `{"message":"failed to notify payment","error":"internal server error"}`,
+				respBody:   `{"type":"https://github.com/IgorRamosBR/g73-techchallenge-payment/blob/master/docs/problems.md#internal","title":"Internal Server Error","status":500,"detail":"failed to enqueue payment notification: internal server error","instance":"/v1/payment/123/notify"}`,
 			},
 			paymentUseCaseCall: paymentUseCaseCall{
-				orderId:   123,
-				paymentId: 7890,
-				times:     1,
-				err:       errors.New("internal server error"),
+				times: 1,
+				err:   errors.New("internal server error"),
 			},
 		},
 		{
-			name: "should return ok when creates payment order successfully",
+			name: "should return ok when enqueues the payment notification to be verified with the broker",
 			args: args{
 				id: "123",
 				reqBody: `{
@@ -207,17 +261,20 @@ func TestPaymentController_NotifyPaymentHandler(t *testing.T) {
 				statusCode: 200,
 			},
 			paymentUseCaseCall: paymentUseCaseCall{
-				orderId:   123,
-				paymentId: 7890,
-				times:     1,
-				err:       nil,
+				times: 1,
+				err:   nil,
 			},
 		},
 	}
 
 	for _, tt := range tests {
-		paymentUseCase.EXPECT().
-			NotifyPayment(gomock.Eq(tt.paymentUseCaseCall.orderId), gomock.Eq(tt.paymentUseCaseCall.paymentId)).
+		notificationUseCase.EXPECT().
+			EnqueueBrokerNotification(gomock.Eq(dto.BrokerNotification{
+				NotificationId: "7890:payment",
+				Topic:          dto.NotificationTopicPayment,
+				ResourceId:     7890,
+				PayloadHash:    dto.HashPayload([]byte(tt.args.reqBody)),
+			})).
 			Times(tt.paymentUseCaseCall.times).
 			Return(tt.paymentUseCaseCall.err)
 
@@ -231,13 +288,894 @@ func TestPaymentController_NotifyPaymentHandler(t *testing.T) {
 	}
 }
 
-func createRouter(paymenteControler PaymentController) *gin.Engine {
+func TestPaymentController_NotifyPaymentHandler_BrokerNotification(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	paymentUseCase := mock_usecases.NewMockPaymentUseCase(ctrl)
+	notificationUseCase := mock_usecases.NewMockNotificationUseCase(ctrl)
+	paymentController := NewPaymentController(paymentUseCase, notificationUseCase)
 
-	router := gin.Default()
-	v1 := router.Group("/v1")
-	{
-		v1.POST("/payment/:id/notify", paymenteControler.NotifyPaymentHandler)
-		v1.POST("/paymentOrder", paymenteControler.CreatePaymentOrderHandler)
+	type args struct {
+		requestId string
+		query     string
+		reqBody   string
+	}
+	type want struct {
+		statusCode int
+		respBody   string
+	}
+	type paymentUseCaseCall struct {
+		notification dto.BrokerNotification
+		times        int
+		err          error
+	}
+	tests := []struct {
+		name string
+		args
+		want
+		paymentUseCaseCall
+	}{
+		{
+			name: "should return bad request when the notification has no topic",
+			args: args{
+				reqBody: `{"description":"Payment received for order 123"}`,
+			},
+			want: want{
+				statusCode: 400,
+				respBody:   `{"type":"https://github.com/IgorRamosBR/g73-techchallenge-payment/blob/master/docs/problems.md#bad-request","title":"Bad Request","status":400,"detail":"failed to bind payment notification payload: notification has no topic","instance":"/v1/payment/123/notify"}`,
+			},
+		},
+		{
+			name: "should return bad request when the notification resource id is invalid",
+			args: args{
+				query: "?topic=payment&id=abc",
+			},
+			want: want{
+				statusCode: 400,
+				respBody:   `{"type":"https://github.com/IgorRamosBR/g73-techchallenge-payment/blob/master/docs/problems.md#bad-request","title":"Bad Request","status":400,"detail":"invalid payment notification payload: notification resource id [abc] is invalid","instance":"/v1/payment/123/notify"}`,
+			},
+		},
+		{
+			name: "should return ok and ignore notifications of other topics",
+			args: args{
+				query:   "?type=point_integration_wh&data.id=123",
+				reqBody: `{"action":"state_FINISHED","type":"point_integration_wh","data":{"id":"123"}}`,
+			},
+			want: want{
+				statusCode: 200,
+			},
+		},
+		{
+			name: "should return internal server error when notification use case fails to enqueue the notification",
+			args: args{
+				query: "?topic=payment&id=7890",
+			},
+			want: want{
+				statusCode: 500,
+				respBody:   `{"type":"https://github.com/IgorRamosBR/g73-techchallenge-payment/blob/master/docs/problems.md#internal","title":"Internal Server Error","status":500,"detail":"failed to enqueue payment notification: internal server error","instance":"/v1/payment/123/notify"}`,
+			},
+			paymentUseCaseCall: paymentUseCaseCall{
+				notification: dto.BrokerNotification{
+					NotificationId: "7890:payment",
+					Topic:          dto.NotificationTopicPayment,
+					ResourceId:     7890,
+				},
+				times: 1,
+				err:   errors.New("internal server error"),
+			},
+		},
+		{
+			name: "should return ok when enqueues an ipn merchant order notification",
+			args: args{
+				reqBody: `{"resource":"https://api.mercadolibre.com/merchant_orders/123456","topic":"merchant_order"}`,
+			},
+			want: want{
+				statusCode: 200,
+			},
+			paymentUseCaseCall: paymentUseCaseCall{
+				notification: dto.BrokerNotification{
+					NotificationId: "123456:merchant_order",
+					Topic:          dto.NotificationTopicMerchantOrder,
+					ResourceId:     123456,
+					PayloadHash:    dto.HashPayload([]byte(`{"resource":"https://api.mercadolibre.com/merchant_orders/123456","topic":"merchant_order"}`)),
+				},
+				times: 1,
+			},
+		},
+		{
+			name: "should return ok when enqueues a webhook payment notification",
+			args: args{
+				requestId: "bb56a2f1-6aae-46ac-982e-9dcd3581d08e",
+				query:     "?data.id=7890&type=payment",
+				reqBody:   `{"action":"payment.updated","api_version":"v1","data":{"id":"7890"},"id":111,"live_mode":true,"type":"payment"}`,
+			},
+			want: want{
+				statusCode: 200,
+			},
+			paymentUseCaseCall: paymentUseCaseCall{
+				notification: dto.BrokerNotification{
+					NotificationId: "bb56a2f1-6aae-46ac-982e-9dcd3581d08e",
+					Topic:          dto.NotificationTopicPayment,
+					ResourceId:     7890,
+					Action:         "payment.updated",
+					PayloadHash:    dto.HashPayload([]byte(`{"action":"payment.updated","api_version":"v1","data":{"id":"7890"},"id":111,"live_mode":true,"type":"payment"}`)),
+				},
+				times: 1,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		notificationUseCase.EXPECT().
+			EnqueueBrokerNotification(gomock.Eq(tt.paymentUseCaseCall.notification)).
+			Times(tt.paymentUseCaseCall.times).
+			Return(tt.paymentUseCaseCall.err)
+
+		router := createRouter(paymentController)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/v1/payment/123/notify"+tt.args.query, strings.NewReader(tt.args.reqBody))
+		req.Header.Set("x-request-id", tt.args.requestId)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, tt.want.statusCode, w.Code)
+		assert.Equal(t, tt.want.respBody, w.Body.String())
+	}
+}
+
+func TestPaymentController_RefundPaymentHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	paymentUseCase := mock_usecases.NewMockPaymentUseCase(ctrl)
+	notificationUseCase := mock_usecases.NewMockNotificationUseCase(ctrl)
+	paymentController := NewPaymentController(paymentUseCase, notificationUseCase)
+
+	type args struct {
+		id      string
+		reqBody string
+		chunked bool
+	}
+	type want struct {
+		statusCode int
+		respBody   string
+	}
+	type paymentUseCaseCall struct {
+		orderId       int
+		refundRequest dto.RefundRequestDTO
+		times         int
+		refund        dto.RefundDTO
+		err           error
+	}
+	tests := []struct {
+		name string
+		args
+		want
+		paymentUseCaseCall
+	}{
+		{
+			name: "should return bad request when orderId is not a number",
+			args: args{
+				id: "abc",
+			},
+			want: want{
+				statusCode: 400,
+				respBody:   `{"type":"https://github.com/IgorRamosBR/g73-techchallenge-payment/blob/master/docs/problems.md#bad-request","title":"Bad Request","status":400,"detail":"[id] path parameter is invalid: strconv.Atoi: parsing \"abc\": invalid syntax","instance":"/v1/payment/abc/refunds"}`,
+			},
+		},
+		{
+			name: "should return bad request when amount is negative",
+			args: args{
+				id:      "123",
+				reqBody: `{"amount":-1}`,
+			},
+			want: want{
+				statusCode: 400,
+				respBody:   `{"type":"https://github.com/IgorRamosBR/g73-techchallenge-payment/blob/master/docs/problems.md#bad-request","title":"Bad Request","status":400,"detail":"invalid refund payload: Amount must not be negative","instance":"/v1/payment/123/refunds"}`,
+			},
+		},
+		{
+			name: "should return not found when payment order does not exist",
+			args: args{
+				id:      "123",
+				reqBody: `{"amount":5}`,
+			},
+			want: want{
+				statusCode: 404,
+				respBody:   `{"type":"https://github.com/IgorRamosBR/g73-techchallenge-payment/blob/master/docs/problems.md#not-found","title":"Not Found","status":404,"detail":"failed to refund payment: payment order not found","instance":"/v1/payment/123/refunds"}`,
+			},
+			paymentUseCaseCall: paymentUseCaseCall{
+				orderId:       123,
+				refundRequest: dto.RefundRequestDTO{Amount: 5},
+				times:         1,
+				err:           gateways.ErrPaymentOrderNotFound,
+			},
+		},
+		{
+			name: "should return unprocessable entity when refund amount exceeds the paid amount",
+			args: args{
+				id:      "123",
+				reqBody: `{"amount":500}`,
+			},
+			want: want{
+				statusCode: 422,
+				respBody:   `{"type":"https://github.com/IgorRamosBR/g73-techchallenge-payment/blob/master/docs/problems.md#validation","title":"Unprocessable Entity","status":422,"detail":"failed to refund payment: refund amount exceeds the remaining paid amount","instance":"/v1/payment/123/refunds"}`,
+			},
+			paymentUseCaseCall: paymentUseCaseCall{
+				orderId:       123,
+				refundRequest: dto.RefundRequestDTO{Amount: 500},
+				times:         1,
+				err:           usecases.ErrRefundAmountExceeded,
+			},
+		},
+		{
+			name: "should return internal server error when payment use case fails to refund",
+			args: args{
+				id:      "123",
+				reqBody: `{"amount":5}`,
+			},
+			want: want{
+				statusCode: 500,
+				respBody:   `{"type":"https://github.com/IgorRamosBR/g73-techchallenge-payment/blob/master/docs/problems.md#internal","title":"Internal Server Error","status":500,"detail":"failed to refund payment: internal server error","instance":"/v1/payment/123/refunds"}`,
+			},
+			paymentUseCaseCall: paymentUseCaseCall{
+				orderId:       123,
+				refundRequest: dto.RefundRequestDTO{Amount: 5},
+				times:         1,
+				err:           errors.New("internal server error"),
+			},
+		},
+		{
+			name: "should return created when refunds the whole payment without body",
+			args: args{
+				id: "123",
+			},
+			want: want{
+				statusCode: 201,
+				respBody:   `{"refundId":999,"orderId":123,"amount":9.99,"refundedAmount":9.99,"status":"REFUNDED"}`,
+			},
+			paymentUseCaseCall: paymentUseCaseCall{
+				orderId:       123,
+				refundRequest: dto.RefundRequestDTO{},
+				times:         1,
+				refund: dto.RefundDTO{
+					RefundId:       999,
+					OrderId:        123,
+					Amount:         9.99,
+					RefundedAmount: 9.99,
+					Status:         entities.PaymentStatusRefunded,
+				},
+			},
+		},
+		{
+			name: "should return created when refunds the whole payment without a chunked body",
+			args: args{
+				id:      "123",
+				chunked: true,
+			},
+			want: want{
+				statusCode: 201,
+				respBody:   `{"refundId":999,"orderId":123,"amount":9.99,"refundedAmount":9.99,"status":"REFUNDED"}`,
+			},
+			paymentUseCaseCall: paymentUseCaseCall{
+				orderId:       123,
+				refundRequest: dto.RefundRequestDTO{},
+				times:         1,
+				refund: dto.RefundDTO{
+					RefundId:       999,
+					OrderId:        123,
+					Amount:         9.99,
+					RefundedAmount: 9.99,
+					Status:         entities.PaymentStatusRefunded,
+				},
+			},
+		},
+		{
+			name: "should return created when refunds part of the payment with a chunked body",
+			args: args{
+				id:      "123",
+				reqBody: `{"amount":5}`,
+				chunked: true,
+			},
+			want: want{
+				statusCode: 201,
+				respBody:   `{"refundId":999,"orderId":123,"amount":5,"refundedAmount":5,"status":"PARTIALLY_REFUNDED"}`,
+			},
+			paymentUseCaseCall: paymentUseCaseCall{
+				orderId:       123,
+				refundRequest: dto.RefundRequestDTO{Amount: 5},
+				times:         1,
+				refund: dto.RefundDTO{
+					RefundId:       999,
+					OrderId:        123,
+					Amount:         5,
+					RefundedAmount: 5,
+					Status:         entities.PaymentStatusPartiallyRefunded,
+				},
+			},
+		},
+		{
+			name: "should return bad request when the body is not valid json",
+			args: args{
+				id:      "123",
+				reqBody: `{"amount":`,
+			},
+			want: want{
+				statusCode: 400,
+				respBody:   `{"type":"https://github.com/IgorRamosBR/g73-techchallenge-payment/blob/master/docs/problems.md#bad-request","title":"Bad Request","status":400,"detail":"failed to bind refund payload: unexpected EOF","instance":"/v1/payment/123/refunds"}`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		paymentUseCase.EXPECT().
+			RefundPayment(gomock.Eq(tt.paymentUseCaseCall.orderId), gomock.Eq(tt.paymentUseCaseCall.refundRequest), gomock.Any()).
+			Times(tt.paymentUseCaseCall.times).
+			Return(tt.paymentUseCaseCall.refund, tt.paymentUseCaseCall.err)
+
+		router := createRouter(paymentController)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", fmt.Sprintf("/v1/payment/%s/refunds", tt.args.id), strings.NewReader(tt.args.reqBody))
+		if tt.args.chunked {
+			req.ContentLength = -1
+		}
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, tt.want.statusCode, w.Code, tt.name)
+		assert.Equal(t, tt.want.respBody, w.Body.String(), tt.name)
+	}
+}
+
+func TestPaymentController_CancelPaymentOrderHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	paymentUseCase := mock_usecases.NewMockPaymentUseCase(ctrl)
+	notificationUseCase := mock_usecases.NewMockNotificationUseCase(ctrl)
+	paymentController := NewPaymentController(paymentUseCase, notificationUseCase)
+
+	type args struct {
+		orderId string
+	}
+	type want struct {
+		statusCode int
+		respBody   string
+	}
+	type paymentUseCaseCall struct {
+		orderId int
+		times   int
+		err     error
+	}
+	tests := []struct {
+		name string
+		args
+		want
+		paymentUseCaseCall
+	}{
+		{
+			name: "should return bad request when orderId is not a number",
+			args: args{
+				orderId: "abc",
+			},
+			want: want{
+				statusCode: 400,
+				respBody:   `{"type":"https://github.com/IgorRamosBR/g73-techchallenge-payment/blob/master/docs/problems.md#bad-request","title":"Bad Request","status":400,"detail":"[orderId] path parameter is invalid: strconv.Atoi: parsing \"abc\": invalid syntax","instance":"/v1/paymentOrder/abc"}`,
+			},
+		},
+		{
+			name: "should return not found when payment order does not exist",
+			args: args{
+				orderId: "123",
+			},
+			want: want{
+				statusCode: 404,
+				respBody:   `{"type":"https://github.com/IgorRamosBR/g73-techchallenge-payment/blob/master/docs/problems.md#not-found","title":"Not Found","status":404,"detail":"failed to cancel payment order: payment order not found","instance":"/v1/paymentOrder/123"}`,
+			},
+			paymentUseCaseCall: paymentUseCaseCall{
+				orderId: 123,
+				times:   1,
+				err:     gateways.ErrPaymentOrderNotFound,
+			},
+		},
+		{
+			name: "should return conflict when payment order is not pending",
+			args: args{
+				orderId: "123",
+			},
+			want: want{
+				statusCode: 409,
+				respBody:   `{"type":"https://github.com/IgorRamosBR/g73-techchallenge-payment/blob/master/docs/problems.md#conflict","title":"Conflict","status":409,"detail":"failed to cancel payment order: payment order is not pending","instance":"/v1/paymentOrder/123"}`,
+			},
+			paymentUseCaseCall: paymentUseCaseCall{
+				orderId: 123,
+				times:   1,
+				err:     usecases.ErrPaymentNotCancellable,
+			},
+		},
+		{
+			name: "should return internal server error when payment use case fails to cancel",
+			args: args{
+				orderId: "123",
+			},
+			want: want{
+				statusCode: 500,
+				respBody:   `{"type":"https://github.com/IgorRamosBR/g73-techchallenge-payment/blob/master/docs/problems.md#internal","title":"Internal Server Error","status":500,"detail":"failed to cancel payment order: internal server error","instance":"/v1/paymentOrder/123"}`,
+			},
+			paymentUseCaseCall: paymentUseCaseCall{
+				orderId: 123,
+				times:   1,
+				err:     errors.New("internal server error"),
+			},
+		},
+		{
+			name: "should return no content when cancels payment order successfully",
+			args: args{
+				orderId: "123",
+			},
+			want: want{
+				statusCode: 204,
+			},
+			paymentUseCaseCall: paymentUseCaseCall{
+				orderId: 123,
+				times:   1,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		paymentUseCase.EXPECT().
+			CancelPaymentOrder(gomock.Eq(tt.paymentUseCaseCall.orderId), gomock.Any()).
+			Times(tt.paymentUseCaseCall.times).
+			Return(tt.paymentUseCaseCall.err)
+
+		router := createRouter(paymentController)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("DELETE", fmt.Sprintf("/v1/paymentOrder/%s", tt.args.orderId), nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, tt.want.statusCode, w.Code)
+		assert.Equal(t, tt.want.respBody, w.Body.String())
+	}
+}
+
+func TestPaymentController_GetPaymentEventsHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	paymentUseCase := mock_usecases.NewMockPaymentUseCase(ctrl)
+	notificationUseCase := mock_usecases.NewMockNotificationUseCase(ctrl)
+	paymentController := NewPaymentController(paymentUseCase, notificationUseCase)
+
+	type args struct {
+		id string
+	}
+	type want struct {
+		statusCode int
+		respBody   string
+	}
+	type paymentUseCaseCall struct {
+		orderId       int
+		times         int
+		paymentEvents []dto.PaymentEventDTO
+		err           error
+	}
+	tests := []struct {
+		name string
+		args
+		want
+		paymentUseCaseCall
+	}{
+		{
+			name: "should return bad request when orderId is not a number",
+			args: args{
+				id: "abc",
+			},
+			want: want{
+				statusCode: 400,
+				respBody:   `{"type":"https://github.com/IgorRamosBR/g73-techchallenge-payment/blob/master/docs/problems.md#bad-request","title":"Bad Request","status":400,"detail":"[id] path parameter is invalid: strconv.Atoi: parsing \"abc\": invalid syntax","instance":"/v1/payment/abc/events"}`,
+			},
+		},
+		{
+			name: "should return internal server error when payment use case fails to get events",
+			args: args{
+				id: "123",
+			},
+			want: want{
+				statusCode: 500,
+				respBody:   `{"type":"https://github.com/IgorRamosBR/g73-techchallenge-payment/blob/master/docs/problems.md#internal","title":"Internal Server Error","status":500,"detail":"failed to get payment events: internal server error","instance":"/v1/payment/123/events"}`,
+			},
+			paymentUseCaseCall: paymentUseCaseCall{
+				orderId: 123,
+				times:   1,
+				err:     errors.New("internal server error"),
+			},
+		},
+		{
+			name: "should return ok with the payment events",
+			args: args{
+				id: "123",
+			},
+			want: want{
+				statusCode: 200,
+				respBody:   `[{"eventId":"1714564800000000000-a1b2c3d4","type":"PAYMENT_CREATED","toStatus":"PENDING","source":"api","createdAt":"2024-05-01T12:00:00Z"}]`,
+			},
+			paymentUseCaseCall: paymentUseCaseCall{
+				orderId: 123,
+				times:   1,
+				paymentEvents: []dto.PaymentEventDTO{
+					{
+						EventId:   "1714564800000000000-a1b2c3d4",
+						Type:      entities.PaymentEventTypeCreated,
+						ToStatus:  entities.PaymentStatusPending,
+						Source:    entities.PaymentEventSourceApi,
+						CreatedAt: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+					},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		paymentUseCase.EXPECT().
+			GetPaymentEvents(gomock.Eq(tt.paymentUseCaseCall.orderId)).
+			Times(tt.paymentUseCaseCall.times).
+			Return(tt.paymentUseCaseCall.paymentEvents, tt.paymentUseCaseCall.err)
+
+		router := createRouter(paymentController)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", fmt.Sprintf("/v1/payment/%s/events", tt.args.id), nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, tt.want.statusCode, w.Code)
+		assert.Equal(t, tt.want.respBody, w.Body.String())
+	}
+}
+
+func TestPaymentController_CreatePaymentHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	paymentUseCase := mock_usecases.NewMockPaymentUseCase(ctrl)
+	notificationUseCase := mock_usecases.NewMockNotificationUseCase(ctrl)
+	paymentController := NewPaymentController(paymentUseCase, notificationUseCase)
+
+	paymentUseCase.EXPECT().
+		CreatePaymentOrder(gomock.Eq(createPaymentOrder())).
+		Times(1).
+		Return("mercadopago123456", nil)
+
+	router := createRouter(paymentController)
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/v2/payments", strings.NewReader(string(paymentRequestValid)))
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, 201, w.Code)
+	assert.Equal(t, "/v2/payments/123456", w.Header().Get("Location"))
+	assert.Equal(t, `{"qrcode":"mercadopago123456"}`, w.Body.String())
+}
+
+func TestPaymentController_GetPaymentOrderHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	paymentUseCase := mock_usecases.NewMockPaymentUseCase(ctrl)
+	notificationUseCase := mock_usecases.NewMockNotificationUseCase(ctrl)
+	paymentController := NewPaymentController(paymentUseCase, notificationUseCase)
+
+	createdAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	type args struct {
+		orderId string
+	}
+	type want struct {
+		statusCode int
+		respBody   string
+	}
+	type paymentUseCaseCall struct {
+		times   int
+		payment dto.PaymentDTO
+		err     error
+	}
+	tests := []struct {
+		name string
+		args
+		want
+		paymentUseCaseCall
+	}{
+		{
+			name: "should return bad request when order id is invalid",
+			args: args{
+				orderId: "abc",
+			},
+			want: want{
+				statusCode: 400,
+				respBody:   `{"type":"` + problemTypeUrl + `bad-request","title":"Bad Request","status":400,"detail":"[orderId] path parameter is invalid: strconv.Atoi: parsing \"abc\": invalid syntax","instance":"/v2/payments/abc"}`,
+			},
+		},
+		{
+			name: "should return not found when payment order does not exist",
+			args: args{
+				orderId: "123",
+			},
+			want: want{
+				statusCode: 404,
+				respBody:   `{"type":"` + problemTypeUrl + `not-found","title":"Not Found","status":404,"detail":"failed to get payment order: payment order not found","instance":"/v2/payments/123"}`,
+			},
+			paymentUseCaseCall: paymentUseCaseCall{
+				times: 1,
+				err:   gateways.ErrPaymentOrderNotFound,
+			},
+		},
+		{
+			name: "should return the payment order",
+			args: args{
+				orderId: "123",
+			},
+			want: want{
+				statusCode: 200,
+				respBody:   `{"orderId":123,"paymentId":456,"customerCpf":"123.456.789-00","items":[],"totalAmount":89.97,"refundedAmount":0,"status":"PAID","createdAt":"2024-05-01T12:00:00Z","updatedAt":"2024-05-01T12:00:00Z","expiresAt":"2024-05-01T12:15:00Z"}`,
+			},
+			paymentUseCaseCall: paymentUseCaseCall{
+				times: 1,
+				payment: dto.PaymentDTO{
+					OrderId:     123,
+					PaymentId:   456,
+					CustomerCPF: "123.456.789-00",
+					Items:       []dto.PaymentOrderItem{},
+					TotalAmount: 89.97,
+					Status:      entities.PaymentStatusPaid,
+					CreatedAt:   createdAt,
+					UpdatedAt:   createdAt,
+					ExpiresAt:   createdAt.Add(15 * time.Minute),
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		paymentUseCase.EXPECT().
+			GetPaymentOrder(gomock.Eq(123)).
+			Times(tt.paymentUseCaseCall.times).
+			Return(tt.paymentUseCaseCall.payment, tt.paymentUseCaseCall.err)
+
+		router := createRouter(paymentController)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", fmt.Sprintf("/v2/payments/%s", tt.args.orderId), nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, tt.want.statusCode, w.Code)
+		assert.Equal(t, tt.want.respBody, w.Body.String())
+	}
+}
+
+func TestPaymentController_GetPaymentQRCodeHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	paymentUseCase := mock_usecases.NewMockPaymentUseCase(ctrl)
+	notificationUseCase := mock_usecases.NewMockNotificationUseCase(ctrl)
+	paymentController := NewPaymentController(paymentUseCase, notificationUseCase)
+
+	type want struct {
+		statusCode int
+		respBody   string
+	}
+	type paymentUseCaseCall struct {
+		qrCode dto.PaymentQRCode
+		err    error
+	}
+	tests := []struct {
+		name string
+		want
+		paymentUseCaseCall
+	}{
+		{
+			name: "should return conflict when payment order is no longer awaiting payment",
+			want: want{
+				statusCode: 409,
+				respBody:   `{"type":"` + problemTypeUrl + `conflict","title":"Conflict","status":409,"detail":"failed to get payment qrcode: payment order is no longer awaiting payment","instance":"/v2/payments/123/qrcode"}`,
+			},
+			paymentUseCaseCall: paymentUseCaseCall{
+				err: usecases.ErrPaymentNotPayable,
+			},
+		},
+		{
+			name: "should return the payment qrcode",
+			want: want{
+				statusCode: 200,
+				respBody:   `{"qrcode":"mercadopago123456"}`,
+			},
+			paymentUseCaseCall: paymentUseCaseCall{
+				qrCode: dto.PaymentQRCode{QRCode: "mercadopago123456"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		paymentUseCase.EXPECT().
+			GetPaymentQRCode(gomock.Eq(123)).
+			Times(1).
+			Return(tt.paymentUseCaseCall.qrCode, tt.paymentUseCaseCall.err)
+
+		router := createRouter(paymentController)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/v2/payments/123/qrcode", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, tt.want.statusCode, w.Code)
+		assert.Equal(t, tt.want.respBody, w.Body.String())
+	}
+}
+
+func TestPaymentController_MercadoPagoWebhookHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	paymentUseCase := mock_usecases.NewMockPaymentUseCase(ctrl)
+	notificationUseCase := mock_usecases.NewMockNotificationUseCase(ctrl)
+	paymentController := NewPaymentController(paymentUseCase, notificationUseCase)
+
+	type args struct {
+		reqBody string
+	}
+	type want struct {
+		statusCode int
+	}
+	type notificationUseCaseCall struct {
+		times int
+	}
+	tests := []struct {
+		name string
+		args
+		want
+		notificationUseCaseCall
+	}{
+		{
+			name: "should return bad request for a legacy notification",
+			args: args{
+				reqBody: `{"description":"Payment received for order 123","payment_id":456}`,
+			},
+			want: want{
+				statusCode: 400,
+			},
+		},
+		{
+			name: "should enqueue a broker notification",
+			args: args{
+				reqBody: `{"action":"payment.updated","type":"payment","data":{"id":"456"}}`,
+			},
+			want: want{
+				statusCode: 200,
+			},
+			notificationUseCaseCall: notificationUseCaseCall{
+				times: 1,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		notificationUseCase.EXPECT().
+			EnqueueBrokerNotification(gomock.Any()).
+			Times(tt.notificationUseCaseCall.times).
+			Return(nil)
+
+		router := createRouter(paymentController)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/v2/webhooks/mercadopago", strings.NewReader(tt.args.reqBody))
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, tt.want.statusCode, w.Code, tt.name)
+	}
+}
+
+func TestPaymentController_SearchPaymentOrdersHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	paymentUseCase := mock_usecases.NewMockPaymentUseCase(ctrl)
+	notificationUseCase := mock_usecases.NewMockNotificationUseCase(ctrl)
+	paymentController := NewPaymentController(paymentUseCase, notificationUseCase)
+
+	createdAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	type args struct {
+		query string
+	}
+	type want struct {
+		statusCode int
+		respBody   string
+	}
+	type paymentUseCaseCall struct {
+		times  int
+		search dto.PaymentSearchDTO
+		page   dto.PaymentPageDTO
+		err    error
+	}
+	tests := []struct {
+		name string
+		args
+		want
+		paymentUseCaseCall
+	}{
+		{
+			name: "should return bad request when the date is invalid",
+			args: args{
+				query: "from=yesterday",
+			},
+			want: want{
+				statusCode: 400,
+				respBody:   `{"type":"` + problemTypeUrl + `bad-request","title":"Bad Request","status":400,"detail":"failed to bind payment search: parsing time \"yesterday\" as \"2006-01-02T15:04:05Z07:00\": cannot parse \"yesterday\" as \"2006\"","instance":"/v2/payments"}`,
+			},
+		},
+		{
+			name: "should return bad request when the status is unknown",
+			args: args{
+				query: "status=SETTLED",
+			},
+			want: want{
+				statusCode: 400,
+				respBody:   `{"type":"` + problemTypeUrl + `bad-request","title":"Bad Request","status":400,"detail":"invalid payment search: Status SETTLED is not a payment status","instance":"/v2/payments"}`,
+			},
+		},
+		{
+			name: "should return unprocessable entity when the cursor is invalid",
+			args: args{
+				query: "status=PAID&cursor=abc",
+			},
+			want: want{
+				statusCode: 422,
+				respBody:   `{"type":"` + problemTypeUrl + `validation","title":"Unprocessable Entity","status":422,"detail":"failed to search payment orders: payment order cursor is invalid","instance":"/v2/payments"}`,
+			},
+			paymentUseCaseCall: paymentUseCaseCall{
+				times:  1,
+				search: dto.PaymentSearchDTO{Status: "PAID", Cursor: "abc"},
+				err:    gateways.ErrInvalidPaymentOrderCursor,
+			},
+		},
+		{
+			name: "should return a page of payment orders",
+			args: args{
+				query: "status=PAID&from=2024-05-01T00:00:00Z&maxAmount=50&broker=mercado-pago&limit=1",
+			},
+			want: want{
+				statusCode: 200,
+				respBody:   `{"items":[{"orderId":123,"customerCpf":"123.456.789-00","items":[],"totalAmount":9.99,"refundedAmount":0,"status":"PAID","createdAt":"2024-05-01T12:00:00Z","updatedAt":"2024-05-01T12:00:00Z","expiresAt":"0001-01-01T00:00:00Z"}],"nextCursor":"next"}`,
+			},
+			paymentUseCaseCall: paymentUseCaseCall{
+				times:  1,
+				search: dto.PaymentSearchDTO{Status: "PAID", Broker: "mercado-pago", From: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), MaxAmount: 50, Limit: 1},
+				page: dto.PaymentPageDTO{
+					Items: []dto.PaymentDTO{
+						{OrderId: 123, CustomerCPF: "123.456.789-00", Items: []dto.PaymentOrderItem{}, TotalAmount: 9.99, Status: entities.PaymentStatusPaid, CreatedAt: createdAt, UpdatedAt: createdAt},
+					},
+					NextCursor: "next",
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		paymentUseCase.EXPECT().
+			SearchPaymentOrders(gomock.Eq(tt.paymentUseCaseCall.search)).
+			Times(tt.paymentUseCaseCall.times).
+			Return(tt.paymentUseCaseCall.page, tt.paymentUseCaseCall.err)
+
+		router := createRouter(paymentController)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/v2/payments?"+tt.args.query, nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, tt.want.statusCode, w.Code, tt.name)
+		assert.Equal(t, tt.want.respBody, w.Body.String(), tt.name)
+	}
+}
+
+func createRouter(paymenteControler PaymentController) *gin.Engine {
+
+	router := gin.Default()
+	router.Use(middleware.ErrorHandler(true))
+	v1 := router.Group("/v1")
+	{
+		v1.POST("/payment/:id/notify", paymenteControler.NotifyPaymentHandler)
+		v1.POST("/paymentOrder", paymenteControler.CreatePaymentOrderHandler)
+		v1.POST("/payment/:id/refunds", paymenteControler.RefundPaymentHandler)
+		v1.GET("/payment/:id", paymenteControler.GetPaymentOrderHandler)
+		v1.GET("/payment/:id/events", paymenteControler.GetPaymentEventsHandler)
+		v1.GET("/payment/:id/stream", paymenteControler.StreamPaymentStatusHandler)
+		v1.GET("/payment/:id/ws", paymenteControler.WatchPaymentStatusHandler)
+		v1.DELETE("/paymentOrder/:orderId", paymenteControler.CancelPaymentOrderHandler)
+	}
+	v2 := router.Group("/v2")
+	{
+		v2.POST("/payments", paymenteControler.CreatePaymentHandler)
+		v2.GET("/payments", paymenteControler.SearchPaymentOrdersHandler)
+		v2.GET("/payments/:orderId", paymenteControler.GetPaymentOrderHandler)
+		v2.GET("/payments/:orderId/qrcode", paymenteControler.GetPaymentQRCodeHandler)
+		v2.GET("/payments/:orderId/stream", paymenteControler.StreamPaymentStatusHandler)
+		v2.GET("/payments/:orderId/ws", paymenteControler.WatchPaymentStatusHandler)
+		v2.POST("/webhooks/mercadopago", paymenteControler.MercadoPagoWebhookHandler)
 	}
 	return router
 }
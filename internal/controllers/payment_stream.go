@@ -0,0 +1,256 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/entities"
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/usecases/dto"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// paymentStatusEvent is the server-sent event carrying a payment status.
+	paymentStatusEvent = "payment-status"
+	// paymentStreamKeepAlive is how often an idle stream is written to, so the proxies in between
+	// do not close it.
+	paymentStreamKeepAlive = 15 * time.Second
+	paymentStreamWriteWait = 10 * time.Second
+
+	// defaultPaymentWaitTimeout and maxPaymentWaitTimeout bound how long a request waiting for a
+	// payment status is held.
+	defaultPaymentWaitTimeout = 30 * time.Second
+	maxPaymentWaitTimeout     = time.Minute
+)
+
+var paymentStatusUpgrader = websocket.Upgrader{}
+
+// StreamPaymentStatusHandler pushes the payment order status as server-sent events: the current
+// one first, then every change, until the payment order reaches a final status or the client
+// goes away.
+func (p PaymentController) StreamPaymentStatusHandler(c *gin.Context) {
+	current, changes, unsubscribe, ok := p.subscribePaymentStatus(c)
+	if !ok {
+		return
+	}
+	defer unsubscribe()
+
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+
+	send := func(status dto.PaymentStatusDTO) error {
+		c.SSEvent(paymentStatusEvent, status)
+		c.Writer.Flush()
+		return nil
+	}
+	keepAlive := func() error {
+		_, err := c.Writer.WriteString(": keep-alive\n\n")
+		c.Writer.Flush()
+		return err
+	}
+
+	watchPaymentStatus(c.Request.Context().Done(), current, changes, send, keepAlive)
+}
+
+// WatchPaymentStatusHandler is the WebSocket version of StreamPaymentStatusHandler, sending each
+// status as a JSON text message and closing the connection once the status is final.
+func (p PaymentController) WatchPaymentStatusHandler(c *gin.Context) {
+	current, changes, unsubscribe, ok := p.subscribePaymentStatus(c)
+	if !ok {
+		return
+	}
+	defer unsubscribe()
+
+	conn, err := paymentStatusUpgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		log.Warnf("failed to upgrade the payment status connection of the order [%d], error: %v", current.OrderId, err)
+		return
+	}
+	defer conn.Close()
+
+	// the client sends nothing, but reading is what handles its close and pong messages
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			_, _, err := conn.NextReader()
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	send := func(status dto.PaymentStatusDTO) error {
+		_ = conn.SetWriteDeadline(time.Now().Add(paymentStreamWriteWait))
+		return conn.WriteJSON(status)
+	}
+	keepAlive := func() error {
+		return conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(paymentStreamWriteWait))
+	}
+
+	watchPaymentStatus(closed, current, changes, send, keepAlive)
+
+	message := websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")
+	_ = conn.WriteControl(websocket.CloseMessage, message, time.Now().Add(paymentStreamWriteWait))
+}
+
+// waitForPaymentStatus holds the request until the payment order reaches one of the [waitFor]
+// statuses, or a final one, or the [timeout] elapses, then answers with the payment order as it is
+// read again, so a change missed while waiting is not left out of the answer.
+func (p PaymentController) waitForPaymentStatus(c *gin.Context, orderId int) {
+	statuses, ok := getWaitForStatuses(c)
+	if !ok {
+		return
+	}
+	timeout, ok := getWaitTimeout(c)
+	if !ok {
+		return
+	}
+
+	changes, unsubscribe := p.paymentUsecase.SubscribePaymentStatus(orderId)
+	defer unsubscribe()
+
+	payment, err := p.paymentUsecase.GetPaymentOrder(orderId)
+	if err != nil {
+		handleErrorResponse(c, "failed to get payment order", err)
+		return
+	}
+
+	if !waitsFor(statuses, payment.Status) {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+
+	wait:
+		for {
+			select {
+			case <-c.Request.Context().Done():
+				return
+			case <-timer.C:
+				break wait
+			case change, ok := <-changes:
+				if !ok {
+					break wait
+				}
+				if waitsFor(statuses, change.Status) {
+					break wait
+				}
+			}
+		}
+
+		payment, err = p.paymentUsecase.GetPaymentOrder(orderId)
+		if err != nil {
+			handleErrorResponse(c, "failed to get payment order", err)
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, payment)
+}
+
+// waitsFor reports whether waiting is over for the status, which is either awaited or final.
+func waitsFor(statuses []entities.PaymentStatus, status entities.PaymentStatus) bool {
+	if status.IsFinal() {
+		return true
+	}
+	for _, awaited := range statuses {
+		if awaited == status {
+			return true
+		}
+	}
+	return false
+}
+
+func getWaitForStatuses(c *gin.Context) ([]entities.PaymentStatus, bool) {
+	statuses := []entities.PaymentStatus{}
+	for _, value := range strings.Split(c.Query("waitFor"), ",") {
+		status := entities.PaymentStatus(strings.TrimSpace(value))
+		if !status.IsValid() {
+			handleBadRequestResponse(c, "[waitFor] query parameter is invalid", fmt.Errorf("%s is not a payment status", status))
+			return nil, false
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, true
+}
+
+func getWaitTimeout(c *gin.Context) (time.Duration, bool) {
+	value := c.Query("timeout")
+	if value == "" {
+		return defaultPaymentWaitTimeout, true
+	}
+
+	timeout, err := time.ParseDuration(value)
+	if err != nil {
+		handleBadRequestResponse(c, "[timeout] query parameter is invalid", err)
+		return 0, false
+	}
+	if timeout <= 0 || timeout > maxPaymentWaitTimeout {
+		handleBadRequestResponse(c, "[timeout] query parameter is invalid", fmt.Errorf("timeout must be positive and at most %s", maxPaymentWaitTimeout))
+		return 0, false
+	}
+	return timeout, true
+}
+
+// subscribePaymentStatus subscribes before reading the current status, so no change is missed in
+// between. It renders the error itself, while the response can still be a problem.
+func (p PaymentController) subscribePaymentStatus(c *gin.Context) (dto.PaymentStatusDTO, <-chan entities.PaymentStatusChange, func(), bool) {
+	orderId, ok := getOrderId(c)
+	if !ok {
+		return dto.PaymentStatusDTO{}, nil, nil, false
+	}
+
+	changes, unsubscribe := p.paymentUsecase.SubscribePaymentStatus(orderId)
+	payment, err := p.paymentUsecase.GetPaymentOrder(orderId)
+	if err != nil {
+		unsubscribe()
+		handleErrorResponse(c, "failed to get payment order", err)
+		return dto.PaymentStatusDTO{}, nil, nil, false
+	}
+
+	current := dto.PaymentStatusDTO{OrderId: payment.OrderId, Status: payment.Status, UpdatedAt: payment.UpdatedAt}
+	return current, changes, unsubscribe, true
+}
+
+// watchPaymentStatus sends the current status, then every different one, until the status is final,
+// done is closed or sending fails.
+func watchPaymentStatus(done <-chan struct{}, current dto.PaymentStatusDTO, changes <-chan entities.PaymentStatusChange, send func(dto.PaymentStatusDTO) error, keepAlive func() error) {
+	err := send(current)
+	if err != nil || current.Status.IsFinal() {
+		return
+	}
+
+	ticker := time.NewTicker(paymentStreamKeepAlive)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			err := keepAlive()
+			if err != nil {
+				return
+			}
+		case change, ok := <-changes:
+			if !ok {
+				return
+			}
+			// the change may already be reflected in the status read at the start
+			if change.Status == current.Status {
+				continue
+			}
+
+			current = dto.NewPaymentStatusDTO(change)
+			err := send(current)
+			if err != nil || current.Status.IsFinal() {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,401 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/entities"
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/usecases/dto"
+	mock_usecases "github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/usecases/mocks"
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/infra/gateways"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestPaymentController_StreamPaymentStatusHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	paymentUseCase := mock_usecases.NewMockPaymentUseCase(ctrl)
+	notificationUseCase := mock_usecases.NewMockNotificationUseCase(ctrl)
+	paymentController := NewPaymentController(paymentUseCase, notificationUseCase)
+
+	updatedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	type args struct {
+		path string
+	}
+	type want struct {
+		statusCode int
+		respBody   string
+	}
+	type subscribeCall struct {
+		times   int
+		changes []entities.PaymentStatusChange
+	}
+	type paymentUseCaseCall struct {
+		times   int
+		payment dto.PaymentDTO
+		err     error
+	}
+	tests := []struct {
+		name string
+		args
+		want
+		subscribeCall
+		paymentUseCaseCall
+	}{
+		{
+			name: "should return bad request when order id is invalid",
+			args: args{
+				path: "/v2/payments/abc/stream",
+			},
+			want: want{
+				statusCode: 400,
+				respBody:   `{"type":"` + problemTypeUrl + `bad-request","title":"Bad Request","status":400,"detail":"[orderId] path parameter is invalid: strconv.Atoi: parsing \"abc\": invalid syntax","instance":"/v2/payments/abc/stream"}`,
+			},
+		},
+		{
+			name: "should return not found when payment order does not exist",
+			args: args{
+				path: "/v2/payments/123/stream",
+			},
+			want: want{
+				statusCode: 404,
+				respBody:   `{"type":"` + problemTypeUrl + `not-found","title":"Not Found","status":404,"detail":"failed to get payment order: payment order not found","instance":"/v2/payments/123/stream"}`,
+			},
+			subscribeCall: subscribeCall{
+				times: 1,
+			},
+			paymentUseCaseCall: paymentUseCaseCall{
+				times: 1,
+				err:   gateways.ErrPaymentOrderNotFound,
+			},
+		},
+		{
+			name: "should only send the status when it is final",
+			args: args{
+				path: "/v1/payment/123/stream",
+			},
+			want: want{
+				statusCode: 200,
+				respBody:   "event:payment-status\ndata:{\"orderId\":123,\"status\":\"CANCELLED\",\"updatedAt\":\"2024-05-01T12:00:00Z\"}\n\n",
+			},
+			subscribeCall: subscribeCall{
+				times: 1,
+			},
+			paymentUseCaseCall: paymentUseCaseCall{
+				times:   1,
+				payment: dto.PaymentDTO{OrderId: 123, Status: entities.PaymentStatusCancelled, UpdatedAt: updatedAt},
+			},
+		},
+		{
+			name: "should send the status changes until the status is final",
+			args: args{
+				path: "/v2/payments/123/stream",
+			},
+			want: want{
+				statusCode: 200,
+				respBody: "event:payment-status\ndata:{\"orderId\":123,\"status\":\"PENDING\",\"updatedAt\":\"2024-05-01T12:00:00Z\"}\n\n" +
+					"event:payment-status\ndata:{\"orderId\":123,\"status\":\"PAID\",\"updatedAt\":\"2024-05-01T12:01:00Z\"}\n\n" +
+					"event:payment-status\ndata:{\"orderId\":123,\"status\":\"REFUNDED\",\"updatedAt\":\"2024-05-01T12:02:00Z\"}\n\n",
+			},
+			subscribeCall: subscribeCall{
+				times: 1,
+				changes: []entities.PaymentStatusChange{
+					{OrderId: 123, Status: entities.PaymentStatusPending, UpdatedAt: updatedAt},
+					{OrderId: 123, Status: entities.PaymentStatusPaid, UpdatedAt: updatedAt.Add(time.Minute)},
+					{OrderId: 123, Status: entities.PaymentStatusRefunded, UpdatedAt: updatedAt.Add(2 * time.Minute)},
+				},
+			},
+			paymentUseCaseCall: paymentUseCaseCall{
+				times:   1,
+				payment: dto.PaymentDTO{OrderId: 123, Status: entities.PaymentStatusPending, UpdatedAt: updatedAt},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		changes := make(chan entities.PaymentStatusChange, len(tt.subscribeCall.changes))
+		for _, change := range tt.subscribeCall.changes {
+			changes <- change
+		}
+		unsubscribed := 0
+
+		paymentUseCase.EXPECT().
+			SubscribePaymentStatus(gomock.Eq(123)).
+			Times(tt.subscribeCall.times).
+			Return(changes, func() { unsubscribed++ })
+
+		paymentUseCase.EXPECT().
+			GetPaymentOrder(gomock.Eq(123)).
+			Times(tt.paymentUseCaseCall.times).
+			Return(tt.paymentUseCaseCall.payment, tt.paymentUseCaseCall.err)
+
+		router := createRouter(paymentController)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", tt.args.path, nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, tt.want.statusCode, w.Code, tt.name)
+		assert.Equal(t, tt.want.respBody, w.Body.String(), tt.name)
+		assert.Equal(t, tt.subscribeCall.times, unsubscribed, tt.name)
+	}
+}
+
+func TestPaymentController_WatchPaymentStatusHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	paymentUseCase := mock_usecases.NewMockPaymentUseCase(ctrl)
+	notificationUseCase := mock_usecases.NewMockNotificationUseCase(ctrl)
+	paymentController := NewPaymentController(paymentUseCase, notificationUseCase)
+
+	server := httptest.NewServer(createRouter(paymentController))
+	defer server.Close()
+
+	updatedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		path string
+	}{
+		{
+			name: "should send the status until it is final",
+			path: "/v2/payments/123/ws",
+		},
+		{
+			name: "should send the status until it is final on the v1 route",
+			path: "/v1/payment/123/ws",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changes := make(chan entities.PaymentStatusChange, 1)
+			changes <- entities.PaymentStatusChange{OrderId: 123, Status: entities.PaymentStatusExpired, UpdatedAt: updatedAt.Add(time.Minute)}
+
+			paymentUseCase.EXPECT().
+				SubscribePaymentStatus(gomock.Eq(123)).
+				Times(1).
+				Return(changes, func() {})
+
+			paymentUseCase.EXPECT().
+				GetPaymentOrder(gomock.Eq(123)).
+				Times(1).
+				Return(dto.PaymentDTO{OrderId: 123, Status: entities.PaymentStatusPending, UpdatedAt: updatedAt}, nil)
+
+			url := fmt.Sprintf("ws%s%s", strings.TrimPrefix(server.URL, "http"), tt.path)
+			conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+			assert.Nil(t, err)
+			defer conn.Close()
+
+			statuses := []dto.PaymentStatusDTO{}
+			for {
+				status := dto.PaymentStatusDTO{}
+				err := conn.ReadJSON(&status)
+				if err != nil {
+					assert.True(t, websocket.IsCloseError(err, websocket.CloseNormalClosure))
+					break
+				}
+				statuses = append(statuses, status)
+			}
+
+			assert.Equal(t, []dto.PaymentStatusDTO{
+				{OrderId: 123, Status: entities.PaymentStatusPending, UpdatedAt: updatedAt},
+				{OrderId: 123, Status: entities.PaymentStatusExpired, UpdatedAt: updatedAt.Add(time.Minute)},
+			}, statuses)
+		})
+	}
+}
+
+func TestPaymentController_GetPaymentOrderHandler_WaitFor(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	paymentUseCase := mock_usecases.NewMockPaymentUseCase(ctrl)
+	notificationUseCase := mock_usecases.NewMockNotificationUseCase(ctrl)
+	paymentController := NewPaymentController(paymentUseCase, notificationUseCase)
+
+	updatedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	pending := dto.PaymentDTO{OrderId: 123, Status: entities.PaymentStatusPending, UpdatedAt: updatedAt}
+	paid := dto.PaymentDTO{OrderId: 123, Status: entities.PaymentStatusPaid, UpdatedAt: updatedAt.Add(time.Minute)}
+
+	type args struct {
+		path string
+	}
+	type want struct {
+		statusCode int
+		respBody   string
+	}
+	type subscribeCall struct {
+		times   int
+		changes []entities.PaymentStatusChange
+		closed  bool
+	}
+	type paymentUseCaseCall struct {
+		payments []dto.PaymentDTO
+		err      error
+	}
+	tests := []struct {
+		name string
+		args
+		want
+		subscribeCall
+		paymentUseCaseCall
+	}{
+		{
+			name: "should return bad request when a status is unknown",
+			args: args{
+				path: "/v1/payment/123?waitFor=PAID,SETTLED",
+			},
+			want: want{
+				statusCode: 400,
+				respBody:   `{"type":"` + problemTypeUrl + `bad-request","title":"Bad Request","status":400,"detail":"[waitFor] query parameter is invalid: SETTLED is not a payment status","instance":"/v1/payment/123"}`,
+			},
+		},
+		{
+			name: "should return bad request when the timeout is too long",
+			args: args{
+				path: "/v1/payment/123?waitFor=PAID&timeout=1h",
+			},
+			want: want{
+				statusCode: 400,
+				respBody:   `{"type":"` + problemTypeUrl + `bad-request","title":"Bad Request","status":400,"detail":"[timeout] query parameter is invalid: timeout must be positive and at most 1m0s","instance":"/v1/payment/123"}`,
+			},
+		},
+		{
+			name: "should return not found when payment order does not exist",
+			args: args{
+				path: "/v1/payment/123?waitFor=PAID",
+			},
+			want: want{
+				statusCode: 404,
+				respBody:   `{"type":"` + problemTypeUrl + `not-found","title":"Not Found","status":404,"detail":"failed to get payment order: payment order not found","instance":"/v1/payment/123"}`,
+			},
+			subscribeCall: subscribeCall{
+				times: 1,
+			},
+			paymentUseCaseCall: paymentUseCaseCall{
+				payments: []dto.PaymentDTO{{}},
+				err:      gateways.ErrPaymentOrderNotFound,
+			},
+		},
+		{
+			name: "should return the payment order at once when it already has the status",
+			args: args{
+				path: "/v1/payment/123?waitFor=PENDING,PAID",
+			},
+			want: want{
+				statusCode: 200,
+				respBody:   `{"orderId":123,"customerCpf":"","items":null,"totalAmount":0,"refundedAmount":0,"status":"PENDING","createdAt":"0001-01-01T00:00:00Z","updatedAt":"2024-05-01T12:00:00Z","expiresAt":"0001-01-01T00:00:00Z"}`,
+			},
+			subscribeCall: subscribeCall{
+				times: 1,
+			},
+			paymentUseCaseCall: paymentUseCaseCall{
+				payments: []dto.PaymentDTO{pending},
+			},
+		},
+		{
+			name: "should return the payment order once it reaches the status",
+			args: args{
+				path: "/v2/payments/123?waitFor=PAID&timeout=30s",
+			},
+			want: want{
+				statusCode: 200,
+				respBody:   `{"orderId":123,"customerCpf":"","items":null,"totalAmount":0,"refundedAmount":0,"status":"PAID","createdAt":"0001-01-01T00:00:00Z","updatedAt":"2024-05-01T12:01:00Z","expiresAt":"0001-01-01T00:00:00Z"}`,
+			},
+			subscribeCall: subscribeCall{
+				times: 1,
+				changes: []entities.PaymentStatusChange{
+					{OrderId: 123, Status: entities.PaymentStatusAuthorized, UpdatedAt: updatedAt},
+					{OrderId: 123, Status: entities.PaymentStatusPaid, UpdatedAt: updatedAt.Add(time.Minute)},
+				},
+			},
+			paymentUseCaseCall: paymentUseCaseCall{
+				payments: []dto.PaymentDTO{pending, paid},
+			},
+		},
+		{
+			name: "should return the payment order as it is when the timeout elapses",
+			args: args{
+				path: "/v1/payment/123?waitFor=PAID&timeout=10ms",
+			},
+			want: want{
+				statusCode: 200,
+				respBody:   `{"orderId":123,"customerCpf":"","items":null,"totalAmount":0,"refundedAmount":0,"status":"PENDING","createdAt":"0001-01-01T00:00:00Z","updatedAt":"2024-05-01T12:00:00Z","expiresAt":"0001-01-01T00:00:00Z"}`,
+			},
+			subscribeCall: subscribeCall{
+				times: 1,
+			},
+			paymentUseCaseCall: paymentUseCaseCall{
+				payments: []dto.PaymentDTO{pending, pending},
+			},
+		},
+		{
+			name: "should return the payment order as it is read again when the timeout elapses",
+			args: args{
+				path: "/v1/payment/123?waitFor=PAID&timeout=10ms",
+			},
+			want: want{
+				statusCode: 200,
+				respBody:   `{"orderId":123,"customerCpf":"","items":null,"totalAmount":0,"refundedAmount":0,"status":"PAID","createdAt":"0001-01-01T00:00:00Z","updatedAt":"2024-05-01T12:01:00Z","expiresAt":"0001-01-01T00:00:00Z"}`,
+			},
+			subscribeCall: subscribeCall{
+				times: 1,
+			},
+			paymentUseCaseCall: paymentUseCaseCall{
+				payments: []dto.PaymentDTO{pending, paid},
+			},
+		},
+		{
+			name: "should return the payment order as it is read again when the changes are closed",
+			args: args{
+				path: "/v2/payments/123?waitFor=PAID&timeout=30s",
+			},
+			want: want{
+				statusCode: 200,
+				respBody:   `{"orderId":123,"customerCpf":"","items":null,"totalAmount":0,"refundedAmount":0,"status":"PAID","createdAt":"0001-01-01T00:00:00Z","updatedAt":"2024-05-01T12:01:00Z","expiresAt":"0001-01-01T00:00:00Z"}`,
+			},
+			subscribeCall: subscribeCall{
+				times:  1,
+				closed: true,
+			},
+			paymentUseCaseCall: paymentUseCaseCall{
+				payments: []dto.PaymentDTO{pending, paid},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		changes := make(chan entities.PaymentStatusChange, len(tt.subscribeCall.changes))
+		for _, change := range tt.subscribeCall.changes {
+			changes <- change
+		}
+		if tt.subscribeCall.closed {
+			close(changes)
+		}
+
+		paymentUseCase.EXPECT().
+			SubscribePaymentStatus(gomock.Eq(123)).
+			Times(tt.subscribeCall.times).
+			Return(changes, func() {})
+
+		calls := make([]any, 0, len(tt.paymentUseCaseCall.payments))
+		for _, payment := range tt.paymentUseCaseCall.payments {
+			calls = append(calls, paymentUseCase.EXPECT().
+				GetPaymentOrder(gomock.Eq(123)).
+				Times(1).
+				Return(payment, tt.paymentUseCaseCall.err))
+		}
+		gomock.InOrder(calls...)
+
+		router := createRouter(paymentController)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", tt.args.path, nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, tt.want.statusCode, w.Code, tt.name)
+		assert.Equal(t, tt.want.respBody, w.Body.String(), tt.name)
+	}
+}
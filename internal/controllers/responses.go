@@ -1,28 +1,17 @@
 package controllers
 
 import (
-	"net/http"
-
 	"github.com/gin-gonic/gin"
 )
 
-type ErrorResponse struct {
-	Message string `json:"message"`
-	Err     string `json:"error"`
-}
-
+// handleBadRequestResponse and handleErrorResponse hand the error to the error middleware,
+// which renders it as a problem: a bad request, or the status code of the error kind.
 func handleBadRequestResponse(c *gin.Context, message string, err error) {
-	badRequestError := ErrorResponse{
-		Message: message,
-		Err:     err.Error(),
-	}
-	c.JSON(http.StatusBadRequest, badRequestError)
+	_ = c.Error(err).SetType(gin.ErrorTypeBind).SetMeta(message)
+	c.Abort()
 }
 
-func handleInternalServerResponse(c *gin.Context, message string, err error) {
-	internalServerError := ErrorResponse{
-		Message: message,
-		Err:     err.Error(),
-	}
-	c.JSON(http.StatusInternalServerError, internalServerError)
+func handleErrorResponse(c *gin.Context, message string, err error) {
+	_ = c.Error(err).SetMeta(message)
+	c.Abort()
 }
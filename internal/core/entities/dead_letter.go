@@ -0,0 +1,32 @@
+package entities
+
+import "time"
+
+type DeadLetterType string
+
+// A PRODUCTION_NOTIFICATION dead letter holds the ProductionOrder the production service could
+// not receive, an ORDER_NOTIFICATION one an OrderNotification.
+var (
+	DeadLetterTypeBrokerNotification     DeadLetterType = "BROKER_NOTIFICATION"
+	DeadLetterTypeOrderNotification      DeadLetterType = "ORDER_NOTIFICATION"
+	DeadLetterTypeProductionNotification DeadLetterType = "PRODUCTION_NOTIFICATION"
+)
+
+// DeadLetter keeps a message that could not be processed after every retry, so it can be
+// inspected and replayed later.
+type DeadLetter struct {
+	MessageId string         `dynamodbav:"MessageId"`
+	Type      DeadLetterType `dynamodbav:"Type"`
+	Payload   string         `dynamodbav:"Payload"`
+	Attempts  int            `dynamodbav:"Attempts"`
+	LastError string         `dynamodbav:"LastError"`
+	CreatedAt time.Time      `dynamodbav:"CreatedAt,unixtime"`
+	UpdatedAt time.Time      `dynamodbav:"UpdatedAt,unixtime"`
+}
+
+// OrderNotification is the payload of an ORDER_NOTIFICATION dead letter: a payment status the
+// order service could not be told about.
+type OrderNotification struct {
+	OrderId int           `json:"orderId"`
+	Status  PaymentStatus `json:"status"`
+}
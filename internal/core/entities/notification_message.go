@@ -0,0 +1,25 @@
+package entities
+
+import "time"
+
+// NotificationMessage is a broker notification accepted by the webhook and waiting to be processed.
+// Legacy notifications name the payment directly, the others point to a broker resource by topic.
+type NotificationMessage struct {
+	MessageId       string    `dynamodbav:"MessageId"`
+	NotificationId  string    `dynamodbav:"NotificationId"`
+	Topic           string    `dynamodbav:"Topic"`
+	ResourceId      int       `dynamodbav:"ResourceId"`
+	Action          string    `dynamodbav:"Action"`
+	OrderId         int       `dynamodbav:"OrderId"`
+	PaymentId       int       `dynamodbav:"PaymentId"`
+	MerchantOrderId int       `dynamodbav:"MerchantOrderId"`
+	PayloadHash     string    `dynamodbav:"PayloadHash"`
+	Attempts        int       `dynamodbav:"Attempts"`
+	LastError       string    `dynamodbav:"LastError"`
+	CreatedAt       time.Time `dynamodbav:"CreatedAt,unixtime"`
+	VisibleAt       time.Time `dynamodbav:"VisibleAt,unixtime"`
+}
+
+func (m NotificationMessage) IsLegacy() bool {
+	return m.Topic == ""
+}
@@ -1,16 +1,113 @@
 package entities
 
+import (
+	"math"
+	"time"
+)
+
 type PaymentStatus string
 
 var (
-	PaymentStatusPending PaymentStatus = "PENDING"
-	PaymentStatusPaid    PaymentStatus = "PAID"
+	PaymentStatusPending           PaymentStatus = "PENDING"
+	PaymentStatusAuthorized        PaymentStatus = "AUTHORIZED"
+	PaymentStatusPaid              PaymentStatus = "PAID"
+	PaymentStatusExpired           PaymentStatus = "EXPIRED"
+	PaymentStatusCancelled         PaymentStatus = "CANCELLED"
+	PaymentStatusPartiallyRefunded PaymentStatus = "PARTIALLY_REFUNDED"
+	PaymentStatusRefunded          PaymentStatus = "REFUNDED"
+	PaymentStatusRefundPending     PaymentStatus = "REFUND_PENDING"
 )
 
+var paymentStatuses = []PaymentStatus{
+	PaymentStatusPending,
+	PaymentStatusAuthorized,
+	PaymentStatusPaid,
+	PaymentStatusExpired,
+	PaymentStatusCancelled,
+	PaymentStatusPartiallyRefunded,
+	PaymentStatusRefunded,
+	PaymentStatusRefundPending,
+}
+
+var paymentStatusTransitions = map[PaymentStatus][]PaymentStatus{
+	PaymentStatusPending:           {PaymentStatusAuthorized, PaymentStatusPaid, PaymentStatusExpired, PaymentStatusCancelled},
+	PaymentStatusAuthorized:        {PaymentStatusPaid},
+	PaymentStatusPaid:              {PaymentStatusPartiallyRefunded, PaymentStatusRefunded, PaymentStatusRefundPending},
+	PaymentStatusPartiallyRefunded: {PaymentStatusPartiallyRefunded, PaymentStatusRefunded},
+	PaymentStatusRefundPending:     {PaymentStatusRefunded},
+}
+
+// IsValid reports whether the status is one of the payment statuses.
+func (s PaymentStatus) IsValid() bool {
+	for _, status := range paymentStatuses {
+		if status == s {
+			return true
+		}
+	}
+	return false
+}
+
+// IsFinal reports whether the payment order can no longer change status.
+func (s PaymentStatus) IsFinal() bool {
+	return len(paymentStatusTransitions[s]) == 0
+}
+
+func (s PaymentStatus) CanTransitionTo(status PaymentStatus) bool {
+	for _, allowed := range paymentStatusTransitions[s] {
+		if allowed == status {
+			return true
+		}
+	}
+	return false
+}
+
 type PaymentOrder struct {
-	OrderId     int           `dynamodbav:"OrderId"`
-	CustomerCPF string        `dynamodbav:"CustomerCPF"`
-	TotalAmout  float64       `dynamodbav:"TotalAmount"`
-	Status      PaymentStatus `dynamodbav:"Status"`
-	QRCode      string        `dynamodbav:"QRCode"`
+	OrderId         int           `dynamodbav:"OrderId"`
+	PaymentId       int           `dynamodbav:"PaymentId"`
+	MerchantOrderId int           `dynamodbav:"MerchantOrderId"`
+	Broker          string        `dynamodbav:"Broker"`
+	InStoreOrderId  string        `dynamodbav:"InStoreOrderId"`
+	CustomerCPF     string        `dynamodbav:"CustomerCPF"`
+	Items           []PaymentItem `dynamodbav:"Items"`
+	TotalAmout      float64       `dynamodbav:"TotalAmount"`
+	RefundedAmount  float64       `dynamodbav:"RefundedAmount"`
+	Status          PaymentStatus `dynamodbav:"Status"`
+	QRCode          string        `dynamodbav:"QRCode"`
+	RefundRequired  bool          `dynamodbav:"RefundRequired"`
+	Refunds         []Refund      `dynamodbav:"Refunds,omitempty"`
+	CreatedAt       time.Time     `dynamodbav:"CreatedAt,unixtime"`
+	UpdatedAt       time.Time     `dynamodbav:"UpdatedAt,unixtime"`
+	ExpiresAt       time.Time     `dynamodbav:"ExpiresAt,unixtime"`
+	PaidAt          time.Time     `dynamodbav:"PaidAt,unixtime"`
+}
+
+type PaymentItem struct {
+	SkuId     string  `dynamodbav:"SkuId"`
+	Name      string  `dynamodbav:"Name"`
+	Category  string  `dynamodbav:"Category"`
+	Type      string  `dynamodbav:"Type"`
+	Quantity  int     `dynamodbav:"Quantity"`
+	UnitPrice float64 `dynamodbav:"UnitPrice"`
+}
+
+// RemainingAmount is the paid amount that has not been refunded yet, rounded to cents.
+func (p PaymentOrder) RemainingAmount() float64 {
+	return math.Round((p.TotalAmout-p.RefundedAmount)*100) / 100
+}
+
+// IsClosed tells whether the payment order can no longer be paid.
+func (p PaymentOrder) IsClosed() bool {
+	return p.Status == PaymentStatusCancelled || p.Status == PaymentStatusExpired
+}
+
+// RefundStatusPending is the status of a refund reserved on the payment order and not yet
+// answered by the broker.
+const RefundStatusPending = "pending"
+
+type Refund struct {
+	RefundId    int       `dynamodbav:"RefundId"`
+	ReferenceId string    `dynamodbav:"ReferenceId"`
+	Amount      float64   `dynamodbav:"Amount"`
+	Status      string    `dynamodbav:"Status"`
+	CreatedAt   time.Time `dynamodbav:"CreatedAt,unixtime"`
 }
@@ -0,0 +1,33 @@
+package entities
+
+import "time"
+
+type PaymentDomainEventType string
+
+var (
+	PaymentDomainEventTypeCreated  PaymentDomainEventType = "PaymentCreated"
+	PaymentDomainEventTypePaid     PaymentDomainEventType = "PaymentPaid"
+	PaymentDomainEventTypeExpired  PaymentDomainEventType = "PaymentExpired"
+	PaymentDomainEventTypeRefunded PaymentDomainEventType = "PaymentRefunded"
+)
+
+// PaymentDomainEventVersion is the version of the event schema published to other services,
+// described in docs/events. It must be increased on every breaking change to the payload.
+const PaymentDomainEventVersion = 1
+
+// PaymentDomainEvent is published to the message bus whenever a payment order changes in a way
+// other services care about.
+type PaymentDomainEvent struct {
+	EventId    string                 `json:"eventId"`
+	Type       PaymentDomainEventType `json:"type"`
+	Version    int                    `json:"version"`
+	OccurredAt time.Time              `json:"occurredAt"`
+	Data       PaymentDomainEventData `json:"data"`
+}
+
+type PaymentDomainEventData struct {
+	OrderId   int           `json:"orderId"`
+	PaymentId int           `json:"paymentId,omitempty"`
+	Status    PaymentStatus `json:"status"`
+	Amount    float64       `json:"amount,omitempty"`
+}
@@ -0,0 +1,39 @@
+package entities
+
+import "time"
+
+type PaymentEventType string
+
+var (
+	PaymentEventTypeCreated          PaymentEventType = "PAYMENT_CREATED"
+	PaymentEventTypeStatusChanged    PaymentEventType = "STATUS_CHANGED"
+	PaymentEventTypeRefundRequested  PaymentEventType = "REFUND_REQUESTED"
+	PaymentEventTypeRefunded         PaymentEventType = "REFUNDED"
+	PaymentEventTypeRefundFailed     PaymentEventType = "REFUND_FAILED"
+	PaymentEventTypeFlaggedForRefund PaymentEventType = "FLAGGED_FOR_REFUND"
+	PaymentEventTypeCompensated      PaymentEventType = "COMPENSATED"
+)
+
+type PaymentEventSource string
+
+var (
+	PaymentEventSourceApi        PaymentEventSource = "api"
+	PaymentEventSourceWebhook    PaymentEventSource = "webhook"
+	PaymentEventSourceReconciler PaymentEventSource = "reconciler"
+	PaymentEventSourceSweeper    PaymentEventSource = "sweeper"
+	PaymentEventSourceAdmin      PaymentEventSource = "admin"
+)
+
+// PaymentEvent is an append-only record of a change made to a payment order.
+type PaymentEvent struct {
+	OrderId     int                `dynamodbav:"OrderId"`
+	EventId     string             `dynamodbav:"EventId"`
+	Type        PaymentEventType   `dynamodbav:"Type"`
+	FromStatus  PaymentStatus      `dynamodbav:"FromStatus"`
+	ToStatus    PaymentStatus      `dynamodbav:"ToStatus"`
+	Source      PaymentEventSource `dynamodbav:"Source"`
+	Actor       string             `dynamodbav:"Actor"`
+	PayloadHash string             `dynamodbav:"PayloadHash"`
+	Reason      string             `dynamodbav:"Reason,omitempty"`
+	CreatedAt   time.Time          `dynamodbav:"CreatedAt,unixtime"`
+}
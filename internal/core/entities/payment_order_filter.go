@@ -0,0 +1,14 @@
+package entities
+
+import "time"
+
+// PaymentOrderFilter selects the payment orders to list. Zero fields select any payment order.
+type PaymentOrderFilter struct {
+	Status      PaymentStatus
+	CustomerCPF string
+	Broker      string
+	CreatedFrom time.Time
+	CreatedTo   time.Time
+	MinAmount   float64
+	MaxAmount   float64
+}
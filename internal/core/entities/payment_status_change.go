@@ -0,0 +1,11 @@
+package entities
+
+import "time"
+
+// PaymentStatusChange tells that a payment order moved to a status, pushed to the clients
+// watching the payment order.
+type PaymentStatusChange struct {
+	OrderId   int
+	Status    PaymentStatus
+	UpdatedAt time.Time
+}
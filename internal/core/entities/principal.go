@@ -0,0 +1,16 @@
+package entities
+
+// Principal is the authenticated caller of the api, with the scopes granted to its token.
+type Principal struct {
+	Subject string
+	Scopes  []string
+}
+
+func (p Principal) HasScope(scope string) bool {
+	for _, granted := range p.Scopes {
+		if granted == scope {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,35 @@
+package entities
+
+// ProductionOrder is what the production service needs to start preparing a paid order.
+type ProductionOrder struct {
+	OrderId     int                   `json:"orderId"`
+	CustomerCPF string                `json:"customerCpf"`
+	Items       []ProductionOrderItem `json:"items"`
+}
+
+type ProductionOrderItem struct {
+	SkuId    string `json:"skuId"`
+	Name     string `json:"name"`
+	Category string `json:"category"`
+	Type     string `json:"type"`
+	Quantity int    `json:"quantity"`
+}
+
+func (p PaymentOrder) ToProductionOrder() ProductionOrder {
+	items := []ProductionOrderItem{}
+	for _, item := range p.Items {
+		items = append(items, ProductionOrderItem{
+			SkuId:    item.SkuId,
+			Name:     item.Name,
+			Category: item.Category,
+			Type:     item.Type,
+			Quantity: item.Quantity,
+		})
+	}
+
+	return ProductionOrder{
+		OrderId:     p.OrderId,
+		CustomerCPF: p.CustomerCPF,
+		Items:       items,
+	}
+}
@@ -0,0 +1,70 @@
+// Package errors classifies failures by kind, so the adapters can react to them, e.g. with an
+// HTTP status code, without knowing which gateway or use case produced them.
+package errors
+
+import "errors"
+
+type Kind string
+
+const (
+	KindNotFound            Kind = "NOT_FOUND"
+	KindConflict            Kind = "CONFLICT"
+	KindValidation          Kind = "VALIDATION"
+	KindUpstreamUnavailable Kind = "UPSTREAM_UNAVAILABLE"
+	KindUpstreamRejected    Kind = "UPSTREAM_REJECTED"
+	KindUnauthenticated     Kind = "UNAUTHENTICATED"
+	KindForbidden           Kind = "FORBIDDEN"
+	KindRateLimited         Kind = "RATE_LIMITED"
+)
+
+// Error is a failure of a known kind. Sentinel errors are declared with New and matched with
+// errors.Is, while Wrap adds a kind to an error coming from elsewhere.
+type Error struct {
+	kind    Kind
+	message string
+	err     error
+}
+
+func New(kind Kind, message string) *Error {
+	return &Error{kind: kind, message: message}
+}
+
+func Wrap(kind Kind, err error) *Error {
+	return &Error{kind: kind, err: err}
+}
+
+func (e *Error) Error() string {
+	if e.err != nil {
+		return e.err.Error()
+	}
+	return e.message
+}
+
+func (e *Error) Unwrap() error {
+	return e.err
+}
+
+func (e *Error) Kind() Kind {
+	return e.kind
+}
+
+// KindOf returns the kind of the first error in the chain that has one.
+func KindOf(err error) (Kind, bool) {
+	var kinded interface{ Kind() Kind }
+	if errors.As(err, &kinded) {
+		return kinded.Kind(), true
+	}
+	return "", false
+}
+
+// UpstreamStatusKind classifies a non-2xx answer from another service: the request itself was
+// refused on 400, 409, 410 and 422. Any other status, such as a timeout, throttling or a missing
+// credential, may succeed later and is taken as unavailable like 5xx.
+func UpstreamStatusKind(statusCode int) Kind {
+	switch statusCode {
+	case 400, 409, 410, 422:
+		return KindUpstreamRejected
+	default:
+		return KindUpstreamUnavailable
+	}
+}
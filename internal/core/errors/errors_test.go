@@ -0,0 +1,43 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKindOf(t *testing.T) {
+	errNotFound := New(KindNotFound, "payment order not found")
+
+	tests := []struct {
+		name     string
+		err      error
+		wantKind Kind
+		wantOk   bool
+	}{
+		{name: "should return the kind of a sentinel error", err: errNotFound, wantKind: KindNotFound, wantOk: true},
+		{name: "should return the kind of a wrapped sentinel error", err: fmt.Errorf("%w: order [123]", errNotFound), wantKind: KindNotFound, wantOk: true},
+		{name: "should return the kind given to an error", err: Wrap(KindUpstreamUnavailable, errors.New("timeout")), wantKind: KindUpstreamUnavailable, wantOk: true},
+		{name: "should not return a kind for an unclassified error", err: errors.New("internal error"), wantKind: "", wantOk: false},
+	}
+
+	for _, tt := range tests {
+		kind, ok := KindOf(tt.err)
+
+		assert.Equal(t, tt.wantKind, kind, tt.name)
+		assert.Equal(t, tt.wantOk, ok, tt.name)
+	}
+}
+
+func TestUpstreamStatusKind(t *testing.T) {
+	assert.Equal(t, KindUpstreamRejected, UpstreamStatusKind(400))
+	assert.Equal(t, KindUpstreamRejected, UpstreamStatusKind(409))
+	assert.Equal(t, KindUpstreamRejected, UpstreamStatusKind(422))
+	assert.Equal(t, KindUpstreamUnavailable, UpstreamStatusKind(401))
+	assert.Equal(t, KindUpstreamUnavailable, UpstreamStatusKind(404))
+	assert.Equal(t, KindUpstreamUnavailable, UpstreamStatusKind(408))
+	assert.Equal(t, KindUpstreamUnavailable, UpstreamStatusKind(429))
+	assert.Equal(t, KindUpstreamUnavailable, UpstreamStatusKind(503))
+}
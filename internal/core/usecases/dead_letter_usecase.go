@@ -0,0 +1,167 @@
+package usecases
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/entities"
+	coreErrors "github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/errors"
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/usecases/dto"
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/infra/gateways"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type DeadLetterUseCase interface {
+	GetDeadLetters(deadLetterType entities.DeadLetterType) ([]dto.DeadLetterDTO, error)
+	GetDeadLetter(messageId string) (dto.DeadLetterDTO, error)
+	ReplayDeadLetter(messageId string) error
+	ReplayDeadLetters(deadLetterType entities.DeadLetterType) (dto.DeadLetterReplayReport, error)
+	DiscardDeadLetter(messageId string) error
+}
+
+var ErrDeadLetterNotReplayable = coreErrors.New(coreErrors.KindValidation, "dead letter cannot be replayed")
+
+type deadLetterUseCase struct {
+	deadLetterRepository gateways.DeadLetterRepositoryGateway
+	notificationQueue    gateways.NotificationQueue
+	paymentUseCase       PaymentUseCase
+	productionClient     gateways.ProductionClient
+}
+
+type DeadLetterUseCaseConfig struct {
+	DeadLetterRepository gateways.DeadLetterRepositoryGateway
+	NotificationQueue    gateways.NotificationQueue
+	PaymentUseCase       PaymentUseCase
+	ProductionClient     gateways.ProductionClient
+}
+
+func NewDeadLetterUseCase(config DeadLetterUseCaseConfig) deadLetterUseCase {
+	return deadLetterUseCase{
+		deadLetterRepository: config.DeadLetterRepository,
+		notificationQueue:    config.NotificationQueue,
+		paymentUseCase:       config.PaymentUseCase,
+		productionClient:     config.ProductionClient,
+	}
+}
+
+func (u deadLetterUseCase) GetDeadLetters(deadLetterType entities.DeadLetterType) ([]dto.DeadLetterDTO, error) {
+	deadLetters, err := u.deadLetterRepository.GetDeadLetters(deadLetterType)
+	if err != nil {
+		log.Errorf("failed to get dead letters, error: %v", err)
+		return nil, err
+	}
+
+	deadLetterDTOs := []dto.DeadLetterDTO{}
+	for _, deadLetter := range deadLetters {
+		deadLetterDTOs = append(deadLetterDTOs, dto.NewDeadLetterDTO(deadLetter))
+	}
+
+	return deadLetterDTOs, nil
+}
+
+func (u deadLetterUseCase) GetDeadLetter(messageId string) (dto.DeadLetterDTO, error) {
+	deadLetter, err := u.deadLetterRepository.GetDeadLetter(messageId)
+	if err != nil {
+		return dto.DeadLetterDTO{}, err
+	}
+
+	return dto.NewDeadLetterDTO(deadLetter), nil
+}
+
+func (u deadLetterUseCase) ReplayDeadLetter(messageId string) error {
+	deadLetter, err := u.deadLetterRepository.GetDeadLetter(messageId)
+	if err != nil {
+		return err
+	}
+
+	return u.replay(deadLetter)
+}
+
+// ReplayDeadLetters replays every dead letter of the given type, or of every type when it is
+// empty. A failed replay does not stop the others and is listed in the report.
+func (u deadLetterUseCase) ReplayDeadLetters(deadLetterType entities.DeadLetterType) (dto.DeadLetterReplayReport, error) {
+	deadLetters, err := u.deadLetterRepository.GetDeadLetters(deadLetterType)
+	if err != nil {
+		log.Errorf("failed to get dead letters, error: %v", err)
+		return dto.DeadLetterReplayReport{}, err
+	}
+
+	report := dto.DeadLetterReplayReport{Failed: []dto.DeadLetterReplayFailure{}}
+	for _, deadLetter := range deadLetters {
+		err := u.replay(deadLetter)
+		if err != nil {
+			report.Failed = append(report.Failed, dto.DeadLetterReplayFailure{MessageId: deadLetter.MessageId, Error: err.Error()})
+			continue
+		}
+		report.Replayed++
+	}
+
+	return report, nil
+}
+
+func (u deadLetterUseCase) DiscardDeadLetter(messageId string) error {
+	_, err := u.deadLetterRepository.GetDeadLetter(messageId)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("discarding dead letter [%s]", messageId)
+	return u.deadLetterRepository.DeleteDeadLetter(messageId)
+}
+
+// replay delivers the dead letter again and removes it from the store. When the delivery fails
+// the dead letter is kept with the new attempt count and error.
+func (u deadLetterUseCase) replay(deadLetter entities.DeadLetter) error {
+	err := u.redeliver(deadLetter)
+	if errors.Is(err, ErrDeadLetterNotReplayable) {
+		return err
+	}
+	if err != nil {
+		log.Errorf("failed to replay dead letter [%s], error: %v", deadLetter.MessageId, err)
+		deadLetter.Attempts++
+		deadLetter.LastError = err.Error()
+		saveErr := u.deadLetterRepository.SaveDeadLetter(deadLetter)
+		if saveErr != nil {
+			log.Errorf("failed to update dead letter [%s], error: %v", deadLetter.MessageId, saveErr)
+		}
+		return err
+	}
+
+	return u.deadLetterRepository.DeleteDeadLetter(deadLetter.MessageId)
+}
+
+func (u deadLetterUseCase) redeliver(deadLetter entities.DeadLetter) error {
+	switch deadLetter.Type {
+	case entities.DeadLetterTypeOrderNotification:
+		var orderNotification entities.OrderNotification
+		err := json.Unmarshal([]byte(deadLetter.Payload), &orderNotification)
+		if err != nil {
+			return fmt.Errorf("%w: invalid payload, error: %v", ErrDeadLetterNotReplayable, err)
+		}
+		// replayed through the payment use case, which checks the payment order still has the status
+		return u.paymentUseCase.ReplayOrderNotification(orderNotification)
+	case entities.DeadLetterTypeProductionNotification:
+		var productionOrder entities.ProductionOrder
+		err := json.Unmarshal([]byte(deadLetter.Payload), &productionOrder)
+		if err != nil {
+			return fmt.Errorf("%w: invalid payload, error: %v", ErrDeadLetterNotReplayable, err)
+		}
+		return u.productionClient.SubmitProductionOrder(productionOrder)
+	case entities.DeadLetterTypeBrokerNotification:
+		var message entities.NotificationMessage
+		err := json.Unmarshal([]byte(deadLetter.Payload), &message)
+		if err != nil {
+			return fmt.Errorf("%w: invalid payload, error: %v", ErrDeadLetterNotReplayable, err)
+		}
+		// the message goes back to the queue as a new delivery, with every retry available again
+		message.Attempts = 0
+		message.LastError = ""
+		message.VisibleAt = time.Time{}
+		return u.notificationQueue.Enqueue(message)
+	default:
+		return fmt.Errorf("%w: unknown type [%s]", ErrDeadLetterNotReplayable, deadLetter.Type)
+	}
+}
@@ -0,0 +1,244 @@
+package usecases
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/entities"
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/usecases/dto"
+	mock_usecases "github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/usecases/mocks"
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/infra/gateways"
+	mock_gateways "github.com/IgorRamosBR/g73-techchallenge-payment/internal/infra/gateways/mocks"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestDeadLetterUseCase_ReplayDeadLetter(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	deadLetterRepository := mock_gateways.NewMockDeadLetterRepositoryGateway(ctrl)
+	notificationQueue := mock_gateways.NewMockNotificationQueue(ctrl)
+	paymentUseCase := mock_usecases.NewMockPaymentUseCase(ctrl)
+	productionClient := mock_gateways.NewMockProductionClient(ctrl)
+
+	orderDeadLetter := entities.DeadLetter{
+		MessageId: "1714564800000000000-a1b2c3d4",
+		Type:      entities.DeadLetterTypeOrderNotification,
+		Payload:   `{"orderId":123,"status":"PAID"}`,
+		Attempts:  1,
+		LastError: "failed to call order api, status [503] non-2xx",
+	}
+
+	type want struct {
+		err error
+	}
+	type getDeadLetterCall struct {
+		times      int
+		deadLetter entities.DeadLetter
+		err        error
+	}
+	type replayOrderNotificationCall struct {
+		times int
+		err   error
+	}
+	type productionClientCall struct {
+		times int
+	}
+	type enqueueCall struct {
+		times int
+	}
+	type saveDeadLetterCall struct {
+		times int
+	}
+	type deleteDeadLetterCall struct {
+		times int
+	}
+	tests := []struct {
+		name string
+		want
+		getDeadLetterCall
+		replayOrderNotificationCall
+		productionClientCall
+		enqueueCall
+		saveDeadLetterCall
+		deleteDeadLetterCall
+	}{
+		{
+			name: "should fail to replay when the dead letter does not exist",
+			want: want{
+				err: gateways.ErrDeadLetterNotFound,
+			},
+			getDeadLetterCall: getDeadLetterCall{
+				times: 1,
+				err:   gateways.ErrDeadLetterNotFound,
+			},
+		},
+		{
+			name: "should not replay a dead letter of an unknown type",
+			want: want{
+				err: errors.New("dead letter cannot be replayed: unknown type [UNKNOWN]"),
+			},
+			getDeadLetterCall: getDeadLetterCall{
+				times:      1,
+				deadLetter: entities.DeadLetter{MessageId: "1714564800000000000-a1b2c3d4", Type: "UNKNOWN"},
+			},
+		},
+		{
+			name: "should keep the dead letter with one more attempt when the order api fails again",
+			want: want{
+				err: errors.New("failed to call order api, status [503] non-2xx"),
+			},
+			getDeadLetterCall: getDeadLetterCall{
+				times:      1,
+				deadLetter: orderDeadLetter,
+			},
+			replayOrderNotificationCall: replayOrderNotificationCall{
+				times: 1,
+				err:   errors.New("failed to call order api, status [503] non-2xx"),
+			},
+			saveDeadLetterCall: saveDeadLetterCall{
+				times: 1,
+			},
+		},
+		{
+			name: "should notify the order api and remove the dead letter",
+			getDeadLetterCall: getDeadLetterCall{
+				times:      1,
+				deadLetter: orderDeadLetter,
+			},
+			replayOrderNotificationCall: replayOrderNotificationCall{
+				times: 1,
+			},
+			deleteDeadLetterCall: deleteDeadLetterCall{
+				times: 1,
+			},
+		},
+		{
+			name: "should submit the production order and remove the dead letter",
+			getDeadLetterCall: getDeadLetterCall{
+				times: 1,
+				deadLetter: entities.DeadLetter{
+					MessageId: "1714564800000000000-a1b2c3d4",
+					Type:      entities.DeadLetterTypeProductionNotification,
+					Payload:   `{"orderId":123,"customerCpf":"12345678900","items":[{"skuId":"1","name":"X-Burger","category":"Lanche","type":"UNIT","quantity":2}]}`,
+				},
+			},
+			productionClientCall: productionClientCall{
+				times: 1,
+			},
+			deleteDeadLetterCall: deleteDeadLetterCall{
+				times: 1,
+			},
+		},
+		{
+			name: "should enqueue the broker notification again and remove the dead letter",
+			getDeadLetterCall: getDeadLetterCall{
+				times: 1,
+				deadLetter: entities.DeadLetter{
+					MessageId: "1714564800000000000-a1b2c3d4",
+					Type:      entities.DeadLetterTypeBrokerNotification,
+					Payload:   `{"MessageId":"1714564800000000000-a1b2c3d4","Topic":"payment","ResourceId":7890,"Attempts":5,"LastError":"timeout"}`,
+				},
+			},
+			enqueueCall: enqueueCall{
+				times: 1,
+			},
+			deleteDeadLetterCall: deleteDeadLetterCall{
+				times: 1,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		deadLetterRepository.EXPECT().
+			GetDeadLetter(gomock.Eq("1714564800000000000-a1b2c3d4")).
+			Times(tt.getDeadLetterCall.times).
+			Return(tt.getDeadLetterCall.deadLetter, tt.getDeadLetterCall.err)
+
+		paymentUseCase.EXPECT().
+			ReplayOrderNotification(gomock.Eq(entities.OrderNotification{OrderId: 123, Status: entities.PaymentStatusPaid})).
+			Times(tt.replayOrderNotificationCall.times).
+			Return(tt.replayOrderNotificationCall.err)
+
+		productionClient.EXPECT().
+			SubmitProductionOrder(gomock.Eq(entities.ProductionOrder{
+				OrderId:     123,
+				CustomerCPF: "12345678900",
+				Items: []entities.ProductionOrderItem{
+					{SkuId: "1", Name: "X-Burger", Category: "Lanche", Type: "UNIT", Quantity: 2},
+				},
+			})).
+			Times(tt.productionClientCall.times).
+			Return(nil)
+
+		notificationQueue.EXPECT().
+			Enqueue(gomock.Eq(entities.NotificationMessage{
+				MessageId:  "1714564800000000000-a1b2c3d4",
+				Topic:      "payment",
+				ResourceId: 7890,
+			})).
+			Times(tt.enqueueCall.times).
+			Return(nil)
+
+		deadLetterRepository.EXPECT().
+			SaveDeadLetter(gomock.Cond(func(x any) bool {
+				deadLetter := x.(entities.DeadLetter)
+				return deadLetter.Attempts == 2 && deadLetter.LastError == "failed to call order api, status [503] non-2xx"
+			})).
+			Times(tt.saveDeadLetterCall.times).
+			Return(nil)
+
+		deadLetterRepository.EXPECT().
+			DeleteDeadLetter(gomock.Eq("1714564800000000000-a1b2c3d4")).
+			Times(tt.deleteDeadLetterCall.times).
+			Return(nil)
+
+		config := DeadLetterUseCaseConfig{
+			DeadLetterRepository: deadLetterRepository,
+			NotificationQueue:    notificationQueue,
+			PaymentUseCase:       paymentUseCase,
+			ProductionClient:     productionClient,
+		}
+		deadLetterUseCase := NewDeadLetterUseCase(config)
+
+		err := deadLetterUseCase.ReplayDeadLetter("1714564800000000000-a1b2c3d4")
+
+		if tt.want.err == nil {
+			assert.Nil(t, err)
+		} else {
+			assert.EqualError(t, err, tt.want.err.Error())
+		}
+	}
+}
+
+func TestDeadLetterUseCase_ReplayDeadLetters(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	deadLetterRepository := mock_gateways.NewMockDeadLetterRepositoryGateway(ctrl)
+	paymentUseCase := mock_usecases.NewMockPaymentUseCase(ctrl)
+
+	deadLetterRepository.EXPECT().
+		GetDeadLetters(gomock.Eq(entities.DeadLetterTypeOrderNotification)).
+		Times(1).
+		Return([]entities.DeadLetter{
+			{MessageId: "1", Type: entities.DeadLetterTypeOrderNotification, Payload: `{"orderId":123,"status":"PAID"}`, Attempts: 1},
+			{MessageId: "2", Type: entities.DeadLetterTypeOrderNotification, Payload: `{"orderId":124,"status":"EXPIRED"}`, Attempts: 1},
+		}, nil)
+
+	paymentUseCase.EXPECT().ReplayOrderNotification(gomock.Eq(entities.OrderNotification{OrderId: 123, Status: entities.PaymentStatusPaid})).Times(1).Return(nil)
+	paymentUseCase.EXPECT().ReplayOrderNotification(gomock.Eq(entities.OrderNotification{OrderId: 124, Status: entities.PaymentStatusExpired})).Times(1).Return(errors.New("order not found"))
+	deadLetterRepository.EXPECT().DeleteDeadLetter(gomock.Eq("1")).Times(1).Return(nil)
+	deadLetterRepository.EXPECT().SaveDeadLetter(gomock.Any()).Times(1).Return(nil)
+
+	config := DeadLetterUseCaseConfig{
+		DeadLetterRepository: deadLetterRepository,
+		PaymentUseCase:       paymentUseCase,
+	}
+	deadLetterUseCase := NewDeadLetterUseCase(config)
+
+	report, err := deadLetterUseCase.ReplayDeadLetters(entities.DeadLetterTypeOrderNotification)
+
+	assert.Nil(t, err)
+	assert.Equal(t, dto.DeadLetterReplayReport{
+		Replayed: 1,
+		Failed:   []dto.DeadLetterReplayFailure{{MessageId: "2", Error: "order not found"}},
+	}, report)
+}
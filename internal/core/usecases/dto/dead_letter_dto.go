@@ -0,0 +1,40 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/entities"
+)
+
+type DeadLetterDTO struct {
+	MessageId string                  `json:"messageId"`
+	Type      entities.DeadLetterType `json:"type"`
+	Payload   json.RawMessage         `json:"payload"`
+	Attempts  int                     `json:"attempts"`
+	LastError string                  `json:"lastError"`
+	CreatedAt time.Time               `json:"createdAt"`
+	UpdatedAt time.Time               `json:"updatedAt"`
+}
+
+func NewDeadLetterDTO(deadLetter entities.DeadLetter) DeadLetterDTO {
+	return DeadLetterDTO{
+		MessageId: deadLetter.MessageId,
+		Type:      deadLetter.Type,
+		Payload:   json.RawMessage(deadLetter.Payload),
+		Attempts:  deadLetter.Attempts,
+		LastError: deadLetter.LastError,
+		CreatedAt: deadLetter.CreatedAt,
+		UpdatedAt: deadLetter.UpdatedAt,
+	}
+}
+
+type DeadLetterReplayReport struct {
+	Replayed int                       `json:"replayed"`
+	Failed   []DeadLetterReplayFailure `json:"failed"`
+}
+
+type DeadLetterReplayFailure struct {
+	MessageId string `json:"messageId"`
+	Error     string `json:"error"`
+}
@@ -0,0 +1,163 @@
+package dto
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"strconv"
+)
+
+type NotificationTopic string
+
+const (
+	NotificationTopicPayment       NotificationTopic = "payment"
+	NotificationTopicMerchantOrder NotificationTopic = "merchant_order"
+)
+
+var ErrNotificationTopicMissing = errors.New("notification has no topic")
+
+// MercadoPagoNotificationDTO holds a notification in any of the formats sent to the notification url:
+// IPN ({resource, topic} or ?topic=&id=), Webhooks v2 ({action, type, data.id} or ?type=&data.id=)
+// and the legacy {description, merchant_order, payment_id} body.
+type MercadoPagoNotificationDTO struct {
+	Action   string                      `json:"action"`
+	Type     string                      `json:"type"`
+	Data     MercadoPagoNotificationData `json:"data"`
+	Topic    string                      `json:"topic"`
+	Resource string                      `json:"resource"`
+	PaymentNotificationDTO
+}
+
+type MercadoPagoNotificationData struct {
+	Id json.Number `json:"id"`
+}
+
+// BrokerNotification points to the broker resource that changed. Notifications carry no payment
+// status, so the resource must be fetched from the broker before acting on it.
+type BrokerNotification struct {
+	NotificationId string
+	Topic          NotificationTopic
+	ResourceId     int
+	Action         string
+	PayloadHash    string
+}
+
+// ParseMercadoPagoNotification reads the notification from the body and the query string. IPN may
+// be delivered with an empty body, so query parameters take precedence over the body fields.
+func ParseMercadoPagoNotification(query url.Values, payload []byte) (MercadoPagoNotificationDTO, error) {
+	var notification MercadoPagoNotificationDTO
+	if len(bytes.TrimSpace(payload)) > 0 {
+		err := json.Unmarshal(payload, &notification)
+		if err != nil {
+			return MercadoPagoNotificationDTO{}, err
+		}
+	}
+
+	if topic := query.Get("topic"); topic != "" {
+		notification.Topic = topic
+	}
+	if id := query.Get("id"); id != "" {
+		notification.Resource = id
+	}
+	if notificationType := query.Get("type"); notificationType != "" {
+		notification.Type = notificationType
+	}
+	if dataId := query.Get("data.id"); dataId != "" {
+		notification.Data.Id = json.Number(dataId)
+	}
+
+	if notification.GetTopic() == "" && !notification.IsLegacy() {
+		return MercadoPagoNotificationDTO{}, ErrNotificationTopicMissing
+	}
+
+	return notification, nil
+}
+
+// IsLegacy reports whether the notification uses the legacy body, which already names the payment.
+// The body is not trusted any more than the others, so it is handled as a notification of the
+// payment topic and the payment is fetched from the broker.
+func (n MercadoPagoNotificationDTO) IsLegacy() bool {
+	return n.GetTopic() == "" && n.PaymentId != 0
+}
+
+// IsSigned reports whether Mercado Pago signs the notification, which it only does for the
+// Webhooks v2 ones.
+func (n MercadoPagoNotificationDTO) IsSigned() bool {
+	return n.Type != ""
+}
+
+func (n MercadoPagoNotificationDTO) GetTopic() NotificationTopic {
+	if n.Type != "" {
+		return NotificationTopic(n.Type)
+	}
+	return NotificationTopic(n.Topic)
+}
+
+// IsRelevant reports whether the notification may change a payment. Other topics, such as
+// chargebacks or point integration events, are acknowledged and ignored.
+func (n MercadoPagoNotificationDTO) IsRelevant() bool {
+	topic := n.getBrokerTopic()
+	return topic == NotificationTopicPayment || topic == NotificationTopicMerchantOrder
+}
+
+// GetNotificationId identifies the notification across broker retries: the x-request-id header
+// when it is sent, otherwise the notified resource and action.
+func (n MercadoPagoNotificationDTO) GetNotificationId(requestId string) string {
+	if requestId != "" {
+		return requestId
+	}
+	if n.IsLegacy() {
+		return fmt.Sprintf("%d:%s", n.PaymentId, NotificationTopicPayment)
+	}
+
+	action := n.Action
+	if action == "" {
+		action = string(n.GetTopic())
+	}
+	return fmt.Sprintf("%s:%s", n.getResourceId(), action)
+}
+
+func (n MercadoPagoNotificationDTO) ToBrokerNotification(payload []byte, requestId string) (BrokerNotification, error) {
+	resourceId := n.getResourceId()
+	if resourceId == "" {
+		return BrokerNotification{}, errors.New("notification has no resource id")
+	}
+
+	id, err := strconv.Atoi(resourceId)
+	if err != nil {
+		return BrokerNotification{}, fmt.Errorf("notification resource id [%s] is invalid", resourceId)
+	}
+
+	return BrokerNotification{
+		NotificationId: n.GetNotificationId(requestId),
+		Topic:          n.getBrokerTopic(),
+		ResourceId:     id,
+		Action:         n.Action,
+		PayloadHash:    HashPayload(payload),
+	}, nil
+}
+
+// getBrokerTopic is the topic of the broker resource the notification points to.
+func (n MercadoPagoNotificationDTO) getBrokerTopic() NotificationTopic {
+	if n.IsLegacy() {
+		return NotificationTopicPayment
+	}
+	return n.GetTopic()
+}
+
+func (n MercadoPagoNotificationDTO) getResourceId() string {
+	if n.IsLegacy() {
+		return strconv.Itoa(n.PaymentId)
+	}
+	if n.Data.Id != "" {
+		return n.Data.Id.String()
+	}
+	if n.Resource != "" {
+		// IPN sends either the id or the resource url, e.g. https://api.mercadolibre.com/merchant_orders/123
+		return path.Base(n.Resource)
+	}
+	return ""
+}
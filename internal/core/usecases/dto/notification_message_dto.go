@@ -0,0 +1,33 @@
+package dto
+
+import "github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/entities"
+
+func (n BrokerNotification) ToNotificationMessage() entities.NotificationMessage {
+	return entities.NotificationMessage{
+		NotificationId: n.NotificationId,
+		Topic:          string(n.Topic),
+		ResourceId:     n.ResourceId,
+		Action:         n.Action,
+		PayloadHash:    n.PayloadHash,
+	}
+}
+
+// NewBrokerNotification reads the notification of a queued message. Legacy messages, queued before
+// the legacy body was verified with the broker, name the payment and become payment notifications.
+func NewBrokerNotification(message entities.NotificationMessage) BrokerNotification {
+	if message.IsLegacy() {
+		return BrokerNotification{
+			NotificationId: message.NotificationId,
+			Topic:          NotificationTopicPayment,
+			ResourceId:     message.PaymentId,
+			PayloadHash:    message.PayloadHash,
+		}
+	}
+	return BrokerNotification{
+		NotificationId: message.NotificationId,
+		Topic:          NotificationTopic(message.Topic),
+		ResourceId:     message.ResourceId,
+		Action:         message.Action,
+		PayloadHash:    message.PayloadHash,
+	}
+}
@@ -0,0 +1,10 @@
+package dto
+
+// OrderDTO is the order as known by the order service, the reference for what must be charged.
+type OrderDTO struct {
+	Id          int                `json:"id"`
+	CustomerCPF string             `json:"customerCpf"`
+	Items       []PaymentOrderItem `json:"items"`
+	TotalAmount float64            `json:"totalAmount"`
+	Status      string             `json:"status"`
+}
@@ -1,6 +1,8 @@
 package dto
 
 import (
+	"time"
+
 	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/entities"
 	"github.com/asaskevich/govalidator"
 )
@@ -12,13 +14,22 @@ type PaymentOrderDTO struct {
 	TotalAmount float64            `json:"totalAmount"  valid:"float,required~TotalAmount is required"`
 }
 
-func (p PaymentOrderDTO) ToPaymentOrder(qrCode string) entities.PaymentOrder {
+func (p PaymentOrderDTO) ToPaymentOrder(qrCode string, createdAt, expiresAt time.Time) entities.PaymentOrder {
+	var items []entities.PaymentItem
+	for _, item := range p.Items {
+		items = append(items, item.ToPaymentItem())
+	}
+
 	return entities.PaymentOrder{
 		OrderId:     p.OrderId,
 		CustomerCPF: p.CustomerCPF,
+		Items:       items,
 		TotalAmout:  p.TotalAmount,
 		Status:      entities.PaymentStatusPending,
 		QRCode:      qrCode,
+		CreatedAt:   createdAt,
+		UpdatedAt:   createdAt,
+		ExpiresAt:   expiresAt,
 	}
 }
 
@@ -27,6 +38,17 @@ type PaymentOrderItem struct {
 	Product  OrderItemProduct `json:"product" valid:"required~Product is required"`
 }
 
+func (i PaymentOrderItem) ToPaymentItem() entities.PaymentItem {
+	return entities.PaymentItem{
+		SkuId:     i.Product.SkuId,
+		Name:      i.Product.Name,
+		Category:  i.Product.Category,
+		Type:      i.Product.Type,
+		Quantity:  i.Quantity,
+		UnitPrice: i.Product.Price,
+	}
+}
+
 type OrderItemType string
 
 const (
@@ -55,3 +77,74 @@ func (p PaymentOrderDTO) ValidatePaymentOrder() (bool, error) {
 type PaymentQRCode struct {
 	QRCode string `json:"qrcode"`
 }
+
+type PaymentDTO struct {
+	OrderId         int                    `json:"orderId"`
+	PaymentId       int                    `json:"paymentId,omitempty"`
+	MerchantOrderId int                    `json:"merchantOrderId,omitempty"`
+	Broker          string                 `json:"broker,omitempty"`
+	InStoreOrderId  string                 `json:"inStoreOrderId,omitempty"`
+	CustomerCPF     string                 `json:"customerCpf"`
+	Items           []PaymentOrderItem     `json:"items"`
+	TotalAmount     float64                `json:"totalAmount"`
+	RefundedAmount  float64                `json:"refundedAmount"`
+	Status          entities.PaymentStatus `json:"status"`
+	CreatedAt       time.Time              `json:"createdAt"`
+	UpdatedAt       time.Time              `json:"updatedAt"`
+	ExpiresAt       time.Time              `json:"expiresAt"`
+	// PaidAt is only set once the payment order was paid.
+	PaidAt *time.Time `json:"paidAt,omitempty"`
+}
+
+func NewPaymentDTO(paymentOrder entities.PaymentOrder) PaymentDTO {
+	items := []PaymentOrderItem{}
+	for _, item := range paymentOrder.Items {
+		items = append(items, PaymentOrderItem{
+			Quantity: item.Quantity,
+			Product: OrderItemProduct{
+				Name:     item.Name,
+				SkuId:    item.SkuId,
+				Category: item.Category,
+				Type:     item.Type,
+				Price:    item.UnitPrice,
+			},
+		})
+	}
+
+	var paidAt *time.Time
+	if !paymentOrder.PaidAt.IsZero() {
+		paidAt = &paymentOrder.PaidAt
+	}
+
+	return PaymentDTO{
+		OrderId:         paymentOrder.OrderId,
+		PaymentId:       paymentOrder.PaymentId,
+		MerchantOrderId: paymentOrder.MerchantOrderId,
+		Broker:          paymentOrder.Broker,
+		InStoreOrderId:  paymentOrder.InStoreOrderId,
+		CustomerCPF:     paymentOrder.CustomerCPF,
+		Items:           items,
+		TotalAmount:     paymentOrder.TotalAmout,
+		RefundedAmount:  paymentOrder.RefundedAmount,
+		Status:          paymentOrder.Status,
+		CreatedAt:       paymentOrder.CreatedAt,
+		UpdatedAt:       paymentOrder.UpdatedAt,
+		ExpiresAt:       paymentOrder.ExpiresAt,
+		PaidAt:          paidAt,
+	}
+}
+
+// PaymentStatusDTO is pushed to the clients watching a payment order, on every status change.
+type PaymentStatusDTO struct {
+	OrderId   int                    `json:"orderId"`
+	Status    entities.PaymentStatus `json:"status"`
+	UpdatedAt time.Time              `json:"updatedAt"`
+}
+
+func NewPaymentStatusDTO(change entities.PaymentStatusChange) PaymentStatusDTO {
+	return PaymentStatusDTO{
+		OrderId:   change.OrderId,
+		Status:    change.Status,
+		UpdatedAt: change.UpdatedAt,
+	}
+}
@@ -0,0 +1,33 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/entities"
+)
+
+type PaymentEventDTO struct {
+	EventId     string                      `json:"eventId"`
+	Type        entities.PaymentEventType   `json:"type"`
+	FromStatus  entities.PaymentStatus      `json:"fromStatus,omitempty"`
+	ToStatus    entities.PaymentStatus      `json:"toStatus,omitempty"`
+	Source      entities.PaymentEventSource `json:"source"`
+	Actor       string                      `json:"actor,omitempty"`
+	PayloadHash string                      `json:"payloadHash,omitempty"`
+	Reason      string                      `json:"reason,omitempty"`
+	CreatedAt   time.Time                   `json:"createdAt"`
+}
+
+func NewPaymentEventDTO(paymentEvent entities.PaymentEvent) PaymentEventDTO {
+	return PaymentEventDTO{
+		EventId:     paymentEvent.EventId,
+		Type:        paymentEvent.Type,
+		FromStatus:  paymentEvent.FromStatus,
+		ToStatus:    paymentEvent.ToStatus,
+		Source:      paymentEvent.Source,
+		Actor:       paymentEvent.Actor,
+		PayloadHash: paymentEvent.PayloadHash,
+		Reason:      paymentEvent.Reason,
+		CreatedAt:   paymentEvent.CreatedAt,
+	}
+}
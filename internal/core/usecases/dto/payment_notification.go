@@ -1,11 +1,33 @@
 package dto
 
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
 type PaymentNotificationDTO struct {
 	Description   string `json:"description"`
 	MerchantOrder int    `json:"merchant_order"`
 	PaymentId     int    `json:"payment_id"`
 }
 
+// PaymentNotification is a payment confirmation received from the broker.
+type PaymentNotification struct {
+	NotificationId  string
+	OrderId         int
+	PaymentId       int
+	MerchantOrderId int
+	PayloadHash     string
+}
+
+func HashPayload(payload []byte) string {
+	if len(payload) == 0 {
+		return ""
+	}
+	hash := sha256.Sum256(payload)
+	return hex.EncodeToString(hash[:])
+}
+
 type PaymentOrderStatusDTO struct {
 	Status string `json:"status"`
 }
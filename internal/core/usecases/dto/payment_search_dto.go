@@ -0,0 +1,67 @@
+package dto
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/entities"
+)
+
+const (
+	DefaultPaymentSearchLimit = 20
+	MaxPaymentSearchLimit     = 100
+)
+
+// PaymentSearchDTO holds the filters of a payment order listing, the page size and the cursor
+// of the page, as received in the query string.
+type PaymentSearchDTO struct {
+	Status      string    `form:"status"`
+	CustomerCPF string    `form:"customerCpf"`
+	Broker      string    `form:"broker"`
+	From        time.Time `form:"from"`
+	To          time.Time `form:"to"`
+	MinAmount   float64   `form:"minAmount"`
+	MaxAmount   float64   `form:"maxAmount"`
+	Limit       int       `form:"limit"`
+	Cursor      string    `form:"cursor"`
+}
+
+func (s PaymentSearchDTO) ValidatePaymentSearch() (bool, error) {
+	if s.Status != "" && !entities.PaymentStatus(s.Status).IsValid() {
+		return false, fmt.Errorf("Status %s is not a payment status", s.Status)
+	}
+	if !s.From.IsZero() && !s.To.IsZero() && s.To.Before(s.From) {
+		return false, errors.New("To must not be before From")
+	}
+	if s.MinAmount < 0 || s.MaxAmount < 0 {
+		return false, errors.New("Amounts must not be negative")
+	}
+	if s.MaxAmount > 0 && s.MaxAmount < s.MinAmount {
+		return false, errors.New("MaxAmount must not be less than MinAmount")
+	}
+	if s.Limit < 0 || s.Limit > MaxPaymentSearchLimit {
+		return false, fmt.Errorf("Limit must be between 1 and %d", MaxPaymentSearchLimit)
+	}
+
+	return true, nil
+}
+
+func (s PaymentSearchDTO) ToPaymentOrderFilter() entities.PaymentOrderFilter {
+	return entities.PaymentOrderFilter{
+		Status:      entities.PaymentStatus(s.Status),
+		CustomerCPF: s.CustomerCPF,
+		Broker:      s.Broker,
+		CreatedFrom: s.From,
+		CreatedTo:   s.To,
+		MinAmount:   s.MinAmount,
+		MaxAmount:   s.MaxAmount,
+	}
+}
+
+// PaymentPageDTO is a page of payment orders, with the cursor of the next page unless it is the
+// last one.
+type PaymentPageDTO struct {
+	Items      []PaymentDTO `json:"items"`
+	NextCursor string       `json:"nextCursor,omitempty"`
+}
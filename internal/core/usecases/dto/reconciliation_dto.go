@@ -0,0 +1,31 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/entities"
+)
+
+type ReconciliationReport struct {
+	StartedAt  time.Time             `json:"startedAt"`
+	FinishedAt time.Time             `json:"finishedAt"`
+	Checked    int                   `json:"checked"`
+	Fixed      []ReconciliationEntry `json:"fixed"`
+	Unresolved []ReconciliationEntry `json:"unresolved"`
+}
+
+type ReconciliationEntry struct {
+	OrderId      int                    `json:"orderId"`
+	PaymentId    int                    `json:"paymentId,omitempty"`
+	LocalStatus  entities.PaymentStatus `json:"localStatus"`
+	BrokerStatus string                 `json:"brokerStatus,omitempty"`
+	Reason       string                 `json:"reason,omitempty"`
+}
+
+func NewReconciliationReport(startedAt time.Time) ReconciliationReport {
+	return ReconciliationReport{
+		StartedAt:  startedAt,
+		Fixed:      []ReconciliationEntry{},
+		Unresolved: []ReconciliationEntry{},
+	}
+}
@@ -0,0 +1,27 @@
+package dto
+
+import (
+	"errors"
+
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/entities"
+)
+
+type RefundRequestDTO struct {
+	Amount float64 `json:"amount"`
+}
+
+func (r RefundRequestDTO) ValidateRefundRequest() (bool, error) {
+	if r.Amount < 0 {
+		return false, errors.New("Amount must not be negative")
+	}
+
+	return true, nil
+}
+
+type RefundDTO struct {
+	RefundId       int                    `json:"refundId"`
+	OrderId        int                    `json:"orderId"`
+	Amount         float64                `json:"amount"`
+	RefundedAmount float64                `json:"refundedAmount"`
+	Status         entities.PaymentStatus `json:"status"`
+}
@@ -0,0 +1,114 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: dead_letter_usecase.go
+//
+// Generated by this command:
+//
+//	mockgen -source=dead_letter_usecase.go -destination=mocks/dead_letter_usecase.go
+//
+
+// Package mock_usecases is a generated GoMock package.
+package mock_usecases
+
+import (
+	reflect "reflect"
+
+	entities "github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/entities"
+	dto "github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/usecases/dto"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockDeadLetterUseCase is a mock of DeadLetterUseCase interface.
+type MockDeadLetterUseCase struct {
+	ctrl     *gomock.Controller
+	recorder *MockDeadLetterUseCaseMockRecorder
+}
+
+// MockDeadLetterUseCaseMockRecorder is the mock recorder for MockDeadLetterUseCase.
+type MockDeadLetterUseCaseMockRecorder struct {
+	mock *MockDeadLetterUseCase
+}
+
+// NewMockDeadLetterUseCase creates a new mock instance.
+func NewMockDeadLetterUseCase(ctrl *gomock.Controller) *MockDeadLetterUseCase {
+	mock := &MockDeadLetterUseCase{ctrl: ctrl}
+	mock.recorder = &MockDeadLetterUseCaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDeadLetterUseCase) EXPECT() *MockDeadLetterUseCaseMockRecorder {
+	return m.recorder
+}
+
+// DiscardDeadLetter mocks base method.
+func (m *MockDeadLetterUseCase) DiscardDeadLetter(messageId string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DiscardDeadLetter", messageId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DiscardDeadLetter indicates an expected call of DiscardDeadLetter.
+func (mr *MockDeadLetterUseCaseMockRecorder) DiscardDeadLetter(messageId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DiscardDeadLetter", reflect.TypeOf((*MockDeadLetterUseCase)(nil).DiscardDeadLetter), messageId)
+}
+
+// GetDeadLetter mocks base method.
+func (m *MockDeadLetterUseCase) GetDeadLetter(messageId string) (dto.DeadLetterDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeadLetter", messageId)
+	ret0, _ := ret[0].(dto.DeadLetterDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeadLetter indicates an expected call of GetDeadLetter.
+func (mr *MockDeadLetterUseCaseMockRecorder) GetDeadLetter(messageId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeadLetter", reflect.TypeOf((*MockDeadLetterUseCase)(nil).GetDeadLetter), messageId)
+}
+
+// GetDeadLetters mocks base method.
+func (m *MockDeadLetterUseCase) GetDeadLetters(deadLetterType entities.DeadLetterType) ([]dto.DeadLetterDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeadLetters", deadLetterType)
+	ret0, _ := ret[0].([]dto.DeadLetterDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeadLetters indicates an expected call of GetDeadLetters.
+func (mr *MockDeadLetterUseCaseMockRecorder) GetDeadLetters(deadLetterType any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeadLetters", reflect.TypeOf((*MockDeadLetterUseCase)(nil).GetDeadLetters), deadLetterType)
+}
+
+// ReplayDeadLetter mocks base method.
+func (m *MockDeadLetterUseCase) ReplayDeadLetter(messageId string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplayDeadLetter", messageId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReplayDeadLetter indicates an expected call of ReplayDeadLetter.
+func (mr *MockDeadLetterUseCaseMockRecorder) ReplayDeadLetter(messageId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplayDeadLetter", reflect.TypeOf((*MockDeadLetterUseCase)(nil).ReplayDeadLetter), messageId)
+}
+
+// ReplayDeadLetters mocks base method.
+func (m *MockDeadLetterUseCase) ReplayDeadLetters(deadLetterType entities.DeadLetterType) (dto.DeadLetterReplayReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplayDeadLetters", deadLetterType)
+	ret0, _ := ret[0].(dto.DeadLetterReplayReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReplayDeadLetters indicates an expected call of ReplayDeadLetters.
+func (mr *MockDeadLetterUseCaseMockRecorder) ReplayDeadLetters(deadLetterType any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplayDeadLetters", reflect.TypeOf((*MockDeadLetterUseCase)(nil).ReplayDeadLetters), deadLetterType)
+}
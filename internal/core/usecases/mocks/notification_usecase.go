@@ -0,0 +1,84 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: notification_usecase.go
+//
+// Generated by this command:
+//
+//	mockgen -source=notification_usecase.go -destination=mocks/notification_usecase.go
+//
+
+// Package mock_usecases is a generated GoMock package.
+package mock_usecases
+
+import (
+	reflect "reflect"
+
+	entities "github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/entities"
+	dto "github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/usecases/dto"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockNotificationUseCase is a mock of NotificationUseCase interface.
+type MockNotificationUseCase struct {
+	ctrl     *gomock.Controller
+	recorder *MockNotificationUseCaseMockRecorder
+}
+
+// MockNotificationUseCaseMockRecorder is the mock recorder for MockNotificationUseCase.
+type MockNotificationUseCaseMockRecorder struct {
+	mock *MockNotificationUseCase
+}
+
+// NewMockNotificationUseCase creates a new mock instance.
+func NewMockNotificationUseCase(ctrl *gomock.Controller) *MockNotificationUseCase {
+	mock := &MockNotificationUseCase{ctrl: ctrl}
+	mock.recorder = &MockNotificationUseCaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockNotificationUseCase) EXPECT() *MockNotificationUseCaseMockRecorder {
+	return m.recorder
+}
+
+// EnqueueBrokerNotification mocks base method.
+func (m *MockNotificationUseCase) EnqueueBrokerNotification(notification dto.BrokerNotification) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnqueueBrokerNotification", notification)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EnqueueBrokerNotification indicates an expected call of EnqueueBrokerNotification.
+func (mr *MockNotificationUseCaseMockRecorder) EnqueueBrokerNotification(notification any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnqueueBrokerNotification", reflect.TypeOf((*MockNotificationUseCase)(nil).EnqueueBrokerNotification), notification)
+}
+
+// ProcessNotificationMessage mocks base method.
+func (m *MockNotificationUseCase) ProcessNotificationMessage(message entities.NotificationMessage) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ProcessNotificationMessage", message)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ProcessNotificationMessage indicates an expected call of ProcessNotificationMessage.
+func (mr *MockNotificationUseCaseMockRecorder) ProcessNotificationMessage(message any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProcessNotificationMessage", reflect.TypeOf((*MockNotificationUseCase)(nil).ProcessNotificationMessage), message)
+}
+
+// ReceiveNotificationMessages mocks base method.
+func (m *MockNotificationUseCase) ReceiveNotificationMessages(maxMessages int) ([]entities.NotificationMessage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReceiveNotificationMessages", maxMessages)
+	ret0, _ := ret[0].([]entities.NotificationMessage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReceiveNotificationMessages indicates an expected call of ReceiveNotificationMessages.
+func (mr *MockNotificationUseCaseMockRecorder) ReceiveNotificationMessages(maxMessages any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReceiveNotificationMessages", reflect.TypeOf((*MockNotificationUseCase)(nil).ReceiveNotificationMessages), maxMessages)
+}
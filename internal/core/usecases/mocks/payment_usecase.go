@@ -11,7 +11,9 @@ package mock_usecases
 
 import (
 	reflect "reflect"
+	time "time"
 
+	entities "github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/entities"
 	dto "github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/usecases/dto"
 	gomock "go.uber.org/mock/gomock"
 )
@@ -39,6 +41,20 @@ func (m *MockPaymentUseCase) EXPECT() *MockPaymentUseCaseMockRecorder {
 	return m.recorder
 }
 
+// CancelPaymentOrder mocks base method.
+func (m *MockPaymentUseCase) CancelPaymentOrder(orderId int, actor string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelPaymentOrder", orderId, actor)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CancelPaymentOrder indicates an expected call of CancelPaymentOrder.
+func (mr *MockPaymentUseCaseMockRecorder) CancelPaymentOrder(orderId, actor any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelPaymentOrder", reflect.TypeOf((*MockPaymentUseCase)(nil).CancelPaymentOrder), orderId, actor)
+}
+
 // CreatePaymentOrder mocks base method.
 func (m *MockPaymentUseCase) CreatePaymentOrder(paymentOrder dto.PaymentOrderDTO) (string, error) {
 	m.ctrl.T.Helper()
@@ -54,16 +70,163 @@ func (mr *MockPaymentUseCaseMockRecorder) CreatePaymentOrder(paymentOrder any) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePaymentOrder", reflect.TypeOf((*MockPaymentUseCase)(nil).CreatePaymentOrder), paymentOrder)
 }
 
+// ExpirePaymentOrders mocks base method.
+func (m *MockPaymentUseCase) ExpirePaymentOrders() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExpirePaymentOrders")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExpirePaymentOrders indicates an expected call of ExpirePaymentOrders.
+func (mr *MockPaymentUseCaseMockRecorder) ExpirePaymentOrders() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExpirePaymentOrders", reflect.TypeOf((*MockPaymentUseCase)(nil).ExpirePaymentOrders))
+}
+
+// GetPaymentEvents mocks base method.
+func (m *MockPaymentUseCase) GetPaymentEvents(orderId int) ([]dto.PaymentEventDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPaymentEvents", orderId)
+	ret0, _ := ret[0].([]dto.PaymentEventDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPaymentEvents indicates an expected call of GetPaymentEvents.
+func (mr *MockPaymentUseCaseMockRecorder) GetPaymentEvents(orderId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPaymentEvents", reflect.TypeOf((*MockPaymentUseCase)(nil).GetPaymentEvents), orderId)
+}
+
+// GetPaymentOrder mocks base method.
+func (m *MockPaymentUseCase) GetPaymentOrder(orderId int) (dto.PaymentDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPaymentOrder", orderId)
+	ret0, _ := ret[0].(dto.PaymentDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPaymentOrder indicates an expected call of GetPaymentOrder.
+func (mr *MockPaymentUseCaseMockRecorder) GetPaymentOrder(orderId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPaymentOrder", reflect.TypeOf((*MockPaymentUseCase)(nil).GetPaymentOrder), orderId)
+}
+
+// GetPaymentQRCode mocks base method.
+func (m *MockPaymentUseCase) GetPaymentQRCode(orderId int) (dto.PaymentQRCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPaymentQRCode", orderId)
+	ret0, _ := ret[0].(dto.PaymentQRCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPaymentQRCode indicates an expected call of GetPaymentQRCode.
+func (mr *MockPaymentUseCaseMockRecorder) GetPaymentQRCode(orderId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPaymentQRCode", reflect.TypeOf((*MockPaymentUseCase)(nil).GetPaymentQRCode), orderId)
+}
+
 // NotifyPayment mocks base method.
-func (m *MockPaymentUseCase) NotifyPayment(orderId, paymentId int) error {
+func (m *MockPaymentUseCase) NotifyPayment(notification dto.PaymentNotification) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "NotifyPayment", orderId, paymentId)
+	ret := m.ctrl.Call(m, "NotifyPayment", notification)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // NotifyPayment indicates an expected call of NotifyPayment.
-func (mr *MockPaymentUseCaseMockRecorder) NotifyPayment(orderId, paymentId any) *gomock.Call {
+func (mr *MockPaymentUseCaseMockRecorder) NotifyPayment(notification any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NotifyPayment", reflect.TypeOf((*MockPaymentUseCase)(nil).NotifyPayment), notification)
+}
+
+// ProcessBrokerNotification mocks base method.
+func (m *MockPaymentUseCase) ProcessBrokerNotification(notification dto.BrokerNotification) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ProcessBrokerNotification", notification)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ProcessBrokerNotification indicates an expected call of ProcessBrokerNotification.
+func (mr *MockPaymentUseCaseMockRecorder) ProcessBrokerNotification(notification any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProcessBrokerNotification", reflect.TypeOf((*MockPaymentUseCase)(nil).ProcessBrokerNotification), notification)
+}
+
+// ReconcilePayments mocks base method.
+func (m *MockPaymentUseCase) ReconcilePayments(olderThan time.Duration) (dto.ReconciliationReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReconcilePayments", olderThan)
+	ret0, _ := ret[0].(dto.ReconciliationReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReconcilePayments indicates an expected call of ReconcilePayments.
+func (mr *MockPaymentUseCaseMockRecorder) ReconcilePayments(olderThan any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReconcilePayments", reflect.TypeOf((*MockPaymentUseCase)(nil).ReconcilePayments), olderThan)
+}
+
+// RefundPayment mocks base method.
+func (m *MockPaymentUseCase) RefundPayment(orderId int, refundRequest dto.RefundRequestDTO, actor string) (dto.RefundDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefundPayment", orderId, refundRequest, actor)
+	ret0, _ := ret[0].(dto.RefundDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RefundPayment indicates an expected call of RefundPayment.
+func (mr *MockPaymentUseCaseMockRecorder) RefundPayment(orderId, refundRequest, actor any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefundPayment", reflect.TypeOf((*MockPaymentUseCase)(nil).RefundPayment), orderId, refundRequest, actor)
+}
+
+// ReplayOrderNotification mocks base method.
+func (m *MockPaymentUseCase) ReplayOrderNotification(notification entities.OrderNotification) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplayOrderNotification", notification)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReplayOrderNotification indicates an expected call of ReplayOrderNotification.
+func (mr *MockPaymentUseCaseMockRecorder) ReplayOrderNotification(notification any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplayOrderNotification", reflect.TypeOf((*MockPaymentUseCase)(nil).ReplayOrderNotification), notification)
+}
+
+// SearchPaymentOrders mocks base method.
+func (m *MockPaymentUseCase) SearchPaymentOrders(search dto.PaymentSearchDTO) (dto.PaymentPageDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchPaymentOrders", search)
+	ret0, _ := ret[0].(dto.PaymentPageDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchPaymentOrders indicates an expected call of SearchPaymentOrders.
+func (mr *MockPaymentUseCaseMockRecorder) SearchPaymentOrders(search any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchPaymentOrders", reflect.TypeOf((*MockPaymentUseCase)(nil).SearchPaymentOrders), search)
+}
+
+// SubscribePaymentStatus mocks base method.
+func (m *MockPaymentUseCase) SubscribePaymentStatus(orderId int) (<-chan entities.PaymentStatusChange, func()) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SubscribePaymentStatus", orderId)
+	ret0, _ := ret[0].(<-chan entities.PaymentStatusChange)
+	ret1, _ := ret[1].(func())
+	return ret0, ret1
+}
+
+// SubscribePaymentStatus indicates an expected call of SubscribePaymentStatus.
+func (mr *MockPaymentUseCaseMockRecorder) SubscribePaymentStatus(orderId any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NotifyPayment", reflect.TypeOf((*MockPaymentUseCase)(nil).NotifyPayment), orderId, paymentId)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubscribePaymentStatus", reflect.TypeOf((*MockPaymentUseCase)(nil).SubscribePaymentStatus), orderId)
 }
@@ -0,0 +1,124 @@
+package usecases
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/entities"
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/usecases/dto"
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/infra/gateways"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type NotificationUseCase interface {
+	EnqueueBrokerNotification(notification dto.BrokerNotification) error
+	ReceiveNotificationMessages(maxMessages int) ([]entities.NotificationMessage, error)
+	ProcessNotificationMessage(message entities.NotificationMessage) error
+}
+
+// defaultNotificationMaxAttempts and defaultNotificationRetryDelay apply when the attempts or the
+// retry delay are not configured, so a failed message is retried instead of dead-lettered at once.
+const (
+	defaultNotificationMaxAttempts = 5
+	defaultNotificationRetryDelay  = 10 * time.Second
+)
+
+type notificationUseCase struct {
+	paymentUseCase       PaymentUseCase
+	notificationQueue    gateways.NotificationQueue
+	deadLetterRepository gateways.DeadLetterRepositoryGateway
+	maxAttempts          int
+	retryDelay           time.Duration
+}
+
+type NotificationUseCaseConfig struct {
+	PaymentUseCase       PaymentUseCase
+	NotificationQueue    gateways.NotificationQueue
+	DeadLetterRepository gateways.DeadLetterRepositoryGateway
+	MaxAttempts          int
+	RetryDelay           time.Duration
+}
+
+func NewNotificationUseCase(config NotificationUseCaseConfig) notificationUseCase {
+	maxAttempts := config.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = defaultNotificationMaxAttempts
+	}
+	retryDelay := config.RetryDelay
+	if retryDelay <= 0 {
+		retryDelay = defaultNotificationRetryDelay
+	}
+
+	return notificationUseCase{
+		paymentUseCase:       config.PaymentUseCase,
+		notificationQueue:    config.NotificationQueue,
+		deadLetterRepository: config.DeadLetterRepository,
+		maxAttempts:          maxAttempts,
+		retryDelay:           retryDelay,
+	}
+}
+
+func (u notificationUseCase) EnqueueBrokerNotification(notification dto.BrokerNotification) error {
+	return u.enqueue(notification.ToNotificationMessage())
+}
+
+func (u notificationUseCase) enqueue(message entities.NotificationMessage) error {
+	err := u.notificationQueue.Enqueue(message)
+	if err != nil {
+		log.Errorf("failed to enqueue notification [%s], error: %v", message.NotificationId, err)
+		return err
+	}
+
+	return nil
+}
+
+func (u notificationUseCase) ReceiveNotificationMessages(maxMessages int) ([]entities.NotificationMessage, error) {
+	return u.notificationQueue.Receive(maxMessages)
+}
+
+// ProcessNotificationMessage hands the message to the payment use case. A failed message is
+// retried with an exponential delay and moved to the dead-letter store after the last attempt.
+// Only failures to update the queue itself are returned.
+func (u notificationUseCase) ProcessNotificationMessage(message entities.NotificationMessage) error {
+	err := u.processNotificationMessage(message)
+	if err == nil {
+		return u.notificationQueue.Delete(message)
+	}
+
+	message.Attempts++
+	message.LastError = err.Error()
+	if message.Attempts >= u.maxAttempts {
+		log.Errorf("notification message [%s] failed %d times, moving it to the dead-letter store, error: %v", message.MessageId, message.Attempts, err)
+		return u.deadLetter(message)
+	}
+
+	message.VisibleAt = time.Now().Add(u.retryDelay * time.Duration(1<<(message.Attempts-1)))
+	log.Warnf("notification message [%s] failed, retrying at %s, error: %v", message.MessageId, message.VisibleAt.Format(time.RFC3339), err)
+	return u.notificationQueue.Retry(message)
+}
+
+func (u notificationUseCase) processNotificationMessage(message entities.NotificationMessage) error {
+	return u.paymentUseCase.ProcessBrokerNotification(dto.NewBrokerNotification(message))
+}
+
+func (u notificationUseCase) deadLetter(message entities.NotificationMessage) error {
+	payload, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	err = u.deadLetterRepository.SaveDeadLetter(entities.DeadLetter{
+		MessageId: message.MessageId,
+		Type:      entities.DeadLetterTypeBrokerNotification,
+		Payload:   string(payload),
+		Attempts:  message.Attempts,
+		LastError: message.LastError,
+	})
+	if err != nil {
+		log.Errorf("failed to save dead letter [%s], error: %v", message.MessageId, err)
+		return err
+	}
+
+	return u.notificationQueue.Delete(message)
+}
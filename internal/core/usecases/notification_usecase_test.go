@@ -0,0 +1,218 @@
+package usecases
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/entities"
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/usecases/dto"
+	mock_usecases "github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/usecases/mocks"
+	mock_gateways "github.com/IgorRamosBR/g73-techchallenge-payment/internal/infra/gateways/mocks"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestNotificationUseCase_EnqueueBrokerNotification(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	notificationQueue := mock_gateways.NewMockNotificationQueue(ctrl)
+
+	notificationQueue.EXPECT().
+		Enqueue(gomock.Eq(entities.NotificationMessage{
+			NotificationId: "7890:payment.updated",
+			Topic:          "payment",
+			ResourceId:     7890,
+			Action:         "payment.updated",
+		})).
+		Times(1).
+		Return(errors.New("internal server error"))
+
+	config := NotificationUseCaseConfig{
+		NotificationQueue: notificationQueue,
+	}
+	notificationUseCase := NewNotificationUseCase(config)
+
+	err := notificationUseCase.EnqueueBrokerNotification(dto.BrokerNotification{
+		NotificationId: "7890:payment.updated",
+		Topic:          dto.NotificationTopicPayment,
+		ResourceId:     7890,
+		Action:         "payment.updated",
+	})
+
+	assert.Equal(t, errors.New("internal server error"), err)
+}
+
+func TestNotificationUseCase_ProcessNotificationMessage(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	paymentUseCase := mock_usecases.NewMockPaymentUseCase(ctrl)
+	notificationQueue := mock_gateways.NewMockNotificationQueue(ctrl)
+	deadLetterRepository := mock_gateways.NewMockDeadLetterRepositoryGateway(ctrl)
+
+	brokerMessage := entities.NotificationMessage{
+		MessageId:  "1714564800000000000-a1b2c3d4",
+		Topic:      "payment",
+		ResourceId: 7890,
+	}
+	legacyMessage := entities.NotificationMessage{
+		MessageId: "1714564800000000000-a1b2c3d4",
+		OrderId:   123,
+		PaymentId: 7890,
+	}
+
+	type args struct {
+		message entities.NotificationMessage
+	}
+	type want struct {
+		err error
+	}
+	type processBrokerNotificationCall struct {
+		times int
+		err   error
+	}
+	type deleteCall struct {
+		times int
+		err   error
+	}
+	type retryCall struct {
+		times    int
+		attempts int
+	}
+	type deadLetterCall struct {
+		times int
+		err   error
+	}
+	tests := []struct {
+		name string
+		args
+		want
+		processBrokerNotificationCall
+		deleteCall
+		retryCall
+		deadLetterCall
+	}{
+		{
+			name: "should verify the payment of the legacy message with the broker and delete it",
+			args: args{
+				message: legacyMessage,
+			},
+			processBrokerNotificationCall: processBrokerNotificationCall{
+				times: 1,
+			},
+			deleteCall: deleteCall{
+				times: 1,
+			},
+		},
+		{
+			name: "should delete the broker message once it is processed",
+			args: args{
+				message: brokerMessage,
+			},
+			processBrokerNotificationCall: processBrokerNotificationCall{
+				times: 1,
+			},
+			deleteCall: deleteCall{
+				times: 1,
+			},
+		},
+		{
+			name: "should schedule a retry when processing fails",
+			args: args{
+				message: brokerMessage,
+			},
+			processBrokerNotificationCall: processBrokerNotificationCall{
+				times: 1,
+				err:   errors.New("order api unavailable"),
+			},
+			retryCall: retryCall{
+				times:    1,
+				attempts: 1,
+			},
+		},
+		{
+			name: "should fail to move the message to the dead-letter store when the repository returns error",
+			args: args{
+				message: entities.NotificationMessage{MessageId: "1714564800000000000-a1b2c3d4", Topic: "payment", ResourceId: 7890, Attempts: 2},
+			},
+			want: want{
+				err: errors.New("internal server error"),
+			},
+			processBrokerNotificationCall: processBrokerNotificationCall{
+				times: 1,
+				err:   errors.New("order api unavailable"),
+			},
+			deadLetterCall: deadLetterCall{
+				times: 1,
+				err:   errors.New("internal server error"),
+			},
+		},
+		{
+			name: "should move the message to the dead-letter store after the last attempt",
+			args: args{
+				message: entities.NotificationMessage{MessageId: "1714564800000000000-a1b2c3d4", Topic: "payment", ResourceId: 7890, Attempts: 2},
+			},
+			processBrokerNotificationCall: processBrokerNotificationCall{
+				times: 1,
+				err:   errors.New("order api unavailable"),
+			},
+			deadLetterCall: deadLetterCall{
+				times: 1,
+			},
+			deleteCall: deleteCall{
+				times: 1,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		paymentUseCase.EXPECT().
+			ProcessBrokerNotification(gomock.Eq(dto.BrokerNotification{Topic: dto.NotificationTopicPayment, ResourceId: 7890})).
+			Times(tt.processBrokerNotificationCall.times).
+			Return(tt.processBrokerNotificationCall.err)
+
+		notificationQueue.EXPECT().
+			Delete(gomock.Any()).
+			Times(tt.deleteCall.times).
+			Return(tt.deleteCall.err)
+
+		notificationQueue.EXPECT().
+			Retry(gomock.Cond(func(x any) bool {
+				message := x.(entities.NotificationMessage)
+				return message.Attempts == tt.retryCall.attempts &&
+					message.LastError == "order api unavailable" &&
+					message.VisibleAt.After(time.Now())
+			})).
+			Times(tt.retryCall.times).
+			Return(nil)
+
+		deadLetterRepository.EXPECT().
+			SaveDeadLetter(gomock.Cond(func(x any) bool {
+				deadLetter := x.(entities.DeadLetter)
+				return deadLetter.MessageId == tt.args.message.MessageId &&
+					deadLetter.Type == entities.DeadLetterTypeBrokerNotification &&
+					deadLetter.Attempts == 3 &&
+					deadLetter.LastError == "order api unavailable"
+			})).
+			Times(tt.deadLetterCall.times).
+			Return(tt.deadLetterCall.err)
+
+		config := NotificationUseCaseConfig{
+			PaymentUseCase:       paymentUseCase,
+			NotificationQueue:    notificationQueue,
+			DeadLetterRepository: deadLetterRepository,
+			MaxAttempts:          3,
+			RetryDelay:           time.Second,
+		}
+		notificationUseCase := NewNotificationUseCase(config)
+
+		err := notificationUseCase.ProcessNotificationMessage(tt.args.message)
+
+		assert.Equal(t, tt.want.err, err)
+	}
+}
+
+func TestNewNotificationUseCase_Defaults(t *testing.T) {
+	notificationUseCase := NewNotificationUseCase(NotificationUseCaseConfig{})
+
+	assert.Equal(t, defaultNotificationMaxAttempts, notificationUseCase.maxAttempts)
+	assert.Equal(t, defaultNotificationRetryDelay, notificationUseCase.retryDelay)
+}
@@ -1,7 +1,15 @@
 package usecases
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
 	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/entities"
+	coreErrors "github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/errors"
 	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/usecases/dto"
 	drivers "github.com/IgorRamosBR/g73-techchallenge-payment/internal/infra/drivers/payment"
 	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/infra/gateways"
@@ -11,57 +19,966 @@ import (
 
 type PaymentUseCase interface {
 	CreatePaymentOrder(paymentOrder dto.PaymentOrderDTO) (string, error)
-	NotifyPayment(orderId, paymentId int) error
+	NotifyPayment(notification dto.PaymentNotification) error
+	ProcessBrokerNotification(notification dto.BrokerNotification) error
+	ExpirePaymentOrders() error
+	ReconcilePayments(olderThan time.Duration) (dto.ReconciliationReport, error)
+	RefundPayment(orderId int, refundRequest dto.RefundRequestDTO, actor string) (dto.RefundDTO, error)
+	CancelPaymentOrder(orderId int, actor string) error
+	GetPaymentEvents(orderId int) ([]dto.PaymentEventDTO, error)
+	GetPaymentOrder(orderId int) (dto.PaymentDTO, error)
+	GetPaymentQRCode(orderId int) (dto.PaymentQRCode, error)
+	SearchPaymentOrders(search dto.PaymentSearchDTO) (dto.PaymentPageDTO, error)
+	SubscribePaymentStatus(orderId int) (<-chan entities.PaymentStatusChange, func())
+	ReplayOrderNotification(notification entities.OrderNotification) error
 }
 
+var (
+	ErrPaymentNotRefundable  = coreErrors.New(coreErrors.KindValidation, "payment order is not paid")
+	ErrRefundAmountExceeded  = coreErrors.New(coreErrors.KindValidation, "refund amount exceeds the remaining paid amount")
+	ErrPaymentNotCancellable = coreErrors.New(coreErrors.KindConflict, "payment order is not pending")
+	ErrOrderMismatch         = coreErrors.New(coreErrors.KindValidation, "payment order does not match the order")
+	ErrOrderNotPayable       = coreErrors.New(coreErrors.KindValidation, "order cannot be paid")
+	ErrPaymentNotPayable     = coreErrors.New(coreErrors.KindConflict, "payment order is no longer awaiting payment")
+)
+
+const (
+	systemActor = "system"
+	brokerActor = "broker"
+)
+
+// defaultPaymentExpiration applies when no payment expiration is configured, so the payment
+// orders are not created already overdue.
+const defaultPaymentExpiration = 15 * time.Minute
+
+// notificationProcessingLease is how long a notification being processed blocks its other
+// deliveries, so one whose processing stopped midway is handled again by a later delivery.
+const notificationProcessingLease = 5 * time.Minute
+
 type paymentUseCase struct {
-	paymentBroker     drivers.PaymentBroker
-	paymentRepository gateways.PaymentRepositoryGateway
-	orderClient       gateways.OrderClient
+	paymentBroker                   drivers.PaymentBroker
+	paymentRepository               gateways.PaymentRepositoryGateway
+	paymentEventRepository          gateways.PaymentEventRepositoryGateway
+	processedNotificationRepository gateways.ProcessedNotificationRepositoryGateway
+	deadLetterRepository            gateways.DeadLetterRepositoryGateway
+	orderClient                     gateways.OrderClient
+	productionClient                gateways.ProductionClient
+	productionEnabled               bool
+	strictOrderVerification         bool
+	payableOrderStatuses            []string
+	cancelledOrderStatuses          []string
+	eventPublisher                  gateways.EventPublisher
+	paymentStatusHub                gateways.PaymentStatusHub
+	paymentExpiration               time.Duration
+	notificationDeduplicationTTL    time.Duration
 }
 
 type PaymentUseCaseConfig struct {
-	PaymentBroker     drivers.PaymentBroker
-	PaymentRepository gateways.PaymentRepositoryGateway
-	OrderClient       gateways.OrderClient
+	PaymentBroker                   drivers.PaymentBroker
+	PaymentRepository               gateways.PaymentRepositoryGateway
+	PaymentEventRepository          gateways.PaymentEventRepositoryGateway
+	ProcessedNotificationRepository gateways.ProcessedNotificationRepositoryGateway
+	DeadLetterRepository            gateways.DeadLetterRepositoryGateway
+	OrderClient                     gateways.OrderClient
+	ProductionClient                gateways.ProductionClient
+	ProductionEnabled               bool
+	StrictOrderVerification         bool
+	PayableOrderStatuses            []string
+	CancelledOrderStatuses          []string
+	EventPublisher                  gateways.EventPublisher
+	PaymentStatusHub                gateways.PaymentStatusHub
+	PaymentExpiration               time.Duration
+	NotificationDeduplicationTTL    time.Duration
+}
+
+// paymentDomainEventTypes are the events published when a payment order moves to the status.
+var paymentDomainEventTypes = map[entities.PaymentStatus]entities.PaymentDomainEventType{
+	entities.PaymentStatusPaid:              entities.PaymentDomainEventTypePaid,
+	entities.PaymentStatusExpired:           entities.PaymentDomainEventTypeExpired,
+	entities.PaymentStatusPartiallyRefunded: entities.PaymentDomainEventTypeRefunded,
+	entities.PaymentStatusRefunded:          entities.PaymentDomainEventTypeRefunded,
+}
+
+// eventOrigin identifies who triggered a payment order change and why, so it can be kept in the
+// history.
+type eventOrigin struct {
+	source      entities.PaymentEventSource
+	actor       string
+	payloadHash string
+	reason      string
 }
 
 func NewPaymentUseCase(config PaymentUseCaseConfig) paymentUseCase {
+	paymentExpiration := config.PaymentExpiration
+	if paymentExpiration <= 0 {
+		paymentExpiration = defaultPaymentExpiration
+	}
+
 	return paymentUseCase{
-		paymentBroker:     config.PaymentBroker,
-		paymentRepository: config.PaymentRepository,
-		orderClient:       config.OrderClient,
+		paymentBroker:                   config.PaymentBroker,
+		paymentRepository:               config.PaymentRepository,
+		paymentEventRepository:          config.PaymentEventRepository,
+		processedNotificationRepository: config.ProcessedNotificationRepository,
+		deadLetterRepository:            config.DeadLetterRepository,
+		orderClient:                     config.OrderClient,
+		productionClient:                config.ProductionClient,
+		productionEnabled:               config.ProductionEnabled,
+		strictOrderVerification:         config.StrictOrderVerification,
+		payableOrderStatuses:            config.PayableOrderStatuses,
+		cancelledOrderStatuses:          config.CancelledOrderStatuses,
+		eventPublisher:                  config.EventPublisher,
+		paymentStatusHub:                config.PaymentStatusHub,
+		paymentExpiration:               paymentExpiration,
+		notificationDeduplicationTTL:    config.NotificationDeduplicationTTL,
 	}
 }
 
 func (u paymentUseCase) CreatePaymentOrder(paymentOrder dto.PaymentOrderDTO) (string, error) {
-	paymentQRCode, err := u.paymentBroker.GeneratePaymentQRCode(paymentOrder)
+	if u.strictOrderVerification {
+		err := u.verifyOrder(paymentOrder)
+		if err != nil {
+			log.Warnf("refusing to create payment order [%d], error: %v", paymentOrder.OrderId, err)
+			return "", err
+		}
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(u.paymentExpiration)
+
+	paymentQRCode, err := u.paymentBroker.GeneratePaymentQRCode(paymentOrder, expiresAt)
 	if err != nil {
 		log.Errorf("failed to generate payment qrcode for the order [%d], error: %v", paymentOrder.OrderId, err)
 		return "", err
 	}
 
-	err = u.paymentRepository.SavePaymentOrder(paymentOrder, paymentQRCode.QrData)
+	newPaymentOrder := paymentOrder.ToPaymentOrder(paymentQRCode.QrData, now, expiresAt)
+	newPaymentOrder.Broker = u.paymentBroker.GetName()
+	newPaymentOrder.InStoreOrderId = paymentQRCode.StoreOrderId
+
+	origin := eventOrigin{source: entities.PaymentEventSourceApi}
+	paymentEvent := newPaymentEvent(paymentOrder.OrderId, entities.PaymentEventTypeCreated, "", entities.PaymentStatusPending, origin)
+	err = u.paymentRepository.SavePaymentOrder(newPaymentOrder, paymentEvent)
 	if err != nil {
 		log.Errorf("failed to save payment order [%d], error: %v", paymentOrder.OrderId, err)
 		return "", err
 	}
 
+	u.publishPaymentStatusChange(paymentOrder.OrderId, entities.PaymentStatusPending)
+	u.publishPaymentEvent(entities.PaymentDomainEventTypeCreated, entities.PaymentDomainEventData{
+		OrderId: newPaymentOrder.OrderId,
+		Status:  entities.PaymentStatusPending,
+		Amount:  newPaymentOrder.TotalAmout,
+	})
+
 	return paymentQRCode.QrData, err
 }
 
-func (u paymentUseCase) NotifyPayment(orderId, paymentId int) error {
-	err := u.paymentRepository.UpdatePaymentOrderStatus(orderId, paymentId, entities.PaymentStatusPaid)
+// verifyOrder compares the payment order with the order kept by the order service, so the
+// customer is charged for what was actually ordered.
+func (u paymentUseCase) verifyOrder(paymentOrder dto.PaymentOrderDTO) error {
+	order, err := u.orderClient.GetOrder(paymentOrder.OrderId)
+	if err != nil {
+		return err
+	}
+
+	if !u.isPayableOrderStatus(order.Status) {
+		return fmt.Errorf("%w: order status is [%s]", ErrOrderNotPayable, order.Status)
+	}
+	if order.CustomerCPF != paymentOrder.CustomerCPF {
+		return fmt.Errorf("%w: customer differs from the order", ErrOrderMismatch)
+	}
+	if math.Round(order.TotalAmount*100) != math.Round(paymentOrder.TotalAmount*100) {
+		return fmt.Errorf("%w: total amount [%.2f] differs from the order total [%.2f]", ErrOrderMismatch, paymentOrder.TotalAmount, order.TotalAmount)
+	}
+	if !sameOrderItems(order.Items, paymentOrder.Items) {
+		return fmt.Errorf("%w: items differ from the order items", ErrOrderMismatch)
+	}
+
+	return nil
+}
+
+func (u paymentUseCase) isPayableOrderStatus(status string) bool {
+	for _, payableStatus := range u.payableOrderStatuses {
+		if payableStatus == status {
+			return true
+		}
+	}
+	return false
+}
+
+// isOrderCancelled asks the order service whether the order was cancelled, so a payment is only
+// refunded when no order will be delivered for it.
+func (u paymentUseCase) isOrderCancelled(orderId int) bool {
+	order, err := u.orderClient.GetOrder(orderId)
+	if err != nil {
+		log.Errorf("failed to get order [%d], error: %v", orderId, err)
+		return false
+	}
+
+	for _, cancelledStatus := range u.cancelledOrderStatuses {
+		if cancelledStatus == order.Status {
+			return true
+		}
+	}
+	return false
+}
+
+// sameOrderItems tells whether both lists hold the same quantity of each product at the same
+// price, in any order.
+func sameOrderItems(orderItems, paymentItems []dto.PaymentOrderItem) bool {
+	type itemKey struct {
+		skuId      string
+		priceCents int64
+	}
+	quantities := map[itemKey]int{}
+	for _, item := range orderItems {
+		quantities[itemKey{item.Product.SkuId, int64(math.Round(item.Product.Price * 100))}] += item.Quantity
+	}
+	for _, item := range paymentItems {
+		quantities[itemKey{item.Product.SkuId, int64(math.Round(item.Product.Price * 100))}] -= item.Quantity
+	}
+
+	for _, quantity := range quantities {
+		if quantity != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// NotifyPayment confirms the payment once per notification id. Notifications of the same payment
+// under different ids, like an IPN and a webhook, race on the conditional transition to PAID, so
+// only the one that wins it notifies the order and production services.
+func (u paymentUseCase) NotifyPayment(notification dto.PaymentNotification) error {
+	return u.processNotificationOnce(notification.NotificationId, func() error {
+		return u.notifyPayment(notification)
+	})
+}
+
+// notifyPayment confirms the payment of the order. The order only moves to PAID while it is still
+// in the status read, so when a cancel, the sweeper or another notification of the payment commits
+// first, the payment is handled again against the status they left: flagged for refund when the
+// order was closed, skipped when it was already paid.
+func (u paymentUseCase) notifyPayment(notification dto.PaymentNotification) error {
+	orderId, paymentId := notification.OrderId, notification.PaymentId
+	origin := eventOrigin{
+		source:      entities.PaymentEventSourceWebhook,
+		actor:       brokerActor,
+		payloadHash: notification.PayloadHash,
+	}
+
+	paymentOrder, err := u.paymentRepository.GetPaymentOrder(orderId)
+	if err != nil {
+		log.Errorf("failed to get payment order [%d], error: %v", orderId, err)
+		return err
+	}
+
+	// every conflict means the status moved forward, so this ends once no transition to PAID is left
+	for {
+		if paymentOrder.IsClosed() {
+			return u.flagLatePayment(paymentOrder, paymentId, origin)
+		}
+		if !paymentOrder.Status.CanTransitionTo(entities.PaymentStatusPaid) {
+			log.Infof("payment order [%d] is already [%s], skipping notification", orderId, paymentOrder.Status)
+			return nil
+		}
+
+		paymentEvent := newPaymentEvent(orderId, entities.PaymentEventTypeStatusChanged, paymentOrder.Status, entities.PaymentStatusPaid, origin)
+		err = u.paymentRepository.TransitionPaymentOrderStatus(orderId, paymentId, notification.MerchantOrderId, paymentOrder.Status, entities.PaymentStatusPaid, paymentEvent)
+		if !errors.Is(err, gateways.ErrPaymentOrderStatusConflict) {
+			break
+		}
+
+		log.Infof("payment order [%d] changed while confirming the payment [%d], reading it again", orderId, paymentId)
+		paymentOrder, err = u.paymentRepository.GetPaymentOrder(orderId)
+		if err != nil {
+			log.Errorf("failed to get payment order [%d], error: %v", orderId, err)
+			return err
+		}
+	}
 	if err != nil {
 		log.Errorf("failed to payment payment status for the order [%d], error: %v", orderId, err)
 		return err
 	}
+	u.publishPaymentStatusChange(orderId, entities.PaymentStatusPaid)
+	u.publishPaymentEvent(entities.PaymentDomainEventTypePaid, entities.PaymentDomainEventData{
+		OrderId:   orderId,
+		PaymentId: paymentId,
+		Status:    entities.PaymentStatusPaid,
+		Amount:    paymentOrder.TotalAmout,
+	})
 
-	err = u.orderClient.NotifyPaymentOrder(orderId, entities.PaymentStatusPaid)
+	paymentOrder.Status = entities.PaymentStatusPaid
+	paymentOrder.PaymentId = paymentId
+	return u.notifyPaid(paymentOrder, origin)
+}
+
+// flagLatePayment flags a payment received for a closed payment order, so the money is returned to
+// the customer. It does nothing when the payment order is already flagged.
+func (u paymentUseCase) flagLatePayment(paymentOrder entities.PaymentOrder, paymentId int, origin eventOrigin) error {
+	orderId := paymentOrder.OrderId
+	if paymentOrder.RefundRequired {
+		log.Infof("%s payment order [%d] is already flagged for refund, skipping notification", paymentOrder.Status, orderId)
+		return nil
+	}
+
+	log.Warnf("payment [%d] received for the %s order [%d], flagging it for refund", paymentId, paymentOrder.Status, orderId)
+	paymentEvent := newPaymentEvent(orderId, entities.PaymentEventTypeFlaggedForRefund, paymentOrder.Status, paymentOrder.Status, origin)
+	err := u.paymentRepository.FlagPaymentOrderForRefund(orderId, paymentId, paymentEvent)
+	if err != nil {
+		log.Errorf("failed to flag payment order [%d] for refund, error: %v", orderId, err)
+		return err
+	}
+	return nil
+}
+
+// ProcessBrokerNotification fetches the resource a broker notification points to and, when it
+// holds an approved payment, confirms the payment of the order it belongs to.
+func (u paymentUseCase) ProcessBrokerNotification(notification dto.BrokerNotification) error {
+	brokerPayment, merchantOrderId, err := u.resolveNotificationPayment(notification)
+	if errors.Is(err, drivers.ErrPaymentNotFound) {
+		log.Infof("no approved payment found for the %s notification [%d], skipping", notification.Topic, notification.ResourceId)
+		return nil
+	}
+	if err != nil {
+		log.Errorf("failed to resolve the %s notification [%d], error: %v", notification.Topic, notification.ResourceId, err)
+		return err
+	}
+
+	status, ok := brokerPayment.GetPaymentStatus()
+	if !ok || status != entities.PaymentStatusPaid {
+		log.Infof("payment [%d] is [%s] in the broker, skipping notification", brokerPayment.Id, brokerPayment.Status)
+		return nil
+	}
+
+	orderId, err := strconv.Atoi(brokerPayment.ExternalReference)
+	if err != nil {
+		log.Warnf("payment [%d] has an unknown external reference [%s], skipping notification", brokerPayment.Id, brokerPayment.ExternalReference)
+		return nil
+	}
+
+	// the broker calls above are read only, so deduplication only guards the payment confirmation
+	return u.NotifyPayment(dto.PaymentNotification{
+		NotificationId:  notification.NotificationId,
+		OrderId:         orderId,
+		PaymentId:       brokerPayment.Id,
+		MerchantOrderId: merchantOrderId,
+		PayloadHash:     notification.PayloadHash,
+	})
+}
+
+// processNotificationOnce runs process unless a notification with the same id was already
+// processed or is being processed. The id is held for a short lease while processing and kept
+// for the deduplication TTL once it succeeds. It is released when processing fails, so the next
+// broker retry is handled.
+func (u paymentUseCase) processNotificationOnce(notificationId string, process func() error) error {
+	if notificationId == "" {
+		return process()
+	}
+
+	registered, err := u.processedNotificationRepository.RegisterNotification(notificationId, time.Now().Add(notificationProcessingLease))
+	if err != nil {
+		log.Errorf("failed to register notification [%s], error: %v", notificationId, err)
+		return err
+	}
+	if !registered {
+		log.Infof("notification [%s] was already processed, skipping", notificationId)
+		return nil
+	}
+
+	err = process()
+	if err != nil {
+		releaseErr := u.processedNotificationRepository.ReleaseNotification(notificationId)
+		if releaseErr != nil {
+			log.Errorf("failed to release notification [%s], error: %v", notificationId, releaseErr)
+		}
+		return err
+	}
+
+	// the notification was processed, so failing to keep it only lets a retry process it again
+	err = u.processedNotificationRepository.CompleteNotification(notificationId, time.Now().Add(u.notificationDeduplicationTTL))
+	if err != nil {
+		log.Errorf("failed to complete notification [%s], error: %v", notificationId, err)
+	}
+
+	return nil
+}
+
+// resolveNotificationPayment returns the broker payment a notification refers to, along with the
+// merchant order id when the notification is about a merchant order.
+func (u paymentUseCase) resolveNotificationPayment(notification dto.BrokerNotification) (drivers.PaymentResponse, int, error) {
+	if notification.Topic == dto.NotificationTopicMerchantOrder {
+		merchantOrder, err := u.paymentBroker.GetMerchantOrder(notification.ResourceId)
+		if err != nil {
+			return drivers.PaymentResponse{}, 0, err
+		}
+
+		brokerPayment, ok := merchantOrder.GetApprovedPayment()
+		if !ok {
+			return drivers.PaymentResponse{}, 0, drivers.ErrPaymentNotFound
+		}
+		return brokerPayment, merchantOrder.Id, nil
+	}
+
+	brokerPayment, err := u.paymentBroker.GetPayment(notification.ResourceId)
+	return brokerPayment, 0, err
+}
+
+// ExpirePaymentOrders moves every overdue PENDING payment order to EXPIRED. A failure on one
+// order is logged and does not stop the others from being expired.
+func (u paymentUseCase) ExpirePaymentOrders() error {
+	paymentOrders, err := u.paymentRepository.GetExpiredPaymentOrders(time.Now())
+	if err != nil {
+		log.Errorf("failed to get expired payment orders, error: %v", err)
+		return err
+	}
+
+	for _, paymentOrder := range paymentOrders {
+		err = u.expirePaymentOrder(paymentOrder)
+		if err != nil {
+			log.Errorf("failed to expire payment order [%d], error: %v", paymentOrder.OrderId, err)
+		}
+	}
+
+	return nil
+}
+
+func (u paymentUseCase) expirePaymentOrder(paymentOrder entities.PaymentOrder) error {
+	orderId := paymentOrder.OrderId
+	err := u.paymentBroker.CancelPaymentOrder(orderId)
+	if err != nil {
+		log.Warnf("failed to cancel broker payment order [%d], error: %v", orderId, err)
+	}
+
+	origin := eventOrigin{source: entities.PaymentEventSourceSweeper, actor: systemActor}
+	err = u.transitionPaymentStatus(paymentOrder, 0, entities.PaymentStatusExpired, origin)
+	if errors.Is(err, gateways.ErrPaymentOrderStatusConflict) {
+		log.Infof("payment order [%d] is no longer pending, skipping expiration", orderId)
+		return nil
+	}
+
+	return err
+}
+
+// ReconcilePayments compares the PENDING, AUTHORIZED and REFUND_PENDING payment orders created
+// before the threshold with the broker and fixes the ones whose status diverged.
+func (u paymentUseCase) ReconcilePayments(olderThan time.Duration) (dto.ReconciliationReport, error) {
+	report := dto.NewReconciliationReport(time.Now())
+
+	statuses := []entities.PaymentStatus{entities.PaymentStatusPending, entities.PaymentStatusAuthorized, entities.PaymentStatusRefundPending}
+	paymentOrders, err := u.paymentRepository.GetPaymentOrdersByStatus(statuses, report.StartedAt.Add(-olderThan))
+	if err != nil {
+		log.Errorf("failed to get payment orders to reconcile, error: %v", err)
+		return dto.ReconciliationReport{}, err
+	}
+
+	for _, paymentOrder := range paymentOrders {
+		report.Checked++
+
+		entry, fixed, err := u.reconcilePaymentOrder(paymentOrder)
+		if err != nil {
+			log.Warnf("failed to reconcile payment order [%d], error: %v", paymentOrder.OrderId, err)
+			entry.Reason = err.Error()
+			report.Unresolved = append(report.Unresolved, entry)
+			continue
+		}
+		if fixed {
+			report.Fixed = append(report.Fixed, entry)
+		}
+	}
+
+	report.FinishedAt = time.Now()
+	return report, nil
+}
+
+func (u paymentUseCase) reconcilePaymentOrder(paymentOrder entities.PaymentOrder) (dto.ReconciliationEntry, bool, error) {
+	entry := dto.ReconciliationEntry{
+		OrderId:     paymentOrder.OrderId,
+		LocalStatus: paymentOrder.Status,
+	}
+
+	brokerPayment, err := u.paymentBroker.GetPaymentByExternalReference(paymentOrder.OrderId)
+	if errors.Is(err, drivers.ErrPaymentNotFound) && paymentOrder.Status == entities.PaymentStatusPending {
+		return entry, false, nil
+	}
+	if err != nil {
+		return entry, false, err
+	}
+	entry.PaymentId = brokerPayment.Id
+	entry.BrokerStatus = brokerPayment.Status
+
+	status, ok := brokerPayment.GetPaymentStatus()
+	if !ok {
+		return entry, false, fmt.Errorf("broker status [%s] has no payment order equivalent", brokerPayment.Status)
+	}
+	if status == paymentOrder.Status {
+		return entry, false, nil
+	}
+	// a compensation refund stays approved in the broker until it is processed
+	if paymentOrder.Status == entities.PaymentStatusRefundPending && status != entities.PaymentStatusRefunded {
+		return entry, false, nil
+	}
+
+	origin := eventOrigin{source: entities.PaymentEventSourceReconciler, actor: systemActor}
+	err = u.transitionPaymentStatus(paymentOrder, brokerPayment.Id, status, origin)
+	if err != nil {
+		return entry, false, err
+	}
+
+	return entry, true, nil
+}
+
+// CancelPaymentOrder withdraws the QR code from the broker and cancels the pending payment order.
+func (u paymentUseCase) CancelPaymentOrder(orderId int, actor string) error {
+	paymentOrder, err := u.paymentRepository.GetPaymentOrder(orderId)
+	if err != nil {
+		log.Errorf("failed to get payment order [%d], error: %v", orderId, err)
+		return err
+	}
+
+	if paymentOrder.Status != entities.PaymentStatusPending {
+		return ErrPaymentNotCancellable
+	}
+
+	err = u.paymentBroker.CancelPaymentOrder(orderId)
+	if err != nil {
+		log.Errorf("failed to cancel broker payment order [%d], error: %v", orderId, err)
+		return err
+	}
+
+	origin := eventOrigin{source: entities.PaymentEventSourceAdmin, actor: actor}
+	return u.transitionPaymentStatus(paymentOrder, 0, entities.PaymentStatusCancelled, origin)
+}
+
+// RefundPayment refunds the given amount of a paid payment order, or everything that was not
+// refunded yet when the amount is zero. The amount is reserved on the payment order before the
+// broker is called, so concurrent requests cannot refund more than was paid, and given back when
+// the broker refuses the refund.
+func (u paymentUseCase) RefundPayment(orderId int, refundRequest dto.RefundRequestDTO, actor string) (dto.RefundDTO, error) {
+	paymentOrder, err := u.paymentRepository.GetPaymentOrder(orderId)
+	if err != nil {
+		log.Errorf("failed to get payment order [%d], error: %v", orderId, err)
+		return dto.RefundDTO{}, err
+	}
+
+	if paymentOrder.Status != entities.PaymentStatusPaid && paymentOrder.Status != entities.PaymentStatusPartiallyRefunded {
+		return dto.RefundDTO{}, ErrPaymentNotRefundable
+	}
+
+	remainingAmount := paymentOrder.RemainingAmount()
+	amount := math.Round(refundRequest.Amount*100) / 100
+	if amount == 0 {
+		amount = remainingAmount
+	}
+	if amount > remainingAmount {
+		return dto.RefundDTO{}, ErrRefundAmountExceeded
+	}
+
+	status := entities.PaymentStatusPartiallyRefunded
+	if amount == remainingAmount {
+		status = entities.PaymentStatusRefunded
+	}
+
+	origin := eventOrigin{source: entities.PaymentEventSourceAdmin, actor: actor}
+	index, refund := len(paymentOrder.Refunds), newPendingRefund(paymentOrder, amount)
+	paymentEvent := newPaymentEvent(orderId, entities.PaymentEventTypeRefundRequested, paymentOrder.Status, status, origin)
+	err = u.paymentRepository.SaveRefund(paymentOrder, refund, status, paymentEvent)
+	if err != nil {
+		log.Errorf("failed to reserve refund [%s] of the order [%d], error: %v", refund.ReferenceId, orderId, err)
+		return dto.RefundDTO{}, err
+	}
+
+	refundResponse, err := u.paymentBroker.RefundPayment(paymentOrder.PaymentId, amount, refund.ReferenceId)
+	if err != nil {
+		log.Errorf("failed to refund payment [%d] of the order [%d], error: %v", paymentOrder.PaymentId, orderId, err)
+		u.releaseRefund(reservedRefund(paymentOrder, refund, status), index, paymentOrder.Status, err, origin)
+		return dto.RefundDTO{}, err
+	}
+
+	refund.RefundId, refund.Status = refundResponse.Id, refundResponse.Status
+	paymentEvent = newPaymentEvent(orderId, entities.PaymentEventTypeRefunded, status, status, origin)
+	err = u.paymentRepository.SettleRefund(orderId, index, refund, paymentEvent)
+	if err != nil {
+		// the amount stays reserved, so the refund made by the broker is not made again
+		log.Errorf("failed to save refund [%d] of the order [%d], leaving it pending, error: %v", refund.RefundId, orderId, err)
+	}
+
+	if status != paymentOrder.Status {
+		u.publishPaymentStatusChange(orderId, status)
+	}
+	u.publishPaymentEvent(entities.PaymentDomainEventTypeRefunded, entities.PaymentDomainEventData{
+		OrderId:   orderId,
+		PaymentId: paymentOrder.PaymentId,
+		Status:    status,
+		Amount:    amount,
+	})
+
+	err = u.notifyOrder(orderId, status)
+	if err != nil {
+		return dto.RefundDTO{}, err
+	}
+
+	return dto.RefundDTO{
+		RefundId:       refund.RefundId,
+		OrderId:        orderId,
+		Amount:         amount,
+		RefundedAmount: math.Round((paymentOrder.RefundedAmount+amount)*100) / 100,
+		Status:         status,
+	}, nil
+}
+
+// newPendingRefund is the refund reserved on the payment order before the broker is called. Its
+// reference is sent to the broker as the idempotency key, so a repeated request refunds only once.
+func newPendingRefund(paymentOrder entities.PaymentOrder, amount float64) entities.Refund {
+	return entities.Refund{
+		ReferenceId: fmt.Sprintf("%d-refund-%d", paymentOrder.OrderId, len(paymentOrder.Refunds)+1),
+		Amount:      amount,
+		Status:      entities.RefundStatusPending,
+		CreatedAt:   time.Now(),
+	}
+}
+
+// reservedRefund is the payment order as stored once the refund is reserved.
+func reservedRefund(paymentOrder entities.PaymentOrder, refund entities.Refund, status entities.PaymentStatus) entities.PaymentOrder {
+	paymentOrder.Refunds = append(append([]entities.Refund{}, paymentOrder.Refunds...), refund)
+	paymentOrder.RefundedAmount = math.Round((paymentOrder.RefundedAmount+refund.Amount)*100) / 100
+	paymentOrder.Status = status
+	return paymentOrder
+}
+
+// releaseRefund gives back the amount of a refund refused by the broker. A failure is only logged,
+// leaving the refund pending to be checked by hand.
+func (u paymentUseCase) releaseRefund(paymentOrder entities.PaymentOrder, index int, status entities.PaymentStatus, cause error, origin eventOrigin) {
+	origin.reason = fmt.Sprintf("broker refused the refund: %v", cause)
+	paymentEvent := newPaymentEvent(paymentOrder.OrderId, entities.PaymentEventTypeRefundFailed, paymentOrder.Status, status, origin)
+	err := u.paymentRepository.ReleaseRefund(paymentOrder, index, status, paymentEvent)
+	if err != nil {
+		log.Errorf("failed to release refund [%s] of the order [%d], leaving it pending, error: %v", paymentOrder.Refunds[index].ReferenceId, paymentOrder.OrderId, err)
+	}
+}
+
+// transitionPaymentStatus is the single path used to move a payment order between statuses:
+// it validates the transition, applies it only if the stored status is still the one read and
+// lets the order service know about the new status.
+func (u paymentUseCase) transitionPaymentStatus(paymentOrder entities.PaymentOrder, paymentId int, to entities.PaymentStatus, origin eventOrigin) error {
+	orderId, from := paymentOrder.OrderId, paymentOrder.Status
+	if !from.CanTransitionTo(to) {
+		return coreErrors.New(coreErrors.KindConflict, fmt.Sprintf("payment status transition from [%s] to [%s] is not allowed", from, to))
+	}
+
+	paymentEvent := newPaymentEvent(orderId, entities.PaymentEventTypeStatusChanged, from, to, origin)
+	err := u.paymentRepository.TransitionPaymentOrderStatus(orderId, paymentId, 0, from, to, paymentEvent)
+	if err != nil {
+		return err
+	}
+	u.publishPaymentStatusChange(orderId, to)
+	// a compensation refund was announced when it was made, so its confirmation is not
+	if eventType, ok := paymentDomainEventTypes[to]; ok && from != entities.PaymentStatusRefundPending {
+		u.publishPaymentEvent(eventType, entities.PaymentDomainEventData{
+			OrderId:   orderId,
+			PaymentId: paymentId,
+			Status:    to,
+			Amount:    paymentEventAmount(paymentOrder, to),
+		})
+	}
+
+	if to == entities.PaymentStatusPaid {
+		paymentOrder.Status = to
+		paymentOrder.PaymentId = paymentId
+		return u.notifyPaid(paymentOrder, origin)
+	}
+	return u.notifyOrder(orderId, to)
+}
+
+// paymentEventAmount is the amount announced for a move to the status: what was left to refund
+// when the payment was refunded, the order total otherwise.
+func paymentEventAmount(paymentOrder entities.PaymentOrder, to entities.PaymentStatus) float64 {
+	if to == entities.PaymentStatusRefunded || to == entities.PaymentStatusPartiallyRefunded {
+		return paymentOrder.RemainingAmount()
+	}
+	return paymentOrder.TotalAmout
+}
+
+// notifyPaid lets the order service know the order was paid and sends it to production. Both are
+// attempted even when the first one fails. When the order service refuses the payment for good
+// and the order was cancelled, nothing will be delivered, so the payment is refunded instead.
+func (u paymentUseCase) notifyPaid(paymentOrder entities.PaymentOrder, origin eventOrigin) error {
+	orderId := paymentOrder.OrderId
+	compensated, err := u.notifyOrderPaid(paymentOrder, origin)
+	if compensated {
+		return err
+	}
+
+	var orderErr error
 	if err != nil {
 		log.Errorf("failed to notify payment order for the order [%d], error: %v", orderId, err)
+		orderErr = u.saveFailedNotification(entities.DeadLetterTypeOrderNotification, orderId, entities.OrderNotification{OrderId: orderId, Status: entities.PaymentStatusPaid}, err)
+	}
+
+	productionErr := u.notifyProduction(paymentOrder)
+	if orderErr != nil {
+		return orderErr
+	}
+	return productionErr
+}
+
+// notifyOrderPaid lets the order service know the order was paid. When the order service refuses
+// the payment for good and the order was cancelled, the payment is refunded instead and
+// compensated is true.
+func (u paymentUseCase) notifyOrderPaid(paymentOrder entities.PaymentOrder, origin eventOrigin) (compensated bool, err error) {
+	orderId := paymentOrder.OrderId
+	err = u.orderClient.NotifyPaymentOrder(orderId, entities.PaymentStatusPaid)
+	if gateways.IsPermanentOrderApiError(err) {
+		if u.isOrderCancelled(orderId) {
+			log.Warnf("order service rejected the payment of the cancelled order [%d], refunding it, error: %v", orderId, err)
+			return true, u.compensatePayment(paymentOrder, err, origin)
+		}
+		log.Warnf("order service rejected the payment of the order [%d], which is not cancelled, error: %v", orderId, err)
+	}
+	return false, err
+}
+
+// ReplayOrderNotification sends a failed order notification again, as long as the payment order is
+// still in the notified status. A stale notification is dropped, since the order service is told
+// about the newer status on its own. A PAID notification takes the same path as a new payment,
+// except for the production order, which has a dead letter of its own.
+func (u paymentUseCase) ReplayOrderNotification(notification entities.OrderNotification) error {
+	orderId := notification.OrderId
+	paymentOrder, err := u.paymentRepository.GetPaymentOrder(orderId)
+	if err != nil {
+		log.Errorf("failed to get payment order [%d], error: %v", orderId, err)
+		return err
+	}
+
+	if paymentOrder.Status != notification.Status {
+		log.Infof("payment order [%d] is now [%s], dropping the %s notification", orderId, paymentOrder.Status, notification.Status)
+		return nil
+	}
+	if notification.Status != entities.PaymentStatusPaid {
+		return u.orderClient.NotifyPaymentOrder(orderId, notification.Status)
+	}
+
+	origin := eventOrigin{source: entities.PaymentEventSourceAdmin, actor: systemActor}
+	_, err = u.notifyOrderPaid(paymentOrder, origin)
+	return err
+}
+
+// compensatePayment refunds the whole payment through the broker and leaves the payment order
+// REFUND_PENDING until the reconciler sees the refund confirmed. The refund is reserved before the
+// broker is called, so it is never made twice. When it cannot be reserved or the broker refuses
+// it, the payment order is flagged to be refunded manually instead.
+func (u paymentUseCase) compensatePayment(paymentOrder entities.PaymentOrder, cause error, origin eventOrigin) error {
+	orderId, paymentId, amount := paymentOrder.OrderId, paymentOrder.PaymentId, paymentOrder.RemainingAmount()
+	origin = eventOrigin{
+		source:      origin.source,
+		actor:       systemActor,
+		payloadHash: origin.payloadHash,
+		reason:      fmt.Sprintf("order service rejected the payment: %v", cause),
+	}
+
+	index, refund := len(paymentOrder.Refunds), newPendingRefund(paymentOrder, amount)
+	paymentEvent := newPaymentEvent(orderId, entities.PaymentEventTypeCompensated, paymentOrder.Status, entities.PaymentStatusRefundPending, origin)
+	err := u.paymentRepository.SaveRefund(paymentOrder, refund, entities.PaymentStatusRefundPending, paymentEvent)
+	if err != nil {
+		log.Errorf("failed to reserve compensation refund of the order [%d], flagging it for refund, error: %v", orderId, err)
+		return u.flagForManualRefund(paymentOrder, origin)
+	}
+
+	refundResponse, err := u.paymentBroker.RefundPayment(paymentId, amount, refund.ReferenceId)
+	if err != nil {
+		log.Errorf("failed to refund payment [%d] of the order [%d], flagging it for refund, error: %v", paymentId, orderId, err)
+		u.releaseRefund(reservedRefund(paymentOrder, refund, entities.PaymentStatusRefundPending), index, paymentOrder.Status, err, origin)
+		return u.flagForManualRefund(paymentOrder, origin)
+	}
+
+	refund.RefundId, refund.Status = refundResponse.Id, refundResponse.Status
+	paymentEvent = newPaymentEvent(orderId, entities.PaymentEventTypeRefunded, entities.PaymentStatusRefundPending, entities.PaymentStatusRefundPending, origin)
+	err = u.paymentRepository.SettleRefund(orderId, index, refund, paymentEvent)
+	if err != nil {
+		// the amount stays reserved, so the refund made by the broker is not made again
+		log.Errorf("failed to save compensation refund [%d] of the order [%d], leaving it pending, error: %v", refund.RefundId, orderId, err)
+	}
+
+	u.publishPaymentStatusChange(orderId, entities.PaymentStatusRefundPending)
+	u.publishPaymentEvent(entities.PaymentDomainEventTypeRefunded, entities.PaymentDomainEventData{
+		OrderId:   orderId,
+		PaymentId: paymentId,
+		Status:    entities.PaymentStatusRefundPending,
+		Amount:    amount,
+	})
+
+	return nil
+}
+
+// flagForManualRefund flags a payment that could not be refunded automatically.
+func (u paymentUseCase) flagForManualRefund(paymentOrder entities.PaymentOrder, origin eventOrigin) error {
+	orderId, paymentId := paymentOrder.OrderId, paymentOrder.PaymentId
+	paymentEvent := newPaymentEvent(orderId, entities.PaymentEventTypeFlaggedForRefund, paymentOrder.Status, paymentOrder.Status, origin)
+	err := u.paymentRepository.FlagPaymentOrderForRefund(orderId, paymentId, paymentEvent)
+	if err != nil {
+		log.Errorf("failed to flag payment order [%d] for refund, error: %v", orderId, err)
+		return err
+	}
+	return nil
+}
+
+// notifyOrder lets the order service know about the new payment status, keeping a failed notification to be replayed.
+func (u paymentUseCase) notifyOrder(orderId int, status entities.PaymentStatus) error {
+	err := u.orderClient.NotifyPaymentOrder(orderId, status)
+	if err == nil {
+		return nil
+	}
+	log.Errorf("failed to notify payment order for the order [%d], error: %v", orderId, err)
+
+	return u.saveFailedNotification(entities.DeadLetterTypeOrderNotification, orderId, entities.OrderNotification{OrderId: orderId, Status: status}, err)
+}
+
+// notifyProduction submits the paid order to the production service, unless it is disabled. A
+// failed submission is kept in the dead-letter store like the order notifications.
+func (u paymentUseCase) notifyProduction(paymentOrder entities.PaymentOrder) error {
+	if !u.productionEnabled {
+		return nil
+	}
+
+	productionOrder := paymentOrder.ToProductionOrder()
+	err := u.productionClient.SubmitProductionOrder(productionOrder)
+	if err == nil {
+		return nil
+	}
+	log.Errorf("failed to submit the order [%d] to production, error: %v", paymentOrder.OrderId, err)
+
+	return u.saveFailedNotification(entities.DeadLetterTypeProductionNotification, paymentOrder.OrderId, productionOrder, err)
+}
+
+// saveFailedNotification stores the notification payload as a dead letter to be replayed. The
+// notification error is only returned when the dead letter cannot be stored.
+func (u paymentUseCase) saveFailedNotification(deadLetterType entities.DeadLetterType, orderId int, notification any, err error) error {
+	payload, marshalErr := json.Marshal(notification)
+	if marshalErr != nil {
+		return err
+	}
+
+	deadLetterErr := u.deadLetterRepository.SaveDeadLetter(entities.DeadLetter{
+		Type:      deadLetterType,
+		Payload:   string(payload),
+		Attempts:  1,
+		LastError: err.Error(),
+	})
+	if deadLetterErr != nil {
+		log.Errorf("failed to save the %s of the order [%d] as dead letter, error: %v", deadLetterType, orderId, deadLetterErr)
 		return err
 	}
 
 	return nil
 }
+
+func (u paymentUseCase) GetPaymentOrder(orderId int) (dto.PaymentDTO, error) {
+	paymentOrder, err := u.paymentRepository.GetPaymentOrder(orderId)
+	if err != nil {
+		log.Errorf("failed to get payment order [%d], error: %v", orderId, err)
+		return dto.PaymentDTO{}, err
+	}
+
+	return dto.NewPaymentDTO(paymentOrder), nil
+}
+
+// GetPaymentQRCode returns the QR code of a payment order that can still be paid.
+func (u paymentUseCase) GetPaymentQRCode(orderId int) (dto.PaymentQRCode, error) {
+	paymentOrder, err := u.paymentRepository.GetPaymentOrder(orderId)
+	if err != nil {
+		log.Errorf("failed to get payment order [%d], error: %v", orderId, err)
+		return dto.PaymentQRCode{}, err
+	}
+
+	if paymentOrder.Status != entities.PaymentStatusPending {
+		return dto.PaymentQRCode{}, ErrPaymentNotPayable
+	}
+
+	return dto.PaymentQRCode{QRCode: paymentOrder.QRCode}, nil
+}
+
+func (u paymentUseCase) SearchPaymentOrders(search dto.PaymentSearchDTO) (dto.PaymentPageDTO, error) {
+	limit := search.Limit
+	if limit == 0 {
+		limit = dto.DefaultPaymentSearchLimit
+	}
+
+	paymentOrders, nextCursor, err := u.paymentRepository.SearchPaymentOrders(search.ToPaymentOrderFilter(), limit, search.Cursor)
+	if err != nil {
+		log.Errorf("failed to search payment orders, error: %v", err)
+		return dto.PaymentPageDTO{}, err
+	}
+
+	payments := []dto.PaymentDTO{}
+	for _, paymentOrder := range paymentOrders {
+		payments = append(payments, dto.NewPaymentDTO(paymentOrder))
+	}
+
+	return dto.PaymentPageDTO{Items: payments, NextCursor: nextCursor}, nil
+}
+
+// SubscribePaymentStatus returns the status changes of the payment order, from any replica,
+// until unsubscribe is called. Subscribe before reading the payment order so no change is missed
+// in between.
+func (u paymentUseCase) SubscribePaymentStatus(orderId int) (<-chan entities.PaymentStatusChange, func()) {
+	return u.paymentStatusHub.Subscribe(orderId)
+}
+
+func (u paymentUseCase) GetPaymentEvents(orderId int) ([]dto.PaymentEventDTO, error) {
+	paymentEvents, err := u.paymentEventRepository.GetPaymentEvents(orderId)
+	if err != nil {
+		log.Errorf("failed to get payment events of the order [%d], error: %v", orderId, err)
+		return nil, err
+	}
+
+	paymentEventDTOs := []dto.PaymentEventDTO{}
+	for _, paymentEvent := range paymentEvents {
+		paymentEventDTOs = append(paymentEventDTOs, dto.NewPaymentEventDTO(paymentEvent))
+	}
+
+	return paymentEventDTOs, nil
+}
+
+// newPaymentEvent describes a change of the payment order, to be written along with it.
+func newPaymentEvent(orderId int, eventType entities.PaymentEventType, from, to entities.PaymentStatus, origin eventOrigin) entities.PaymentEvent {
+	return entities.PaymentEvent{
+		OrderId:     orderId,
+		Type:        eventType,
+		FromStatus:  from,
+		ToStatus:    to,
+		Source:      origin.source,
+		Actor:       origin.actor,
+		PayloadHash: origin.payloadHash,
+		Reason:      origin.reason,
+		CreatedAt:   time.Now(),
+	}
+}
+
+// publishPaymentStatusChange pushes the new status to the clients subscribed to the payment order.
+func (u paymentUseCase) publishPaymentStatusChange(orderId int, status entities.PaymentStatus) {
+	err := u.paymentStatusHub.Publish(entities.PaymentStatusChange{
+		OrderId:   orderId,
+		Status:    status,
+		UpdatedAt: time.Now(),
+	})
+	if err != nil {
+		log.Errorf("failed to publish the %s status of the order [%d], error: %v", status, orderId, err)
+	}
+}
+
+// publishPaymentEvent announces the change to the other services through the message bus.
+func (u paymentUseCase) publishPaymentEvent(eventType entities.PaymentDomainEventType, data entities.PaymentDomainEventData) {
+	occurredAt := time.Now()
+	event := entities.PaymentDomainEvent{
+		EventId:    fmt.Sprintf("%d-%s-%d", data.OrderId, eventType, occurredAt.UnixNano()),
+		Type:       eventType,
+		Version:    entities.PaymentDomainEventVersion,
+		OccurredAt: occurredAt,
+		Data:       data,
+	}
+
+	err := u.eventPublisher.Publish(event)
+	if err != nil {
+		log.Errorf("failed to publish %s event of the order [%d], error: %v", eventType, data.OrderId, err)
+	}
+}
@@ -2,12 +2,16 @@ package usecases
 
 import (
 	"errors"
+	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/entities"
 	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/usecases/dto"
 	drivers "github.com/IgorRamosBR/g73-techchallenge-payment/internal/infra/drivers/payment"
 	mock_payment "github.com/IgorRamosBR/g73-techchallenge-payment/internal/infra/drivers/payment/mocks"
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/infra/gateways"
 	mock_gateways "github.com/IgorRamosBR/g73-techchallenge-payment/internal/infra/gateways/mocks"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/mock/gomock"
@@ -17,6 +21,8 @@ func TestPaymentUseCase_CreatePaymentOrder(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	paymentBroker := mock_payment.NewMockPaymentBroker(ctrl)
 	paymentRepository := mock_gateways.NewMockPaymentRepositoryGateway(ctrl)
+	paymentEventRepository := mock_gateways.NewMockPaymentEventRepositoryGateway(ctrl)
+	eventPublisher := mock_gateways.NewMockEventPublisher(ctrl)
 
 	type args struct {
 		paymentOrder dto.PaymentOrderDTO
@@ -37,12 +43,16 @@ func TestPaymentUseCase_CreatePaymentOrder(t *testing.T) {
 		times        int
 		err          error
 	}
+	type eventPublisherCall struct {
+		times int
+	}
 	tests := []struct {
 		name string
 		args
 		want
 		paymentBrokerCall
 		paymentRepositoryCall
+		eventPublisherCall
 	}{
 		{
 			name: "should fail to create payment order when payment broker returns error",
@@ -109,24 +119,53 @@ func TestPaymentUseCase_CreatePaymentOrder(t *testing.T) {
 				times:        1,
 				err:          nil,
 			},
+			eventPublisherCall: eventPublisherCall{
+				times: 1,
+			},
 		},
 	}
 
 	for _, tt := range tests {
 		paymentBroker.EXPECT().
-			GeneratePaymentQRCode(gomock.Eq(tt.paymentBrokerCall.paymentOrder)).
+			GeneratePaymentQRCode(gomock.Eq(tt.paymentBrokerCall.paymentOrder), gomock.Any()).
 			Times(tt.paymentBrokerCall.times).
 			Return(tt.paymentBrokerCall.paymentQRCode, tt.paymentBrokerCall.err)
 
 		paymentRepository.EXPECT().
-			SavePaymentOrder(gomock.Eq(tt.paymentRepositoryCall.paymentOrder), gomock.Eq(tt.paymentRepositoryCall.qrCode)).
+			SavePaymentOrder(gomock.Cond(func(x any) bool {
+				paymentOrder := x.(entities.PaymentOrder)
+				return paymentOrder.OrderId == tt.paymentRepositoryCall.paymentOrder.OrderId &&
+					paymentOrder.QRCode == tt.paymentRepositoryCall.qrCode &&
+					paymentOrder.Broker == "MERCADO_PAGO" &&
+					paymentOrder.InStoreOrderId == "98765" &&
+					len(paymentOrder.Items) == len(tt.paymentRepositoryCall.paymentOrder.Items)
+			}), gomock.Cond(func(x any) bool {
+				paymentEvent := x.(entities.PaymentEvent)
+				return paymentEvent.Type == entities.PaymentEventTypeCreated && paymentEvent.ToStatus == entities.PaymentStatusPending
+			})).
 			Times(tt.paymentRepositoryCall.times).
 			Return(tt.paymentRepositoryCall.err)
 
+		paymentBroker.EXPECT().GetName().AnyTimes().Return("MERCADO_PAGO")
+
+		eventPublisher.EXPECT().
+			Publish(gomock.Cond(func(x any) bool {
+				event := x.(entities.PaymentDomainEvent)
+				return event.Type == entities.PaymentDomainEventTypeCreated &&
+					event.Version == entities.PaymentDomainEventVersion &&
+					event.Data.OrderId == tt.args.paymentOrder.OrderId &&
+					event.Data.Status == entities.PaymentStatusPending
+			})).
+			Times(tt.eventPublisherCall.times).
+			Return(nil)
+
 		config := PaymentUseCaseConfig{
-			PaymentBroker:     paymentBroker,
-			PaymentRepository: paymentRepository,
-			OrderClient:       nil,
+			PaymentBroker:          paymentBroker,
+			PaymentRepository:      paymentRepository,
+			PaymentEventRepository: paymentEventRepository,
+			EventPublisher:         eventPublisher,
+			OrderClient:            nil,
+			PaymentStatusHub:       gateways.NewPaymentStatusHub(gateways.NewInMemoryPaymentStatusBroadcaster()),
 		}
 		paymentUseCase := NewPaymentUseCase(config)
 
@@ -137,23 +176,175 @@ func TestPaymentUseCase_CreatePaymentOrder(t *testing.T) {
 	}
 }
 
+func TestPaymentUseCase_CreatePaymentOrder_StrictOrderVerification(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	paymentBroker := mock_payment.NewMockPaymentBroker(ctrl)
+	paymentRepository := mock_gateways.NewMockPaymentRepositoryGateway(ctrl)
+	paymentEventRepository := mock_gateways.NewMockPaymentEventRepositoryGateway(ctrl)
+	eventPublisher := mock_gateways.NewMockEventPublisher(ctrl)
+	orderClient := mock_gateways.NewMockOrderClient(ctrl)
+
+	order := dto.OrderDTO{
+		Id:          123,
+		CustomerCPF: "111222333444",
+		Items:       createPaymentOrderDTO().Items,
+		TotalAmount: 9.99,
+		Status:      "CREATED",
+	}
+
+	type want struct {
+		qrCode string
+		err    error
+	}
+	type orderClientCall struct {
+		order dto.OrderDTO
+		err   error
+	}
+	type paymentBrokerCall struct {
+		times int
+	}
+	tests := []struct {
+		name string
+		want
+		orderClientCall
+		paymentBrokerCall
+	}{
+		{
+			name: "should refuse the payment order when the order does not exist",
+			want: want{
+				err: gateways.ErrOrderNotFound,
+			},
+			orderClientCall: orderClientCall{
+				err: gateways.ErrOrderNotFound,
+			},
+		},
+		{
+			name: "should refuse the payment order when the order is not payable",
+			want: want{
+				err: fmt.Errorf("%w: order status is [%s]", ErrOrderNotPayable, "CANCELLED"),
+			},
+			orderClientCall: orderClientCall{
+				order: func() dto.OrderDTO { o := order; o.Status = "CANCELLED"; return o }(),
+			},
+		},
+		{
+			name: "should refuse the payment order when the customer differs",
+			want: want{
+				err: fmt.Errorf("%w: customer differs from the order", ErrOrderMismatch),
+			},
+			orderClientCall: orderClientCall{
+				order: func() dto.OrderDTO { o := order; o.CustomerCPF = "999888777666"; return o }(),
+			},
+		},
+		{
+			name: "should refuse the payment order when the total differs",
+			want: want{
+				err: fmt.Errorf("%w: total amount [9.99] differs from the order total [19.98]", ErrOrderMismatch),
+			},
+			orderClientCall: orderClientCall{
+				order: func() dto.OrderDTO { o := order; o.TotalAmount = 19.98; return o }(),
+			},
+		},
+		{
+			name: "should refuse the payment order when the items differ",
+			want: want{
+				err: fmt.Errorf("%w: items differ from the order items", ErrOrderMismatch),
+			},
+			orderClientCall: orderClientCall{
+				order: func() dto.OrderDTO {
+					o := order
+					o.Items = []dto.PaymentOrderItem{{Quantity: 1, Product: dto.OrderItemProduct{SkuId: "444", Price: 9.99}}}
+					return o
+				}(),
+			},
+		},
+		{
+			name: "should create the payment order when it matches the order",
+			want: want{
+				qrCode: "mercadopago123456",
+			},
+			orderClientCall: orderClientCall{
+				order: order,
+			},
+			paymentBrokerCall: paymentBrokerCall{
+				times: 1,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		orderClient.EXPECT().
+			GetOrder(gomock.Eq(123)).
+			Times(1).
+			Return(tt.orderClientCall.order, tt.orderClientCall.err)
+
+		paymentBroker.EXPECT().
+			GeneratePaymentQRCode(gomock.Any(), gomock.Any()).
+			Times(tt.paymentBrokerCall.times).
+			Return(drivers.PaymentQRCodeResponse{QrData: "mercadopago123456", StoreOrderId: "98765"}, nil)
+
+		paymentRepository.EXPECT().
+			SavePaymentOrder(gomock.Any(), gomock.Any()).
+			Times(tt.paymentBrokerCall.times).
+			Return(nil)
+
+		paymentBroker.EXPECT().GetName().AnyTimes().Return("MERCADO_PAGO")
+
+		eventPublisher.EXPECT().Publish(gomock.Any()).AnyTimes().Return(nil)
+
+		config := PaymentUseCaseConfig{
+			PaymentBroker:           paymentBroker,
+			PaymentRepository:       paymentRepository,
+			PaymentEventRepository:  paymentEventRepository,
+			EventPublisher:          eventPublisher,
+			OrderClient:             orderClient,
+			StrictOrderVerification: true,
+			PayableOrderStatuses:    []string{"CREATED", "AWAITING_PAYMENT"},
+			PaymentStatusHub:        gateways.NewPaymentStatusHub(gateways.NewInMemoryPaymentStatusBroadcaster()),
+		}
+		paymentUseCase := NewPaymentUseCase(config)
+
+		qrCode, err := paymentUseCase.CreatePaymentOrder(createPaymentOrderDTO())
+
+		assert.Equal(t, tt.want.qrCode, qrCode)
+		assert.Equal(t, tt.want.err, err)
+	}
+}
+
 func TestPaymentUseCase_NotifyPayment(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	paymentRepository := mock_gateways.NewMockPaymentRepositoryGateway(ctrl)
+	paymentEventRepository := mock_gateways.NewMockPaymentEventRepositoryGateway(ctrl)
+	eventPublisher := mock_gateways.NewMockEventPublisher(ctrl)
 	orderClient := mock_gateways.NewMockOrderClient(ctrl)
+	deadLetterRepository := mock_gateways.NewMockDeadLetterRepositoryGateway(ctrl)
 
 	type args struct {
-		orderId   int
-		paymentId int
+		orderId         int
+		paymentId       int
+		merchantOrderId int
 	}
 	type want struct {
 		err error
 	}
+	type getPaymentOrderCall struct {
+		times        int
+		paymentOrder entities.PaymentOrder
+		err          error
+	}
+	type flagForRefundCall struct {
+		times int
+		err   error
+	}
 	type orderClientCall struct {
 		orderId int
 		times   int
 		err     error
 	}
+	type deadLetterCall struct {
+		times int
+		err   error
+	}
 	type paymentRepositoryCall struct {
 		orderId   int
 		paymentId int
@@ -164,18 +355,89 @@ func TestPaymentUseCase_NotifyPayment(t *testing.T) {
 		name string
 		args
 		want
+		getPaymentOrderCall
+		flagForRefundCall
 		orderClientCall
+		deadLetterCall
 		paymentRepositoryCall
 	}{
+		{
+			name: "should fail to notify payment when payment order is not found",
+			args: args{
+				orderId:         123,
+				paymentId:       111,
+				merchantOrderId: 222,
+			},
+			want: want{
+				err: gateways.ErrPaymentOrderNotFound,
+			},
+			getPaymentOrderCall: getPaymentOrderCall{
+				times: 1,
+				err:   gateways.ErrPaymentOrderNotFound,
+			},
+		},
+		{
+			name: "should flag payment order for refund when it was cancelled",
+			args: args{
+				orderId:         123,
+				paymentId:       111,
+				merchantOrderId: 222,
+			},
+			want: want{
+				err: nil,
+			},
+			getPaymentOrderCall: getPaymentOrderCall{
+				times:        1,
+				paymentOrder: entities.PaymentOrder{OrderId: 123, Status: entities.PaymentStatusCancelled},
+			},
+			flagForRefundCall: flagForRefundCall{
+				times: 1,
+			},
+		},
+		{
+			name: "should skip notification when the closed payment order is already flagged for refund",
+			args: args{
+				orderId:         123,
+				paymentId:       111,
+				merchantOrderId: 222,
+			},
+			want: want{
+				err: nil,
+			},
+			getPaymentOrderCall: getPaymentOrderCall{
+				times:        1,
+				paymentOrder: entities.PaymentOrder{OrderId: 123, Status: entities.PaymentStatusCancelled, RefundRequired: true},
+			},
+		},
+		{
+			name: "should skip notification when the payment order is already paid",
+			args: args{
+				orderId:         123,
+				paymentId:       111,
+				merchantOrderId: 222,
+			},
+			want: want{
+				err: nil,
+			},
+			getPaymentOrderCall: getPaymentOrderCall{
+				times:        1,
+				paymentOrder: entities.PaymentOrder{OrderId: 123, Status: entities.PaymentStatusPaid},
+			},
+		},
 		{
 			name: "should fail to notify payment when payment repository returns error",
 			args: args{
-				orderId:   123,
-				paymentId: 111,
+				orderId:         123,
+				paymentId:       111,
+				merchantOrderId: 222,
 			},
 			want: want{
 				err: errors.New("internal server error"),
 			},
+			getPaymentOrderCall: getPaymentOrderCall{
+				times:        1,
+				paymentOrder: entities.PaymentOrder{OrderId: 123, Status: entities.PaymentStatusPending},
+			},
 			paymentRepositoryCall: paymentRepositoryCall{
 				orderId:   123,
 				paymentId: 111,
@@ -184,14 +446,19 @@ func TestPaymentUseCase_NotifyPayment(t *testing.T) {
 			},
 		},
 		{
-			name: "should fail to notify payment when order client returns error",
+			name: "should fail to notify payment when order client and dead-letter repository return error",
 			args: args{
-				orderId:   123,
-				paymentId: 111,
+				orderId:         123,
+				paymentId:       111,
+				merchantOrderId: 222,
 			},
 			want: want{
 				err: errors.New("internal server error"),
 			},
+			getPaymentOrderCall: getPaymentOrderCall{
+				times:        1,
+				paymentOrder: entities.PaymentOrder{OrderId: 123, Status: entities.PaymentStatusPending},
+			},
 			paymentRepositoryCall: paymentRepositoryCall{
 				orderId:   123,
 				paymentId: 111,
@@ -203,16 +470,54 @@ func TestPaymentUseCase_NotifyPayment(t *testing.T) {
 				times:   1,
 				err:     errors.New("internal server error"),
 			},
+			deadLetterCall: deadLetterCall{
+				times: 1,
+				err:   errors.New("dynamodb unavailable"),
+			},
 		},
 		{
-			name: "should notify payment successfully",
+			name: "should save the order notification as dead letter when order client returns error",
 			args: args{
+				orderId:         123,
+				paymentId:       111,
+				merchantOrderId: 222,
+			},
+			want: want{
+				err: nil,
+			},
+			getPaymentOrderCall: getPaymentOrderCall{
+				times:        1,
+				paymentOrder: entities.PaymentOrder{OrderId: 123, Status: entities.PaymentStatusPending},
+			},
+			paymentRepositoryCall: paymentRepositoryCall{
 				orderId:   123,
 				paymentId: 111,
+				times:     1,
+				err:       nil,
+			},
+			orderClientCall: orderClientCall{
+				orderId: 123,
+				times:   1,
+				err:     errors.New("internal server error"),
+			},
+			deadLetterCall: deadLetterCall{
+				times: 1,
+			},
+		},
+		{
+			name: "should notify payment successfully",
+			args: args{
+				orderId:         123,
+				paymentId:       111,
+				merchantOrderId: 222,
 			},
 			want: want{
 				err: nil,
 			},
+			getPaymentOrderCall: getPaymentOrderCall{
+				times:        1,
+				paymentOrder: entities.PaymentOrder{OrderId: 123, Status: entities.PaymentStatusPending},
+			},
 			paymentRepositoryCall: paymentRepositoryCall{
 				orderId:   123,
 				paymentId: 111,
@@ -229,7 +534,17 @@ func TestPaymentUseCase_NotifyPayment(t *testing.T) {
 
 	for _, tt := range tests {
 		paymentRepository.EXPECT().
-			UpdatePaymentOrderStatus(gomock.Eq(tt.paymentRepositoryCall.orderId), gomock.Eq(tt.paymentRepositoryCall.paymentId), gomock.Eq(entities.PaymentStatusPaid)).
+			GetPaymentOrder(gomock.Eq(tt.args.orderId)).
+			Times(tt.getPaymentOrderCall.times).
+			Return(tt.getPaymentOrderCall.paymentOrder, tt.getPaymentOrderCall.err)
+
+		paymentRepository.EXPECT().
+			FlagPaymentOrderForRefund(gomock.Eq(tt.args.orderId), gomock.Eq(tt.args.paymentId), gomock.Any()).
+			Times(tt.flagForRefundCall.times).
+			Return(tt.flagForRefundCall.err)
+
+		paymentRepository.EXPECT().
+			TransitionPaymentOrderStatus(gomock.Eq(tt.paymentRepositoryCall.orderId), gomock.Eq(tt.paymentRepositoryCall.paymentId), gomock.Eq(tt.args.merchantOrderId), gomock.Eq(entities.PaymentStatusPending), gomock.Eq(entities.PaymentStatusPaid), gomock.Any()).
 			Times(tt.paymentRepositoryCall.times).
 			Return(tt.paymentRepositoryCall.err)
 
@@ -238,35 +553,2242 @@ func TestPaymentUseCase_NotifyPayment(t *testing.T) {
 			Times(tt.orderClientCall.times).
 			Return(tt.orderClientCall.err)
 
+		deadLetterRepository.EXPECT().
+			SaveDeadLetter(gomock.Cond(func(x any) bool {
+				deadLetter := x.(entities.DeadLetter)
+				return deadLetter.Type == entities.DeadLetterTypeOrderNotification &&
+					deadLetter.Payload == `{"orderId":123,"status":"PAID"}` &&
+					deadLetter.Attempts == 1 &&
+					deadLetter.LastError == "internal server error"
+			})).
+			Times(tt.deadLetterCall.times).
+			Return(tt.deadLetterCall.err)
+
+		eventPublisher.EXPECT().Publish(gomock.Any()).AnyTimes().Return(nil)
+
 		config := PaymentUseCaseConfig{
-			PaymentRepository: paymentRepository,
-			OrderClient:       orderClient,
+			PaymentRepository:      paymentRepository,
+			PaymentEventRepository: paymentEventRepository,
+			EventPublisher:         eventPublisher,
+			OrderClient:            orderClient,
+			DeadLetterRepository:   deadLetterRepository,
+			PaymentStatusHub:       gateways.NewPaymentStatusHub(gateways.NewInMemoryPaymentStatusBroadcaster()),
 		}
 		paymentUseCase := NewPaymentUseCase(config)
 
-		err := paymentUseCase.NotifyPayment(tt.args.orderId, tt.args.paymentId)
+		err := paymentUseCase.NotifyPayment(dto.PaymentNotification{
+			OrderId:         tt.args.orderId,
+			PaymentId:       tt.args.paymentId,
+			MerchantOrderId: tt.args.merchantOrderId,
+		})
 
 		assert.Equal(t, tt.want.err, err)
 	}
 }
 
-func createPaymentOrderDTO() dto.PaymentOrderDTO {
-	return dto.PaymentOrderDTO{
+func TestPaymentUseCase_NotifyPayment_Production(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	paymentRepository := mock_gateways.NewMockPaymentRepositoryGateway(ctrl)
+	paymentEventRepository := mock_gateways.NewMockPaymentEventRepositoryGateway(ctrl)
+	eventPublisher := mock_gateways.NewMockEventPublisher(ctrl)
+	orderClient := mock_gateways.NewMockOrderClient(ctrl)
+	productionClient := mock_gateways.NewMockProductionClient(ctrl)
+	deadLetterRepository := mock_gateways.NewMockDeadLetterRepositoryGateway(ctrl)
+
+	paymentOrder := entities.PaymentOrder{
 		OrderId:     123,
-		CustomerCPF: "111222333444",
-		Items: []dto.PaymentOrderItem{
-			{
-				Quantity: 1,
-				Product: dto.OrderItemProduct{
-					Name:        "Batata frita",
-					SkuId:       "333",
-					Description: "Batata canoa",
-					Category:    "Acompanhamento",
-					Type:        "UNIT",
-					Price:       9.99,
-				},
+		CustomerCPF: "12345678900",
+		Status:      entities.PaymentStatusPending,
+		Items: []entities.PaymentItem{
+			{SkuId: "1", Name: "X-Burger", Category: "Lanche", Type: "UNIT", Quantity: 2, UnitPrice: 17.75},
+		},
+	}
+
+	type args struct {
+		productionEnabled bool
+	}
+	type want struct {
+		err error
+	}
+	type orderClientCall struct {
+		err error
+	}
+	type productionClientCall struct {
+		times int
+		err   error
+	}
+	type deadLetterCall struct {
+		deadLetterType entities.DeadLetterType
+		times          int
+		err            error
+	}
+	tests := []struct {
+		name string
+		args
+		want
+		orderClientCall
+		productionClientCall
+		deadLetterCall
+	}{
+		{
+			name: "should not submit the order to production when it is disabled",
+			args: args{
+				productionEnabled: false,
+			},
+			want: want{
+				err: nil,
+			},
+		},
+		{
+			name: "should submit the paid order to production",
+			args: args{
+				productionEnabled: true,
+			},
+			want: want{
+				err: nil,
+			},
+			productionClientCall: productionClientCall{
+				times: 1,
+			},
+		},
+		{
+			name: "should save the production order as dead letter when production fails",
+			args: args{
+				productionEnabled: true,
+			},
+			want: want{
+				err: nil,
+			},
+			productionClientCall: productionClientCall{
+				times: 1,
+				err:   errors.New("failed to call production api, status [503] non-2xx"),
+			},
+			deadLetterCall: deadLetterCall{
+				deadLetterType: entities.DeadLetterTypeProductionNotification,
+				times:          1,
+			},
+		},
+		{
+			name: "should submit the order to production even when the order notification fails",
+			args: args{
+				productionEnabled: true,
+			},
+			want: want{
+				err: errors.New("failed to call order api, status [503] non-2xx"),
+			},
+			orderClientCall: orderClientCall{
+				err: errors.New("failed to call order api, status [503] non-2xx"),
+			},
+			productionClientCall: productionClientCall{
+				times: 1,
+			},
+			deadLetterCall: deadLetterCall{
+				deadLetterType: entities.DeadLetterTypeOrderNotification,
+				times:          1,
+				err:            errors.New("internal server error"),
 			},
 		},
-		TotalAmount: 9.99,
 	}
+
+	for _, tt := range tests {
+		paymentRepository.EXPECT().
+			GetPaymentOrder(gomock.Eq(123)).
+			Times(1).
+			Return(paymentOrder, nil)
+
+		paymentRepository.EXPECT().
+			TransitionPaymentOrderStatus(gomock.Eq(123), gomock.Eq(111), gomock.Eq(0), gomock.Eq(entities.PaymentStatusPending), gomock.Eq(entities.PaymentStatusPaid), gomock.Cond(func(x any) bool {
+				return x.(entities.PaymentEvent).Type == entities.PaymentEventTypeStatusChanged
+			})).
+			Times(1).
+			Return(nil)
+
+		orderClient.EXPECT().
+			NotifyPaymentOrder(gomock.Eq(123), gomock.Eq(entities.PaymentStatusPaid)).
+			Times(1).
+			Return(tt.orderClientCall.err)
+
+		productionClient.EXPECT().
+			SubmitProductionOrder(gomock.Eq(entities.ProductionOrder{
+				OrderId:     123,
+				CustomerCPF: "12345678900",
+				Items: []entities.ProductionOrderItem{
+					{SkuId: "1", Name: "X-Burger", Category: "Lanche", Type: "UNIT", Quantity: 2},
+				},
+			})).
+			Times(tt.productionClientCall.times).
+			Return(tt.productionClientCall.err)
+
+		deadLetterRepository.EXPECT().
+			SaveDeadLetter(gomock.Cond(func(x any) bool {
+				deadLetter := x.(entities.DeadLetter)
+				return deadLetter.Type == tt.deadLetterCall.deadLetterType && deadLetter.Attempts == 1
+			})).
+			Times(tt.deadLetterCall.times).
+			Return(tt.deadLetterCall.err)
+
+		eventPublisher.EXPECT().Publish(gomock.Any()).AnyTimes().Return(nil)
+
+		config := PaymentUseCaseConfig{
+			PaymentRepository:      paymentRepository,
+			PaymentEventRepository: paymentEventRepository,
+			EventPublisher:         eventPublisher,
+			OrderClient:            orderClient,
+			ProductionClient:       productionClient,
+			ProductionEnabled:      tt.args.productionEnabled,
+			DeadLetterRepository:   deadLetterRepository,
+			PaymentStatusHub:       gateways.NewPaymentStatusHub(gateways.NewInMemoryPaymentStatusBroadcaster()),
+		}
+		paymentUseCase := NewPaymentUseCase(config)
+
+		err := paymentUseCase.NotifyPayment(dto.PaymentNotification{
+			OrderId:   123,
+			PaymentId: 111,
+		})
+
+		assert.Equal(t, tt.want.err, err)
+	}
+}
+
+func TestPaymentUseCase_NotifyPayment_Compensation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	paymentBroker := mock_payment.NewMockPaymentBroker(ctrl)
+	paymentRepository := mock_gateways.NewMockPaymentRepositoryGateway(ctrl)
+	paymentEventRepository := mock_gateways.NewMockPaymentEventRepositoryGateway(ctrl)
+	eventPublisher := mock_gateways.NewMockEventPublisher(ctrl)
+	orderClient := mock_gateways.NewMockOrderClient(ctrl)
+	productionClient := mock_gateways.NewMockProductionClient(ctrl)
+	deadLetterRepository := mock_gateways.NewMockDeadLetterRepositoryGateway(ctrl)
+
+	type want struct {
+		err error
+	}
+	type orderClientCall struct {
+		err           error
+		getOrderTimes int
+		orderStatus   string
+	}
+	type refundCall struct {
+		times int
+		err   error
+	}
+	type saveRefundCall struct {
+		times int
+		err   error
+	}
+	type settleRefundCall struct {
+		times int
+	}
+	type releaseRefundCall struct {
+		times int
+	}
+	type flagForRefundCall struct {
+		times int
+	}
+	type productionClientCall struct {
+		times int
+	}
+	type deadLetterCall struct {
+		times int
+	}
+	tests := []struct {
+		name string
+		want
+		orderClientCall
+		refundCall
+		saveRefundCall
+		settleRefundCall
+		releaseRefundCall
+		flagForRefundCall
+		productionClientCall
+		deadLetterCall
+	}{
+		{
+			name: "should keep the payment and retry later when the order api fails temporarily",
+			want: want{
+				err: nil,
+			},
+			orderClientCall: orderClientCall{
+				err: &gateways.OrderApiError{StatusCode: 503},
+			},
+			productionClientCall: productionClientCall{
+				times: 1,
+			},
+			deadLetterCall: deadLetterCall{
+				times: 1,
+			},
+		},
+		{
+			name: "should refund the payment when the order api rejects it for a cancelled order",
+			want: want{
+				err: nil,
+			},
+			orderClientCall: orderClientCall{
+				err:           &gateways.OrderApiError{StatusCode: 409},
+				getOrderTimes: 1,
+				orderStatus:   "CANCELLED",
+			},
+			refundCall: refundCall{
+				times: 1,
+			},
+			saveRefundCall: saveRefundCall{
+				times: 1,
+			},
+			settleRefundCall: settleRefundCall{
+				times: 1,
+			},
+		},
+		{
+			name: "should keep the payment and retry later when the rejected order is not cancelled",
+			want: want{
+				err: nil,
+			},
+			orderClientCall: orderClientCall{
+				err:           &gateways.OrderApiError{StatusCode: 409},
+				getOrderTimes: 1,
+				orderStatus:   "PREPARING",
+			},
+			productionClientCall: productionClientCall{
+				times: 1,
+			},
+			deadLetterCall: deadLetterCall{
+				times: 1,
+			},
+		},
+		{
+			name: "should keep the payment and retry later when the order api rejects the request itself",
+			want: want{
+				err: nil,
+			},
+			orderClientCall: orderClientCall{
+				err: &gateways.OrderApiError{StatusCode: 404},
+			},
+			productionClientCall: productionClientCall{
+				times: 1,
+			},
+			deadLetterCall: deadLetterCall{
+				times: 1,
+			},
+		},
+		{
+			name: "should release the refund and flag the payment when the broker fails to refund it",
+			want: want{
+				err: nil,
+			},
+			orderClientCall: orderClientCall{
+				err:           &gateways.OrderApiError{StatusCode: 410},
+				getOrderTimes: 1,
+				orderStatus:   "CANCELLED",
+			},
+			refundCall: refundCall{
+				times: 1,
+				err:   errors.New("internal server error"),
+			},
+			saveRefundCall: saveRefundCall{
+				times: 1,
+			},
+			releaseRefundCall: releaseRefundCall{
+				times: 1,
+			},
+			flagForRefundCall: flagForRefundCall{
+				times: 1,
+			},
+		},
+		{
+			name: "should flag the payment without refunding it when the refund cannot be reserved",
+			want: want{
+				err: nil,
+			},
+			orderClientCall: orderClientCall{
+				err:           &gateways.OrderApiError{StatusCode: 409},
+				getOrderTimes: 1,
+				orderStatus:   "CANCELLED",
+			},
+			saveRefundCall: saveRefundCall{
+				times: 1,
+				err:   gateways.ErrPaymentOrderStatusConflict,
+			},
+			flagForRefundCall: flagForRefundCall{
+				times: 1,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		paymentRepository.EXPECT().
+			GetPaymentOrder(gomock.Eq(123)).
+			Times(1).
+			Return(entities.PaymentOrder{OrderId: 123, TotalAmout: 35.5, Status: entities.PaymentStatusPending}, nil)
+
+		paymentRepository.EXPECT().
+			TransitionPaymentOrderStatus(gomock.Eq(123), gomock.Eq(111), gomock.Eq(0), gomock.Eq(entities.PaymentStatusPending), gomock.Eq(entities.PaymentStatusPaid), gomock.Any()).
+			Times(1).
+			Return(nil)
+
+		orderClient.EXPECT().
+			NotifyPaymentOrder(gomock.Eq(123), gomock.Eq(entities.PaymentStatusPaid)).
+			Times(1).
+			Return(tt.orderClientCall.err)
+
+		orderClient.EXPECT().
+			GetOrder(gomock.Eq(123)).
+			Times(tt.orderClientCall.getOrderTimes).
+			Return(dto.OrderDTO{Id: 123, Status: tt.orderClientCall.orderStatus}, nil)
+
+		paymentBroker.EXPECT().
+			RefundPayment(gomock.Eq(111), gomock.Eq(35.5), gomock.Eq("123-refund-1")).
+			Times(tt.refundCall.times).
+			Return(drivers.RefundResponse{Id: 999, Status: "approved"}, tt.refundCall.err)
+
+		paymentRepository.EXPECT().
+			SaveRefund(gomock.Cond(func(x any) bool {
+				paymentOrder := x.(entities.PaymentOrder)
+				return paymentOrder.Status == entities.PaymentStatusPaid && paymentOrder.PaymentId == 111
+			}), gomock.Cond(func(x any) bool {
+				refund := x.(entities.Refund)
+				return refund.Status == entities.RefundStatusPending && refund.Amount == 35.5
+			}), gomock.Eq(entities.PaymentStatusRefundPending), compensationEvent(entities.PaymentEventTypeCompensated)).
+			Times(tt.saveRefundCall.times).
+			Return(tt.saveRefundCall.err)
+
+		paymentRepository.EXPECT().
+			SettleRefund(gomock.Eq(123), gomock.Eq(0), gomock.Cond(func(x any) bool {
+				return x.(entities.Refund).RefundId == 999
+			}), compensationEvent(entities.PaymentEventTypeRefunded)).
+			Times(tt.settleRefundCall.times).
+			Return(nil)
+
+		paymentRepository.EXPECT().
+			ReleaseRefund(gomock.Cond(func(x any) bool {
+				return x.(entities.PaymentOrder).Status == entities.PaymentStatusRefundPending
+			}), gomock.Eq(0), gomock.Eq(entities.PaymentStatusPaid), gomock.Any()).
+			Times(tt.releaseRefundCall.times).
+			Return(nil)
+
+		paymentRepository.EXPECT().
+			FlagPaymentOrderForRefund(gomock.Eq(123), gomock.Eq(111), compensationEvent(entities.PaymentEventTypeFlaggedForRefund)).
+			Times(tt.flagForRefundCall.times).
+			Return(nil)
+
+		productionClient.EXPECT().
+			SubmitProductionOrder(gomock.Any()).
+			Times(tt.productionClientCall.times).
+			Return(nil)
+
+		deadLetterRepository.EXPECT().
+			SaveDeadLetter(gomock.Any()).
+			Times(tt.deadLetterCall.times).
+			Return(nil)
+
+		eventPublisher.EXPECT().Publish(gomock.Any()).AnyTimes().Return(nil)
+
+		config := PaymentUseCaseConfig{
+			PaymentBroker:          paymentBroker,
+			PaymentRepository:      paymentRepository,
+			PaymentEventRepository: paymentEventRepository,
+			EventPublisher:         eventPublisher,
+			OrderClient:            orderClient,
+			ProductionClient:       productionClient,
+			ProductionEnabled:      true,
+			CancelledOrderStatuses: []string{"CANCELLED"},
+			DeadLetterRepository:   deadLetterRepository,
+			PaymentStatusHub:       gateways.NewPaymentStatusHub(gateways.NewInMemoryPaymentStatusBroadcaster()),
+		}
+		paymentUseCase := NewPaymentUseCase(config)
+
+		err := paymentUseCase.NotifyPayment(dto.PaymentNotification{
+			OrderId:   123,
+			PaymentId: 111,
+		})
+
+		assert.Equal(t, tt.want.err, err)
+	}
+}
+
+func TestPaymentUseCase_ReplayOrderNotification(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	paymentBroker := mock_payment.NewMockPaymentBroker(ctrl)
+	paymentRepository := mock_gateways.NewMockPaymentRepositoryGateway(ctrl)
+	paymentEventRepository := mock_gateways.NewMockPaymentEventRepositoryGateway(ctrl)
+	eventPublisher := mock_gateways.NewMockEventPublisher(ctrl)
+	orderClient := mock_gateways.NewMockOrderClient(ctrl)
+	productionClient := mock_gateways.NewMockProductionClient(ctrl)
+	deadLetterRepository := mock_gateways.NewMockDeadLetterRepositoryGateway(ctrl)
+
+	type want struct {
+		err error
+	}
+	type getPaymentOrderCall struct {
+		status entities.PaymentStatus
+	}
+	type notifyPaymentOrderCall struct {
+		times  int
+		status entities.PaymentStatus
+		err    error
+	}
+	type getOrderCall struct {
+		times int
+	}
+	type compensationCall struct {
+		times int
+	}
+	tests := []struct {
+		name         string
+		notification entities.OrderNotification
+		want
+		getPaymentOrderCall
+		notifyPaymentOrderCall
+		getOrderCall
+		compensationCall
+	}{
+		{
+			name:         "should drop the notification when the payment order changed status since",
+			notification: entities.OrderNotification{OrderId: 123, Status: entities.PaymentStatusPaid},
+			getPaymentOrderCall: getPaymentOrderCall{
+				status: entities.PaymentStatusRefunded,
+			},
+		},
+		{
+			name:         "should notify the order api of a status other than paid",
+			notification: entities.OrderNotification{OrderId: 123, Status: entities.PaymentStatusExpired},
+			getPaymentOrderCall: getPaymentOrderCall{
+				status: entities.PaymentStatusExpired,
+			},
+			notifyPaymentOrderCall: notifyPaymentOrderCall{
+				times:  1,
+				status: entities.PaymentStatusExpired,
+			},
+		},
+		{
+			name:         "should keep the dead letter when the order api fails again",
+			notification: entities.OrderNotification{OrderId: 123, Status: entities.PaymentStatusPaid},
+			want: want{
+				err: &gateways.OrderApiError{StatusCode: 503},
+			},
+			getPaymentOrderCall: getPaymentOrderCall{
+				status: entities.PaymentStatusPaid,
+			},
+			notifyPaymentOrderCall: notifyPaymentOrderCall{
+				times:  1,
+				status: entities.PaymentStatusPaid,
+				err:    &gateways.OrderApiError{StatusCode: 503},
+			},
+		},
+		{
+			name:         "should refund the payment when the order api rejects it for a cancelled order",
+			notification: entities.OrderNotification{OrderId: 123, Status: entities.PaymentStatusPaid},
+			getPaymentOrderCall: getPaymentOrderCall{
+				status: entities.PaymentStatusPaid,
+			},
+			notifyPaymentOrderCall: notifyPaymentOrderCall{
+				times:  1,
+				status: entities.PaymentStatusPaid,
+				err:    &gateways.OrderApiError{StatusCode: 409},
+			},
+			getOrderCall: getOrderCall{
+				times: 1,
+			},
+			compensationCall: compensationCall{
+				times: 1,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		paymentRepository.EXPECT().
+			GetPaymentOrder(gomock.Eq(123)).
+			Times(1).
+			Return(entities.PaymentOrder{OrderId: 123, PaymentId: 111, TotalAmout: 35.5, Status: tt.getPaymentOrderCall.status}, nil)
+
+		orderClient.EXPECT().
+			NotifyPaymentOrder(gomock.Eq(123), gomock.Eq(tt.notifyPaymentOrderCall.status)).
+			Times(tt.notifyPaymentOrderCall.times).
+			Return(tt.notifyPaymentOrderCall.err)
+
+		orderClient.EXPECT().
+			GetOrder(gomock.Eq(123)).
+			Times(tt.getOrderCall.times).
+			Return(dto.OrderDTO{Id: 123, Status: "CANCELLED"}, nil)
+
+		paymentRepository.EXPECT().
+			SaveRefund(gomock.Any(), gomock.Any(), gomock.Eq(entities.PaymentStatusRefundPending), compensationEvent(entities.PaymentEventTypeCompensated)).
+			Times(tt.compensationCall.times).
+			Return(nil)
+
+		paymentBroker.EXPECT().
+			RefundPayment(gomock.Eq(111), gomock.Eq(35.5), gomock.Eq("123-refund-1")).
+			Times(tt.compensationCall.times).
+			Return(drivers.RefundResponse{Id: 999, Status: "approved"}, nil)
+
+		paymentRepository.EXPECT().
+			SettleRefund(gomock.Eq(123), gomock.Eq(0), gomock.Any(), compensationEvent(entities.PaymentEventTypeRefunded)).
+			Times(tt.compensationCall.times).
+			Return(nil)
+
+		eventPublisher.EXPECT().Publish(gomock.Any()).AnyTimes().Return(nil)
+
+		config := PaymentUseCaseConfig{
+			PaymentBroker:          paymentBroker,
+			PaymentRepository:      paymentRepository,
+			PaymentEventRepository: paymentEventRepository,
+			EventPublisher:         eventPublisher,
+			OrderClient:            orderClient,
+			ProductionClient:       productionClient,
+			ProductionEnabled:      true,
+			CancelledOrderStatuses: []string{"CANCELLED"},
+			DeadLetterRepository:   deadLetterRepository,
+			PaymentStatusHub:       gateways.NewPaymentStatusHub(gateways.NewInMemoryPaymentStatusBroadcaster()),
+		}
+		paymentUseCase := NewPaymentUseCase(config)
+
+		err := paymentUseCase.ReplayOrderNotification(tt.notification)
+
+		assert.Equal(t, tt.want.err, err)
+	}
+}
+
+func TestPaymentUseCase_NotifyPayment_Deduplication(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	paymentRepository := mock_gateways.NewMockPaymentRepositoryGateway(ctrl)
+	paymentEventRepository := mock_gateways.NewMockPaymentEventRepositoryGateway(ctrl)
+	eventPublisher := mock_gateways.NewMockEventPublisher(ctrl)
+	processedNotificationRepository := mock_gateways.NewMockProcessedNotificationRepositoryGateway(ctrl)
+	orderClient := mock_gateways.NewMockOrderClient(ctrl)
+	deadLetterRepository := mock_gateways.NewMockDeadLetterRepositoryGateway(ctrl)
+
+	type want struct {
+		err error
+	}
+	type registerNotificationCall struct {
+		times      int
+		registered bool
+		err        error
+	}
+	type releaseNotificationCall struct {
+		times int
+	}
+	type completeNotificationCall struct {
+		times int
+		err   error
+	}
+	type notifyPaymentCall struct {
+		times int
+		err   error
+	}
+	tests := []struct {
+		name string
+		want
+		registerNotificationCall
+		releaseNotificationCall
+		completeNotificationCall
+		notifyPaymentCall
+	}{
+		{
+			name: "should fail to notify payment when the notification cannot be registered",
+			want: want{
+				err: errors.New("internal server error"),
+			},
+			registerNotificationCall: registerNotificationCall{
+				times: 1,
+				err:   errors.New("internal server error"),
+			},
+		},
+		{
+			name: "should skip a notification that was already processed",
+			want: want{
+				err: nil,
+			},
+			registerNotificationCall: registerNotificationCall{
+				times:      1,
+				registered: false,
+			},
+		},
+		{
+			name: "should release the notification when it fails to be processed",
+			want: want{
+				err: errors.New("internal server error"),
+			},
+			registerNotificationCall: registerNotificationCall{
+				times:      1,
+				registered: true,
+			},
+			releaseNotificationCall: releaseNotificationCall{
+				times: 1,
+			},
+			notifyPaymentCall: notifyPaymentCall{
+				times: 1,
+				err:   errors.New("internal server error"),
+			},
+		},
+		{
+			name: "should process a new notification",
+			want: want{
+				err: nil,
+			},
+			registerNotificationCall: registerNotificationCall{
+				times:      1,
+				registered: true,
+			},
+			completeNotificationCall: completeNotificationCall{
+				times: 1,
+			},
+			notifyPaymentCall: notifyPaymentCall{
+				times: 1,
+			},
+		},
+		{
+			name: "should process a new notification even when it fails to be completed",
+			want: want{
+				err: nil,
+			},
+			registerNotificationCall: registerNotificationCall{
+				times:      1,
+				registered: true,
+			},
+			completeNotificationCall: completeNotificationCall{
+				times: 1,
+				err:   errors.New("internal server error"),
+			},
+			notifyPaymentCall: notifyPaymentCall{
+				times: 1,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		now := time.Now()
+		processedNotificationRepository.EXPECT().
+			RegisterNotification(gomock.Eq("7890:payment"), gomock.Cond(func(expiresAt any) bool {
+				return expiresAt.(time.Time).Before(now.Add(time.Hour))
+			})).
+			Times(tt.registerNotificationCall.times).
+			Return(tt.registerNotificationCall.registered, tt.registerNotificationCall.err)
+
+		processedNotificationRepository.EXPECT().
+			CompleteNotification(gomock.Eq("7890:payment"), gomock.Cond(func(expiresAt any) bool {
+				return expiresAt.(time.Time).After(now.Add(23 * time.Hour))
+			})).
+			Times(tt.completeNotificationCall.times).
+			Return(tt.completeNotificationCall.err)
+
+		processedNotificationRepository.EXPECT().
+			ReleaseNotification(gomock.Eq("7890:payment")).
+			Times(tt.releaseNotificationCall.times).
+			Return(nil)
+
+		paymentRepository.EXPECT().
+			GetPaymentOrder(gomock.Eq(123)).
+			Times(tt.notifyPaymentCall.times).
+			Return(entities.PaymentOrder{OrderId: 123, Status: entities.PaymentStatusPending}, nil)
+
+		paymentRepository.EXPECT().
+			TransitionPaymentOrderStatus(gomock.Eq(123), gomock.Eq(7890), gomock.Eq(0), gomock.Eq(entities.PaymentStatusPending), gomock.Eq(entities.PaymentStatusPaid), gomock.Any()).
+			Times(tt.notifyPaymentCall.times).
+			Return(tt.notifyPaymentCall.err)
+
+		deadLetterRepository.EXPECT().SaveDeadLetter(gomock.Any()).AnyTimes().Return(nil)
+		orderClient.EXPECT().NotifyPaymentOrder(gomock.Any(), gomock.Any()).AnyTimes().Return(nil)
+
+		eventPublisher.EXPECT().Publish(gomock.Any()).AnyTimes().Return(nil)
+
+		config := PaymentUseCaseConfig{
+			PaymentRepository:               paymentRepository,
+			PaymentEventRepository:          paymentEventRepository,
+			EventPublisher:                  eventPublisher,
+			ProcessedNotificationRepository: processedNotificationRepository,
+			OrderClient:                     orderClient,
+			DeadLetterRepository:            deadLetterRepository,
+			NotificationDeduplicationTTL:    24 * time.Hour,
+			PaymentStatusHub:                gateways.NewPaymentStatusHub(gateways.NewInMemoryPaymentStatusBroadcaster()),
+		}
+		paymentUseCase := NewPaymentUseCase(config)
+
+		err := paymentUseCase.NotifyPayment(dto.PaymentNotification{
+			NotificationId: "7890:payment",
+			OrderId:        123,
+			PaymentId:      7890,
+		})
+
+		assert.Equal(t, tt.want.err, err)
+	}
+}
+
+func TestPaymentUseCase_NotifyPayment_ConcurrentChange(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	paymentRepository := mock_gateways.NewMockPaymentRepositoryGateway(ctrl)
+	paymentEventRepository := mock_gateways.NewMockPaymentEventRepositoryGateway(ctrl)
+	eventPublisher := mock_gateways.NewMockEventPublisher(ctrl)
+	orderClient := mock_gateways.NewMockOrderClient(ctrl)
+
+	type want struct {
+		err error
+	}
+	type reloadCall struct {
+		paymentOrder entities.PaymentOrder
+	}
+	type flagForRefundCall struct {
+		times int
+	}
+	type orderClientCall struct {
+		times int
+	}
+	tests := []struct {
+		name string
+		want
+		reloadCall
+		flagForRefundCall
+		orderClientCall
+	}{
+		{
+			name: "should flag the payment for refund when the order is cancelled while confirming it",
+			want: want{
+				err: nil,
+			},
+			reloadCall: reloadCall{
+				paymentOrder: entities.PaymentOrder{OrderId: 123, Status: entities.PaymentStatusCancelled},
+			},
+			flagForRefundCall: flagForRefundCall{
+				times: 1,
+			},
+		},
+		{
+			name: "should flag the payment for refund when the order expires while confirming it",
+			want: want{
+				err: nil,
+			},
+			reloadCall: reloadCall{
+				paymentOrder: entities.PaymentOrder{OrderId: 123, Status: entities.PaymentStatusExpired},
+			},
+			flagForRefundCall: flagForRefundCall{
+				times: 1,
+			},
+		},
+		{
+			name: "should not notify the order again when another notification of the payment confirms it first",
+			want: want{
+				err: nil,
+			},
+			reloadCall: reloadCall{
+				paymentOrder: entities.PaymentOrder{OrderId: 123, PaymentId: 111, Status: entities.PaymentStatusPaid},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		gomock.InOrder(
+			paymentRepository.EXPECT().
+				GetPaymentOrder(gomock.Eq(123)).
+				Times(1).
+				Return(entities.PaymentOrder{OrderId: 123, Status: entities.PaymentStatusPending}, nil),
+			paymentRepository.EXPECT().
+				TransitionPaymentOrderStatus(gomock.Eq(123), gomock.Eq(111), gomock.Eq(0), gomock.Eq(entities.PaymentStatusPending), gomock.Eq(entities.PaymentStatusPaid), gomock.Any()).
+				Times(1).
+				Return(gateways.ErrPaymentOrderStatusConflict),
+			paymentRepository.EXPECT().
+				GetPaymentOrder(gomock.Eq(123)).
+				Times(1).
+				Return(tt.reloadCall.paymentOrder, nil),
+		)
+
+		paymentRepository.EXPECT().
+			FlagPaymentOrderForRefund(gomock.Eq(123), gomock.Eq(111), gomock.Any()).
+			Times(tt.flagForRefundCall.times).
+			Return(nil)
+
+		orderClient.EXPECT().
+			NotifyPaymentOrder(gomock.Eq(123), gomock.Eq(entities.PaymentStatusPaid)).
+			Times(tt.orderClientCall.times).
+			Return(nil)
+
+		eventPublisher.EXPECT().Publish(gomock.Any()).AnyTimes().Return(nil)
+
+		config := PaymentUseCaseConfig{
+			PaymentRepository:      paymentRepository,
+			PaymentEventRepository: paymentEventRepository,
+			EventPublisher:         eventPublisher,
+			OrderClient:            orderClient,
+			PaymentStatusHub:       gateways.NewPaymentStatusHub(gateways.NewInMemoryPaymentStatusBroadcaster()),
+		}
+		paymentUseCase := NewPaymentUseCase(config)
+
+		err := paymentUseCase.NotifyPayment(dto.PaymentNotification{
+			OrderId:   123,
+			PaymentId: 111,
+		})
+
+		assert.Equal(t, tt.want.err, err)
+	}
+}
+
+func TestPaymentUseCase_ProcessBrokerNotification(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	paymentBroker := mock_payment.NewMockPaymentBroker(ctrl)
+	paymentRepository := mock_gateways.NewMockPaymentRepositoryGateway(ctrl)
+	paymentEventRepository := mock_gateways.NewMockPaymentEventRepositoryGateway(ctrl)
+	eventPublisher := mock_gateways.NewMockEventPublisher(ctrl)
+	orderClient := mock_gateways.NewMockOrderClient(ctrl)
+	deadLetterRepository := mock_gateways.NewMockDeadLetterRepositoryGateway(ctrl)
+
+	type args struct {
+		notification dto.BrokerNotification
+	}
+	type want struct {
+		err error
+	}
+	type getPaymentCall struct {
+		times   int
+		payment drivers.PaymentResponse
+		err     error
+	}
+	type getMerchantOrderCall struct {
+		times         int
+		merchantOrder drivers.MerchantOrderResponse
+		err           error
+	}
+	type notifyPaymentCall struct {
+		times           int
+		paymentId       int
+		merchantOrderId int
+	}
+	tests := []struct {
+		name string
+		args
+		want
+		getPaymentCall
+		getMerchantOrderCall
+		notifyPaymentCall
+	}{
+		{
+			name: "should fail to process notification when broker fails to get the payment",
+			args: args{
+				notification: dto.BrokerNotification{Topic: dto.NotificationTopicPayment, ResourceId: 7890},
+			},
+			want: want{
+				err: errors.New("failed to call mercado pago broker"),
+			},
+			getPaymentCall: getPaymentCall{
+				times: 1,
+				err:   errors.New("failed to call mercado pago broker"),
+			},
+		},
+		{
+			name: "should skip notification when the payment does not exist in the broker",
+			args: args{
+				notification: dto.BrokerNotification{Topic: dto.NotificationTopicPayment, ResourceId: 7890},
+			},
+			getPaymentCall: getPaymentCall{
+				times: 1,
+				err:   drivers.ErrPaymentNotFound,
+			},
+		},
+		{
+			name: "should skip notification when the payment is not approved",
+			args: args{
+				notification: dto.BrokerNotification{Topic: dto.NotificationTopicPayment, ResourceId: 7890},
+			},
+			getPaymentCall: getPaymentCall{
+				times:   1,
+				payment: drivers.PaymentResponse{Id: 7890, Status: "in_process", ExternalReference: "123"},
+			},
+		},
+		{
+			name: "should skip notification when the payment does not belong to an order",
+			args: args{
+				notification: dto.BrokerNotification{Topic: dto.NotificationTopicPayment, ResourceId: 7890},
+			},
+			getPaymentCall: getPaymentCall{
+				times:   1,
+				payment: drivers.PaymentResponse{Id: 7890, Status: "approved", ExternalReference: "online-store"},
+			},
+		},
+		{
+			name: "should notify payment when the payment is approved",
+			args: args{
+				notification: dto.BrokerNotification{Topic: dto.NotificationTopicPayment, ResourceId: 7890},
+			},
+			getPaymentCall: getPaymentCall{
+				times:   1,
+				payment: drivers.PaymentResponse{Id: 7890, Status: "approved", ExternalReference: "123"},
+			},
+			notifyPaymentCall: notifyPaymentCall{
+				times:     1,
+				paymentId: 7890,
+			},
+		},
+		{
+			name: "should skip notification when the merchant order has no approved payment",
+			args: args{
+				notification: dto.BrokerNotification{Topic: dto.NotificationTopicMerchantOrder, ResourceId: 456},
+			},
+			getMerchantOrderCall: getMerchantOrderCall{
+				times: 1,
+				merchantOrder: drivers.MerchantOrderResponse{
+					Id:                456,
+					ExternalReference: "123",
+					Payments:          []drivers.PaymentResponse{{Id: 7889, Status: "rejected"}},
+				},
+			},
+		},
+		{
+			name: "should notify payment when the merchant order has an approved payment",
+			args: args{
+				notification: dto.BrokerNotification{Topic: dto.NotificationTopicMerchantOrder, ResourceId: 456},
+			},
+			getMerchantOrderCall: getMerchantOrderCall{
+				times: 1,
+				merchantOrder: drivers.MerchantOrderResponse{
+					Id:                456,
+					ExternalReference: "123",
+					Payments: []drivers.PaymentResponse{
+						{Id: 7889, Status: "rejected"},
+						{Id: 7890, Status: "approved"},
+					},
+				},
+			},
+			notifyPaymentCall: notifyPaymentCall{
+				times:           1,
+				paymentId:       7890,
+				merchantOrderId: 456,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		paymentBroker.EXPECT().
+			GetPayment(gomock.Eq(tt.args.notification.ResourceId)).
+			Times(tt.getPaymentCall.times).
+			Return(tt.getPaymentCall.payment, tt.getPaymentCall.err)
+
+		paymentBroker.EXPECT().
+			GetMerchantOrder(gomock.Eq(tt.args.notification.ResourceId)).
+			Times(tt.getMerchantOrderCall.times).
+			Return(tt.getMerchantOrderCall.merchantOrder, tt.getMerchantOrderCall.err)
+
+		paymentRepository.EXPECT().
+			GetPaymentOrder(gomock.Eq(123)).
+			Times(tt.notifyPaymentCall.times).
+			Return(entities.PaymentOrder{OrderId: 123, Status: entities.PaymentStatusPending}, nil)
+
+		paymentRepository.EXPECT().
+			TransitionPaymentOrderStatus(gomock.Eq(123), gomock.Eq(tt.notifyPaymentCall.paymentId), gomock.Eq(tt.notifyPaymentCall.merchantOrderId), gomock.Eq(entities.PaymentStatusPending), gomock.Eq(entities.PaymentStatusPaid), gomock.Any()).
+			Times(tt.notifyPaymentCall.times).
+			Return(nil)
+
+		orderClient.EXPECT().
+			NotifyPaymentOrder(gomock.Eq(123), gomock.Eq(entities.PaymentStatusPaid)).
+			Times(tt.notifyPaymentCall.times).
+			Return(nil)
+
+		deadLetterRepository.EXPECT().SaveDeadLetter(gomock.Any()).AnyTimes().Return(nil)
+
+		eventPublisher.EXPECT().Publish(gomock.Any()).AnyTimes().Return(nil)
+
+		config := PaymentUseCaseConfig{
+			PaymentBroker:          paymentBroker,
+			PaymentRepository:      paymentRepository,
+			PaymentEventRepository: paymentEventRepository,
+			EventPublisher:         eventPublisher,
+			OrderClient:            orderClient,
+			DeadLetterRepository:   deadLetterRepository,
+			PaymentStatusHub:       gateways.NewPaymentStatusHub(gateways.NewInMemoryPaymentStatusBroadcaster()),
+		}
+		paymentUseCase := NewPaymentUseCase(config)
+
+		err := paymentUseCase.ProcessBrokerNotification(tt.args.notification)
+
+		assert.Equal(t, tt.want.err, err)
+	}
+}
+
+func TestPaymentUseCase_CancelPaymentOrder(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	paymentBroker := mock_payment.NewMockPaymentBroker(ctrl)
+	paymentRepository := mock_gateways.NewMockPaymentRepositoryGateway(ctrl)
+	paymentEventRepository := mock_gateways.NewMockPaymentEventRepositoryGateway(ctrl)
+	eventPublisher := mock_gateways.NewMockEventPublisher(ctrl)
+	orderClient := mock_gateways.NewMockOrderClient(ctrl)
+	deadLetterRepository := mock_gateways.NewMockDeadLetterRepositoryGateway(ctrl)
+
+	type want struct {
+		err           error
+		statusChanged bool
+	}
+	type getPaymentOrderCall struct {
+		times        int
+		paymentOrder entities.PaymentOrder
+		err          error
+	}
+	type paymentBrokerCall struct {
+		times int
+		err   error
+	}
+	type transitionCall struct {
+		times int
+		err   error
+	}
+	type orderClientCall struct {
+		times int
+		err   error
+	}
+	tests := []struct {
+		name string
+		want
+		getPaymentOrderCall
+		paymentBrokerCall
+		transitionCall
+		orderClientCall
+	}{
+		{
+			name: "should fail to cancel payment order when it is not pending",
+			want: want{
+				err: ErrPaymentNotCancellable,
+			},
+			getPaymentOrderCall: getPaymentOrderCall{
+				times:        1,
+				paymentOrder: entities.PaymentOrder{OrderId: 123, Status: entities.PaymentStatusPaid},
+			},
+		},
+		{
+			name: "should fail to cancel payment order when payment broker returns error",
+			want: want{
+				err: errors.New("internal server error"),
+			},
+			getPaymentOrderCall: getPaymentOrderCall{
+				times:        1,
+				paymentOrder: entities.PaymentOrder{OrderId: 123, Status: entities.PaymentStatusPending},
+			},
+			paymentBrokerCall: paymentBrokerCall{
+				times: 1,
+				err:   errors.New("internal server error"),
+			},
+		},
+		{
+			name: "should fail to cancel payment order when it was paid meanwhile",
+			want: want{
+				err: gateways.ErrPaymentOrderStatusConflict,
+			},
+			getPaymentOrderCall: getPaymentOrderCall{
+				times:        1,
+				paymentOrder: entities.PaymentOrder{OrderId: 123, Status: entities.PaymentStatusPending},
+			},
+			paymentBrokerCall: paymentBrokerCall{
+				times: 1,
+			},
+			transitionCall: transitionCall{
+				times: 1,
+				err:   gateways.ErrPaymentOrderStatusConflict,
+			},
+		},
+		{
+			name: "should cancel payment order successfully",
+			want: want{
+				err:           nil,
+				statusChanged: true,
+			},
+			getPaymentOrderCall: getPaymentOrderCall{
+				times:        1,
+				paymentOrder: entities.PaymentOrder{OrderId: 123, Status: entities.PaymentStatusPending},
+			},
+			paymentBrokerCall: paymentBrokerCall{
+				times: 1,
+			},
+			transitionCall: transitionCall{
+				times: 1,
+			},
+			orderClientCall: orderClientCall{
+				times: 1,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		paymentRepository.EXPECT().
+			GetPaymentOrder(gomock.Eq(123)).
+			Times(tt.getPaymentOrderCall.times).
+			Return(tt.getPaymentOrderCall.paymentOrder, tt.getPaymentOrderCall.err)
+
+		paymentBroker.EXPECT().
+			CancelPaymentOrder(gomock.Eq(123)).
+			Times(tt.paymentBrokerCall.times).
+			Return(tt.paymentBrokerCall.err)
+
+		paymentRepository.EXPECT().
+			TransitionPaymentOrderStatus(gomock.Eq(123), gomock.Eq(0), gomock.Eq(0), gomock.Eq(entities.PaymentStatusPending), gomock.Eq(entities.PaymentStatusCancelled), gomock.Any()).
+			Times(tt.transitionCall.times).
+			Return(tt.transitionCall.err)
+
+		orderClient.EXPECT().
+			NotifyPaymentOrder(gomock.Eq(123), gomock.Eq(entities.PaymentStatusCancelled)).
+			Times(tt.orderClientCall.times).
+			Return(tt.orderClientCall.err)
+
+		deadLetterRepository.EXPECT().SaveDeadLetter(gomock.Any()).AnyTimes().Return(nil)
+
+		eventPublisher.EXPECT().Publish(gomock.Any()).AnyTimes().Return(nil)
+
+		config := PaymentUseCaseConfig{
+			PaymentBroker:          paymentBroker,
+			PaymentRepository:      paymentRepository,
+			PaymentEventRepository: paymentEventRepository,
+			EventPublisher:         eventPublisher,
+			OrderClient:            orderClient,
+			DeadLetterRepository:   deadLetterRepository,
+			PaymentStatusHub:       gateways.NewPaymentStatusHub(gateways.NewInMemoryPaymentStatusBroadcaster()),
+		}
+		paymentUseCase := NewPaymentUseCase(config)
+		changes, unsubscribe := paymentUseCase.SubscribePaymentStatus(123)
+
+		err := paymentUseCase.CancelPaymentOrder(123, "admin")
+		unsubscribe()
+
+		assert.Equal(t, tt.want.err, err)
+		change, statusChanged := <-changes
+		assert.Equal(t, tt.want.statusChanged, statusChanged)
+		if statusChanged {
+			assert.Equal(t, entities.PaymentStatusCancelled, change.Status)
+		}
+	}
+}
+
+func TestPaymentUseCase_ExpirePaymentOrders(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	paymentBroker := mock_payment.NewMockPaymentBroker(ctrl)
+	paymentRepository := mock_gateways.NewMockPaymentRepositoryGateway(ctrl)
+	paymentEventRepository := mock_gateways.NewMockPaymentEventRepositoryGateway(ctrl)
+	eventPublisher := mock_gateways.NewMockEventPublisher(ctrl)
+	orderClient := mock_gateways.NewMockOrderClient(ctrl)
+	deadLetterRepository := mock_gateways.NewMockDeadLetterRepositoryGateway(ctrl)
+
+	type want struct {
+		err error
+	}
+	type getExpiredCall struct {
+		times         int
+		paymentOrders []entities.PaymentOrder
+		err           error
+	}
+	type paymentBrokerCall struct {
+		times int
+		err   error
+	}
+	type transitionCall struct {
+		times int
+		err   error
+	}
+	type orderClientCall struct {
+		times int
+		err   error
+	}
+	tests := []struct {
+		name string
+		want
+		getExpiredCall
+		paymentBrokerCall
+		transitionCall
+		orderClientCall
+	}{
+		{
+			name: "should fail to expire payment orders when payment repository returns error",
+			want: want{
+				err: errors.New("internal server error"),
+			},
+			getExpiredCall: getExpiredCall{
+				times: 1,
+				err:   errors.New("internal server error"),
+			},
+		},
+		{
+			name: "should not notify order when payment order is no longer pending",
+			want: want{
+				err: nil,
+			},
+			getExpiredCall: getExpiredCall{
+				times:         1,
+				paymentOrders: []entities.PaymentOrder{{OrderId: 123, Status: entities.PaymentStatusPending}},
+			},
+			paymentBrokerCall: paymentBrokerCall{
+				times: 1,
+			},
+			transitionCall: transitionCall{
+				times: 1,
+				err:   gateways.ErrPaymentOrderStatusConflict,
+			},
+		},
+		{
+			name: "should expire payment order even when broker fails to cancel the qrcode",
+			want: want{
+				err: nil,
+			},
+			getExpiredCall: getExpiredCall{
+				times:         1,
+				paymentOrders: []entities.PaymentOrder{{OrderId: 123, Status: entities.PaymentStatusPending}},
+			},
+			paymentBrokerCall: paymentBrokerCall{
+				times: 1,
+				err:   errors.New("internal server error"),
+			},
+			transitionCall: transitionCall{
+				times: 1,
+			},
+			orderClientCall: orderClientCall{
+				times: 1,
+			},
+		},
+		{
+			name: "should expire payment orders successfully",
+			want: want{
+				err: nil,
+			},
+			getExpiredCall: getExpiredCall{
+				times:         1,
+				paymentOrders: []entities.PaymentOrder{{OrderId: 123, Status: entities.PaymentStatusPending}},
+			},
+			paymentBrokerCall: paymentBrokerCall{
+				times: 1,
+			},
+			transitionCall: transitionCall{
+				times: 1,
+			},
+			orderClientCall: orderClientCall{
+				times: 1,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		paymentRepository.EXPECT().
+			GetExpiredPaymentOrders(gomock.Any()).
+			Times(tt.getExpiredCall.times).
+			Return(tt.getExpiredCall.paymentOrders, tt.getExpiredCall.err)
+
+		paymentBroker.EXPECT().
+			CancelPaymentOrder(gomock.Eq(123)).
+			Times(tt.paymentBrokerCall.times).
+			Return(tt.paymentBrokerCall.err)
+
+		paymentRepository.EXPECT().
+			TransitionPaymentOrderStatus(gomock.Eq(123), gomock.Eq(0), gomock.Eq(0), gomock.Eq(entities.PaymentStatusPending), gomock.Eq(entities.PaymentStatusExpired), gomock.Any()).
+			Times(tt.transitionCall.times).
+			Return(tt.transitionCall.err)
+
+		orderClient.EXPECT().
+			NotifyPaymentOrder(gomock.Eq(123), gomock.Eq(entities.PaymentStatusExpired)).
+			Times(tt.orderClientCall.times).
+			Return(tt.orderClientCall.err)
+
+		deadLetterRepository.EXPECT().SaveDeadLetter(gomock.Any()).AnyTimes().Return(nil)
+
+		eventPublisher.EXPECT().Publish(gomock.Any()).AnyTimes().Return(nil)
+
+		config := PaymentUseCaseConfig{
+			PaymentBroker:          paymentBroker,
+			PaymentRepository:      paymentRepository,
+			PaymentEventRepository: paymentEventRepository,
+			EventPublisher:         eventPublisher,
+			OrderClient:            orderClient,
+			DeadLetterRepository:   deadLetterRepository,
+			PaymentStatusHub:       gateways.NewPaymentStatusHub(gateways.NewInMemoryPaymentStatusBroadcaster()),
+		}
+		paymentUseCase := NewPaymentUseCase(config)
+
+		err := paymentUseCase.ExpirePaymentOrders()
+
+		assert.Equal(t, tt.want.err, err)
+	}
+}
+
+func TestPaymentUseCase_ReconcilePayments(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	paymentBroker := mock_payment.NewMockPaymentBroker(ctrl)
+	paymentRepository := mock_gateways.NewMockPaymentRepositoryGateway(ctrl)
+	paymentEventRepository := mock_gateways.NewMockPaymentEventRepositoryGateway(ctrl)
+	eventPublisher := mock_gateways.NewMockEventPublisher(ctrl)
+	orderClient := mock_gateways.NewMockOrderClient(ctrl)
+	deadLetterRepository := mock_gateways.NewMockDeadLetterRepositoryGateway(ctrl)
+
+	type want struct {
+		checked    int
+		fixed      []dto.ReconciliationEntry
+		unresolved []dto.ReconciliationEntry
+		err        error
+	}
+	type getPaymentOrdersCall struct {
+		times         int
+		paymentOrders []entities.PaymentOrder
+		err           error
+	}
+	type paymentBrokerCall struct {
+		times   int
+		payment drivers.PaymentResponse
+		err     error
+	}
+	type transitionCall struct {
+		times int
+		err   error
+	}
+	type orderClientCall struct {
+		times int
+		err   error
+	}
+	tests := []struct {
+		name string
+		want
+		getPaymentOrdersCall
+		paymentBrokerCall
+		transitionCall
+		orderClientCall
+	}{
+		{
+			name: "should fail to reconcile payments when payment repository returns error",
+			want: want{
+				err: errors.New("internal server error"),
+			},
+			getPaymentOrdersCall: getPaymentOrdersCall{
+				times: 1,
+				err:   errors.New("internal server error"),
+			},
+		},
+		{
+			name: "should not fix pending payment order when broker has no payment",
+			want: want{
+				checked:    1,
+				fixed:      []dto.ReconciliationEntry{},
+				unresolved: []dto.ReconciliationEntry{},
+			},
+			getPaymentOrdersCall: getPaymentOrdersCall{
+				times:         1,
+				paymentOrders: []entities.PaymentOrder{{OrderId: 123, Status: entities.PaymentStatusPending}},
+			},
+			paymentBrokerCall: paymentBrokerCall{
+				times: 1,
+				err:   drivers.ErrPaymentNotFound,
+			},
+		},
+		{
+			name: "should report unresolved payment order when broker returns error",
+			want: want{
+				checked: 1,
+				fixed:   []dto.ReconciliationEntry{},
+				unresolved: []dto.ReconciliationEntry{
+					{OrderId: 123, LocalStatus: entities.PaymentStatusPending, Reason: "internal server error"},
+				},
+			},
+			getPaymentOrdersCall: getPaymentOrdersCall{
+				times:         1,
+				paymentOrders: []entities.PaymentOrder{{OrderId: 123, Status: entities.PaymentStatusPending}},
+			},
+			paymentBrokerCall: paymentBrokerCall{
+				times: 1,
+				err:   errors.New("internal server error"),
+			},
+		},
+		{
+			name: "should report unresolved payment order when broker status has no equivalent",
+			want: want{
+				checked: 1,
+				fixed:   []dto.ReconciliationEntry{},
+				unresolved: []dto.ReconciliationEntry{
+					{OrderId: 123, PaymentId: 111, LocalStatus: entities.PaymentStatusPending, BrokerStatus: "charged_back", Reason: "broker status [charged_back] has no payment order equivalent"},
+				},
+			},
+			getPaymentOrdersCall: getPaymentOrdersCall{
+				times:         1,
+				paymentOrders: []entities.PaymentOrder{{OrderId: 123, Status: entities.PaymentStatusPending}},
+			},
+			paymentBrokerCall: paymentBrokerCall{
+				times:   1,
+				payment: drivers.PaymentResponse{Id: 111, Status: "charged_back"},
+			},
+		},
+		{
+			name: "should fix pending payment order when broker payment is approved",
+			want: want{
+				checked: 1,
+				fixed: []dto.ReconciliationEntry{
+					{OrderId: 123, PaymentId: 111, LocalStatus: entities.PaymentStatusPending, BrokerStatus: "approved"},
+				},
+				unresolved: []dto.ReconciliationEntry{},
+			},
+			getPaymentOrdersCall: getPaymentOrdersCall{
+				times:         1,
+				paymentOrders: []entities.PaymentOrder{{OrderId: 123, Status: entities.PaymentStatusPending}},
+			},
+			paymentBrokerCall: paymentBrokerCall{
+				times:   1,
+				payment: drivers.PaymentResponse{Id: 111, Status: "approved"},
+			},
+			transitionCall: transitionCall{
+				times: 1,
+			},
+			orderClientCall: orderClientCall{
+				times: 1,
+			},
+		},
+		{
+			name: "should keep refund pending payment order while the broker has not refunded it",
+			want: want{
+				checked:    1,
+				fixed:      []dto.ReconciliationEntry{},
+				unresolved: []dto.ReconciliationEntry{},
+			},
+			getPaymentOrdersCall: getPaymentOrdersCall{
+				times:         1,
+				paymentOrders: []entities.PaymentOrder{{OrderId: 123, Status: entities.PaymentStatusRefundPending}},
+			},
+			paymentBrokerCall: paymentBrokerCall{
+				times:   1,
+				payment: drivers.PaymentResponse{Id: 111, Status: "approved"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		paymentRepository.EXPECT().
+			GetPaymentOrdersByStatus(gomock.Eq([]entities.PaymentStatus{entities.PaymentStatusPending, entities.PaymentStatusAuthorized, entities.PaymentStatusRefundPending}), gomock.Any()).
+			Times(tt.getPaymentOrdersCall.times).
+			Return(tt.getPaymentOrdersCall.paymentOrders, tt.getPaymentOrdersCall.err)
+
+		paymentBroker.EXPECT().
+			GetPaymentByExternalReference(gomock.Eq(123)).
+			Times(tt.paymentBrokerCall.times).
+			Return(tt.paymentBrokerCall.payment, tt.paymentBrokerCall.err)
+
+		paymentRepository.EXPECT().
+			TransitionPaymentOrderStatus(gomock.Eq(123), gomock.Eq(111), gomock.Eq(0), gomock.Eq(entities.PaymentStatusPending), gomock.Eq(entities.PaymentStatusPaid), gomock.Any()).
+			Times(tt.transitionCall.times).
+			Return(tt.transitionCall.err)
+
+		orderClient.EXPECT().
+			NotifyPaymentOrder(gomock.Eq(123), gomock.Eq(entities.PaymentStatusPaid)).
+			Times(tt.orderClientCall.times).
+			Return(tt.orderClientCall.err)
+
+		deadLetterRepository.EXPECT().SaveDeadLetter(gomock.Any()).AnyTimes().Return(nil)
+
+		eventPublisher.EXPECT().Publish(gomock.Any()).AnyTimes().Return(nil)
+
+		config := PaymentUseCaseConfig{
+			PaymentBroker:          paymentBroker,
+			PaymentRepository:      paymentRepository,
+			PaymentEventRepository: paymentEventRepository,
+			EventPublisher:         eventPublisher,
+			OrderClient:            orderClient,
+			DeadLetterRepository:   deadLetterRepository,
+			PaymentStatusHub:       gateways.NewPaymentStatusHub(gateways.NewInMemoryPaymentStatusBroadcaster()),
+		}
+		paymentUseCase := NewPaymentUseCase(config)
+
+		report, err := paymentUseCase.ReconcilePayments(5 * time.Minute)
+
+		assert.Equal(t, tt.want.checked, report.Checked)
+		assert.Equal(t, tt.want.fixed, report.Fixed)
+		assert.Equal(t, tt.want.unresolved, report.Unresolved)
+		assert.Equal(t, tt.want.err, err)
+	}
+}
+
+func TestPaymentUseCase_ReconcilePayments_DomainEvents(t *testing.T) {
+	tests := []struct {
+		name          string
+		paymentOrder  entities.PaymentOrder
+		brokerStatus  string
+		status        entities.PaymentStatus
+		publishTimes  int
+		publishAmount float64
+	}{
+		{
+			name:          "should announce the payment with the order total",
+			paymentOrder:  entities.PaymentOrder{OrderId: 123, TotalAmout: 35.5, Status: entities.PaymentStatusPending},
+			brokerStatus:  "approved",
+			status:        entities.PaymentStatusPaid,
+			publishTimes:  1,
+			publishAmount: 35.5,
+		},
+		{
+			name:         "should not announce again a compensation refund confirmed by the broker",
+			paymentOrder: entities.PaymentOrder{OrderId: 123, PaymentId: 111, TotalAmout: 35.5, RefundedAmount: 35.5, Status: entities.PaymentStatusRefundPending},
+			brokerStatus: "refunded",
+			status:       entities.PaymentStatusRefunded,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			paymentBroker := mock_payment.NewMockPaymentBroker(ctrl)
+			paymentRepository := mock_gateways.NewMockPaymentRepositoryGateway(ctrl)
+			eventPublisher := mock_gateways.NewMockEventPublisher(ctrl)
+			orderClient := mock_gateways.NewMockOrderClient(ctrl)
+
+			paymentRepository.EXPECT().
+				GetPaymentOrdersByStatus(gomock.Any(), gomock.Any()).
+				Return([]entities.PaymentOrder{tt.paymentOrder}, nil)
+
+			paymentBroker.EXPECT().
+				GetPaymentByExternalReference(gomock.Eq(123)).
+				Return(drivers.PaymentResponse{Id: 111, Status: tt.brokerStatus}, nil)
+
+			paymentRepository.EXPECT().
+				TransitionPaymentOrderStatus(gomock.Eq(123), gomock.Eq(111), gomock.Eq(0), gomock.Eq(tt.paymentOrder.Status), gomock.Eq(tt.status), gomock.Any()).
+				Return(nil)
+
+			orderClient.EXPECT().
+				NotifyPaymentOrder(gomock.Eq(123), gomock.Eq(tt.status)).
+				Return(nil)
+
+			eventPublisher.EXPECT().
+				Publish(gomock.Cond(func(x any) bool {
+					return x.(entities.PaymentDomainEvent).Data.Amount == tt.publishAmount
+				})).
+				Times(tt.publishTimes).
+				Return(nil)
+
+			config := PaymentUseCaseConfig{
+				PaymentBroker:     paymentBroker,
+				PaymentRepository: paymentRepository,
+				EventPublisher:    eventPublisher,
+				OrderClient:       orderClient,
+				PaymentStatusHub:  gateways.NewPaymentStatusHub(gateways.NewInMemoryPaymentStatusBroadcaster()),
+			}
+			paymentUseCase := NewPaymentUseCase(config)
+
+			report, err := paymentUseCase.ReconcilePayments(time.Minute)
+
+			assert.Nil(t, err)
+			assert.Len(t, report.Fixed, 1)
+		})
+	}
+}
+
+func TestPaymentUseCase_RefundPayment(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	paymentBroker := mock_payment.NewMockPaymentBroker(ctrl)
+	paymentRepository := mock_gateways.NewMockPaymentRepositoryGateway(ctrl)
+	paymentEventRepository := mock_gateways.NewMockPaymentEventRepositoryGateway(ctrl)
+	eventPublisher := mock_gateways.NewMockEventPublisher(ctrl)
+	orderClient := mock_gateways.NewMockOrderClient(ctrl)
+	deadLetterRepository := mock_gateways.NewMockDeadLetterRepositoryGateway(ctrl)
+
+	paidPaymentOrder := entities.PaymentOrder{
+		OrderId:    123,
+		PaymentId:  111,
+		TotalAmout: 20,
+		Status:     entities.PaymentStatusPaid,
+	}
+
+	type args struct {
+		refundRequest dto.RefundRequestDTO
+	}
+	type want struct {
+		refund dto.RefundDTO
+		err    error
+	}
+	type getPaymentOrderCall struct {
+		times        int
+		paymentOrder entities.PaymentOrder
+		err          error
+	}
+	type paymentBrokerCall struct {
+		times  int
+		amount float64
+		refund drivers.RefundResponse
+		err    error
+	}
+	type saveRefundCall struct {
+		times  int
+		status entities.PaymentStatus
+		err    error
+	}
+	type settleRefundCall struct {
+		times int
+	}
+	type releaseRefundCall struct {
+		times  int
+		status entities.PaymentStatus
+	}
+	type orderClientCall struct {
+		times  int
+		status entities.PaymentStatus
+		err    error
+	}
+	tests := []struct {
+		name string
+		args
+		want
+		getPaymentOrderCall
+		paymentBrokerCall
+		saveRefundCall
+		settleRefundCall
+		releaseRefundCall
+		orderClientCall
+	}{
+		{
+			name: "should fail to refund payment when payment order is not found",
+			want: want{
+				err: gateways.ErrPaymentOrderNotFound,
+			},
+			getPaymentOrderCall: getPaymentOrderCall{
+				times: 1,
+				err:   gateways.ErrPaymentOrderNotFound,
+			},
+		},
+		{
+			name: "should fail to refund payment when payment order is not paid",
+			want: want{
+				err: ErrPaymentNotRefundable,
+			},
+			getPaymentOrderCall: getPaymentOrderCall{
+				times:        1,
+				paymentOrder: entities.PaymentOrder{OrderId: 123, Status: entities.PaymentStatusPending},
+			},
+		},
+		{
+			name: "should fail to refund payment when amount exceeds the paid amount",
+			args: args{
+				refundRequest: dto.RefundRequestDTO{Amount: 30},
+			},
+			want: want{
+				err: ErrRefundAmountExceeded,
+			},
+			getPaymentOrderCall: getPaymentOrderCall{
+				times:        1,
+				paymentOrder: paidPaymentOrder,
+			},
+		},
+		{
+			name: "should fail to refund payment when another refund was reserved since it was read",
+			args: args{
+				refundRequest: dto.RefundRequestDTO{Amount: 5},
+			},
+			want: want{
+				err: gateways.ErrPaymentOrderStatusConflict,
+			},
+			getPaymentOrderCall: getPaymentOrderCall{
+				times:        1,
+				paymentOrder: paidPaymentOrder,
+			},
+			saveRefundCall: saveRefundCall{
+				times:  1,
+				status: entities.PaymentStatusPartiallyRefunded,
+				err:    gateways.ErrPaymentOrderStatusConflict,
+			},
+		},
+		{
+			name: "should release the reserved amount when payment broker returns error",
+			args: args{
+				refundRequest: dto.RefundRequestDTO{Amount: 5},
+			},
+			want: want{
+				err: errors.New("internal server error"),
+			},
+			getPaymentOrderCall: getPaymentOrderCall{
+				times:        1,
+				paymentOrder: paidPaymentOrder,
+			},
+			paymentBrokerCall: paymentBrokerCall{
+				times:  1,
+				amount: 5,
+				err:    errors.New("internal server error"),
+			},
+			saveRefundCall: saveRefundCall{
+				times:  1,
+				status: entities.PaymentStatusPartiallyRefunded,
+			},
+			releaseRefundCall: releaseRefundCall{
+				times:  1,
+				status: entities.PaymentStatusPaid,
+			},
+		},
+		{
+			name: "should partially refund payment",
+			args: args{
+				refundRequest: dto.RefundRequestDTO{Amount: 5},
+			},
+			want: want{
+				refund: dto.RefundDTO{
+					RefundId:       999,
+					OrderId:        123,
+					Amount:         5,
+					RefundedAmount: 5,
+					Status:         entities.PaymentStatusPartiallyRefunded,
+				},
+			},
+			getPaymentOrderCall: getPaymentOrderCall{
+				times:        1,
+				paymentOrder: paidPaymentOrder,
+			},
+			paymentBrokerCall: paymentBrokerCall{
+				times:  1,
+				amount: 5,
+				refund: drivers.RefundResponse{Id: 999, PaymentId: 111, Amount: 5, Status: "approved"},
+			},
+			saveRefundCall: saveRefundCall{
+				times:  1,
+				status: entities.PaymentStatusPartiallyRefunded,
+			},
+			settleRefundCall: settleRefundCall{
+				times: 1,
+			},
+			orderClientCall: orderClientCall{
+				times:  1,
+				status: entities.PaymentStatusPartiallyRefunded,
+			},
+		},
+		{
+			name: "should refund the remaining amount when amount is not informed",
+			want: want{
+				refund: dto.RefundDTO{
+					RefundId:       999,
+					OrderId:        123,
+					Amount:         15,
+					RefundedAmount: 20,
+					Status:         entities.PaymentStatusRefunded,
+				},
+			},
+			getPaymentOrderCall: getPaymentOrderCall{
+				times: 1,
+				paymentOrder: entities.PaymentOrder{
+					OrderId:        123,
+					PaymentId:      111,
+					TotalAmout:     20,
+					RefundedAmount: 5,
+					Status:         entities.PaymentStatusPartiallyRefunded,
+				},
+			},
+			paymentBrokerCall: paymentBrokerCall{
+				times:  1,
+				amount: 15,
+				refund: drivers.RefundResponse{Id: 999, PaymentId: 111, Amount: 15, Status: "approved"},
+			},
+			saveRefundCall: saveRefundCall{
+				times:  1,
+				status: entities.PaymentStatusRefunded,
+			},
+			settleRefundCall: settleRefundCall{
+				times: 1,
+			},
+			orderClientCall: orderClientCall{
+				times:  1,
+				status: entities.PaymentStatusRefunded,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		paymentRepository.EXPECT().
+			GetPaymentOrder(gomock.Eq(123)).
+			Times(tt.getPaymentOrderCall.times).
+			Return(tt.getPaymentOrderCall.paymentOrder, tt.getPaymentOrderCall.err)
+
+		paymentBroker.EXPECT().
+			RefundPayment(gomock.Eq(111), gomock.Eq(tt.paymentBrokerCall.amount), gomock.Eq("123-refund-1")).
+			Times(tt.paymentBrokerCall.times).
+			Return(tt.paymentBrokerCall.refund, tt.paymentBrokerCall.err)
+
+		paymentRepository.EXPECT().
+			SaveRefund(gomock.Eq(tt.getPaymentOrderCall.paymentOrder), gomock.Cond(func(x any) bool {
+				refund := x.(entities.Refund)
+				return refund.ReferenceId == "123-refund-1" && refund.Status == entities.RefundStatusPending
+			}), gomock.Eq(tt.saveRefundCall.status), gomock.Any()).
+			Times(tt.saveRefundCall.times).
+			Return(tt.saveRefundCall.err)
+
+		paymentRepository.EXPECT().
+			SettleRefund(gomock.Eq(123), gomock.Eq(0), gomock.Cond(func(x any) bool {
+				refund := x.(entities.Refund)
+				return refund.ReferenceId == "123-refund-1" && refund.RefundId == 999 && refund.Status == "approved"
+			}), gomock.Any()).
+			Times(tt.settleRefundCall.times).
+			Return(nil)
+
+		paymentRepository.EXPECT().
+			ReleaseRefund(gomock.Cond(func(x any) bool {
+				paymentOrder := x.(entities.PaymentOrder)
+				return paymentOrder.RefundedAmount == 5 && len(paymentOrder.Refunds) == 1
+			}), gomock.Eq(0), gomock.Eq(tt.releaseRefundCall.status), gomock.Any()).
+			Times(tt.releaseRefundCall.times).
+			Return(nil)
+
+		orderClient.EXPECT().
+			NotifyPaymentOrder(gomock.Eq(123), gomock.Eq(tt.orderClientCall.status)).
+			Times(tt.orderClientCall.times).
+			Return(tt.orderClientCall.err)
+
+		deadLetterRepository.EXPECT().SaveDeadLetter(gomock.Any()).AnyTimes().Return(nil)
+
+		eventPublisher.EXPECT().Publish(gomock.Any()).AnyTimes().Return(nil)
+
+		config := PaymentUseCaseConfig{
+			PaymentBroker:          paymentBroker,
+			PaymentRepository:      paymentRepository,
+			PaymentEventRepository: paymentEventRepository,
+			EventPublisher:         eventPublisher,
+			OrderClient:            orderClient,
+			DeadLetterRepository:   deadLetterRepository,
+			PaymentStatusHub:       gateways.NewPaymentStatusHub(gateways.NewInMemoryPaymentStatusBroadcaster()),
+		}
+		paymentUseCase := NewPaymentUseCase(config)
+
+		refund, err := paymentUseCase.RefundPayment(123, tt.args.refundRequest, "admin")
+
+		assert.Equal(t, tt.want.refund, refund)
+		assert.Equal(t, tt.want.err, err)
+	}
+}
+
+func TestPaymentUseCase_GetPaymentEvents(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	paymentEventRepository := mock_gateways.NewMockPaymentEventRepositoryGateway(ctrl)
+
+	createdAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	type want struct {
+		paymentEvents []dto.PaymentEventDTO
+		err           error
+	}
+	type paymentEventRepositoryCall struct {
+		times         int
+		paymentEvents []entities.PaymentEvent
+		err           error
+	}
+	tests := []struct {
+		name string
+		want
+		paymentEventRepositoryCall
+	}{
+		{
+			name: "should fail to get payment events when payment event repository returns error",
+			want: want{
+				err: errors.New("internal server error"),
+			},
+			paymentEventRepositoryCall: paymentEventRepositoryCall{
+				times: 1,
+				err:   errors.New("internal server error"),
+			},
+		},
+		{
+			name: "should get payment events",
+			want: want{
+				paymentEvents: []dto.PaymentEventDTO{
+					{
+						EventId:    "1714564800000000000-a1b2c3d4",
+						Type:       entities.PaymentEventTypeStatusChanged,
+						FromStatus: entities.PaymentStatusPending,
+						ToStatus:   entities.PaymentStatusPaid,
+						Source:     entities.PaymentEventSourceWebhook,
+						Actor:      "broker",
+						CreatedAt:  createdAt,
+					},
+				},
+			},
+			paymentEventRepositoryCall: paymentEventRepositoryCall{
+				times: 1,
+				paymentEvents: []entities.PaymentEvent{
+					{
+						OrderId:    123,
+						EventId:    "1714564800000000000-a1b2c3d4",
+						Type:       entities.PaymentEventTypeStatusChanged,
+						FromStatus: entities.PaymentStatusPending,
+						ToStatus:   entities.PaymentStatusPaid,
+						Source:     entities.PaymentEventSourceWebhook,
+						Actor:      "broker",
+						CreatedAt:  createdAt,
+					},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		paymentEventRepository.EXPECT().
+			GetPaymentEvents(gomock.Eq(123)).
+			Times(tt.paymentEventRepositoryCall.times).
+			Return(tt.paymentEventRepositoryCall.paymentEvents, tt.paymentEventRepositoryCall.err)
+
+		config := PaymentUseCaseConfig{
+			PaymentEventRepository: paymentEventRepository,
+			PaymentStatusHub:       gateways.NewPaymentStatusHub(gateways.NewInMemoryPaymentStatusBroadcaster()),
+		}
+		paymentUseCase := NewPaymentUseCase(config)
+
+		paymentEvents, err := paymentUseCase.GetPaymentEvents(123)
+
+		assert.Equal(t, tt.want.paymentEvents, paymentEvents)
+		assert.Equal(t, tt.want.err, err)
+	}
+}
+
+func createPaymentOrderDTO() dto.PaymentOrderDTO {
+	return dto.PaymentOrderDTO{
+		OrderId:     123,
+		CustomerCPF: "111222333444",
+		Items: []dto.PaymentOrderItem{
+			{
+				Quantity: 1,
+				Product: dto.OrderItemProduct{
+					Name:        "Batata frita",
+					SkuId:       "333",
+					Description: "Batata canoa",
+					Category:    "Acompanhamento",
+					Type:        "UNIT",
+					Price:       9.99,
+				},
+			},
+		},
+		TotalAmount: 9.99,
+	}
+}
+
+func TestPaymentUseCase_GetPaymentOrder(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	paymentRepository := mock_gateways.NewMockPaymentRepositoryGateway(ctrl)
+
+	createdAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	paidAt := createdAt.Add(time.Minute)
+
+	type want struct {
+		payment dto.PaymentDTO
+		err     error
+	}
+	type paymentRepositoryCall struct {
+		paymentOrder entities.PaymentOrder
+		err          error
+	}
+	tests := []struct {
+		name string
+		want
+		paymentRepositoryCall
+	}{
+		{
+			name: "should fail to get payment order when it does not exist",
+			want: want{
+				err: gateways.ErrPaymentOrderNotFound,
+			},
+			paymentRepositoryCall: paymentRepositoryCall{
+				err: gateways.ErrPaymentOrderNotFound,
+			},
+		},
+		{
+			name: "should get payment order",
+			want: want{
+				payment: dto.PaymentDTO{
+					OrderId:         123,
+					PaymentId:       456,
+					MerchantOrderId: 789,
+					Broker:          "MERCADO_PAGO",
+					InStoreOrderId:  "123",
+					CustomerCPF:     "111222333444",
+					Items: []dto.PaymentOrderItem{
+						{
+							Quantity: 1,
+							Product:  dto.OrderItemProduct{Name: "Batata frita", SkuId: "333", Category: "Acompanhamento", Type: "UNIT", Price: 9.99},
+						},
+					},
+					TotalAmount: 9.99,
+					Status:      entities.PaymentStatusPaid,
+					CreatedAt:   createdAt,
+					UpdatedAt:   paidAt,
+					ExpiresAt:   createdAt.Add(15 * time.Minute),
+					PaidAt:      &paidAt,
+				},
+			},
+			paymentRepositoryCall: paymentRepositoryCall{
+				paymentOrder: entities.PaymentOrder{
+					OrderId:         123,
+					PaymentId:       456,
+					MerchantOrderId: 789,
+					Broker:          "MERCADO_PAGO",
+					InStoreOrderId:  "123",
+					CustomerCPF:     "111222333444",
+					Items: []entities.PaymentItem{
+						{SkuId: "333", Name: "Batata frita", Category: "Acompanhamento", Type: "UNIT", Quantity: 1, UnitPrice: 9.99},
+					},
+					TotalAmout: 9.99,
+					Status:     entities.PaymentStatusPaid,
+					QRCode:     "mercadopago123456",
+					CreatedAt:  createdAt,
+					UpdatedAt:  paidAt,
+					ExpiresAt:  createdAt.Add(15 * time.Minute),
+					PaidAt:     paidAt,
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		paymentRepository.EXPECT().
+			GetPaymentOrder(gomock.Eq(123)).
+			Times(1).
+			Return(tt.paymentRepositoryCall.paymentOrder, tt.paymentRepositoryCall.err)
+
+		config := PaymentUseCaseConfig{
+			PaymentRepository: paymentRepository,
+			PaymentStatusHub:  gateways.NewPaymentStatusHub(gateways.NewInMemoryPaymentStatusBroadcaster()),
+		}
+		paymentUseCase := NewPaymentUseCase(config)
+
+		payment, err := paymentUseCase.GetPaymentOrder(123)
+
+		assert.Equal(t, tt.want.payment, payment, tt.name)
+		assert.Equal(t, tt.want.err, err, tt.name)
+	}
+}
+
+func TestPaymentUseCase_GetPaymentQRCode(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	paymentRepository := mock_gateways.NewMockPaymentRepositoryGateway(ctrl)
+
+	type want struct {
+		qrCode dto.PaymentQRCode
+		err    error
+	}
+	type paymentRepositoryCall struct {
+		paymentOrder entities.PaymentOrder
+		err          error
+	}
+	tests := []struct {
+		name string
+		want
+		paymentRepositoryCall
+	}{
+		{
+			name: "should fail to get payment qrcode when payment order does not exist",
+			want: want{
+				err: gateways.ErrPaymentOrderNotFound,
+			},
+			paymentRepositoryCall: paymentRepositoryCall{
+				err: gateways.ErrPaymentOrderNotFound,
+			},
+		},
+		{
+			name: "should fail to get payment qrcode when payment order is paid",
+			want: want{
+				err: ErrPaymentNotPayable,
+			},
+			paymentRepositoryCall: paymentRepositoryCall{
+				paymentOrder: entities.PaymentOrder{OrderId: 123, Status: entities.PaymentStatusPaid, QRCode: "mercadopago123456"},
+			},
+		},
+		{
+			name: "should get payment qrcode",
+			want: want{
+				qrCode: dto.PaymentQRCode{QRCode: "mercadopago123456"},
+			},
+			paymentRepositoryCall: paymentRepositoryCall{
+				paymentOrder: entities.PaymentOrder{OrderId: 123, Status: entities.PaymentStatusPending, QRCode: "mercadopago123456"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		paymentRepository.EXPECT().
+			GetPaymentOrder(gomock.Eq(123)).
+			Times(1).
+			Return(tt.paymentRepositoryCall.paymentOrder, tt.paymentRepositoryCall.err)
+
+		config := PaymentUseCaseConfig{
+			PaymentRepository: paymentRepository,
+			PaymentStatusHub:  gateways.NewPaymentStatusHub(gateways.NewInMemoryPaymentStatusBroadcaster()),
+		}
+		paymentUseCase := NewPaymentUseCase(config)
+
+		qrCode, err := paymentUseCase.GetPaymentQRCode(123)
+
+		assert.Equal(t, tt.want.qrCode, qrCode, tt.name)
+		assert.Equal(t, tt.want.err, err, tt.name)
+	}
+}
+
+func TestPaymentUseCase_SearchPaymentOrders(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	paymentRepository := mock_gateways.NewMockPaymentRepositoryGateway(ctrl)
+
+	createdAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	type args struct {
+		search dto.PaymentSearchDTO
+	}
+	type want struct {
+		page dto.PaymentPageDTO
+		err  error
+	}
+	type paymentRepositoryCall struct {
+		filter        entities.PaymentOrderFilter
+		limit         int
+		paymentOrders []entities.PaymentOrder
+		nextCursor    string
+		err           error
+	}
+	tests := []struct {
+		name string
+		args
+		want
+		paymentRepositoryCall
+	}{
+		{
+			name: "should fail to search payment orders when the cursor is invalid",
+			args: args{
+				search: dto.PaymentSearchDTO{Status: "PAID", Cursor: "invalid"},
+			},
+			want: want{
+				err: gateways.ErrInvalidPaymentOrderCursor,
+			},
+			paymentRepositoryCall: paymentRepositoryCall{
+				filter: entities.PaymentOrderFilter{Status: entities.PaymentStatusPaid},
+				limit:  dto.DefaultPaymentSearchLimit,
+				err:    gateways.ErrInvalidPaymentOrderCursor,
+			},
+		},
+		{
+			name: "should search payment orders",
+			args: args{
+				search: dto.PaymentSearchDTO{CustomerCPF: "111222333444", From: createdAt, MinAmount: 5, Limit: 1},
+			},
+			want: want{
+				page: dto.PaymentPageDTO{
+					Items: []dto.PaymentDTO{
+						{OrderId: 123, CustomerCPF: "111222333444", Items: []dto.PaymentOrderItem{}, TotalAmount: 9.99, Status: entities.PaymentStatusPaid, CreatedAt: createdAt},
+					},
+					NextCursor: "next",
+				},
+			},
+			paymentRepositoryCall: paymentRepositoryCall{
+				filter: entities.PaymentOrderFilter{CustomerCPF: "111222333444", CreatedFrom: createdAt, MinAmount: 5},
+				limit:  1,
+				paymentOrders: []entities.PaymentOrder{
+					{OrderId: 123, CustomerCPF: "111222333444", TotalAmout: 9.99, Status: entities.PaymentStatusPaid, CreatedAt: createdAt},
+				},
+				nextCursor: "next",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		paymentRepository.EXPECT().
+			SearchPaymentOrders(gomock.Eq(tt.paymentRepositoryCall.filter), gomock.Eq(tt.paymentRepositoryCall.limit), gomock.Eq(tt.args.search.Cursor)).
+			Times(1).
+			Return(tt.paymentRepositoryCall.paymentOrders, tt.paymentRepositoryCall.nextCursor, tt.paymentRepositoryCall.err)
+
+		config := PaymentUseCaseConfig{
+			PaymentRepository: paymentRepository,
+			PaymentStatusHub:  gateways.NewPaymentStatusHub(gateways.NewInMemoryPaymentStatusBroadcaster()),
+		}
+		paymentUseCase := NewPaymentUseCase(config)
+
+		page, err := paymentUseCase.SearchPaymentOrders(tt.args.search)
+
+		assert.Equal(t, tt.want.page, page, tt.name)
+		assert.Equal(t, tt.want.err, err, tt.name)
+	}
+}
+
+// compensationEvent matches the event recorded when the payment is refunded because the order
+// service rejected it.
+func compensationEvent(eventType entities.PaymentEventType) gomock.Matcher {
+	return gomock.Cond(func(x any) bool {
+		paymentEvent := x.(entities.PaymentEvent)
+		return paymentEvent.Type == eventType &&
+			paymentEvent.Actor == systemActor &&
+			strings.HasPrefix(paymentEvent.Reason, "order service rejected the payment")
+	})
 }
@@ -0,0 +1,105 @@
+package grpcapi
+
+import (
+	"context"
+	"net"
+	"strings"
+
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/api/middleware"
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/grpcapi/paymentpb"
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/infra/gateways"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// methodScopes are the scopes required by the methods, the same as the equivalent HTTP routes.
+// The health and reflection services are left open.
+var methodScopes = map[string]string{
+	paymentpb.PaymentService_CreatePaymentOrder_FullMethodName: middleware.ScopePaymentsWrite,
+	paymentpb.PaymentService_GetPayment_FullMethodName:         middleware.ScopePaymentsRead,
+	paymentpb.PaymentService_CancelPayment_FullMethodName:      middleware.ScopePaymentsWrite,
+	paymentpb.PaymentService_WatchPayment_FullMethodName:       middleware.ScopePaymentsRead,
+}
+
+type actorKey struct{}
+
+type authenticator struct {
+	tokenValidator gateways.TokenValidator
+}
+
+func newAuthenticator(tokenValidator gateways.TokenValidator) authenticator {
+	return authenticator{tokenValidator: tokenValidator}
+}
+
+func (a authenticator) unaryInterceptor(ctx context.Context, request any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	ctx, err := a.authenticate(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, request)
+}
+
+func (a authenticator) streamInterceptor(server any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := a.authenticate(stream.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+	return handler(server, authenticatedStream{ServerStream: stream, ctx: ctx})
+}
+
+// authenticate checks the bearer token of the authorization metadata, as the HTTP api does with
+// the Authorization header, and keeps its subject as the actor of the call.
+func (a authenticator) authenticate(ctx context.Context, method string) (context.Context, error) {
+	scope, ok := methodScopes[method]
+	if !ok {
+		return ctx, nil
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	authorization := md.Get("authorization")
+	if len(authorization) == 0 {
+		return nil, status.Error(codes.Unauthenticated, "authentication required: "+middleware.ErrMissingToken.Error())
+	}
+	scheme, token, found := strings.Cut(authorization[0], " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(token) == "" {
+		return nil, status.Error(codes.Unauthenticated, "authentication required: "+middleware.ErrMissingToken.Error())
+	}
+
+	principal, err := a.tokenValidator.ValidateToken(strings.TrimSpace(token))
+	if err != nil {
+		return nil, statusError("authentication failed", err, false)
+	}
+
+	if !principal.HasScope(scope) && !principal.HasScope(middleware.ScopeAdmin) {
+		return nil, status.Error(codes.PermissionDenied, "scope ["+scope+"] required: "+middleware.ErrInsufficientScope.Error())
+	}
+
+	return context.WithValue(ctx, actorKey{}, principal.Subject), nil
+}
+
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s authenticatedStream) Context() context.Context {
+	return s.ctx
+}
+
+// getActor returns the authenticated caller, or its address when the calls are not authenticated.
+func getActor(ctx context.Context) string {
+	if actor, ok := ctx.Value(actorKey{}).(string); ok && actor != "" {
+		return actor
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		host, _, err := net.SplitHostPort(p.Addr.String())
+		if err != nil {
+			return p.Addr.String()
+		}
+		return host
+	}
+	return ""
+}
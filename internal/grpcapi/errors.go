@@ -0,0 +1,61 @@
+package grpcapi
+
+import (
+	coreErrors "github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/errors"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// statusError converts err to a grpc status, with the same codes as the HTTP statuses of the
+// error kinds. As on the HTTP api, the text of unexpected errors is only exposed when configured.
+func statusError(message string, err error, exposeErrors bool) error {
+	code := Code(err)
+	if code == codes.Internal || code == codes.Unavailable {
+		log.Errorf("grpc call failed, %s, error: %v", message, err)
+	}
+
+	if exposeErrors || isClientError(code) {
+		message += ": " + err.Error()
+	}
+	return status.Error(code, message)
+}
+
+// Code maps the kind of err to a grpc code. Errors without a kind are unexpected and become
+// Internal.
+func Code(err error) codes.Code {
+	kind, ok := coreErrors.KindOf(err)
+	if !ok {
+		return codes.Internal
+	}
+
+	switch kind {
+	case coreErrors.KindNotFound:
+		return codes.NotFound
+	case coreErrors.KindConflict:
+		return codes.FailedPrecondition
+	case coreErrors.KindValidation:
+		return codes.InvalidArgument
+	case coreErrors.KindUpstreamRejected:
+		return codes.Internal
+	case coreErrors.KindUpstreamUnavailable:
+		return codes.Unavailable
+	case coreErrors.KindUnauthenticated:
+		return codes.Unauthenticated
+	case coreErrors.KindForbidden:
+		return codes.PermissionDenied
+	case coreErrors.KindRateLimited:
+		return codes.ResourceExhausted
+	default:
+		return codes.Internal
+	}
+}
+
+func isClientError(code codes.Code) bool {
+	switch code {
+	case codes.NotFound, codes.FailedPrecondition, codes.InvalidArgument, codes.Unauthenticated, codes.PermissionDenied, codes.ResourceExhausted:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,165 @@
+package grpcapi
+
+import (
+	"context"
+	"time"
+
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/entities"
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/usecases"
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/usecases/dto"
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/grpcapi/paymentpb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var paymentStatuses = map[entities.PaymentStatus]paymentpb.PaymentStatus{
+	entities.PaymentStatusPending:           paymentpb.PaymentStatus_PAYMENT_STATUS_PENDING,
+	entities.PaymentStatusAuthorized:        paymentpb.PaymentStatus_PAYMENT_STATUS_AUTHORIZED,
+	entities.PaymentStatusPaid:              paymentpb.PaymentStatus_PAYMENT_STATUS_PAID,
+	entities.PaymentStatusExpired:           paymentpb.PaymentStatus_PAYMENT_STATUS_EXPIRED,
+	entities.PaymentStatusCancelled:         paymentpb.PaymentStatus_PAYMENT_STATUS_CANCELLED,
+	entities.PaymentStatusPartiallyRefunded: paymentpb.PaymentStatus_PAYMENT_STATUS_PARTIALLY_REFUNDED,
+	entities.PaymentStatusRefunded:          paymentpb.PaymentStatus_PAYMENT_STATUS_REFUNDED,
+	entities.PaymentStatusRefundPending:     paymentpb.PaymentStatus_PAYMENT_STATUS_REFUND_PENDING,
+}
+
+type paymentService struct {
+	paymentpb.UnimplementedPaymentServiceServer
+
+	paymentUseCase usecases.PaymentUseCase
+	exposeErrors   bool
+	watchInterval  time.Duration
+}
+
+func newPaymentService(paymentUseCase usecases.PaymentUseCase, exposeErrors bool, watchInterval time.Duration) paymentService {
+	return paymentService{
+		paymentUseCase: paymentUseCase,
+		exposeErrors:   exposeErrors,
+		watchInterval:  watchInterval,
+	}
+}
+
+func (s paymentService) CreatePaymentOrder(ctx context.Context, request *paymentpb.CreatePaymentOrderRequest) (*paymentpb.CreatePaymentOrderResponse, error) {
+	paymentOrder := toPaymentOrderDTO(request)
+	valid, err := paymentOrder.ValidatePaymentOrder()
+	if !valid {
+		return nil, status.Error(codes.InvalidArgument, "invalid payment order: "+err.Error())
+	}
+
+	paymentQRCode, err := s.paymentUseCase.CreatePaymentOrder(paymentOrder)
+	if err != nil {
+		return nil, statusError("failed to create payment order", err, s.exposeErrors)
+	}
+
+	return &paymentpb.CreatePaymentOrderResponse{QrCode: paymentQRCode}, nil
+}
+
+func (s paymentService) GetPayment(ctx context.Context, request *paymentpb.GetPaymentRequest) (*paymentpb.Payment, error) {
+	payment, err := s.paymentUseCase.GetPaymentOrder(int(request.GetOrderId()))
+	if err != nil {
+		return nil, statusError("failed to get payment order", err, s.exposeErrors)
+	}
+
+	return toPayment(payment), nil
+}
+
+func (s paymentService) CancelPayment(ctx context.Context, request *paymentpb.CancelPaymentRequest) (*paymentpb.CancelPaymentResponse, error) {
+	err := s.paymentUseCase.CancelPaymentOrder(int(request.GetOrderId()), getActor(ctx))
+	if err != nil {
+		return nil, statusError("failed to cancel payment order", err, s.exposeErrors)
+	}
+
+	return &paymentpb.CancelPaymentResponse{}, nil
+}
+
+// WatchPayment reads the payment order as soon as its status changes, and every watch interval
+// in case a change was missed, sending it whenever its status or update time changed.
+func (s paymentService) WatchPayment(request *paymentpb.WatchPaymentRequest, stream paymentpb.PaymentService_WatchPaymentServer) error {
+	changes, unsubscribe := s.paymentUseCase.SubscribePaymentStatus(int(request.GetOrderId()))
+	defer unsubscribe()
+
+	ticker := time.NewTicker(s.watchInterval)
+	defer ticker.Stop()
+
+	var last dto.PaymentDTO
+	for {
+		payment, err := s.paymentUseCase.GetPaymentOrder(int(request.GetOrderId()))
+		if err != nil {
+			return statusError("failed to get payment order", err, s.exposeErrors)
+		}
+
+		if payment.Status != last.Status || !payment.UpdatedAt.Equal(last.UpdatedAt) {
+			err = stream.Send(toPayment(payment))
+			if err != nil {
+				return err
+			}
+			last = payment
+		}
+
+		if payment.Status.IsFinal() {
+			return nil
+		}
+
+		select {
+		case <-stream.Context().Done():
+			return status.FromContextError(stream.Context().Err()).Err()
+		case <-changes:
+		case <-ticker.C:
+		}
+	}
+}
+
+func toPaymentOrderDTO(request *paymentpb.CreatePaymentOrderRequest) dto.PaymentOrderDTO {
+	items := make([]dto.PaymentOrderItem, 0, len(request.GetItems()))
+	for _, item := range request.GetItems() {
+		items = append(items, dto.PaymentOrderItem{
+			Quantity: int(item.GetQuantity()),
+			Product: dto.OrderItemProduct{
+				Name:        item.GetProduct().GetName(),
+				SkuId:       item.GetProduct().GetSkuId(),
+				Description: item.GetProduct().GetDescription(),
+				Category:    item.GetProduct().GetCategory(),
+				Type:        item.GetProduct().GetType(),
+				Price:       item.GetProduct().GetPrice(),
+			},
+		})
+	}
+
+	return dto.PaymentOrderDTO{
+		OrderId:     int(request.GetOrderId()),
+		CustomerCPF: request.GetCustomerCpf(),
+		Items:       items,
+		TotalAmount: request.GetTotalAmount(),
+	}
+}
+
+func toPayment(payment dto.PaymentDTO) *paymentpb.Payment {
+	items := make([]*paymentpb.Item, 0, len(payment.Items))
+	for _, item := range payment.Items {
+		items = append(items, &paymentpb.Item{
+			Quantity: int32(item.Quantity),
+			Product: &paymentpb.Product{
+				Name:        item.Product.Name,
+				SkuId:       item.Product.SkuId,
+				Description: item.Product.Description,
+				Category:    item.Product.Category,
+				Type:        item.Product.Type,
+				Price:       item.Product.Price,
+			},
+		})
+	}
+
+	return &paymentpb.Payment{
+		OrderId:        int64(payment.OrderId),
+		PaymentId:      int64(payment.PaymentId),
+		CustomerCpf:    payment.CustomerCPF,
+		Items:          items,
+		TotalAmount:    payment.TotalAmount,
+		RefundedAmount: payment.RefundedAmount,
+		Status:         paymentStatuses[payment.Status],
+		CreatedAt:      timestamppb.New(payment.CreatedAt),
+		UpdatedAt:      timestamppb.New(payment.UpdatedAt),
+		ExpiresAt:      timestamppb.New(payment.ExpiresAt),
+	}
+}
@@ -0,0 +1,45 @@
+package grpcapi
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/api/middleware"
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/grpcapi/paymentpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// methodRateLimits are the rate limit policies of the methods, the same as the equivalent HTTP
+// routes. The other methods of the payment service follow the default policy, while the health
+// and reflection services are not limited.
+var methodRateLimits = map[string]string{
+	paymentpb.PaymentService_CreatePaymentOrder_FullMethodName: middleware.CreatePaymentRateLimitPolicy,
+}
+
+type rateLimiter struct {
+	rateLimiter middleware.RateLimiter
+}
+
+func newRateLimiter(limiter middleware.RateLimiter) rateLimiter {
+	return rateLimiter{rateLimiter: limiter}
+}
+
+// unaryInterceptor limits the calls by caller, sharing the limits of the HTTP api, so it follows
+// the authenticator. The calls are limited by address when they are not authenticated.
+func (r rateLimiter) unaryInterceptor(ctx context.Context, request any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if _, ok := methodScopes[info.FullMethod]; !ok {
+		return handler(ctx, request)
+	}
+
+	allowed, retryAfter := r.rateLimiter.AllowClient(methodRateLimits[info.FullMethod], getActor(ctx))
+	if !allowed {
+		_ = grpc.SetHeader(ctx, metadata.Pairs("retry-after", fmt.Sprint(int64(math.Max(1, math.Ceil(retryAfter.Seconds()))))))
+		return nil, status.Error(codes.ResourceExhausted, "too many requests: "+middleware.ErrRateLimited.Error())
+	}
+
+	return handler(ctx, request)
+}
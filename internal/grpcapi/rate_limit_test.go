@@ -0,0 +1,97 @@
+package grpcapi
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/api/middleware"
+	mock_usecases "github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/usecases/mocks"
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/grpcapi/paymentpb"
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/infra/gateways"
+	mock_gateways "github.com/IgorRamosBR/g73-techchallenge-payment/internal/infra/gateways/mocks"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestRateLimiter_UnaryInterceptor(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	paymentUseCase := mock_usecases.NewMockPaymentUseCase(ctrl)
+	limiter := mock_gateways.NewMockRateLimiter(ctrl)
+
+	policies := map[string]middleware.RateLimitPolicy{
+		middleware.CreatePaymentRateLimitPolicy: {PerClient: gateways.RateLimit{Rate: 1, Burst: 5}},
+	}
+	rateLimiter := middleware.NewRateLimiter(middleware.RateLimiterConfig{RateLimiter: limiter, Policies: policies})
+	client := paymentpb.NewPaymentServiceClient(newTestClient(t, ServerConfig{PaymentUseCase: paymentUseCase, RateLimiter: rateLimiter}))
+
+	request := &paymentpb.CreatePaymentOrderRequest{
+		OrderId:     123,
+		CustomerCpf: "111222333444",
+		Items: []*paymentpb.Item{{
+			Quantity: 1,
+			Product:  &paymentpb.Product{Name: "Lanche", SkuId: "1", Category: "Lanche", Type: "UNIT", Price: 10},
+		}},
+		TotalAmount: 10,
+	}
+
+	type allowCall struct {
+		allowed    bool
+		retryAfter time.Duration
+		err        error
+	}
+	type paymentUseCaseCall struct {
+		times int
+	}
+	tests := []struct {
+		name               string
+		allowCall          allowCall
+		paymentUseCaseCall paymentUseCaseCall
+		wantCode           codes.Code
+		wantRetryAfter     []string
+	}{
+		{
+			name:               "should create payment order within the limit",
+			allowCall:          allowCall{allowed: true},
+			paymentUseCaseCall: paymentUseCaseCall{times: 1},
+			wantCode:           codes.OK,
+		},
+		{
+			name:           "should refuse payment order over the limit",
+			allowCall:      allowCall{retryAfter: 1500 * time.Millisecond},
+			wantCode:       codes.ResourceExhausted,
+			wantRetryAfter: []string{"2"},
+		},
+		{
+			name:               "should let payment order through when the limiter fails",
+			allowCall:          allowCall{err: context.DeadlineExceeded},
+			paymentUseCaseCall: paymentUseCaseCall{times: 1},
+			wantCode:           codes.OK,
+		},
+	}
+
+	for _, tt := range tests {
+		limiter.EXPECT().
+			Allow(gomock.Cond(func(key any) bool {
+				return strings.HasPrefix(key.(string), "client:"+middleware.CreatePaymentRateLimitPolicy+":")
+			}), gomock.Eq(policies[middleware.CreatePaymentRateLimitPolicy].PerClient)).
+			Times(1).
+			Return(tt.allowCall.allowed, tt.allowCall.retryAfter, tt.allowCall.err)
+
+		paymentUseCase.EXPECT().
+			CreatePaymentOrder(gomock.Any()).
+			Times(tt.paymentUseCaseCall.times).
+			Return("qrcode", nil)
+
+		var header metadata.MD
+		_, err := client.CreatePaymentOrder(context.Background(), request, grpc.Header(&header))
+
+		assert.Equal(t, tt.wantCode, status.Code(err), tt.name)
+		assert.Equal(t, tt.wantRetryAfter, header.Get("retry-after"), tt.name)
+	}
+}
@@ -0,0 +1,60 @@
+package grpcapi
+
+import (
+	"time"
+
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/api/middleware"
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/usecases"
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/grpcapi/paymentpb"
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/infra/gateways"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+// defaultWatchInterval applies when no watch interval is configured.
+const defaultWatchInterval = time.Second
+
+type ServerConfig struct {
+	PaymentUseCase usecases.PaymentUseCase
+	ExposeErrors   bool
+	// TokenValidator authenticates the calls, which are open when it is nil.
+	TokenValidator gateways.TokenValidator
+	// RateLimiter limits the unary calls, which are not limited when it is nil.
+	RateLimiter middleware.RateLimiter
+	// WatchInterval is how often WatchPayment reads the payment order when no change is pushed.
+	WatchInterval time.Duration
+}
+
+// NewServer serves the payment service along with the grpc health service and the server
+// reflection, so tools like grpcurl can list the methods.
+func NewServer(config ServerConfig) *grpc.Server {
+	var options []grpc.ServerOption
+	if config.TokenValidator != nil {
+		authenticator := newAuthenticator(config.TokenValidator)
+		options = append(options,
+			grpc.ChainUnaryInterceptor(authenticator.unaryInterceptor),
+			grpc.ChainStreamInterceptor(authenticator.streamInterceptor),
+		)
+	}
+	// chained after the authenticator, so the calls are limited by the authenticated caller
+	if config.RateLimiter != nil {
+		options = append(options, grpc.ChainUnaryInterceptor(newRateLimiter(config.RateLimiter).unaryInterceptor))
+	}
+
+	watchInterval := config.WatchInterval
+	if watchInterval <= 0 {
+		watchInterval = defaultWatchInterval
+	}
+
+	server := grpc.NewServer(options...)
+	paymentpb.RegisterPaymentServiceServer(server, newPaymentService(config.PaymentUseCase, config.ExposeErrors, watchInterval))
+
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus(paymentpb.PaymentService_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(server, healthServer)
+
+	reflection.Register(server)
+	return server
+}
@@ -0,0 +1,277 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/entities"
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/usecases"
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/usecases/dto"
+	mock_usecases "github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/usecases/mocks"
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/grpcapi/paymentpb"
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/infra/gateways"
+	mock_gateways "github.com/IgorRamosBR/g73-techchallenge-payment/internal/infra/gateways/mocks"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// newTestClient serves the server in memory and returns a connection to it.
+func newTestClient(t *testing.T, config ServerConfig) *grpc.ClientConn {
+	listener := bufconn.Listen(1024 * 1024)
+	server := NewServer(config)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestPaymentService_CreatePaymentOrder(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	paymentUseCase := mock_usecases.NewMockPaymentUseCase(ctrl)
+	client := paymentpb.NewPaymentServiceClient(newTestClient(t, ServerConfig{PaymentUseCase: paymentUseCase}))
+
+	validRequest := &paymentpb.CreatePaymentOrderRequest{
+		OrderId:     123,
+		CustomerCpf: "111222333444",
+		Items: []*paymentpb.Item{{
+			Quantity: 1,
+			Product:  &paymentpb.Product{Name: "Lanche", SkuId: "1", Category: "Lanche", Type: "UNIT", Price: 10},
+		}},
+		TotalAmount: 10,
+	}
+
+	type paymentUseCaseCall struct {
+		times  int
+		qrCode string
+		err    error
+	}
+	tests := []struct {
+		name               string
+		request            *paymentpb.CreatePaymentOrderRequest
+		paymentUseCaseCall paymentUseCaseCall
+		wantQRCode         string
+		wantCode           codes.Code
+	}{
+		{
+			name:     "should refuse invalid payment order",
+			request:  &paymentpb.CreatePaymentOrderRequest{OrderId: 123},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:               "should fail when the payment order does not match the order",
+			request:            validRequest,
+			paymentUseCaseCall: paymentUseCaseCall{times: 1, err: usecases.ErrOrderMismatch},
+			wantCode:           codes.InvalidArgument,
+		},
+		{
+			name:               "should create payment order",
+			request:            validRequest,
+			paymentUseCaseCall: paymentUseCaseCall{times: 1, qrCode: "qrcode"},
+			wantQRCode:         "qrcode",
+			wantCode:           codes.OK,
+		},
+	}
+
+	for _, tt := range tests {
+		paymentUseCase.EXPECT().
+			CreatePaymentOrder(gomock.Any()).
+			Times(tt.paymentUseCaseCall.times).
+			Return(tt.paymentUseCaseCall.qrCode, tt.paymentUseCaseCall.err)
+
+		response, err := client.CreatePaymentOrder(context.Background(), tt.request)
+
+		assert.Equal(t, tt.wantCode, status.Code(err), tt.name)
+		assert.Equal(t, tt.wantQRCode, response.GetQrCode(), tt.name)
+	}
+}
+
+func TestPaymentService_GetPayment(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	paymentUseCase := mock_usecases.NewMockPaymentUseCase(ctrl)
+	client := paymentpb.NewPaymentServiceClient(newTestClient(t, ServerConfig{PaymentUseCase: paymentUseCase}))
+
+	type paymentUseCaseCall struct {
+		payment dto.PaymentDTO
+		err     error
+	}
+	tests := []struct {
+		name               string
+		paymentUseCaseCall paymentUseCaseCall
+		wantStatus         paymentpb.PaymentStatus
+		wantCode           codes.Code
+		wantMessage        string
+	}{
+		{
+			name:               "should return not found when the payment order does not exist",
+			paymentUseCaseCall: paymentUseCaseCall{err: gateways.ErrPaymentOrderNotFound},
+			wantCode:           codes.NotFound,
+			wantMessage:        "failed to get payment order: " + gateways.ErrPaymentOrderNotFound.Error(),
+		},
+		{
+			name:               "should hide unexpected errors",
+			paymentUseCaseCall: paymentUseCaseCall{err: errors.New("internal error")},
+			wantCode:           codes.Internal,
+			wantMessage:        "failed to get payment order",
+		},
+		{
+			name:               "should return payment order",
+			paymentUseCaseCall: paymentUseCaseCall{payment: dto.PaymentDTO{OrderId: 123, Status: entities.PaymentStatusPaid}},
+			wantStatus:         paymentpb.PaymentStatus_PAYMENT_STATUS_PAID,
+			wantCode:           codes.OK,
+		},
+	}
+
+	for _, tt := range tests {
+		paymentUseCase.EXPECT().
+			GetPaymentOrder(gomock.Eq(123)).
+			Times(1).
+			Return(tt.paymentUseCaseCall.payment, tt.paymentUseCaseCall.err)
+
+		payment, err := client.GetPayment(context.Background(), &paymentpb.GetPaymentRequest{OrderId: 123})
+
+		assert.Equal(t, tt.wantCode, status.Code(err), tt.name)
+		assert.Equal(t, tt.wantMessage, status.Convert(err).Message(), tt.name)
+		assert.Equal(t, tt.wantStatus, payment.GetStatus(), tt.name)
+	}
+}
+
+func TestPaymentService_CancelPayment(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	paymentUseCase := mock_usecases.NewMockPaymentUseCase(ctrl)
+	tokenValidator := mock_gateways.NewMockTokenValidator(ctrl)
+	client := paymentpb.NewPaymentServiceClient(newTestClient(t, ServerConfig{PaymentUseCase: paymentUseCase, TokenValidator: tokenValidator}))
+
+	type validateTokenCall struct {
+		times     int
+		principal entities.Principal
+		err       error
+	}
+	tests := []struct {
+		name              string
+		authorization     string
+		validateTokenCall validateTokenCall
+		cancelTimes       int
+		cancelErr         error
+		wantCode          codes.Code
+	}{
+		{
+			name:     "should refuse call without token",
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:              "should refuse call with invalid token",
+			authorization:     "Bearer token",
+			validateTokenCall: validateTokenCall{times: 1, err: gateways.ErrInvalidToken},
+			wantCode:          codes.Unauthenticated,
+		},
+		{
+			name:              "should refuse call when the token does not grant the scope",
+			authorization:     "Bearer token",
+			validateTokenCall: validateTokenCall{times: 1, principal: entities.Principal{Subject: "order-service", Scopes: []string{"payments:read"}}},
+			wantCode:          codes.PermissionDenied,
+		},
+		{
+			name:              "should fail when the payment order is not pending",
+			authorization:     "Bearer token",
+			validateTokenCall: validateTokenCall{times: 1, principal: entities.Principal{Subject: "order-service", Scopes: []string{"payments:write"}}},
+			cancelTimes:       1,
+			cancelErr:         usecases.ErrPaymentNotCancellable,
+			wantCode:          codes.FailedPrecondition,
+		},
+		{
+			name:              "should cancel payment order on behalf of the token subject",
+			authorization:     "Bearer token",
+			validateTokenCall: validateTokenCall{times: 1, principal: entities.Principal{Subject: "order-service", Scopes: []string{"payments:write"}}},
+			cancelTimes:       1,
+			wantCode:          codes.OK,
+		},
+	}
+
+	for _, tt := range tests {
+		tokenValidator.EXPECT().
+			ValidateToken(gomock.Eq("token")).
+			Times(tt.validateTokenCall.times).
+			Return(tt.validateTokenCall.principal, tt.validateTokenCall.err)
+		paymentUseCase.EXPECT().
+			CancelPaymentOrder(gomock.Eq(123), gomock.Eq("order-service")).
+			Times(tt.cancelTimes).
+			Return(tt.cancelErr)
+
+		ctx := context.Background()
+		if tt.authorization != "" {
+			ctx = metadata.AppendToOutgoingContext(ctx, "authorization", tt.authorization)
+		}
+		_, err := client.CancelPayment(ctx, &paymentpb.CancelPaymentRequest{OrderId: 123})
+
+		assert.Equal(t, tt.wantCode, status.Code(err), tt.name)
+	}
+}
+
+func TestPaymentService_WatchPayment(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	paymentUseCase := mock_usecases.NewMockPaymentUseCase(ctrl)
+	client := paymentpb.NewPaymentServiceClient(newTestClient(t, ServerConfig{PaymentUseCase: paymentUseCase, WatchInterval: time.Millisecond}))
+
+	createdAt := time.Now()
+	pending := dto.PaymentDTO{OrderId: 123, Status: entities.PaymentStatusPending, UpdatedAt: createdAt}
+	paid := dto.PaymentDTO{OrderId: 123, Status: entities.PaymentStatusPaid, UpdatedAt: createdAt.Add(time.Second)}
+	expired := dto.PaymentDTO{OrderId: 123, Status: entities.PaymentStatusExpired, UpdatedAt: createdAt.Add(time.Hour)}
+
+	paymentUseCase.EXPECT().
+		SubscribePaymentStatus(gomock.Eq(123)).
+		Times(1).
+		Return(make(chan entities.PaymentStatusChange), func() {})
+
+	gomock.InOrder(
+		paymentUseCase.EXPECT().GetPaymentOrder(gomock.Eq(123)).Times(2).Return(pending, nil),
+		paymentUseCase.EXPECT().GetPaymentOrder(gomock.Eq(123)).Times(1).Return(paid, nil),
+		paymentUseCase.EXPECT().GetPaymentOrder(gomock.Eq(123)).Times(1).Return(expired, nil),
+	)
+
+	stream, err := client.WatchPayment(context.Background(), &paymentpb.WatchPaymentRequest{OrderId: 123})
+	assert.Nil(t, err)
+
+	var statuses []paymentpb.PaymentStatus
+	for {
+		payment, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		assert.Nil(t, err)
+		statuses = append(statuses, payment.GetStatus())
+	}
+
+	assert.Equal(t, []paymentpb.PaymentStatus{
+		paymentpb.PaymentStatus_PAYMENT_STATUS_PENDING,
+		paymentpb.PaymentStatus_PAYMENT_STATUS_PAID,
+		paymentpb.PaymentStatus_PAYMENT_STATUS_EXPIRED,
+	}, statuses)
+}
+
+func TestNewServer_Health(t *testing.T) {
+	client := healthpb.NewHealthClient(newTestClient(t, ServerConfig{TokenValidator: mock_gateways.NewMockTokenValidator(gomock.NewController(t))}))
+
+	response, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: paymentpb.PaymentService_ServiceDesc.ServiceName})
+
+	assert.Nil(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, response.GetStatus())
+}
@@ -2,24 +2,113 @@ package dynamodb
 
 import (
 	"context"
+	"fmt"
+	"sync"
+	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 )
 
+const (
+	// batchGetMaxKeys and batchWriteMaxItems are the most a single request of each batch accepts.
+	batchGetMaxKeys    = 100
+	batchWriteMaxItems = 25
+	// batchMaxAttempts bounds how many times a batch is sent while DynamoDB leaves part of it
+	// unprocessed, waiting twice as long before each new attempt.
+	batchMaxAttempts      = 5
+	batchRetryBaseDelay   = 50 * time.Millisecond
+	transactWriteMaxItems = 100
+)
+
 type DynamoDBClient interface {
 	GetItem(tableName string, key map[string]types.AttributeValue) (map[string]types.AttributeValue, error)
 	PutItem(tableName string, item map[string]types.AttributeValue) error
+	PutItemWithCondition(tableName string, item map[string]types.AttributeValue, expr expression.Expression) error
+	DeleteItem(tableName string, key map[string]types.AttributeValue) error
+	DeleteItemWithCondition(tableName string, key map[string]types.AttributeValue, expr expression.Expression) error
 	UpdateItem(tableName string, key map[string]types.AttributeValue, expr expression.Expression) error
+	Scan(tableName string, expr expression.Expression) ([]map[string]types.AttributeValue, error)
+	ParallelScan(input ScanInput, segments int32) ([]map[string]types.AttributeValue, error)
+	Query(tableName string, expr expression.Expression) ([]map[string]types.AttributeValue, error)
+	QueryPage(input QueryInput) (Page, error)
+	ScanPage(input ScanInput) (Page, error)
+	QueryPages(input QueryInput, handlePage func(Page) bool) error
+	ScanPages(input ScanInput, handlePage func(Page) bool) error
+	BatchGetItem(tableName string, keys []map[string]types.AttributeValue) ([]map[string]types.AttributeValue, error)
+	BatchWriteItem(tableName string, puts []map[string]types.AttributeValue, deletes []map[string]types.AttributeValue) error
+	TransactWriteItems(items []TransactWriteItem) error
+}
+
+// QueryInput is a query of a table or, when IndexName is set, of one of its indexes.
+type QueryInput struct {
+	TableName  string
+	IndexName  string
+	Expression expression.Expression
+	// Descending reads the items from the greatest sort key.
+	Descending        bool
+	Limit             int32
+	ExclusiveStartKey map[string]types.AttributeValue
+}
+
+// ScanInput is a scan of a table or, when IndexName is set, of one of its indexes.
+type ScanInput struct {
+	TableName         string
+	IndexName         string
+	Expression        expression.Expression
+	Limit             int32
+	ExclusiveStartKey map[string]types.AttributeValue
+}
+
+// Page holds the items read by a single request, and the key to start the next one from, which
+// is nil once there is nothing left to read. The limit applies before the filter, so a page may
+// hold fewer items than the limit, none even, and still not be the last.
+type Page struct {
+	Items            []map[string]types.AttributeValue
+	LastEvaluatedKey map[string]types.AttributeValue
+}
+
+type TransactWriteAction int
+
+const (
+	TransactPut TransactWriteAction = iota
+	TransactUpdate
+	TransactDelete
+	TransactConditionCheck
+)
+
+// TransactWriteItem is one write of a transaction. A put writes Item, while the other actions are
+// keyed by Key; the expression holds the update of TransactUpdate and the condition of any action.
+type TransactWriteItem struct {
+	Action     TransactWriteAction
+	TableName  string
+	Item       map[string]types.AttributeValue
+	Key        map[string]types.AttributeValue
+	Expression expression.Expression
+}
+
+// dynamoDBAPI is the part of the SDK client the driver uses.
+type dynamoDBAPI interface {
+	dynamodb.QueryAPIClient
+	dynamodb.ScanAPIClient
+	GetItem(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error)
+	PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error)
+	DeleteItem(ctx context.Context, params *dynamodb.DeleteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error)
+	UpdateItem(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error)
+	BatchGetItem(ctx context.Context, params *dynamodb.BatchGetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchGetItemOutput, error)
+	BatchWriteItem(ctx context.Context, params *dynamodb.BatchWriteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchWriteItemOutput, error)
+	TransactWriteItems(ctx context.Context, params *dynamodb.TransactWriteItemsInput, optFns ...func(*dynamodb.Options)) (*dynamodb.TransactWriteItemsOutput, error)
 }
 
 type dynamoDBClient struct {
-	client *dynamodb.Client
+	client          dynamoDBAPI
+	batchRetryDelay time.Duration
 }
 
 func NewDynamoDBClient(client *dynamodb.Client) *dynamoDBClient {
-	return &dynamoDBClient{client: client}
+	return &dynamoDBClient{client: client, batchRetryDelay: batchRetryBaseDelay}
 }
 
 func (d *dynamoDBClient) PutItem(tableName string, item map[string]types.AttributeValue) error {
@@ -33,6 +122,45 @@ func (d *dynamoDBClient) PutItem(tableName string, item map[string]types.Attribu
 	return nil
 }
 
+func (d *dynamoDBClient) PutItemWithCondition(tableName string, item map[string]types.AttributeValue, expr expression.Expression) error {
+	_, err := d.client.PutItem(context.TODO(), &dynamodb.PutItemInput{
+		TableName:                 &tableName,
+		Item:                      item,
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		ConditionExpression:       expr.Condition(),
+	})
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (d *dynamoDBClient) DeleteItem(tableName string, key map[string]types.AttributeValue) error {
+	_, err := d.client.DeleteItem(context.TODO(), &dynamodb.DeleteItemInput{
+		TableName: &tableName,
+		Key:       key,
+	})
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (d *dynamoDBClient) DeleteItemWithCondition(tableName string, key map[string]types.AttributeValue, expr expression.Expression) error {
+	_, err := d.client.DeleteItem(context.TODO(), &dynamodb.DeleteItemInput{
+		TableName:                 &tableName,
+		Key:                       key,
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		ConditionExpression:       expr.Condition(),
+	})
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
 func (d *dynamoDBClient) GetItem(tableName string, key map[string]types.AttributeValue) (map[string]types.AttributeValue, error) {
 	result, err := d.client.GetItem(context.TODO(), &dynamodb.GetItemInput{
 		TableName: &tableName,
@@ -51,9 +179,322 @@ func (d *dynamoDBClient) UpdateItem(tableName string, key map[string]types.Attri
 		ExpressionAttributeNames:  expr.Names(),
 		ExpressionAttributeValues: expr.Values(),
 		UpdateExpression:          expr.Update(),
+		ConditionExpression:       expr.Condition(),
 	})
 	if err != nil {
 		return err
 	}
 	return nil
 }
+
+func (d *dynamoDBClient) Scan(tableName string, expr expression.Expression) ([]map[string]types.AttributeValue, error) {
+	var items []map[string]types.AttributeValue
+
+	err := d.ScanPages(ScanInput{TableName: tableName, Expression: expr}, func(page Page) bool {
+		items = append(items, page.Items...)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// ParallelScan reads the whole table, or index, split into segments scanned at the same time. The
+// items come back grouped by segment, and the first error stops the segments still running. Each
+// segment reads from its own start, so the exclusive start key of the input is left out.
+func (d *dynamoDBClient) ParallelScan(input ScanInput, segments int32) ([]map[string]types.AttributeValue, error) {
+	if segments < 1 {
+		return nil, fmt.Errorf("invalid number of scan segments [%d]", segments)
+	}
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+
+	segmentItems := make([][]map[string]types.AttributeValue, segments)
+	errs := make([]error, segments)
+
+	var wg sync.WaitGroup
+	for segment := int32(0); segment < segments; segment++ {
+		wg.Add(1)
+		go func(segment int32) {
+			defer wg.Done()
+
+			scanInput := toScanInput(input)
+			scanInput.ExclusiveStartKey = nil
+			scanInput.Segment = aws.Int32(segment)
+			scanInput.TotalSegments = aws.Int32(segments)
+			errs[segment] = d.paginateScan(ctx, scanInput, func(page Page) bool {
+				segmentItems[segment] = append(segmentItems[segment], page.Items...)
+				return true
+			})
+			if errs[segment] != nil {
+				cancel()
+			}
+		}(segment)
+	}
+	wg.Wait()
+
+	var items []map[string]types.AttributeValue
+	for segment := range segmentItems {
+		if errs[segment] != nil {
+			return nil, errs[segment]
+		}
+		items = append(items, segmentItems[segment]...)
+	}
+
+	return items, nil
+}
+
+func (d *dynamoDBClient) Query(tableName string, expr expression.Expression) ([]map[string]types.AttributeValue, error) {
+	var items []map[string]types.AttributeValue
+
+	err := d.QueryPages(QueryInput{TableName: tableName, Expression: expr}, func(page Page) bool {
+		items = append(items, page.Items...)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+func (d *dynamoDBClient) QueryPage(input QueryInput) (Page, error) {
+	result, err := d.client.Query(context.TODO(), toQueryInput(input))
+	if err != nil {
+		return Page{}, err
+	}
+	return Page{Items: result.Items, LastEvaluatedKey: result.LastEvaluatedKey}, nil
+}
+
+func (d *dynamoDBClient) ScanPage(input ScanInput) (Page, error) {
+	result, err := d.client.Scan(context.TODO(), toScanInput(input))
+	if err != nil {
+		return Page{}, err
+	}
+	return Page{Items: result.Items, LastEvaluatedKey: result.LastEvaluatedKey}, nil
+}
+
+// QueryPages reads the query page after page, from the exclusive start key, handing each one to
+// handlePage until there is nothing left to read or it returns false. The limit is the page size.
+func (d *dynamoDBClient) QueryPages(input QueryInput, handlePage func(Page) bool) error {
+	paginator := dynamodb.NewQueryPaginator(d.client, toQueryInput(input))
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(context.TODO())
+		if err != nil {
+			return err
+		}
+		if !handlePage(Page{Items: page.Items, LastEvaluatedKey: page.LastEvaluatedKey}) {
+			return nil
+		}
+	}
+	return nil
+}
+
+// ScanPages reads the scan like QueryPages reads a query.
+func (d *dynamoDBClient) ScanPages(input ScanInput, handlePage func(Page) bool) error {
+	return d.paginateScan(context.TODO(), toScanInput(input), handlePage)
+}
+
+func (d *dynamoDBClient) paginateScan(ctx context.Context, scanInput *dynamodb.ScanInput, handlePage func(Page) bool) error {
+	paginator := dynamodb.NewScanPaginator(d.client, scanInput)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return err
+		}
+		if !handlePage(Page{Items: page.Items, LastEvaluatedKey: page.LastEvaluatedKey}) {
+			return nil
+		}
+	}
+	return nil
+}
+
+// BatchGetItem reads the items of the keys, in requests of up to a hundred keys. The keys DynamoDB
+// leaves unprocessed are requested again, and the items come back in no particular order, without
+// the ones that do not exist.
+func (d *dynamoDBClient) BatchGetItem(tableName string, keys []map[string]types.AttributeValue) ([]map[string]types.AttributeValue, error) {
+	var items []map[string]types.AttributeValue
+
+	for start := 0; start < len(keys); start += batchGetMaxKeys {
+		pending := keys[start:batchEnd(start, batchGetMaxKeys, len(keys))]
+		for attempt := 1; len(pending) > 0; attempt++ {
+			if attempt > batchMaxAttempts {
+				return nil, fmt.Errorf("failed to read %d keys of the table [%s] after %d attempts", len(pending), tableName, batchMaxAttempts)
+			}
+			if attempt > 1 {
+				d.waitBatchRetry(attempt)
+			}
+
+			result, err := d.client.BatchGetItem(context.TODO(), &dynamodb.BatchGetItemInput{
+				RequestItems: map[string]types.KeysAndAttributes{
+					tableName: {Keys: pending},
+				},
+			})
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, result.Responses[tableName]...)
+			pending = result.UnprocessedKeys[tableName].Keys
+		}
+	}
+
+	return items, nil
+}
+
+// BatchWriteItem puts and deletes the items, in requests of up to twenty-five writes. The writes
+// DynamoDB leaves unprocessed are sent again. A batch is not a transaction: when it fails, the
+// writes already processed stay.
+func (d *dynamoDBClient) BatchWriteItem(tableName string, puts []map[string]types.AttributeValue, deletes []map[string]types.AttributeValue) error {
+	requests := make([]types.WriteRequest, 0, len(puts)+len(deletes))
+	for _, item := range puts {
+		requests = append(requests, types.WriteRequest{PutRequest: &types.PutRequest{Item: item}})
+	}
+	for _, key := range deletes {
+		requests = append(requests, types.WriteRequest{DeleteRequest: &types.DeleteRequest{Key: key}})
+	}
+
+	for start := 0; start < len(requests); start += batchWriteMaxItems {
+		pending := requests[start:batchEnd(start, batchWriteMaxItems, len(requests))]
+		for attempt := 1; len(pending) > 0; attempt++ {
+			if attempt > batchMaxAttempts {
+				return fmt.Errorf("failed to write %d items of the table [%s] after %d attempts", len(pending), tableName, batchMaxAttempts)
+			}
+			if attempt > 1 {
+				d.waitBatchRetry(attempt)
+			}
+
+			result, err := d.client.BatchWriteItem(context.TODO(), &dynamodb.BatchWriteItemInput{
+				RequestItems: map[string][]types.WriteRequest{
+					tableName: pending,
+				},
+			})
+			if err != nil {
+				return err
+			}
+			pending = result.UnprocessedItems[tableName]
+		}
+	}
+
+	return nil
+}
+
+func batchEnd(start, size, total int) int {
+	if start+size > total {
+		return total
+	}
+	return start + size
+}
+
+func (d *dynamoDBClient) waitBatchRetry(attempt int) {
+	time.Sleep(d.batchRetryDelay << (attempt - 2))
+}
+
+// TransactWriteItems writes the items all or none, so a failed condition of any of them cancels
+// every other write.
+func (d *dynamoDBClient) TransactWriteItems(items []TransactWriteItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+	if len(items) > transactWriteMaxItems {
+		return fmt.Errorf("a transaction accepts at most %d items, got %d", transactWriteMaxItems, len(items))
+	}
+
+	transactItems := make([]types.TransactWriteItem, 0, len(items))
+	for _, item := range items {
+		transactItem, err := toTransactWriteItem(item)
+		if err != nil {
+			return err
+		}
+		transactItems = append(transactItems, transactItem)
+	}
+
+	_, err := d.client.TransactWriteItems(context.TODO(), &dynamodb.TransactWriteItemsInput{
+		TransactItems: transactItems,
+	})
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func toTransactWriteItem(item TransactWriteItem) (types.TransactWriteItem, error) {
+	tableName := item.TableName
+	expr := item.Expression
+
+	switch item.Action {
+	case TransactPut:
+		return types.TransactWriteItem{Put: &types.Put{
+			TableName:                 &tableName,
+			Item:                      item.Item,
+			ExpressionAttributeNames:  expr.Names(),
+			ExpressionAttributeValues: expr.Values(),
+			ConditionExpression:       expr.Condition(),
+		}}, nil
+	case TransactUpdate:
+		return types.TransactWriteItem{Update: &types.Update{
+			TableName:                 &tableName,
+			Key:                       item.Key,
+			ExpressionAttributeNames:  expr.Names(),
+			ExpressionAttributeValues: expr.Values(),
+			UpdateExpression:          expr.Update(),
+			ConditionExpression:       expr.Condition(),
+		}}, nil
+	case TransactDelete:
+		return types.TransactWriteItem{Delete: &types.Delete{
+			TableName:                 &tableName,
+			Key:                       item.Key,
+			ExpressionAttributeNames:  expr.Names(),
+			ExpressionAttributeValues: expr.Values(),
+			ConditionExpression:       expr.Condition(),
+		}}, nil
+	case TransactConditionCheck:
+		return types.TransactWriteItem{ConditionCheck: &types.ConditionCheck{
+			TableName:                 &tableName,
+			Key:                       item.Key,
+			ExpressionAttributeNames:  expr.Names(),
+			ExpressionAttributeValues: expr.Values(),
+			ConditionExpression:       expr.Condition(),
+		}}, nil
+	default:
+		return types.TransactWriteItem{}, fmt.Errorf("invalid transaction action [%d] on the table [%s]", item.Action, tableName)
+	}
+}
+
+func toQueryInput(input QueryInput) *dynamodb.QueryInput {
+	queryInput := &dynamodb.QueryInput{
+		TableName:                 &input.TableName,
+		ExpressionAttributeNames:  input.Expression.Names(),
+		ExpressionAttributeValues: input.Expression.Values(),
+		KeyConditionExpression:    input.Expression.KeyCondition(),
+		FilterExpression:          input.Expression.Filter(),
+		ScanIndexForward:          aws.Bool(!input.Descending),
+		ExclusiveStartKey:         input.ExclusiveStartKey,
+	}
+	if input.IndexName != "" {
+		queryInput.IndexName = &input.IndexName
+	}
+	if input.Limit > 0 {
+		queryInput.Limit = &input.Limit
+	}
+	return queryInput
+}
+
+func toScanInput(input ScanInput) *dynamodb.ScanInput {
+	scanInput := &dynamodb.ScanInput{
+		TableName:                 &input.TableName,
+		ExpressionAttributeNames:  input.Expression.Names(),
+		ExpressionAttributeValues: input.Expression.Values(),
+		FilterExpression:          input.Expression.Filter(),
+		ExclusiveStartKey:         input.ExclusiveStartKey,
+	}
+	if input.IndexName != "" {
+		scanInput.IndexName = &input.IndexName
+	}
+	if input.Limit > 0 {
+		scanInput.Limit = &input.Limit
+	}
+	return scanInput
+}
@@ -0,0 +1,280 @@
+package dynamodb
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/go-playground/assert/v2"
+)
+
+const testTableName = "Payment"
+
+// fakeDynamoDB answers the batch, transaction and scan requests of the driver. It leaves the last
+// item of each batch unprocessed for as many requests as unprocessedTimes.
+type fakeDynamoDB struct {
+	dynamoDBAPI
+
+	mu               sync.Mutex
+	unprocessedTimes int
+	batchSizes       []int
+	written          []types.WriteRequest
+	scanPages        map[int32][][]map[string]types.AttributeValue
+	scanErr          error
+	transactItems    []types.TransactWriteItem
+}
+
+func (f *fakeDynamoDB) BatchGetItem(ctx context.Context, params *dynamodb.BatchGetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchGetItemOutput, error) {
+	keys := params.RequestItems[testTableName].Keys
+	f.batchSizes = append(f.batchSizes, len(keys))
+
+	output := &dynamodb.BatchGetItemOutput{Responses: map[string][]map[string]types.AttributeValue{}}
+	if f.unprocessedTimes > 0 {
+		f.unprocessedTimes--
+		output.UnprocessedKeys = map[string]types.KeysAndAttributes{
+			testTableName: {Keys: keys[len(keys)-1:]},
+		}
+		keys = keys[:len(keys)-1]
+	}
+	output.Responses[testTableName] = keys
+	return output, nil
+}
+
+func (f *fakeDynamoDB) BatchWriteItem(ctx context.Context, params *dynamodb.BatchWriteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchWriteItemOutput, error) {
+	requests := params.RequestItems[testTableName]
+	f.batchSizes = append(f.batchSizes, len(requests))
+
+	output := &dynamodb.BatchWriteItemOutput{}
+	if f.unprocessedTimes > 0 {
+		f.unprocessedTimes--
+		output.UnprocessedItems = map[string][]types.WriteRequest{
+			testTableName: requests[len(requests)-1:],
+		}
+		requests = requests[:len(requests)-1]
+	}
+	f.written = append(f.written, requests...)
+	return output, nil
+}
+
+func (f *fakeDynamoDB) TransactWriteItems(ctx context.Context, params *dynamodb.TransactWriteItemsInput, optFns ...func(*dynamodb.Options)) (*dynamodb.TransactWriteItemsOutput, error) {
+	f.transactItems = params.TransactItems
+	return &dynamodb.TransactWriteItemsOutput{}, nil
+}
+
+func (f *fakeDynamoDB) Scan(ctx context.Context, params *dynamodb.ScanInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error) {
+	if f.scanErr != nil && *params.Segment == 1 {
+		return nil, f.scanErr
+	}
+
+	page := 0
+	if params.ExclusiveStartKey != nil {
+		page, _ = strconv.Atoi(params.ExclusiveStartKey["page"].(*types.AttributeValueMemberN).Value)
+	}
+
+	f.mu.Lock()
+	pages := f.scanPages[*params.Segment]
+	f.mu.Unlock()
+
+	output := &dynamodb.ScanOutput{}
+	if len(pages) > 0 {
+		output.Items = pages[page]
+	}
+	if page+1 < len(pages) {
+		output.LastEvaluatedKey = map[string]types.AttributeValue{
+			"page": &types.AttributeValueMemberN{Value: strconv.Itoa(page + 1)},
+		}
+	}
+	return output, nil
+}
+
+func orderKeys(count int) []map[string]types.AttributeValue {
+	keys := make([]map[string]types.AttributeValue, count)
+	for i := range keys {
+		keys[i] = map[string]types.AttributeValue{
+			"OrderId": &types.AttributeValueMemberN{Value: strconv.Itoa(i + 1)},
+		}
+	}
+	return keys
+}
+
+func TestDynamoDBClient_BatchGetItem(t *testing.T) {
+	tests := []struct {
+		name               string
+		keys               int
+		unprocessedTimes   int
+		expectedBatchSizes []int
+		expectedItems      int
+		expectedErr        bool
+	}{
+		{
+			name:               "should read the keys in batches of a hundred",
+			keys:               150,
+			expectedBatchSizes: []int{100, 50},
+			expectedItems:      150,
+		},
+		{
+			name:               "should read the unprocessed keys again",
+			keys:               10,
+			unprocessedTimes:   2,
+			expectedBatchSizes: []int{10, 1, 1},
+			expectedItems:      10,
+		},
+		{
+			name:               "should fail when keys stay unprocessed",
+			keys:               10,
+			unprocessedTimes:   batchMaxAttempts,
+			expectedBatchSizes: []int{10, 1, 1, 1, 1},
+			expectedErr:        true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fake := &fakeDynamoDB{unprocessedTimes: test.unprocessedTimes}
+			client := &dynamoDBClient{client: fake}
+
+			items, err := client.BatchGetItem(testTableName, orderKeys(test.keys))
+
+			assert.Equal(t, test.expectedErr, err != nil)
+			assert.Equal(t, test.expectedBatchSizes, fake.batchSizes)
+			assert.Equal(t, test.expectedItems, len(items))
+		})
+	}
+}
+
+func TestDynamoDBClient_BatchWriteItem(t *testing.T) {
+	tests := []struct {
+		name               string
+		puts               int
+		deletes            int
+		unprocessedTimes   int
+		expectedBatchSizes []int
+		expectedWritten    int
+		expectedErr        bool
+	}{
+		{
+			name:               "should write the puts and deletes in batches of twenty-five",
+			puts:               30,
+			deletes:            10,
+			expectedBatchSizes: []int{25, 15},
+			expectedWritten:    40,
+		},
+		{
+			name:               "should write the unprocessed items again",
+			puts:               5,
+			unprocessedTimes:   1,
+			expectedBatchSizes: []int{5, 1},
+			expectedWritten:    5,
+		},
+		{
+			name:               "should fail when items stay unprocessed",
+			puts:               5,
+			unprocessedTimes:   batchMaxAttempts,
+			expectedBatchSizes: []int{5, 1, 1, 1, 1},
+			expectedWritten:    4,
+			expectedErr:        true,
+		},
+		{
+			name: "should not send a request without items",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fake := &fakeDynamoDB{unprocessedTimes: test.unprocessedTimes}
+			client := &dynamoDBClient{client: fake}
+
+			err := client.BatchWriteItem(testTableName, orderKeys(test.puts), orderKeys(test.deletes))
+
+			assert.Equal(t, test.expectedErr, err != nil)
+			assert.Equal(t, test.expectedBatchSizes, fake.batchSizes)
+			assert.Equal(t, test.expectedWritten, len(fake.written))
+		})
+	}
+}
+
+func TestDynamoDBClient_ParallelScan(t *testing.T) {
+	item := func(orderId string) map[string]types.AttributeValue {
+		return map[string]types.AttributeValue{"OrderId": &types.AttributeValueMemberN{Value: orderId}}
+	}
+
+	t.Run("should read every page of every segment", func(t *testing.T) {
+		fake := &fakeDynamoDB{scanPages: map[int32][][]map[string]types.AttributeValue{
+			0: {{item("1"), item("2")}, {item("3")}},
+			2: {{item("4")}},
+		}}
+		client := &dynamoDBClient{client: fake}
+
+		items, err := client.ParallelScan(ScanInput{TableName: testTableName}, 3)
+
+		assert.Equal(t, nil, err)
+		assert.Equal(t, []map[string]types.AttributeValue{item("1"), item("2"), item("3"), item("4")}, items)
+	})
+
+	t.Run("should fail when a segment fails", func(t *testing.T) {
+		fake := &fakeDynamoDB{scanErr: errors.New("scan failed")}
+		client := &dynamoDBClient{client: fake}
+
+		items, err := client.ParallelScan(ScanInput{TableName: testTableName}, 2)
+
+		assert.Equal(t, "scan failed", err.Error())
+		assert.Equal(t, 0, len(items))
+	})
+
+	t.Run("should fail without segments", func(t *testing.T) {
+		client := &dynamoDBClient{client: &fakeDynamoDB{}}
+
+		_, err := client.ParallelScan(ScanInput{TableName: testTableName}, 0)
+
+		assert.NotEqual(t, nil, err)
+	})
+}
+
+func TestDynamoDBClient_TransactWriteItems(t *testing.T) {
+	key := orderKeys(1)[0]
+	update, _ := expression.NewBuilder().
+		WithUpdate(expression.Set(expression.Name("Status"), expression.Value("PAID"))).
+		WithCondition(expression.Name("Status").Equal(expression.Value("PENDING"))).
+		Build()
+
+	t.Run("should write the items in a single transaction", func(t *testing.T) {
+		fake := &fakeDynamoDB{}
+		client := &dynamoDBClient{client: fake}
+
+		err := client.TransactWriteItems([]TransactWriteItem{
+			{Action: TransactPut, TableName: testTableName, Item: key},
+			{Action: TransactUpdate, TableName: testTableName, Key: key, Expression: update},
+			{Action: TransactDelete, TableName: testTableName, Key: key},
+		})
+
+		assert.Equal(t, nil, err)
+		assert.Equal(t, 3, len(fake.transactItems))
+		assert.Equal(t, key, fake.transactItems[0].Put.Item)
+		assert.Equal(t, update.Update(), fake.transactItems[1].Update.UpdateExpression)
+		assert.Equal(t, update.Condition(), fake.transactItems[1].Update.ConditionExpression)
+		assert.Equal(t, key, fake.transactItems[2].Delete.Key)
+	})
+
+	t.Run("should fail on an invalid action", func(t *testing.T) {
+		fake := &fakeDynamoDB{}
+		client := &dynamoDBClient{client: fake}
+
+		err := client.TransactWriteItems([]TransactWriteItem{{Action: TransactWriteAction(9), TableName: testTableName}})
+
+		assert.NotEqual(t, nil, err)
+		assert.Equal(t, 0, len(fake.transactItems))
+	})
+
+	t.Run("should fail over the transaction limit", func(t *testing.T) {
+		client := &dynamoDBClient{client: &fakeDynamoDB{}}
+
+		err := client.TransactWriteItems(make([]TransactWriteItem, transactWriteMaxItems+1))
+
+		assert.NotEqual(t, nil, err)
+	})
+}
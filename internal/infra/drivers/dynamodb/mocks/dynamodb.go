@@ -3,14 +3,16 @@
 //
 // Generated by this command:
 //
-//	mockgen -source=dynamodb.go -destination=mocks/dynamodb.go
+//	mockgen -source=dynamodb.go -destination=mocks/dynamodb.go -exclude_interfaces=dynamoDBAPI
 //
+
 // Package mock_dynamodb is a generated GoMock package.
 package mock_dynamodb
 
 import (
 	reflect "reflect"
 
+	dynamodb "github.com/IgorRamosBR/g73-techchallenge-payment/internal/infra/drivers/dynamodb"
 	expression "github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
 	types "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 	gomock "go.uber.org/mock/gomock"
@@ -39,6 +41,63 @@ func (m *MockDynamoDBClient) EXPECT() *MockDynamoDBClientMockRecorder {
 	return m.recorder
 }
 
+// BatchGetItem mocks base method.
+func (m *MockDynamoDBClient) BatchGetItem(tableName string, keys []map[string]types.AttributeValue) ([]map[string]types.AttributeValue, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BatchGetItem", tableName, keys)
+	ret0, _ := ret[0].([]map[string]types.AttributeValue)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BatchGetItem indicates an expected call of BatchGetItem.
+func (mr *MockDynamoDBClientMockRecorder) BatchGetItem(tableName, keys any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BatchGetItem", reflect.TypeOf((*MockDynamoDBClient)(nil).BatchGetItem), tableName, keys)
+}
+
+// BatchWriteItem mocks base method.
+func (m *MockDynamoDBClient) BatchWriteItem(tableName string, puts, deletes []map[string]types.AttributeValue) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BatchWriteItem", tableName, puts, deletes)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BatchWriteItem indicates an expected call of BatchWriteItem.
+func (mr *MockDynamoDBClientMockRecorder) BatchWriteItem(tableName, puts, deletes any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BatchWriteItem", reflect.TypeOf((*MockDynamoDBClient)(nil).BatchWriteItem), tableName, puts, deletes)
+}
+
+// DeleteItem mocks base method.
+func (m *MockDynamoDBClient) DeleteItem(tableName string, key map[string]types.AttributeValue) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteItem", tableName, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteItem indicates an expected call of DeleteItem.
+func (mr *MockDynamoDBClientMockRecorder) DeleteItem(tableName, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteItem", reflect.TypeOf((*MockDynamoDBClient)(nil).DeleteItem), tableName, key)
+}
+
+// DeleteItemWithCondition mocks base method.
+func (m *MockDynamoDBClient) DeleteItemWithCondition(tableName string, key map[string]types.AttributeValue, expr expression.Expression) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteItemWithCondition", tableName, key, expr)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteItemWithCondition indicates an expected call of DeleteItemWithCondition.
+func (mr *MockDynamoDBClientMockRecorder) DeleteItemWithCondition(tableName, key, expr any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteItemWithCondition", reflect.TypeOf((*MockDynamoDBClient)(nil).DeleteItemWithCondition), tableName, key, expr)
+}
+
 // GetItem mocks base method.
 func (m *MockDynamoDBClient) GetItem(tableName string, key map[string]types.AttributeValue) (map[string]types.AttributeValue, error) {
 	m.ctrl.T.Helper()
@@ -54,6 +113,21 @@ func (mr *MockDynamoDBClientMockRecorder) GetItem(tableName, key any) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetItem", reflect.TypeOf((*MockDynamoDBClient)(nil).GetItem), tableName, key)
 }
 
+// ParallelScan mocks base method.
+func (m *MockDynamoDBClient) ParallelScan(input dynamodb.ScanInput, segments int32) ([]map[string]types.AttributeValue, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ParallelScan", input, segments)
+	ret0, _ := ret[0].([]map[string]types.AttributeValue)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ParallelScan indicates an expected call of ParallelScan.
+func (mr *MockDynamoDBClientMockRecorder) ParallelScan(input, segments any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ParallelScan", reflect.TypeOf((*MockDynamoDBClient)(nil).ParallelScan), input, segments)
+}
+
 // PutItem mocks base method.
 func (m *MockDynamoDBClient) PutItem(tableName string, item map[string]types.AttributeValue) error {
 	m.ctrl.T.Helper()
@@ -68,6 +142,122 @@ func (mr *MockDynamoDBClientMockRecorder) PutItem(tableName, item any) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PutItem", reflect.TypeOf((*MockDynamoDBClient)(nil).PutItem), tableName, item)
 }
 
+// PutItemWithCondition mocks base method.
+func (m *MockDynamoDBClient) PutItemWithCondition(tableName string, item map[string]types.AttributeValue, expr expression.Expression) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PutItemWithCondition", tableName, item, expr)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PutItemWithCondition indicates an expected call of PutItemWithCondition.
+func (mr *MockDynamoDBClientMockRecorder) PutItemWithCondition(tableName, item, expr any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PutItemWithCondition", reflect.TypeOf((*MockDynamoDBClient)(nil).PutItemWithCondition), tableName, item, expr)
+}
+
+// Query mocks base method.
+func (m *MockDynamoDBClient) Query(tableName string, expr expression.Expression) ([]map[string]types.AttributeValue, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Query", tableName, expr)
+	ret0, _ := ret[0].([]map[string]types.AttributeValue)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Query indicates an expected call of Query.
+func (mr *MockDynamoDBClientMockRecorder) Query(tableName, expr any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Query", reflect.TypeOf((*MockDynamoDBClient)(nil).Query), tableName, expr)
+}
+
+// QueryPage mocks base method.
+func (m *MockDynamoDBClient) QueryPage(input dynamodb.QueryInput) (dynamodb.Page, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "QueryPage", input)
+	ret0, _ := ret[0].(dynamodb.Page)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// QueryPage indicates an expected call of QueryPage.
+func (mr *MockDynamoDBClientMockRecorder) QueryPage(input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryPage", reflect.TypeOf((*MockDynamoDBClient)(nil).QueryPage), input)
+}
+
+// QueryPages mocks base method.
+func (m *MockDynamoDBClient) QueryPages(input dynamodb.QueryInput, handlePage func(dynamodb.Page) bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "QueryPages", input, handlePage)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// QueryPages indicates an expected call of QueryPages.
+func (mr *MockDynamoDBClientMockRecorder) QueryPages(input, handlePage any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryPages", reflect.TypeOf((*MockDynamoDBClient)(nil).QueryPages), input, handlePage)
+}
+
+// Scan mocks base method.
+func (m *MockDynamoDBClient) Scan(tableName string, expr expression.Expression) ([]map[string]types.AttributeValue, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Scan", tableName, expr)
+	ret0, _ := ret[0].([]map[string]types.AttributeValue)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Scan indicates an expected call of Scan.
+func (mr *MockDynamoDBClientMockRecorder) Scan(tableName, expr any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Scan", reflect.TypeOf((*MockDynamoDBClient)(nil).Scan), tableName, expr)
+}
+
+// ScanPage mocks base method.
+func (m *MockDynamoDBClient) ScanPage(input dynamodb.ScanInput) (dynamodb.Page, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ScanPage", input)
+	ret0, _ := ret[0].(dynamodb.Page)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ScanPage indicates an expected call of ScanPage.
+func (mr *MockDynamoDBClientMockRecorder) ScanPage(input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScanPage", reflect.TypeOf((*MockDynamoDBClient)(nil).ScanPage), input)
+}
+
+// ScanPages mocks base method.
+func (m *MockDynamoDBClient) ScanPages(input dynamodb.ScanInput, handlePage func(dynamodb.Page) bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ScanPages", input, handlePage)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ScanPages indicates an expected call of ScanPages.
+func (mr *MockDynamoDBClientMockRecorder) ScanPages(input, handlePage any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScanPages", reflect.TypeOf((*MockDynamoDBClient)(nil).ScanPages), input, handlePage)
+}
+
+// TransactWriteItems mocks base method.
+func (m *MockDynamoDBClient) TransactWriteItems(items []dynamodb.TransactWriteItem) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TransactWriteItems", items)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TransactWriteItems indicates an expected call of TransactWriteItems.
+func (mr *MockDynamoDBClientMockRecorder) TransactWriteItems(items any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TransactWriteItems", reflect.TypeOf((*MockDynamoDBClient)(nil).TransactWriteItems), items)
+}
+
 // UpdateItem mocks base method.
 func (m *MockDynamoDBClient) UpdateItem(tableName string, key map[string]types.AttributeValue, expr expression.Expression) error {
 	m.ctrl.T.Helper()
@@ -0,0 +1,76 @@
+package dynamodbstreams
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodbstreams"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodbstreams/types"
+)
+
+type DynamoDBStreamsClient interface {
+	ListShards(streamArn string) ([]string, error)
+	// GetShardIterator returns an iterator of the shard, at or after the sequence number for the
+	// iterator types that need one.
+	GetShardIterator(streamArn string, shardId string, iteratorType types.ShardIteratorType, sequenceNumber string) (*string, error)
+	// GetRecords returns the records after the iterator and the iterator to read the next ones,
+	// which is nil once the shard is closed and fully read.
+	GetRecords(iterator string) ([]types.Record, *string, error)
+}
+
+type dynamoDBStreamsClient struct {
+	client *dynamodbstreams.Client
+}
+
+func NewDynamoDBStreamsClient(client *dynamodbstreams.Client) *dynamoDBStreamsClient {
+	return &dynamoDBStreamsClient{client: client}
+}
+
+func (d *dynamoDBStreamsClient) ListShards(streamArn string) ([]string, error) {
+	shardIds := []string{}
+	var exclusiveStartShardId *string
+	for {
+		result, err := d.client.DescribeStream(context.TODO(), &dynamodbstreams.DescribeStreamInput{
+			StreamArn:             &streamArn,
+			ExclusiveStartShardId: exclusiveStartShardId,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, shard := range result.StreamDescription.Shards {
+			shardIds = append(shardIds, *shard.ShardId)
+		}
+
+		exclusiveStartShardId = result.StreamDescription.LastEvaluatedShardId
+		if exclusiveStartShardId == nil {
+			return shardIds, nil
+		}
+	}
+}
+
+func (d *dynamoDBStreamsClient) GetShardIterator(streamArn string, shardId string, iteratorType types.ShardIteratorType, sequenceNumber string) (*string, error) {
+	input := &dynamodbstreams.GetShardIteratorInput{
+		StreamArn:         &streamArn,
+		ShardId:           &shardId,
+		ShardIteratorType: iteratorType,
+	}
+	if sequenceNumber != "" {
+		input.SequenceNumber = &sequenceNumber
+	}
+
+	result, err := d.client.GetShardIterator(context.TODO(), input)
+	if err != nil {
+		return nil, err
+	}
+	return result.ShardIterator, nil
+}
+
+func (d *dynamoDBStreamsClient) GetRecords(iterator string) ([]types.Record, *string, error) {
+	result, err := d.client.GetRecords(context.TODO(), &dynamodbstreams.GetRecordsInput{
+		ShardIterator: &iterator,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return result.Records, result.NextShardIterator, nil
+}
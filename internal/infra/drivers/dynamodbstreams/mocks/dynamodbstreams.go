@@ -0,0 +1,86 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: dynamodbstreams.go
+//
+// Generated by this command:
+//
+//	mockgen -source=dynamodbstreams.go -destination=mocks/dynamodbstreams.go
+//
+
+// Package mock_dynamodbstreams is a generated GoMock package.
+package mock_dynamodbstreams
+
+import (
+	reflect "reflect"
+
+	types "github.com/aws/aws-sdk-go-v2/service/dynamodbstreams/types"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockDynamoDBStreamsClient is a mock of DynamoDBStreamsClient interface.
+type MockDynamoDBStreamsClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockDynamoDBStreamsClientMockRecorder
+}
+
+// MockDynamoDBStreamsClientMockRecorder is the mock recorder for MockDynamoDBStreamsClient.
+type MockDynamoDBStreamsClientMockRecorder struct {
+	mock *MockDynamoDBStreamsClient
+}
+
+// NewMockDynamoDBStreamsClient creates a new mock instance.
+func NewMockDynamoDBStreamsClient(ctrl *gomock.Controller) *MockDynamoDBStreamsClient {
+	mock := &MockDynamoDBStreamsClient{ctrl: ctrl}
+	mock.recorder = &MockDynamoDBStreamsClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDynamoDBStreamsClient) EXPECT() *MockDynamoDBStreamsClientMockRecorder {
+	return m.recorder
+}
+
+// GetRecords mocks base method.
+func (m *MockDynamoDBStreamsClient) GetRecords(iterator string) ([]types.Record, *string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRecords", iterator)
+	ret0, _ := ret[0].([]types.Record)
+	ret1, _ := ret[1].(*string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetRecords indicates an expected call of GetRecords.
+func (mr *MockDynamoDBStreamsClientMockRecorder) GetRecords(iterator any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRecords", reflect.TypeOf((*MockDynamoDBStreamsClient)(nil).GetRecords), iterator)
+}
+
+// GetShardIterator mocks base method.
+func (m *MockDynamoDBStreamsClient) GetShardIterator(streamArn, shardId string, iteratorType types.ShardIteratorType, sequenceNumber string) (*string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetShardIterator", streamArn, shardId, iteratorType, sequenceNumber)
+	ret0, _ := ret[0].(*string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetShardIterator indicates an expected call of GetShardIterator.
+func (mr *MockDynamoDBStreamsClientMockRecorder) GetShardIterator(streamArn, shardId, iteratorType, sequenceNumber any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetShardIterator", reflect.TypeOf((*MockDynamoDBStreamsClient)(nil).GetShardIterator), streamArn, shardId, iteratorType, sequenceNumber)
+}
+
+// ListShards mocks base method.
+func (m *MockDynamoDBStreamsClient) ListShards(streamArn string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListShards", streamArn)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListShards indicates an expected call of ListShards.
+func (mr *MockDynamoDBStreamsClientMockRecorder) ListShards(streamArn any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListShards", reflect.TypeOf((*MockDynamoDBStreamsClient)(nil).ListShards), streamArn)
+}
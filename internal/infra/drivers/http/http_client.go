@@ -8,18 +8,29 @@ import (
 
 type HttpClient interface {
 	DoPost(url string, body []byte) (*httpClient.Response, error)
+	DoPostWithHeaders(url string, body []byte, headers map[string]string) (*httpClient.Response, error)
 	DoGet(url string) (*httpClient.Response, error)
+	DoGetWithHeaders(url string, headers map[string]string) (*httpClient.Response, error)
 	DoPut(url string, body []byte) (*httpClient.Response, error)
+	DoDelete(url string) (*httpClient.Response, error)
+	DoDeleteWithHeaders(url string, headers map[string]string) (*httpClient.Response, error)
 }
 
+// defaultTimeout applies when no timeout is configured, so no call waits forever.
+const defaultTimeout = 5 * time.Second
+
 type client struct {
 	client *httpClient.Client
 }
 
-func NewHttpClient(timeoutMs int) HttpClient {
+func NewHttpClient(timeout time.Duration) HttpClient {
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+
 	return client{
 		client: &httpClient.Client{
-			Timeout: time.Duration(timeoutMs) * time.Millisecond,
+			Timeout: timeout,
 		},
 	}
 }
@@ -28,6 +39,18 @@ func (c client) DoPost(url string, body []byte) (*httpClient.Response, error) {
 	return c.client.Post(url, "application/json", bytes.NewBuffer(body))
 }
 
+func (c client) DoPostWithHeaders(url string, body []byte, headers map[string]string) (*httpClient.Response, error) {
+	req, err := httpClient.NewRequest(httpClient.MethodPost, url, bytes.NewBuffer(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	return c.client.Do(req)
+}
+
 func (c client) DoPut(url string, body []byte) (*httpClient.Response, error) {
 	req, err := httpClient.NewRequest(httpClient.MethodPut, url, bytes.NewBuffer(body))
 	if err != nil {
@@ -37,5 +60,35 @@ func (c client) DoPut(url string, body []byte) (*httpClient.Response, error) {
 }
 
 func (c client) DoGet(url string) (*httpClient.Response, error) {
-	return httpClient.Get(url)
+	return c.client.Get(url)
+}
+
+func (c client) DoGetWithHeaders(url string, headers map[string]string) (*httpClient.Response, error) {
+	req, err := httpClient.NewRequest(httpClient.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	return c.client.Do(req)
+}
+
+func (c client) DoDelete(url string) (*httpClient.Response, error) {
+	req, err := httpClient.NewRequest(httpClient.MethodDelete, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.client.Do(req)
+}
+
+func (c client) DoDeleteWithHeaders(url string, headers map[string]string) (*httpClient.Response, error) {
+	req, err := httpClient.NewRequest(httpClient.MethodDelete, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	return c.client.Do(req)
 }
@@ -21,10 +21,25 @@ func (c mockHttpClient) DoPost(url string, body []byte) (*httpClient.Response, e
 	return &response, nil
 }
 
+func (c mockHttpClient) DoPostWithHeaders(url string, body []byte, headers map[string]string) (*httpClient.Response, error) {
+	return c.DoPost(url, body)
+}
+
 func (c mockHttpClient) DoGet(url string) (*httpClient.Response, error) {
 	return httpClient.Get(url)
 }
 
+func (c mockHttpClient) DoGetWithHeaders(url string, headers map[string]string) (*httpClient.Response, error) {
+	request, err := httpClient.NewRequest(httpClient.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range headers {
+		request.Header.Set(key, value)
+	}
+	return httpClient.DefaultClient.Do(request)
+}
+
 func (c mockHttpClient) DoPut(url string, body []byte) (*httpClient.Response, error) {
 	client := httpClient.Client{}
 	request, err := httpClient.NewRequest(httpClient.MethodPut, url, bytes.NewBuffer(body))
@@ -33,3 +48,16 @@ func (c mockHttpClient) DoPut(url string, body []byte) (*httpClient.Response, er
 	}
 	return client.Do(request)
 }
+
+func (c mockHttpClient) DoDelete(url string) (*httpClient.Response, error) {
+	response := httpClient.Response{
+		StatusCode: httpClient.StatusNoContent,
+		Body:       io.NopCloser(bytes.NewBufferString("")),
+	}
+
+	return &response, nil
+}
+
+func (c mockHttpClient) DoDeleteWithHeaders(url string, headers map[string]string) (*httpClient.Response, error) {
+	return c.DoDelete(url)
+}
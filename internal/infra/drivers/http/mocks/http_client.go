@@ -5,6 +5,7 @@
 //
 //	mockgen -source=http_client.go -destination=mocks/http_client.go
 //
+
 // Package mock_http is a generated GoMock package.
 package mock_http
 
@@ -38,6 +39,36 @@ func (m *MockHttpClient) EXPECT() *MockHttpClientMockRecorder {
 	return m.recorder
 }
 
+// DoDelete mocks base method.
+func (m *MockHttpClient) DoDelete(url string) (*http.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DoDelete", url)
+	ret0, _ := ret[0].(*http.Response)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DoDelete indicates an expected call of DoDelete.
+func (mr *MockHttpClientMockRecorder) DoDelete(url any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DoDelete", reflect.TypeOf((*MockHttpClient)(nil).DoDelete), url)
+}
+
+// DoDeleteWithHeaders mocks base method.
+func (m *MockHttpClient) DoDeleteWithHeaders(url string, headers map[string]string) (*http.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DoDeleteWithHeaders", url, headers)
+	ret0, _ := ret[0].(*http.Response)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DoDeleteWithHeaders indicates an expected call of DoDeleteWithHeaders.
+func (mr *MockHttpClientMockRecorder) DoDeleteWithHeaders(url, headers any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DoDeleteWithHeaders", reflect.TypeOf((*MockHttpClient)(nil).DoDeleteWithHeaders), url, headers)
+}
+
 // DoGet mocks base method.
 func (m *MockHttpClient) DoGet(url string) (*http.Response, error) {
 	m.ctrl.T.Helper()
@@ -53,6 +84,21 @@ func (mr *MockHttpClientMockRecorder) DoGet(url any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DoGet", reflect.TypeOf((*MockHttpClient)(nil).DoGet), url)
 }
 
+// DoGetWithHeaders mocks base method.
+func (m *MockHttpClient) DoGetWithHeaders(url string, headers map[string]string) (*http.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DoGetWithHeaders", url, headers)
+	ret0, _ := ret[0].(*http.Response)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DoGetWithHeaders indicates an expected call of DoGetWithHeaders.
+func (mr *MockHttpClientMockRecorder) DoGetWithHeaders(url, headers any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DoGetWithHeaders", reflect.TypeOf((*MockHttpClient)(nil).DoGetWithHeaders), url, headers)
+}
+
 // DoPost mocks base method.
 func (m *MockHttpClient) DoPost(url string, body []byte) (*http.Response, error) {
 	m.ctrl.T.Helper()
@@ -68,6 +114,21 @@ func (mr *MockHttpClientMockRecorder) DoPost(url, body any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DoPost", reflect.TypeOf((*MockHttpClient)(nil).DoPost), url, body)
 }
 
+// DoPostWithHeaders mocks base method.
+func (m *MockHttpClient) DoPostWithHeaders(url string, body []byte, headers map[string]string) (*http.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DoPostWithHeaders", url, body, headers)
+	ret0, _ := ret[0].(*http.Response)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DoPostWithHeaders indicates an expected call of DoPostWithHeaders.
+func (mr *MockHttpClientMockRecorder) DoPostWithHeaders(url, body, headers any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DoPostWithHeaders", reflect.TypeOf((*MockHttpClient)(nil).DoPostWithHeaders), url, body, headers)
+}
+
 // DoPut mocks base method.
 func (m *MockHttpClient) DoPut(url string, body []byte) (*http.Response, error) {
 	m.ctrl.T.Helper()
@@ -3,49 +3,75 @@ package payment
 import (
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"strconv"
+	"time"
 
+	coreErrors "github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/errors"
 	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/usecases/dto"
-	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/infra/drivers/http"
+	httpDriver "github.com/IgorRamosBR/g73-techchallenge-payment/internal/infra/drivers/http"
+)
+
+const (
+	mercadoPagoBrokerName = "MERCADO_PAGO"
+	expirationDateLayout  = "2006-01-02T15:04:05.000-07:00"
 )
 
 type mercadoPagoBroker struct {
-	httpClient      http.HttpClient
+	httpClient      httpDriver.HttpClient
 	brokerPath      string
+	storeOrderPath  string
+	apiPath         string
 	notificationUrl string
 	sponsorId       string
+	accessToken     string
 }
 
 type MercadoPagoBrokerConfig struct {
-	HttpClient      http.HttpClient
+	HttpClient      httpDriver.HttpClient
 	BrokerUrl       string
+	StoreOrderUrl   string
+	ApiUrl          string
 	NotificationUrl string
 	SponsorId       string
+	// AccessToken authenticates every call to the Mercado Pago api.
+	AccessToken string
 }
 
 func NewMercadoPagoBroker(config MercadoPagoBrokerConfig) PaymentBroker {
 	return mercadoPagoBroker{
 		httpClient:      config.HttpClient,
 		brokerPath:      config.BrokerUrl,
+		storeOrderPath:  config.StoreOrderUrl,
+		apiPath:         config.ApiUrl,
 		notificationUrl: config.NotificationUrl,
 		sponsorId:       config.SponsorId,
+		accessToken:     config.AccessToken,
 	}
 }
 
-func (b mercadoPagoBroker) GeneratePaymentQRCode(paymentOrder dto.PaymentOrderDTO) (PaymentQRCodeResponse, error) {
-	paymentRequest := b.createPaymentRequest(paymentOrder)
+func (b mercadoPagoBroker) GetName() string {
+	return mercadoPagoBrokerName
+}
+
+func (b mercadoPagoBroker) GeneratePaymentQRCode(paymentOrder dto.PaymentOrderDTO, expiresAt time.Time) (PaymentQRCodeResponse, error) {
+	paymentRequest := b.createPaymentRequest(paymentOrder, expiresAt)
 
 	reqBody, err := json.Marshal(&paymentRequest)
 	if err != nil {
 		return PaymentQRCodeResponse{}, fmt.Errorf("failed to marshal payment qrcode request, error: %v", err)
 	}
 
-	response, err := b.httpClient.DoPost(b.brokerPath, reqBody)
+	response, err := b.httpClient.DoPostWithHeaders(b.brokerPath, reqBody, b.headers())
 	if err != nil {
-		return PaymentQRCodeResponse{}, fmt.Errorf("failed to call mercado pago broker, error: %v", err)
+		return PaymentQRCodeResponse{}, coreErrors.Wrap(coreErrors.KindUpstreamUnavailable, fmt.Errorf("failed to call mercado pago broker, error: %v", err))
 	}
 	defer response.Body.Close()
 
+	if response.StatusCode > 299 || response.StatusCode < 200 {
+		return PaymentQRCodeResponse{}, coreErrors.Wrap(coreErrors.UpstreamStatusKind(response.StatusCode), fmt.Errorf("failed to generate mercado pago qrcode, status [%d] non-2xx", response.StatusCode))
+	}
+
 	var paymentQRCodeResponse PaymentQRCodeResponse
 	err = json.NewDecoder(response.Body).Decode(&paymentQRCodeResponse)
 	if err != nil {
@@ -55,7 +81,151 @@ func (b mercadoPagoBroker) GeneratePaymentQRCode(paymentOrder dto.PaymentOrderDT
 	return paymentQRCodeResponse, nil
 }
 
-func (b mercadoPagoBroker) createPaymentRequest(paymentOrder dto.PaymentOrderDTO) PaymentRequest {
+// CancelPaymentOrder removes the in-store order from the point of sale. Mercado Pago keeps a
+// single order per POS, so the order is only deleted when it still belongs to the given orderId.
+func (b mercadoPagoBroker) CancelPaymentOrder(orderId int) error {
+	response, err := b.httpClient.DoGetWithHeaders(b.storeOrderPath, b.headers())
+	if err != nil {
+		return coreErrors.Wrap(coreErrors.KindUpstreamUnavailable, fmt.Errorf("failed to call mercado pago broker, error: %v", err))
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	if response.StatusCode > 299 || response.StatusCode < 200 {
+		return coreErrors.Wrap(coreErrors.UpstreamStatusKind(response.StatusCode), fmt.Errorf("failed to get mercado pago store order, status [%d] non-2xx", response.StatusCode))
+	}
+
+	var storeOrder StoreOrderResponse
+	err = json.NewDecoder(response.Body).Decode(&storeOrder)
+	if err != nil {
+		return fmt.Errorf("failed to decode mercado pago response, error: %v", err)
+	}
+
+	if storeOrder.ExternalReference != strconv.Itoa(orderId) {
+		return nil
+	}
+
+	deleteResponse, err := b.httpClient.DoDeleteWithHeaders(b.storeOrderPath, b.headers())
+	if err != nil {
+		return coreErrors.Wrap(coreErrors.KindUpstreamUnavailable, fmt.Errorf("failed to call mercado pago broker, error: %v", err))
+	}
+	defer deleteResponse.Body.Close()
+
+	if deleteResponse.StatusCode > 299 || deleteResponse.StatusCode < 200 {
+		return coreErrors.Wrap(coreErrors.UpstreamStatusKind(deleteResponse.StatusCode), fmt.Errorf("failed to cancel mercado pago store order, status [%d] non-2xx", deleteResponse.StatusCode))
+	}
+
+	return nil
+}
+
+// GetPaymentByExternalReference returns the most recent broker payment made for the order,
+// or ErrPaymentNotFound when the order has not been paid yet.
+func (b mercadoPagoBroker) GetPaymentByExternalReference(orderId int) (PaymentResponse, error) {
+	response, err := b.httpClient.DoGetWithHeaders(fmt.Sprintf("%s/v1/payments/search?sort=date_created&criteria=desc&external_reference=%d", b.apiPath, orderId), b.headers())
+	if err != nil {
+		return PaymentResponse{}, coreErrors.Wrap(coreErrors.KindUpstreamUnavailable, fmt.Errorf("failed to call mercado pago broker, error: %v", err))
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode > 299 || response.StatusCode < 200 {
+		return PaymentResponse{}, coreErrors.Wrap(coreErrors.UpstreamStatusKind(response.StatusCode), fmt.Errorf("failed to search mercado pago payments, status [%d] non-2xx", response.StatusCode))
+	}
+
+	var paymentSearchResponse PaymentSearchResponse
+	err = json.NewDecoder(response.Body).Decode(&paymentSearchResponse)
+	if err != nil {
+		return PaymentResponse{}, fmt.Errorf("failed to decode mercado pago response, error: %v", err)
+	}
+
+	if len(paymentSearchResponse.Results) == 0 {
+		return PaymentResponse{}, ErrPaymentNotFound
+	}
+
+	return paymentSearchResponse.Results[0], nil
+}
+
+// GetPayment returns the broker payment, or ErrPaymentNotFound when it does not exist.
+func (b mercadoPagoBroker) GetPayment(paymentId int) (PaymentResponse, error) {
+	response, err := b.httpClient.DoGetWithHeaders(fmt.Sprintf("%s/v1/payments/%d", b.apiPath, paymentId), b.headers())
+	if err != nil {
+		return PaymentResponse{}, coreErrors.Wrap(coreErrors.KindUpstreamUnavailable, fmt.Errorf("failed to call mercado pago broker, error: %v", err))
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusNotFound {
+		return PaymentResponse{}, ErrPaymentNotFound
+	}
+	if response.StatusCode > 299 || response.StatusCode < 200 {
+		return PaymentResponse{}, coreErrors.Wrap(coreErrors.UpstreamStatusKind(response.StatusCode), fmt.Errorf("failed to get mercado pago payment, status [%d] non-2xx", response.StatusCode))
+	}
+
+	var paymentResponse PaymentResponse
+	err = json.NewDecoder(response.Body).Decode(&paymentResponse)
+	if err != nil {
+		return PaymentResponse{}, fmt.Errorf("failed to decode mercado pago response, error: %v", err)
+	}
+
+	return paymentResponse, nil
+}
+
+// GetMerchantOrder returns the merchant order grouping the payments of an in-store order.
+func (b mercadoPagoBroker) GetMerchantOrder(merchantOrderId int) (MerchantOrderResponse, error) {
+	response, err := b.httpClient.DoGetWithHeaders(fmt.Sprintf("%s/merchant_orders/%d", b.apiPath, merchantOrderId), b.headers())
+	if err != nil {
+		return MerchantOrderResponse{}, coreErrors.Wrap(coreErrors.KindUpstreamUnavailable, fmt.Errorf("failed to call mercado pago broker, error: %v", err))
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode > 299 || response.StatusCode < 200 {
+		return MerchantOrderResponse{}, coreErrors.Wrap(coreErrors.UpstreamStatusKind(response.StatusCode), fmt.Errorf("failed to get mercado pago merchant order, status [%d] non-2xx", response.StatusCode))
+	}
+
+	var merchantOrderResponse MerchantOrderResponse
+	err = json.NewDecoder(response.Body).Decode(&merchantOrderResponse)
+	if err != nil {
+		return MerchantOrderResponse{}, fmt.Errorf("failed to decode mercado pago response, error: %v", err)
+	}
+
+	return merchantOrderResponse, nil
+}
+
+// RefundPayment refunds the amount of the payment. Requests sent again with the same idempotency
+// key are answered with the refund already made instead of refunding twice.
+func (b mercadoPagoBroker) RefundPayment(paymentId int, amount float64, idempotencyKey string) (RefundResponse, error) {
+	reqBody, err := json.Marshal(&RefundRequest{Amount: amount})
+	if err != nil {
+		return RefundResponse{}, fmt.Errorf("failed to marshal refund request, error: %v", err)
+	}
+
+	headers := b.headers()
+	headers["X-Idempotency-Key"] = idempotencyKey
+	response, err := b.httpClient.DoPostWithHeaders(fmt.Sprintf("%s/v1/payments/%d/refunds", b.apiPath, paymentId), reqBody, headers)
+	if err != nil {
+		return RefundResponse{}, coreErrors.Wrap(coreErrors.KindUpstreamUnavailable, fmt.Errorf("failed to call mercado pago broker, error: %v", err))
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode > 299 || response.StatusCode < 200 {
+		return RefundResponse{}, coreErrors.Wrap(coreErrors.UpstreamStatusKind(response.StatusCode), fmt.Errorf("failed to refund mercado pago payment, status [%d] non-2xx", response.StatusCode))
+	}
+
+	var refundResponse RefundResponse
+	err = json.NewDecoder(response.Body).Decode(&refundResponse)
+	if err != nil {
+		return RefundResponse{}, fmt.Errorf("failed to decode mercado pago response, error: %v", err)
+	}
+
+	return refundResponse, nil
+}
+
+// headers authenticates the call with the access token of the Mercado Pago account.
+func (b mercadoPagoBroker) headers() map[string]string {
+	return map[string]string{"Authorization": "Bearer " + b.accessToken}
+}
+
+func (b mercadoPagoBroker) createPaymentRequest(paymentOrder dto.PaymentOrderDTO, expiresAt time.Time) PaymentRequest {
 	var items []PaymentItemRequest
 	for _, item := range paymentOrder.Items {
 		items = append(items, createPaymentItem(item))
@@ -64,10 +234,11 @@ func (b mercadoPagoBroker) createPaymentRequest(paymentOrder dto.PaymentOrderDTO
 	return PaymentRequest{
 		ExternalReference: strconv.FormatUint(uint64(paymentOrder.OrderId), 10),
 		Title:             fmt.Sprintf("Order %d for the Customer[%s]", paymentOrder.OrderId, paymentOrder.CustomerCPF),
-		NotificationURL:   fmt.Sprintf("%s/payment/%d/notify", b.notificationUrl, paymentOrder.OrderId),
+		NotificationURL:   fmt.Sprintf("%s/v2/webhooks/mercadopago", b.notificationUrl),
 		TotalAmount:       paymentOrder.TotalAmount,
 		Items:             items,
 		Sponsor:           b.sponsorId,
+		ExpirationDate:    expiresAt.Format(expirationDateLayout),
 	}
 }
 
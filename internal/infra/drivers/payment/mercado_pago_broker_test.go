@@ -6,7 +6,9 @@ import (
 	"net/http"
 	"strings"
 	"testing"
+	"time"
 
+	coreErrors "github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/errors"
 	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/usecases/dto"
 	mock_http "github.com/IgorRamosBR/g73-techchallenge-payment/internal/infra/drivers/http/mocks"
 	"github.com/go-playground/assert/v2"
@@ -60,7 +62,7 @@ func TestMercadoPagoBroker_GeneratePaymentQRCode(t *testing.T) {
 			},
 			want: want{
 				qrCodeResponse: PaymentQRCodeResponse{},
-				err:            errors.New("failed to call mercado pago broker, error: internal error"),
+				err:            coreErrors.Wrap(coreErrors.KindUpstreamUnavailable, errors.New("failed to call mercado pago broker, error: internal error")),
 			},
 			clientCall: clientCall{
 				brokerPath: "/mercadopago",
@@ -99,7 +101,31 @@ func TestMercadoPagoBroker_GeneratePaymentQRCode(t *testing.T) {
 				brokerPath: "/mercadopago",
 				times:      1,
 				response: &http.Response{
-					Body: io.NopCloser(strings.NewReader("<invalid json>")),
+					StatusCode: 200,
+					Body:       io.NopCloser(strings.NewReader("<invalid json>")),
+				},
+				err: nil,
+			},
+		},
+		{
+			name: "should fail to generate payment qrcode when response is non-2xx",
+			args: args{
+				paymentOrder: dto.PaymentOrderDTO{
+					OrderId:     123,
+					CustomerCPF: "111222333444",
+					TotalAmount: 9.99,
+				},
+			},
+			want: want{
+				qrCodeResponse: PaymentQRCodeResponse{},
+				err:            coreErrors.Wrap(coreErrors.KindUpstreamRejected, errors.New("failed to generate mercado pago qrcode, status [400] non-2xx")),
+			},
+			clientCall: clientCall{
+				brokerPath: "/mercadopago",
+				times:      1,
+				response: &http.Response{
+					StatusCode: 400,
+					Body:       io.NopCloser(strings.NewReader(`{"message":"invalid items"}`)),
 				},
 				err: nil,
 			},
@@ -146,21 +172,496 @@ func TestMercadoPagoBroker_GeneratePaymentQRCode(t *testing.T) {
 	}
 
 	for _, tt := range tests {
-		httpClient.EXPECT().DoPost(gomock.Eq(tt.clientCall.brokerPath), gomock.Any()).
+		httpClient.EXPECT().DoPostWithHeaders(gomock.Eq(tt.clientCall.brokerPath), gomock.Cond(func(x any) bool {
+			return strings.Contains(string(x.([]byte)), `"notification_url":"/notification/v2/webhooks/mercadopago"`)
+		}), gomock.Eq(map[string]string{"Authorization": "Bearer token"})).
 			Times(tt.clientCall.times).
 			Return(tt.clientCall.response, tt.clientCall.err)
 
 		config := MercadoPagoBrokerConfig{
 			HttpClient:      httpClient,
+			AccessToken:     "token",
 			BrokerUrl:       "/mercadopago",
 			NotificationUrl: "/notification",
 			SponsorId:       "3333",
 		}
 		mercadoPagoBroker := NewMercadoPagoBroker(config)
-		mercadoPagoResponse, err := mercadoPagoBroker.GeneratePaymentQRCode(tt.args.paymentOrder)
+		mercadoPagoResponse, err := mercadoPagoBroker.GeneratePaymentQRCode(tt.args.paymentOrder, time.Now())
 
 		assert.Equal(t, tt.want.qrCodeResponse, mercadoPagoResponse)
 		assert.Equal(t, tt.want.err, err)
 	}
 
 }
+
+func TestMercadoPagoBroker_CancelPaymentOrder(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	httpClient := mock_http.NewMockHttpClient(ctrl)
+
+	type want struct {
+		err error
+	}
+	type getCall struct {
+		times    int
+		response *http.Response
+		err      error
+	}
+	type deleteCall struct {
+		times    int
+		response *http.Response
+		err      error
+	}
+	tests := []struct {
+		name string
+		want
+		getCall
+		deleteCall
+	}{
+		{
+			name: "should fail to cancel payment order when http client returns error",
+			want: want{
+				err: coreErrors.Wrap(coreErrors.KindUpstreamUnavailable, errors.New("failed to call mercado pago broker, error: internal error")),
+			},
+			getCall: getCall{
+				times:    1,
+				response: &http.Response{},
+				err:      errors.New("internal error"),
+			},
+		},
+		{
+			name: "should not cancel payment order when there is no order in the point of sale",
+			want: want{
+				err: nil,
+			},
+			getCall: getCall{
+				times: 1,
+				response: &http.Response{
+					StatusCode: 404,
+					Body:       io.NopCloser(strings.NewReader("")),
+				},
+			},
+		},
+		{
+			name: "should not cancel payment order when the point of sale has another order",
+			want: want{
+				err: nil,
+			},
+			getCall: getCall{
+				times: 1,
+				response: &http.Response{
+					StatusCode: 200,
+					Body:       io.NopCloser(strings.NewReader(`{"external_reference":"456"}`)),
+				},
+			},
+		},
+		{
+			name: "should fail to cancel payment order when delete is non-2xx",
+			want: want{
+				err: coreErrors.Wrap(coreErrors.UpstreamStatusKind(500), errors.New("failed to cancel mercado pago store order, status [500] non-2xx")),
+			},
+			getCall: getCall{
+				times: 1,
+				response: &http.Response{
+					StatusCode: 200,
+					Body:       io.NopCloser(strings.NewReader(`{"external_reference":"123"}`)),
+				},
+			},
+			deleteCall: deleteCall{
+				times: 1,
+				response: &http.Response{
+					StatusCode: 500,
+					Body:       io.NopCloser(strings.NewReader("")),
+				},
+			},
+		},
+		{
+			name: "should cancel payment order",
+			want: want{
+				err: nil,
+			},
+			getCall: getCall{
+				times: 1,
+				response: &http.Response{
+					StatusCode: 200,
+					Body:       io.NopCloser(strings.NewReader(`{"external_reference":"123"}`)),
+				},
+			},
+			deleteCall: deleteCall{
+				times: 1,
+				response: &http.Response{
+					StatusCode: 204,
+					Body:       io.NopCloser(strings.NewReader("")),
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		httpClient.EXPECT().DoGetWithHeaders(gomock.Eq("/mercadopago/orders"), gomock.Eq(map[string]string{"Authorization": "Bearer token"})).
+			Times(tt.getCall.times).
+			Return(tt.getCall.response, tt.getCall.err)
+
+		httpClient.EXPECT().DoDeleteWithHeaders(gomock.Eq("/mercadopago/orders"), gomock.Eq(map[string]string{"Authorization": "Bearer token"})).
+			Times(tt.deleteCall.times).
+			Return(tt.deleteCall.response, tt.deleteCall.err)
+
+		config := MercadoPagoBrokerConfig{
+			HttpClient:    httpClient,
+			AccessToken:   "token",
+			BrokerUrl:     "/mercadopago",
+			StoreOrderUrl: "/mercadopago/orders",
+		}
+		mercadoPagoBroker := NewMercadoPagoBroker(config)
+		err := mercadoPagoBroker.CancelPaymentOrder(123)
+
+		assert.Equal(t, tt.want.err, err)
+	}
+}
+
+func TestMercadoPagoBroker_GetPaymentByExternalReference(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	httpClient := mock_http.NewMockHttpClient(ctrl)
+
+	type want struct {
+		payment PaymentResponse
+		err     error
+	}
+	type clientCall struct {
+		times    int
+		response *http.Response
+		err      error
+	}
+	tests := []struct {
+		name string
+		want
+		clientCall
+	}{
+		{
+			name: "should fail to get payment when http client returns error",
+			want: want{
+				err: coreErrors.Wrap(coreErrors.KindUpstreamUnavailable, errors.New("failed to call mercado pago broker, error: internal error")),
+			},
+			clientCall: clientCall{
+				times:    1,
+				response: &http.Response{},
+				err:      errors.New("internal error"),
+			},
+		},
+		{
+			name: "should fail to get payment when response is non-2xx",
+			want: want{
+				err: coreErrors.Wrap(coreErrors.UpstreamStatusKind(401), errors.New("failed to search mercado pago payments, status [401] non-2xx")),
+			},
+			clientCall: clientCall{
+				times: 1,
+				response: &http.Response{
+					StatusCode: 401,
+					Body:       io.NopCloser(strings.NewReader("")),
+				},
+			},
+		},
+		{
+			name: "should return not found when the order has no payments",
+			want: want{
+				err: ErrPaymentNotFound,
+			},
+			clientCall: clientCall{
+				times: 1,
+				response: &http.Response{
+					StatusCode: 200,
+					Body:       io.NopCloser(strings.NewReader(`{"results":[]}`)),
+				},
+			},
+		},
+		{
+			name: "should get the latest payment of the order",
+			want: want{
+				payment: PaymentResponse{
+					Id:                111,
+					Status:            "approved",
+					ExternalReference: "123",
+					TransactionAmount: 9.99,
+				},
+			},
+			clientCall: clientCall{
+				times: 1,
+				response: &http.Response{
+					StatusCode: 200,
+					Body:       io.NopCloser(strings.NewReader(`{"results":[{"id":111,"status":"approved","external_reference":"123","transaction_amount":9.99}]}`)),
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		httpClient.EXPECT().DoGetWithHeaders(gomock.Eq("/api/v1/payments/search?sort=date_created&criteria=desc&external_reference=123"), gomock.Eq(map[string]string{"Authorization": "Bearer token"})).
+			Times(tt.clientCall.times).
+			Return(tt.clientCall.response, tt.clientCall.err)
+
+		config := MercadoPagoBrokerConfig{
+			HttpClient:  httpClient,
+			AccessToken: "token",
+			ApiUrl:      "/api",
+		}
+		mercadoPagoBroker := NewMercadoPagoBroker(config)
+		payment, err := mercadoPagoBroker.GetPaymentByExternalReference(123)
+
+		assert.Equal(t, tt.want.payment, payment)
+		assert.Equal(t, tt.want.err, err)
+	}
+}
+
+func TestMercadoPagoBroker_GetPayment(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	httpClient := mock_http.NewMockHttpClient(ctrl)
+
+	type want struct {
+		payment PaymentResponse
+		err     error
+	}
+	type clientCall struct {
+		times    int
+		response *http.Response
+		err      error
+	}
+	tests := []struct {
+		name string
+		want
+		clientCall
+	}{
+		{
+			name: "should fail to get payment when http client returns error",
+			want: want{
+				err: coreErrors.Wrap(coreErrors.KindUpstreamUnavailable, errors.New("failed to call mercado pago broker, error: internal error")),
+			},
+			clientCall: clientCall{
+				times:    1,
+				response: &http.Response{},
+				err:      errors.New("internal error"),
+			},
+		},
+		{
+			name: "should return not found when the payment does not exist",
+			want: want{
+				err: ErrPaymentNotFound,
+			},
+			clientCall: clientCall{
+				times: 1,
+				response: &http.Response{
+					StatusCode: 404,
+					Body:       io.NopCloser(strings.NewReader("")),
+				},
+			},
+		},
+		{
+			name: "should fail to get payment when response is non-2xx",
+			want: want{
+				err: coreErrors.Wrap(coreErrors.UpstreamStatusKind(401), errors.New("failed to get mercado pago payment, status [401] non-2xx")),
+			},
+			clientCall: clientCall{
+				times: 1,
+				response: &http.Response{
+					StatusCode: 401,
+					Body:       io.NopCloser(strings.NewReader("")),
+				},
+			},
+		},
+		{
+			name: "should get the payment",
+			want: want{
+				payment: PaymentResponse{
+					Id:                7890,
+					Status:            "approved",
+					ExternalReference: "123",
+					TransactionAmount: 9.99,
+				},
+			},
+			clientCall: clientCall{
+				times: 1,
+				response: &http.Response{
+					StatusCode: 200,
+					Body:       io.NopCloser(strings.NewReader(`{"id":7890,"status":"approved","external_reference":"123","transaction_amount":9.99}`)),
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		httpClient.EXPECT().DoGetWithHeaders(gomock.Eq("/api/v1/payments/7890"), gomock.Eq(map[string]string{"Authorization": "Bearer token"})).
+			Times(tt.clientCall.times).
+			Return(tt.clientCall.response, tt.clientCall.err)
+
+		config := MercadoPagoBrokerConfig{
+			HttpClient:  httpClient,
+			AccessToken: "token",
+			ApiUrl:      "/api",
+		}
+		mercadoPagoBroker := NewMercadoPagoBroker(config)
+		payment, err := mercadoPagoBroker.GetPayment(7890)
+
+		assert.Equal(t, tt.want.payment, payment)
+		assert.Equal(t, tt.want.err, err)
+	}
+}
+
+func TestMercadoPagoBroker_GetMerchantOrder(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	httpClient := mock_http.NewMockHttpClient(ctrl)
+
+	type want struct {
+		merchantOrder MerchantOrderResponse
+		err           error
+	}
+	type clientCall struct {
+		times    int
+		response *http.Response
+		err      error
+	}
+	tests := []struct {
+		name string
+		want
+		clientCall
+	}{
+		{
+			name: "should fail to get merchant order when http client returns error",
+			want: want{
+				err: coreErrors.Wrap(coreErrors.KindUpstreamUnavailable, errors.New("failed to call mercado pago broker, error: internal error")),
+			},
+			clientCall: clientCall{
+				times:    1,
+				response: &http.Response{},
+				err:      errors.New("internal error"),
+			},
+		},
+		{
+			name: "should fail to get merchant order when response is non-2xx",
+			want: want{
+				err: coreErrors.Wrap(coreErrors.UpstreamStatusKind(404), errors.New("failed to get mercado pago merchant order, status [404] non-2xx")),
+			},
+			clientCall: clientCall{
+				times: 1,
+				response: &http.Response{
+					StatusCode: 404,
+					Body:       io.NopCloser(strings.NewReader("")),
+				},
+			},
+		},
+		{
+			name: "should get the merchant order",
+			want: want{
+				merchantOrder: MerchantOrderResponse{
+					Id:                456,
+					ExternalReference: "123",
+					OrderStatus:       "paid",
+					Payments: []PaymentResponse{
+						{Id: 7890, Status: "approved", TransactionAmount: 9.99},
+					},
+				},
+			},
+			clientCall: clientCall{
+				times: 1,
+				response: &http.Response{
+					StatusCode: 200,
+					Body:       io.NopCloser(strings.NewReader(`{"id":456,"external_reference":"123","order_status":"paid","payments":[{"id":7890,"status":"approved","transaction_amount":9.99}]}`)),
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		httpClient.EXPECT().DoGetWithHeaders(gomock.Eq("/api/merchant_orders/456"), gomock.Eq(map[string]string{"Authorization": "Bearer token"})).
+			Times(tt.clientCall.times).
+			Return(tt.clientCall.response, tt.clientCall.err)
+
+		config := MercadoPagoBrokerConfig{
+			HttpClient:  httpClient,
+			AccessToken: "token",
+			ApiUrl:      "/api",
+		}
+		mercadoPagoBroker := NewMercadoPagoBroker(config)
+		merchantOrder, err := mercadoPagoBroker.GetMerchantOrder(456)
+
+		assert.Equal(t, tt.want.merchantOrder, merchantOrder)
+		assert.Equal(t, tt.want.err, err)
+	}
+}
+
+func TestMercadoPagoBroker_RefundPayment(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	httpClient := mock_http.NewMockHttpClient(ctrl)
+
+	type want struct {
+		refund RefundResponse
+		err    error
+	}
+	type clientCall struct {
+		times    int
+		response *http.Response
+		err      error
+	}
+	tests := []struct {
+		name string
+		want
+		clientCall
+	}{
+		{
+			name: "should fail to refund payment when http client returns error",
+			want: want{
+				err: coreErrors.Wrap(coreErrors.KindUpstreamUnavailable, errors.New("failed to call mercado pago broker, error: internal error")),
+			},
+			clientCall: clientCall{
+				times:    1,
+				response: &http.Response{},
+				err:      errors.New("internal error"),
+			},
+		},
+		{
+			name: "should fail to refund payment when response is non-2xx",
+			want: want{
+				err: coreErrors.Wrap(coreErrors.UpstreamStatusKind(400), errors.New("failed to refund mercado pago payment, status [400] non-2xx")),
+			},
+			clientCall: clientCall{
+				times: 1,
+				response: &http.Response{
+					StatusCode: 400,
+					Body:       io.NopCloser(strings.NewReader("")),
+				},
+			},
+		},
+		{
+			name: "should refund payment",
+			want: want{
+				refund: RefundResponse{
+					Id:        999,
+					PaymentId: 111,
+					Amount:    5,
+					Status:    "approved",
+				},
+			},
+			clientCall: clientCall{
+				times: 1,
+				response: &http.Response{
+					StatusCode: 201,
+					Body:       io.NopCloser(strings.NewReader(`{"id":999,"payment_id":111,"amount":5,"status":"approved"}`)),
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		httpClient.EXPECT().DoPostWithHeaders(gomock.Eq("/api/v1/payments/111/refunds"), gomock.Eq([]byte(`{"amount":5}`)), gomock.Eq(map[string]string{"Authorization": "Bearer token", "X-Idempotency-Key": "123-refund-1"})).
+			Times(tt.clientCall.times).
+			Return(tt.clientCall.response, tt.clientCall.err)
+
+		config := MercadoPagoBrokerConfig{
+			HttpClient:  httpClient,
+			AccessToken: "token",
+			ApiUrl:      "/api",
+		}
+		mercadoPagoBroker := NewMercadoPagoBroker(config)
+		refund, err := mercadoPagoBroker.RefundPayment(111, 5, "123-refund-1")
+
+		assert.Equal(t, tt.want.refund, refund)
+		assert.Equal(t, tt.want.err, err)
+	}
+}
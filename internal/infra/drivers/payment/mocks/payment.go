@@ -11,6 +11,7 @@ package mock_payment
 
 import (
 	reflect "reflect"
+	time "time"
 
 	dto "github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/usecases/dto"
 	payment "github.com/IgorRamosBR/g73-techchallenge-payment/internal/infra/drivers/payment"
@@ -40,17 +41,105 @@ func (m *MockPaymentBroker) EXPECT() *MockPaymentBrokerMockRecorder {
 	return m.recorder
 }
 
+// CancelPaymentOrder mocks base method.
+func (m *MockPaymentBroker) CancelPaymentOrder(orderId int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelPaymentOrder", orderId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CancelPaymentOrder indicates an expected call of CancelPaymentOrder.
+func (mr *MockPaymentBrokerMockRecorder) CancelPaymentOrder(orderId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelPaymentOrder", reflect.TypeOf((*MockPaymentBroker)(nil).CancelPaymentOrder), orderId)
+}
+
 // GeneratePaymentQRCode mocks base method.
-func (m *MockPaymentBroker) GeneratePaymentQRCode(paymentOrder dto.PaymentOrderDTO) (payment.PaymentQRCodeResponse, error) {
+func (m *MockPaymentBroker) GeneratePaymentQRCode(paymentOrder dto.PaymentOrderDTO, expiresAt time.Time) (payment.PaymentQRCodeResponse, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GeneratePaymentQRCode", paymentOrder)
+	ret := m.ctrl.Call(m, "GeneratePaymentQRCode", paymentOrder, expiresAt)
 	ret0, _ := ret[0].(payment.PaymentQRCodeResponse)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GeneratePaymentQRCode indicates an expected call of GeneratePaymentQRCode.
-func (mr *MockPaymentBrokerMockRecorder) GeneratePaymentQRCode(paymentOrder any) *gomock.Call {
+func (mr *MockPaymentBrokerMockRecorder) GeneratePaymentQRCode(paymentOrder, expiresAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GeneratePaymentQRCode", reflect.TypeOf((*MockPaymentBroker)(nil).GeneratePaymentQRCode), paymentOrder, expiresAt)
+}
+
+// GetMerchantOrder mocks base method.
+func (m *MockPaymentBroker) GetMerchantOrder(merchantOrderId int) (payment.MerchantOrderResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMerchantOrder", merchantOrderId)
+	ret0, _ := ret[0].(payment.MerchantOrderResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMerchantOrder indicates an expected call of GetMerchantOrder.
+func (mr *MockPaymentBrokerMockRecorder) GetMerchantOrder(merchantOrderId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMerchantOrder", reflect.TypeOf((*MockPaymentBroker)(nil).GetMerchantOrder), merchantOrderId)
+}
+
+// GetName mocks base method.
+func (m *MockPaymentBroker) GetName() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetName")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// GetName indicates an expected call of GetName.
+func (mr *MockPaymentBrokerMockRecorder) GetName() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetName", reflect.TypeOf((*MockPaymentBroker)(nil).GetName))
+}
+
+// GetPayment mocks base method.
+func (m *MockPaymentBroker) GetPayment(paymentId int) (payment.PaymentResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPayment", paymentId)
+	ret0, _ := ret[0].(payment.PaymentResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPayment indicates an expected call of GetPayment.
+func (mr *MockPaymentBrokerMockRecorder) GetPayment(paymentId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPayment", reflect.TypeOf((*MockPaymentBroker)(nil).GetPayment), paymentId)
+}
+
+// GetPaymentByExternalReference mocks base method.
+func (m *MockPaymentBroker) GetPaymentByExternalReference(orderId int) (payment.PaymentResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPaymentByExternalReference", orderId)
+	ret0, _ := ret[0].(payment.PaymentResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPaymentByExternalReference indicates an expected call of GetPaymentByExternalReference.
+func (mr *MockPaymentBrokerMockRecorder) GetPaymentByExternalReference(orderId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPaymentByExternalReference", reflect.TypeOf((*MockPaymentBroker)(nil).GetPaymentByExternalReference), orderId)
+}
+
+// RefundPayment mocks base method.
+func (m *MockPaymentBroker) RefundPayment(paymentId int, amount float64, idempotencyKey string) (payment.RefundResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefundPayment", paymentId, amount, idempotencyKey)
+	ret0, _ := ret[0].(payment.RefundResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RefundPayment indicates an expected call of RefundPayment.
+func (mr *MockPaymentBrokerMockRecorder) RefundPayment(paymentId, amount, idempotencyKey any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GeneratePaymentQRCode", reflect.TypeOf((*MockPaymentBroker)(nil).GeneratePaymentQRCode), paymentOrder)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefundPayment", reflect.TypeOf((*MockPaymentBroker)(nil).RefundPayment), paymentId, amount, idempotencyKey)
 }
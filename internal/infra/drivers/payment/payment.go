@@ -1,9 +1,23 @@
 package payment
 
-import "github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/usecases/dto"
+import (
+	"time"
+
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/entities"
+	coreErrors "github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/errors"
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/usecases/dto"
+)
+
+var ErrPaymentNotFound = coreErrors.New(coreErrors.KindNotFound, "payment not found in the broker")
 
 type PaymentBroker interface {
-	GeneratePaymentQRCode(paymentOrder dto.PaymentOrderDTO) (PaymentQRCodeResponse, error)
+	GetName() string
+	GeneratePaymentQRCode(paymentOrder dto.PaymentOrderDTO, expiresAt time.Time) (PaymentQRCodeResponse, error)
+	CancelPaymentOrder(orderId int) error
+	GetPaymentByExternalReference(orderId int) (PaymentResponse, error)
+	GetPayment(paymentId int) (PaymentResponse, error)
+	GetMerchantOrder(merchantOrderId int) (MerchantOrderResponse, error)
+	RefundPayment(paymentId int, amount float64, idempotencyKey string) (RefundResponse, error)
 }
 type PaymentRequest struct {
 	ExternalReference string               `json:"external_reference"`
@@ -12,6 +26,7 @@ type PaymentRequest struct {
 	TotalAmount       float64              `json:"total_amount"`
 	Items             []PaymentItemRequest `json:"items"`
 	Sponsor           string               `json:"sponsor"`
+	ExpirationDate    string               `json:"expiration_date,omitempty"`
 }
 
 type PaymentItemRequest struct {
@@ -29,3 +44,64 @@ type PaymentQRCodeResponse struct {
 	QrData       string `json:"qr_data"`
 	StoreOrderId string `json:"in_store_order_id"`
 }
+
+type StoreOrderResponse struct {
+	ExternalReference string `json:"external_reference"`
+}
+
+type PaymentSearchResponse struct {
+	Results []PaymentResponse `json:"results"`
+}
+
+type PaymentResponse struct {
+	Id                int     `json:"id"`
+	Status            string  `json:"status"`
+	ExternalReference string  `json:"external_reference"`
+	TransactionAmount float64 `json:"transaction_amount"`
+}
+
+// GetPaymentStatus maps the broker payment status to the payment order status, returning false
+// when the broker status has no equivalent.
+func (p PaymentResponse) GetPaymentStatus() (entities.PaymentStatus, bool) {
+	switch p.Status {
+	case "pending", "in_process":
+		return entities.PaymentStatusPending, true
+	case "authorized":
+		return entities.PaymentStatusAuthorized, true
+	case "approved":
+		return entities.PaymentStatusPaid, true
+	case "refunded":
+		return entities.PaymentStatusRefunded, true
+	default:
+		return "", false
+	}
+}
+
+type MerchantOrderResponse struct {
+	Id                int               `json:"id"`
+	ExternalReference string            `json:"external_reference"`
+	OrderStatus       string            `json:"order_status"`
+	Payments          []PaymentResponse `json:"payments"`
+}
+
+// GetApprovedPayment returns the payment that paid the merchant order, if there is one.
+func (m MerchantOrderResponse) GetApprovedPayment() (PaymentResponse, bool) {
+	for _, payment := range m.Payments {
+		if status, ok := payment.GetPaymentStatus(); ok && status == entities.PaymentStatusPaid {
+			payment.ExternalReference = m.ExternalReference
+			return payment, true
+		}
+	}
+	return PaymentResponse{}, false
+}
+
+type RefundRequest struct {
+	Amount float64 `json:"amount"`
+}
+
+type RefundResponse struct {
+	Id        int     `json:"id"`
+	PaymentId int     `json:"payment_id"`
+	Amount    float64 `json:"amount"`
+	Status    string  `json:"status"`
+}
@@ -0,0 +1,53 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: sns.go
+//
+// Generated by this command:
+//
+//	mockgen -source=sns.go -destination=mocks/sns.go
+//
+
+// Package mock_sns is a generated GoMock package.
+package mock_sns
+
+import (
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockSNSClient is a mock of SNSClient interface.
+type MockSNSClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockSNSClientMockRecorder
+}
+
+// MockSNSClientMockRecorder is the mock recorder for MockSNSClient.
+type MockSNSClientMockRecorder struct {
+	mock *MockSNSClient
+}
+
+// NewMockSNSClient creates a new mock instance.
+func NewMockSNSClient(ctrl *gomock.Controller) *MockSNSClient {
+	mock := &MockSNSClient{ctrl: ctrl}
+	mock.recorder = &MockSNSClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSNSClient) EXPECT() *MockSNSClientMockRecorder {
+	return m.recorder
+}
+
+// Publish mocks base method.
+func (m *MockSNSClient) Publish(topicArn, message string, attributes map[string]string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Publish", topicArn, message, attributes)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Publish indicates an expected call of Publish.
+func (mr *MockSNSClientMockRecorder) Publish(topicArn, message, attributes any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Publish", reflect.TypeOf((*MockSNSClient)(nil).Publish), topicArn, message, attributes)
+}
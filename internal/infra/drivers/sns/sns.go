@@ -0,0 +1,41 @@
+package sns
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	"github.com/aws/aws-sdk-go-v2/service/sns/types"
+)
+
+type SNSClient interface {
+	Publish(topicArn string, message string, attributes map[string]string) error
+}
+
+type snsClient struct {
+	client *sns.Client
+}
+
+func NewSNSClient(client *sns.Client) *snsClient {
+	return &snsClient{client: client}
+}
+
+func (s *snsClient) Publish(topicArn string, message string, attributes map[string]string) error {
+	messageAttributes := map[string]types.MessageAttributeValue{}
+	for name, value := range attributes {
+		messageAttributes[name] = types.MessageAttributeValue{
+			DataType:    aws.String("String"),
+			StringValue: aws.String(value),
+		}
+	}
+
+	_, err := s.client.Publish(context.TODO(), &sns.PublishInput{
+		TopicArn:          &topicArn,
+		Message:           &message,
+		MessageAttributes: messageAttributes,
+	})
+	if err != nil {
+		return err
+	}
+	return nil
+}
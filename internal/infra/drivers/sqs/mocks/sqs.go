@@ -0,0 +1,53 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: sqs.go
+//
+// Generated by this command:
+//
+//	mockgen -source=sqs.go -destination=mocks/sqs.go
+//
+
+// Package mock_sqs is a generated GoMock package.
+package mock_sqs
+
+import (
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockSQSClient is a mock of SQSClient interface.
+type MockSQSClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockSQSClientMockRecorder
+}
+
+// MockSQSClientMockRecorder is the mock recorder for MockSQSClient.
+type MockSQSClientMockRecorder struct {
+	mock *MockSQSClient
+}
+
+// NewMockSQSClient creates a new mock instance.
+func NewMockSQSClient(ctrl *gomock.Controller) *MockSQSClient {
+	mock := &MockSQSClient{ctrl: ctrl}
+	mock.recorder = &MockSQSClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSQSClient) EXPECT() *MockSQSClientMockRecorder {
+	return m.recorder
+}
+
+// SendMessage mocks base method.
+func (m *MockSQSClient) SendMessage(queueUrl, body string, attributes map[string]string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendMessage", queueUrl, body, attributes)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendMessage indicates an expected call of SendMessage.
+func (mr *MockSQSClientMockRecorder) SendMessage(queueUrl, body, attributes any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMessage", reflect.TypeOf((*MockSQSClient)(nil).SendMessage), queueUrl, body, attributes)
+}
@@ -0,0 +1,41 @@
+package sqs
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+)
+
+type SQSClient interface {
+	SendMessage(queueUrl string, body string, attributes map[string]string) error
+}
+
+type sqsClient struct {
+	client *sqs.Client
+}
+
+func NewSQSClient(client *sqs.Client) *sqsClient {
+	return &sqsClient{client: client}
+}
+
+func (s *sqsClient) SendMessage(queueUrl string, body string, attributes map[string]string) error {
+	messageAttributes := map[string]types.MessageAttributeValue{}
+	for name, value := range attributes {
+		messageAttributes[name] = types.MessageAttributeValue{
+			DataType:    aws.String("String"),
+			StringValue: aws.String(value),
+		}
+	}
+
+	_, err := s.client.SendMessage(context.TODO(), &sqs.SendMessageInput{
+		QueueUrl:          &queueUrl,
+		MessageBody:       &body,
+		MessageAttributes: messageAttributes,
+	})
+	if err != nil {
+		return err
+	}
+	return nil
+}
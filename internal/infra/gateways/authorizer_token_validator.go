@@ -0,0 +1,124 @@
+package gateways
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/entities"
+	coreErrors "github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/errors"
+	httpDriver "github.com/IgorRamosBR/g73-techchallenge-payment/internal/infra/drivers/http"
+)
+
+var ErrInvalidToken = coreErrors.New(coreErrors.KindUnauthenticated, "invalid token")
+
+type TokenValidator interface {
+	ValidateToken(token string) (entities.Principal, error)
+}
+
+// authorizerResponse accepts both the principalId of API Gateway authorizers and the sub claim,
+// and the scopes either as a list or as a space-separated scope claim.
+type authorizerResponse struct {
+	PrincipalId string   `json:"principalId"`
+	Subject     string   `json:"sub"`
+	Scope       string   `json:"scope"`
+	Scopes      []string `json:"scopes"`
+}
+
+type cachedPrincipal struct {
+	principal entities.Principal
+	expiresAt time.Time
+}
+
+type authorizerTokenValidator struct {
+	httpClient    httpDriver.HttpClient
+	authorizerUrl string
+	cacheTTL      time.Duration
+
+	mu    sync.Mutex
+	cache map[string]cachedPrincipal
+}
+
+// NewAuthorizerTokenValidator validates the tokens with the external authorizer, which receives
+// the token in the Authorization header. Accepted tokens are cached for cacheTTL, so the
+// authorizer is not called on every request.
+func NewAuthorizerTokenValidator(httpClient httpDriver.HttpClient, authorizerUrl string, cacheTTL time.Duration) TokenValidator {
+	return &authorizerTokenValidator{
+		httpClient:    httpClient,
+		authorizerUrl: authorizerUrl,
+		cacheTTL:      cacheTTL,
+		cache:         map[string]cachedPrincipal{},
+	}
+}
+
+func (a *authorizerTokenValidator) ValidateToken(token string) (entities.Principal, error) {
+	cacheKey := hashToken(token)
+	if principal, ok := a.getCached(cacheKey); ok {
+		return principal, nil
+	}
+
+	response, err := a.httpClient.DoGetWithHeaders(a.authorizerUrl, map[string]string{"Authorization": "Bearer " + token})
+	if err != nil {
+		return entities.Principal{}, coreErrors.Wrap(coreErrors.KindUpstreamUnavailable, fmt.Errorf("failed to call authorizer, error: %v", err))
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusUnauthorized || response.StatusCode == http.StatusForbidden {
+		return entities.Principal{}, ErrInvalidToken
+	}
+	if response.StatusCode > 299 || response.StatusCode < 200 {
+		return entities.Principal{}, coreErrors.Wrap(coreErrors.KindUpstreamUnavailable, fmt.Errorf("failed to call authorizer, status [%d] non-2xx", response.StatusCode))
+	}
+
+	var authorizerResp authorizerResponse
+	err = json.NewDecoder(response.Body).Decode(&authorizerResp)
+	if err != nil {
+		return entities.Principal{}, fmt.Errorf("failed to decode authorizer response, error: %v", err)
+	}
+
+	principal := entities.Principal{
+		Subject: authorizerResp.PrincipalId,
+		Scopes:  append(authorizerResp.Scopes, strings.Fields(authorizerResp.Scope)...),
+	}
+	if principal.Subject == "" {
+		principal.Subject = authorizerResp.Subject
+	}
+
+	a.setCached(cacheKey, principal)
+	return principal, nil
+}
+
+func (a *authorizerTokenValidator) getCached(cacheKey string) (entities.Principal, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	cached, ok := a.cache[cacheKey]
+	if !ok || time.Now().After(cached.expiresAt) {
+		return entities.Principal{}, false
+	}
+	return cached.principal, true
+}
+
+func (a *authorizerTokenValidator) setCached(cacheKey string, principal entities.Principal) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	now := time.Now()
+	for key, cached := range a.cache {
+		if now.After(cached.expiresAt) {
+			delete(a.cache, key)
+		}
+	}
+	a.cache[cacheKey] = cachedPrincipal{principal: principal, expiresAt: now.Add(a.cacheTTL)}
+}
+
+// hashToken keys the cache, so the tokens themselves are not kept in memory.
+func hashToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,107 @@
+package gateways
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/entities"
+	coreErrors "github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/errors"
+	mock_http "github.com/IgorRamosBR/g73-techchallenge-payment/internal/infra/drivers/http/mocks"
+	"github.com/go-playground/assert/v2"
+	"go.uber.org/mock/gomock"
+)
+
+func TestAuthorizerTokenValidator_ValidateToken(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	httpClient := mock_http.NewMockHttpClient(ctrl)
+
+	type want struct {
+		principal entities.Principal
+		err       error
+	}
+	type clientCall struct {
+		times    int
+		response *http.Response
+		err      error
+	}
+	tests := []struct {
+		name string
+		want
+		clientCall
+	}{
+		{
+			name: "should fail to validate token when http client returns error",
+			want: want{
+				err: coreErrors.Wrap(coreErrors.KindUpstreamUnavailable, errors.New("failed to call authorizer, error: internal error")),
+			},
+			clientCall: clientCall{
+				times:    1,
+				response: &http.Response{},
+				err:      errors.New("internal error"),
+			},
+		},
+		{
+			name: "should refuse token when the authorizer rejects it",
+			want: want{
+				err: ErrInvalidToken,
+			},
+			clientCall: clientCall{
+				times:    1,
+				response: &http.Response{StatusCode: 401, Body: io.NopCloser(strings.NewReader(""))},
+			},
+		},
+		{
+			name: "should fail to validate token when the authorizer fails",
+			want: want{
+				err: coreErrors.Wrap(coreErrors.KindUpstreamUnavailable, errors.New("failed to call authorizer, status [500] non-2xx")),
+			},
+			clientCall: clientCall{
+				times:    1,
+				response: &http.Response{StatusCode: 500, Body: io.NopCloser(strings.NewReader(""))},
+			},
+		},
+		{
+			name: "should return the principal when the authorizer accepts the token",
+			want: want{
+				principal: entities.Principal{Subject: "client-1", Scopes: []string{"payments:read", "payments:write"}},
+			},
+			clientCall: clientCall{
+				times:    1,
+				response: &http.Response{StatusCode: 200, Body: io.NopCloser(strings.NewReader(`{"principalId":"client-1","scope":"payments:read payments:write"}`))},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		httpClient.EXPECT().DoGetWithHeaders(gomock.Eq("/authorize"), gomock.Eq(map[string]string{"Authorization": "Bearer token"})).
+			Times(tt.clientCall.times).
+			Return(tt.clientCall.response, tt.clientCall.err)
+
+		tokenValidator := NewAuthorizerTokenValidator(httpClient, "/authorize", time.Minute)
+		principal, err := tokenValidator.ValidateToken("token")
+
+		assert.Equal(t, tt.want.principal, principal)
+		assert.Equal(t, tt.want.err, err)
+	}
+}
+
+func TestAuthorizerTokenValidator_ValidateToken_Cached(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	httpClient := mock_http.NewMockHttpClient(ctrl)
+
+	httpClient.EXPECT().DoGetWithHeaders(gomock.Eq("/authorize"), gomock.Any()).
+		Times(1).
+		Return(&http.Response{StatusCode: 200, Body: io.NopCloser(strings.NewReader(`{"sub":"client-1","scopes":["admin"]}`))}, nil)
+
+	tokenValidator := NewAuthorizerTokenValidator(httpClient, "/authorize", time.Minute)
+	for i := 0; i < 2; i++ {
+		principal, err := tokenValidator.ValidateToken("token")
+
+		assert.Equal(t, entities.Principal{Subject: "client-1", Scopes: []string{"admin"}}, principal)
+		assert.Equal(t, nil, err)
+	}
+}
@@ -0,0 +1,87 @@
+package gateways
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// cursorValue is a key attribute in a cursor, which only holds strings and numbers.
+type cursorValue struct {
+	S *string `json:"S,omitempty"`
+	N *string `json:"N,omitempty"`
+}
+
+// cursor is the key to resume a listing from, along with the index it was read from, empty for the
+// table, so it is not used to resume a listing of another index.
+type cursor struct {
+	Index string                 `json:"index,omitempty"`
+	Key   map[string]cursorValue `json:"key"`
+}
+
+// encodeCursor turns the key to resume a listing from into an opaque token for the clients.
+func encodeCursor(indexName string, key map[string]types.AttributeValue) (string, error) {
+	values := map[string]cursorValue{}
+	for name, value := range key {
+		switch v := value.(type) {
+		case *types.AttributeValueMemberS:
+			values[name] = cursorValue{S: &v.Value}
+		case *types.AttributeValueMemberN:
+			values[name] = cursorValue{N: &v.Value}
+		default:
+			return "", fmt.Errorf("key attribute %s is neither a string nor a number", name)
+		}
+	}
+
+	data, err := json.Marshal(cursor{Index: indexName, Key: values})
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// decodeCursor reads the key encoded by encodeCursor, which must come from the same index and have
+// exactly the key attributes.
+func decodeCursor(token string, indexName string, keyAttributes []string) (map[string]types.AttributeValue, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, err
+	}
+
+	var decoded cursor
+	err = json.Unmarshal(data, &decoded)
+	if err != nil {
+		return nil, err
+	}
+	if decoded.Index != indexName {
+		return nil, fmt.Errorf("cursor is of the index [%s], expected [%s]", decoded.Index, indexName)
+	}
+
+	values := decoded.Key
+	if len(values) != len(keyAttributes) {
+		return nil, fmt.Errorf("cursor has %d key attributes, expected %d", len(values), len(keyAttributes))
+	}
+
+	key := map[string]types.AttributeValue{}
+	for _, name := range keyAttributes {
+		value, ok := values[name]
+		switch {
+		case !ok:
+			return nil, fmt.Errorf("cursor misses the key attribute %s", name)
+		case value.S != nil:
+			key[name] = &types.AttributeValueMemberS{Value: *value.S}
+		case value.N != nil:
+			_, err := strconv.ParseFloat(*value.N, 64)
+			if err != nil {
+				return nil, fmt.Errorf("cursor key attribute %s is not a number", name)
+			}
+			key[name] = &types.AttributeValueMemberN{Value: *value.N}
+		default:
+			return nil, fmt.Errorf("cursor key attribute %s has no value", name)
+		}
+	}
+	return key, nil
+}
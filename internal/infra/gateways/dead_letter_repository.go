@@ -0,0 +1,115 @@
+package gateways
+
+import (
+	"sort"
+	"time"
+
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/entities"
+	coreErrors "github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/errors"
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/infra/drivers/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+var ErrDeadLetterNotFound = coreErrors.New(coreErrors.KindNotFound, "dead letter not found")
+
+type DeadLetterRepositoryGateway interface {
+	SaveDeadLetter(deadLetter entities.DeadLetter) error
+	GetDeadLetter(messageId string) (entities.DeadLetter, error)
+	GetDeadLetters(deadLetterType entities.DeadLetterType) ([]entities.DeadLetter, error)
+	DeleteDeadLetter(messageId string) error
+}
+
+type deadLetterRepositoryGateway struct {
+	deadLetterTable string
+	dynamodbClient  dynamodb.DynamoDBClient
+}
+
+func NewDeadLetterRepositoryGateway(dynamodbClient dynamodb.DynamoDBClient, deadLetterTable string) DeadLetterRepositoryGateway {
+	return deadLetterRepositoryGateway{
+		dynamodbClient:  dynamodbClient,
+		deadLetterTable: deadLetterTable,
+	}
+}
+
+// SaveDeadLetter creates the dead letter, or replaces it when the MessageId is already known.
+func (d deadLetterRepositoryGateway) SaveDeadLetter(deadLetter entities.DeadLetter) error {
+	now := time.Now()
+	if deadLetter.CreatedAt.IsZero() {
+		deadLetter.CreatedAt = now
+	}
+	deadLetter.UpdatedAt = now
+	if deadLetter.MessageId == "" {
+		messageId, err := newSortableId(deadLetter.CreatedAt)
+		if err != nil {
+			return err
+		}
+		deadLetter.MessageId = messageId
+	}
+
+	av, err := attributevalue.MarshalMap(deadLetter)
+	if err != nil {
+		return err
+	}
+
+	return d.dynamodbClient.PutItem(d.deadLetterTable, av)
+}
+
+func (d deadLetterRepositoryGateway) GetDeadLetter(messageId string) (entities.DeadLetter, error) {
+	item, err := d.dynamodbClient.GetItem(d.deadLetterTable, d.deadLetterKey(messageId))
+	if err != nil {
+		return entities.DeadLetter{}, err
+	}
+	if len(item) == 0 {
+		return entities.DeadLetter{}, ErrDeadLetterNotFound
+	}
+
+	var deadLetter entities.DeadLetter
+	err = attributevalue.UnmarshalMap(item, &deadLetter)
+	if err != nil {
+		return entities.DeadLetter{}, err
+	}
+
+	return deadLetter, nil
+}
+
+// GetDeadLetters returns the dead letters of the given type, or of every type when it is empty,
+// oldest first.
+func (d deadLetterRepositoryGateway) GetDeadLetters(deadLetterType entities.DeadLetterType) ([]entities.DeadLetter, error) {
+	filter := expression.Name("MessageId").AttributeExists()
+	if deadLetterType != "" {
+		filter = expression.Name("Type").Equal(expression.Value(deadLetterType))
+	}
+	expr, err := expression.NewBuilder().WithFilter(filter).Build()
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := d.dynamodbClient.Scan(d.deadLetterTable, expr)
+	if err != nil {
+		return nil, err
+	}
+
+	deadLetters := []entities.DeadLetter{}
+	err = attributevalue.UnmarshalListOfMaps(items, &deadLetters)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(deadLetters, func(i, j int) bool {
+		return deadLetters[i].MessageId < deadLetters[j].MessageId
+	})
+
+	return deadLetters, nil
+}
+
+func (d deadLetterRepositoryGateway) DeleteDeadLetter(messageId string) error {
+	return d.dynamodbClient.DeleteItem(d.deadLetterTable, d.deadLetterKey(messageId))
+}
+
+func (d deadLetterRepositoryGateway) deadLetterKey(messageId string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"MessageId": &types.AttributeValueMemberS{Value: messageId},
+	}
+}
@@ -0,0 +1,105 @@
+package gateways
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/entities"
+	mock_dynamodb "github.com/IgorRamosBR/g73-techchallenge-payment/internal/infra/drivers/dynamodb/mocks"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/go-playground/assert/v2"
+	"go.uber.org/mock/gomock"
+)
+
+func TestDeadLetterRepository_GetDeadLetter(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	dynamodbClient := mock_dynamodb.NewMockDynamoDBClient(ctrl)
+
+	deadLetter := entities.DeadLetter{
+		MessageId: "1714564800000000000-a1b2c3d4",
+		Type:      entities.DeadLetterTypeOrderNotification,
+		Payload:   `{"orderId":123,"status":"PAID"}`,
+		Attempts:  1,
+	}
+	item, _ := attributevalue.MarshalMap(deadLetter)
+
+	type want struct {
+		deadLetter entities.DeadLetter
+		err        error
+	}
+	type dynamodbCall struct {
+		item map[string]types.AttributeValue
+		err  error
+	}
+	tests := []struct {
+		name string
+		want
+		dynamodbCall
+	}{
+		{
+			name: "should fail to get dead letter when dynamodb client returns error",
+			want: want{
+				err: errors.New("internal error"),
+			},
+			dynamodbCall: dynamodbCall{
+				err: errors.New("internal error"),
+			},
+		},
+		{
+			name: "should return not found when the dead letter does not exist",
+			want: want{
+				err: ErrDeadLetterNotFound,
+			},
+			dynamodbCall: dynamodbCall{
+				item: map[string]types.AttributeValue{},
+			},
+		},
+		{
+			name: "should get the dead letter",
+			want: want{
+				deadLetter: deadLetter,
+			},
+			dynamodbCall: dynamodbCall{
+				item: item,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		dynamodbClient.EXPECT().
+			GetItem(gomock.Eq("DeadLetter"), gomock.Eq(map[string]types.AttributeValue{
+				"MessageId": &types.AttributeValueMemberS{Value: "1714564800000000000-a1b2c3d4"},
+			})).
+			Times(1).
+			Return(tt.dynamodbCall.item, tt.dynamodbCall.err)
+
+		deadLetterRepository := NewDeadLetterRepositoryGateway(dynamodbClient, "DeadLetter")
+		result, err := deadLetterRepository.GetDeadLetter("1714564800000000000-a1b2c3d4")
+
+		assert.Equal(t, tt.want.deadLetter.MessageId, result.MessageId)
+		assert.Equal(t, tt.want.deadLetter.Payload, result.Payload)
+		assert.Equal(t, tt.want.err, err)
+	}
+}
+
+func TestDeadLetterRepository_GetDeadLetters(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	dynamodbClient := mock_dynamodb.NewMockDynamoDBClient(ctrl)
+
+	newer, _ := attributevalue.MarshalMap(entities.DeadLetter{MessageId: "2", Type: entities.DeadLetterTypeOrderNotification})
+	older, _ := attributevalue.MarshalMap(entities.DeadLetter{MessageId: "1", Type: entities.DeadLetterTypeOrderNotification})
+
+	dynamodbClient.EXPECT().
+		Scan(gomock.Eq("DeadLetter"), gomock.Any()).
+		Times(1).
+		Return([]map[string]types.AttributeValue{newer, older}, nil)
+
+	deadLetterRepository := NewDeadLetterRepositoryGateway(dynamodbClient, "DeadLetter")
+	deadLetters, err := deadLetterRepository.GetDeadLetters(entities.DeadLetterTypeOrderNotification)
+
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 2, len(deadLetters))
+	assert.Equal(t, "1", deadLetters[0].MessageId)
+	assert.Equal(t, "2", deadLetters[1].MessageId)
+}
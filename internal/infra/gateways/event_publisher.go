@@ -0,0 +1,80 @@
+package gateways
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/entities"
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/infra/drivers/sns"
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/infra/drivers/sqs"
+)
+
+type EventPublisher interface {
+	Publish(event entities.PaymentDomainEvent) error
+}
+
+type snsEventPublisher struct {
+	snsClient sns.SNSClient
+	topicArn  string
+}
+
+// NewSNSEventPublisher publishes the events to an SNS topic, so every interested service can
+// subscribe its own queue to it.
+func NewSNSEventPublisher(snsClient sns.SNSClient, topicArn string) EventPublisher {
+	return snsEventPublisher{
+		snsClient: snsClient,
+		topicArn:  topicArn,
+	}
+}
+
+func (p snsEventPublisher) Publish(event entities.PaymentDomainEvent) error {
+	message, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s event, error: %v", event.Type, err)
+	}
+
+	err = p.snsClient.Publish(p.topicArn, string(message), eventAttributes(event))
+	if err != nil {
+		return fmt.Errorf("failed to publish %s event to sns, error: %v", event.Type, err)
+	}
+
+	return nil
+}
+
+type sqsEventPublisher struct {
+	sqsClient sqs.SQSClient
+	queueUrl  string
+}
+
+// NewSQSEventPublisher sends the events straight to an SQS queue, for setups without a topic
+// such as a local ElasticMQ.
+func NewSQSEventPublisher(sqsClient sqs.SQSClient, queueUrl string) EventPublisher {
+	return sqsEventPublisher{
+		sqsClient: sqsClient,
+		queueUrl:  queueUrl,
+	}
+}
+
+func (p sqsEventPublisher) Publish(event entities.PaymentDomainEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s event, error: %v", event.Type, err)
+	}
+
+	err = p.sqsClient.SendMessage(p.queueUrl, string(body), eventAttributes(event))
+	if err != nil {
+		return fmt.Errorf("failed to send %s event to sqs, error: %v", event.Type, err)
+	}
+
+	return nil
+}
+
+// eventAttributes are sent along with the message body, so consumers can filter the events
+// they subscribe to without parsing them.
+func eventAttributes(event entities.PaymentDomainEvent) map[string]string {
+	return map[string]string{
+		"eventType":    string(event.Type),
+		"eventVersion": strconv.Itoa(event.Version),
+	}
+}
@@ -0,0 +1,144 @@
+package gateways
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/entities"
+	mock_sns "github.com/IgorRamosBR/g73-techchallenge-payment/internal/infra/drivers/sns/mocks"
+	mock_sqs "github.com/IgorRamosBR/g73-techchallenge-payment/internal/infra/drivers/sqs/mocks"
+	"github.com/go-playground/assert/v2"
+	"go.uber.org/mock/gomock"
+)
+
+func TestSNSEventPublisher_Publish(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	snsClient := mock_sns.NewMockSNSClient(ctrl)
+
+	type want struct {
+		err error
+	}
+	type snsCall struct {
+		err error
+	}
+	tests := []struct {
+		name string
+		want
+		snsCall
+	}{
+		{
+			name: "should fail to publish event when sns client returns error",
+			want: want{
+				err: errors.New("failed to publish PaymentPaid event to sns, error: internal error"),
+			},
+			snsCall: snsCall{
+				err: errors.New("internal error"),
+			},
+		},
+		{
+			name: "should publish event",
+			want: want{
+				err: nil,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		snsClient.EXPECT().
+			Publish(gomock.Eq("arn:aws:sns:us-east-1:000000000000:payment-events"), gomock.Eq(createPaymentDomainEventJSON()), gomock.Eq(map[string]string{
+				"eventType":    "PaymentPaid",
+				"eventVersion": "1",
+			})).
+			Times(1).
+			Return(tt.snsCall.err)
+
+		eventPublisher := NewSNSEventPublisher(snsClient, "arn:aws:sns:us-east-1:000000000000:payment-events")
+		err := eventPublisher.Publish(createPaymentDomainEvent())
+
+		assert.Equal(t, tt.want.err, err)
+	}
+}
+
+func TestSQSEventPublisher_Publish(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	sqsClient := mock_sqs.NewMockSQSClient(ctrl)
+
+	type want struct {
+		err error
+	}
+	type sqsCall struct {
+		err error
+	}
+	tests := []struct {
+		name string
+		want
+		sqsCall
+	}{
+		{
+			name: "should fail to publish event when sqs client returns error",
+			want: want{
+				err: errors.New("failed to send PaymentPaid event to sqs, error: internal error"),
+			},
+			sqsCall: sqsCall{
+				err: errors.New("internal error"),
+			},
+		},
+		{
+			name: "should publish event",
+			want: want{
+				err: nil,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		sqsClient.EXPECT().
+			SendMessage(gomock.Eq("http://localhost:9324/000000000000/payment-events"), gomock.Eq(createPaymentDomainEventJSON()), gomock.Any()).
+			Times(1).
+			Return(tt.sqsCall.err)
+
+		eventPublisher := NewSQSEventPublisher(sqsClient, "http://localhost:9324/000000000000/payment-events")
+		err := eventPublisher.Publish(createPaymentDomainEvent())
+
+		assert.Equal(t, tt.want.err, err)
+	}
+}
+
+func TestInMemoryEventBus_Publish(t *testing.T) {
+	eventBus := NewInMemoryEventBus()
+
+	received := []entities.PaymentDomainEvent{}
+	unsubscribe := eventBus.Subscribe(func(event entities.PaymentDomainEvent) {
+		received = append(received, event)
+	})
+
+	err := eventBus.Publish(createPaymentDomainEvent())
+	assert.Equal(t, nil, err)
+
+	unsubscribe()
+	err = eventBus.Publish(createPaymentDomainEvent())
+	assert.Equal(t, nil, err)
+
+	assert.Equal(t, 1, len(received))
+	assert.Equal(t, entities.PaymentDomainEventTypePaid, received[0].Type)
+}
+
+func createPaymentDomainEvent() entities.PaymentDomainEvent {
+	return entities.PaymentDomainEvent{
+		EventId:    "123-PaymentPaid-1714564800000000000",
+		Type:       entities.PaymentDomainEventTypePaid,
+		Version:    entities.PaymentDomainEventVersion,
+		OccurredAt: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+		Data: entities.PaymentDomainEventData{
+			OrderId:   123,
+			PaymentId: 7890,
+			Status:    entities.PaymentStatusPaid,
+			Amount:    35.5,
+		},
+	}
+}
+
+func createPaymentDomainEventJSON() string {
+	return `{"eventId":"123-PaymentPaid-1714564800000000000","type":"PaymentPaid","version":1,"occurredAt":"2024-05-01T12:00:00Z","data":{"orderId":123,"paymentId":7890,"status":"PAID","amount":35.5}}`
+}
@@ -0,0 +1,58 @@
+package gateways
+
+import (
+	"sync"
+
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/entities"
+)
+
+type EventHandler func(event entities.PaymentDomainEvent)
+
+// InMemoryEventBus delivers the published events to the handlers subscribed in the same process.
+type InMemoryEventBus interface {
+	EventPublisher
+	Subscribe(handler EventHandler) (unsubscribe func())
+}
+
+type inMemoryEventBus struct {
+	mutex    *sync.RWMutex
+	handlers map[int]EventHandler
+	nextId   *int
+}
+
+// NewInMemoryEventBus returns a bus kept in the process memory. Events are not stored, so only
+// the handlers subscribed at publish time receive them.
+func NewInMemoryEventBus() InMemoryEventBus {
+	return inMemoryEventBus{
+		mutex:    &sync.RWMutex{},
+		handlers: map[int]EventHandler{},
+		nextId:   new(int),
+	}
+}
+
+// Publish calls every handler before returning, so handlers must not block.
+func (b inMemoryEventBus) Publish(event entities.PaymentDomainEvent) error {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	for _, handler := range b.handlers {
+		handler(event)
+	}
+
+	return nil
+}
+
+func (b inMemoryEventBus) Subscribe(handler EventHandler) func() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	id := *b.nextId
+	*b.nextId++
+	b.handlers[id] = handler
+
+	return func() {
+		b.mutex.Lock()
+		defer b.mutex.Unlock()
+		delete(b.handlers, id)
+	}
+}
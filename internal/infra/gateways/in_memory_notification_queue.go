@@ -0,0 +1,84 @@
+package gateways
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/entities"
+)
+
+type inMemoryNotificationQueue struct {
+	mutex             *sync.Mutex
+	messages          map[string]entities.NotificationMessage
+	visibilityTimeout time.Duration
+}
+
+// NewInMemoryNotificationQueue returns a queue kept in the process memory. Messages are lost on
+// restart, so it is meant for tests and local runs.
+func NewInMemoryNotificationQueue(visibilityTimeout time.Duration) NotificationQueue {
+	return inMemoryNotificationQueue{
+		mutex:             &sync.Mutex{},
+		messages:          map[string]entities.NotificationMessage{},
+		visibilityTimeout: visibilityTimeout,
+	}
+}
+
+func (q inMemoryNotificationQueue) Enqueue(message entities.NotificationMessage) error {
+	message, err := prepareNotificationMessage(message)
+	if err != nil {
+		return err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	q.messages[message.MessageId] = message
+
+	return nil
+}
+
+func (q inMemoryNotificationQueue) Receive(maxMessages int) ([]entities.NotificationMessage, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	now := time.Now()
+	messages := []entities.NotificationMessage{}
+	for _, message := range q.messages {
+		if !message.VisibleAt.After(now) {
+			messages = append(messages, message)
+		}
+	}
+
+	sort.Slice(messages, func(i, j int) bool {
+		return messages[i].MessageId < messages[j].MessageId
+	})
+	if len(messages) > maxMessages {
+		messages = messages[:maxMessages]
+	}
+
+	for _, message := range messages {
+		message.VisibleAt = now.Add(q.visibilityTimeout)
+		q.messages[message.MessageId] = message
+	}
+
+	return messages, nil
+}
+
+func (q inMemoryNotificationQueue) Delete(message entities.NotificationMessage) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	delete(q.messages, message.MessageId)
+
+	return nil
+}
+
+func (q inMemoryNotificationQueue) Retry(message entities.NotificationMessage) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if _, ok := q.messages[message.MessageId]; ok {
+		q.messages[message.MessageId] = message
+	}
+
+	return nil
+}
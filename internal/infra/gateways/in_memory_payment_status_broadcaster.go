@@ -0,0 +1,35 @@
+package gateways
+
+import (
+	"sync"
+
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/entities"
+)
+
+type inMemoryPaymentStatusBroadcaster struct {
+	mutex    sync.RWMutex
+	handlers []PaymentStatusHandler
+}
+
+// NewInMemoryPaymentStatusBroadcaster delivers the changes within the process, so it only suits
+// a single replica.
+func NewInMemoryPaymentStatusBroadcaster() PaymentStatusBroadcaster {
+	return &inMemoryPaymentStatusBroadcaster{}
+}
+
+func (b *inMemoryPaymentStatusBroadcaster) Broadcast(change entities.PaymentStatusChange) error {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	for _, handler := range b.handlers {
+		handler(change)
+	}
+
+	return nil
+}
+
+func (b *inMemoryPaymentStatusBroadcaster) Listen(handler PaymentStatusHandler) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.handlers = append(b.handlers, handler)
+}
@@ -0,0 +1,50 @@
+package gateways
+
+import (
+	"sync"
+	"time"
+)
+
+// inMemoryRateLimiterSweepInterval is how often the full buckets are dropped.
+const inMemoryRateLimiterSweepInterval = time.Minute
+
+type inMemoryRateLimiter struct {
+	mutex   sync.Mutex
+	buckets map[string]tokenBucket
+	sweptAt time.Time
+}
+
+// NewInMemoryRateLimiter keeps the buckets in the process memory, so each replica applies the
+// limits on its own.
+func NewInMemoryRateLimiter() RateLimiter {
+	return &inMemoryRateLimiter{
+		buckets: map[string]tokenBucket{},
+		sweptAt: time.Now(),
+	}
+}
+
+func (r *inMemoryRateLimiter) Allow(key string, limit RateLimit) (bool, time.Duration, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	now := time.Now()
+	if now.Sub(r.sweptAt) >= inMemoryRateLimiterSweepInterval {
+		for bucketKey, bucket := range r.buckets {
+			if now.After(bucket.ExpiresAt) {
+				delete(r.buckets, bucketKey)
+			}
+		}
+		r.sweptAt = now
+	}
+
+	bucket, ok := r.buckets[key]
+	if !ok {
+		bucket = tokenBucket{BucketKey: key}
+	}
+
+	updated, allowed, retryAfter := bucket.take(limit, now)
+	if allowed {
+		r.buckets[key] = updated
+	}
+	return allowed, retryAfter, nil
+}
@@ -0,0 +1,269 @@
+package gateways
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/entities"
+	coreErrors "github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/errors"
+	httpDriver "github.com/IgorRamosBR/g73-techchallenge-payment/internal/infra/drivers/http"
+)
+
+// jwksRefreshInterval limits how often an unknown key id makes the key set be fetched again,
+// whether the last fetch succeeded or not.
+const jwksRefreshInterval = time.Minute
+
+type jwtHeader struct {
+	Algorithm string `json:"alg"`
+	KeyId     string `json:"kid"`
+}
+
+type jwtClaims struct {
+	Subject   string   `json:"sub"`
+	Issuer    string   `json:"iss"`
+	Audience  audience `json:"aud"`
+	ExpiresAt int64    `json:"exp"`
+	NotBefore int64    `json:"nbf"`
+	Scope     string   `json:"scope"`
+}
+
+// audience is either a single string or a list of strings.
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*a = list
+	return nil
+}
+
+type jsonWebKeySet struct {
+	Keys []jsonWebKey `json:"keys"`
+}
+
+type jsonWebKey struct {
+	KeyType string `json:"kty"`
+	KeyId   string `json:"kid"`
+	N       string `json:"n"`
+	E       string `json:"e"`
+}
+
+type jwksTokenValidator struct {
+	httpClient httpDriver.HttpClient
+	jwksUrl    string
+	issuer     string
+	audience   string
+
+	mu          sync.Mutex
+	keys        map[string]*rsa.PublicKey
+	refreshedAt time.Time
+	// refreshing is closed when the fetch of the key set in flight ends, nil when none is.
+	refreshing chan struct{}
+}
+
+type JWKSTokenValidatorConfig struct {
+	HttpClient httpDriver.HttpClient
+	JWKSUrl    string
+	Issuer     string
+	Audience   string
+}
+
+// NewJWKSTokenValidator validates RS256 JWTs against the keys published at the JWKS url,
+// checking the expiration and, when configured, the issuer and the audience.
+func NewJWKSTokenValidator(config JWKSTokenValidatorConfig) TokenValidator {
+	return &jwksTokenValidator{
+		httpClient: config.HttpClient,
+		jwksUrl:    config.JWKSUrl,
+		issuer:     config.Issuer,
+		audience:   config.Audience,
+		keys:       map[string]*rsa.PublicKey{},
+	}
+}
+
+func (j *jwksTokenValidator) ValidateToken(token string) (entities.Principal, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return entities.Principal{}, fmt.Errorf("%w: token is not a jwt", ErrInvalidToken)
+	}
+
+	var header jwtHeader
+	err := decodeSegment(parts[0], &header)
+	if err != nil {
+		return entities.Principal{}, fmt.Errorf("%w: malformed header", ErrInvalidToken)
+	}
+	if header.Algorithm != "RS256" {
+		return entities.Principal{}, fmt.Errorf("%w: algorithm [%s] is not supported", ErrInvalidToken, header.Algorithm)
+	}
+
+	key, err := j.getKey(header.KeyId)
+	if err != nil {
+		return entities.Principal{}, err
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return entities.Principal{}, fmt.Errorf("%w: malformed signature", ErrInvalidToken)
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	err = rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature)
+	if err != nil {
+		return entities.Principal{}, fmt.Errorf("%w: signature does not match", ErrInvalidToken)
+	}
+
+	var claims jwtClaims
+	err = decodeSegment(parts[1], &claims)
+	if err != nil {
+		return entities.Principal{}, fmt.Errorf("%w: malformed claims", ErrInvalidToken)
+	}
+
+	err = j.validateClaims(claims)
+	if err != nil {
+		return entities.Principal{}, err
+	}
+
+	return entities.Principal{
+		Subject: claims.Subject,
+		Scopes:  strings.Fields(claims.Scope),
+	}, nil
+}
+
+func (j *jwksTokenValidator) validateClaims(claims jwtClaims) error {
+	now := time.Now().Unix()
+	if claims.ExpiresAt == 0 || now >= claims.ExpiresAt {
+		return fmt.Errorf("%w: token expired", ErrInvalidToken)
+	}
+	if claims.NotBefore != 0 && now < claims.NotBefore {
+		return fmt.Errorf("%w: token not valid yet", ErrInvalidToken)
+	}
+	if j.issuer != "" && claims.Issuer != j.issuer {
+		return fmt.Errorf("%w: issuer [%s] is not accepted", ErrInvalidToken, claims.Issuer)
+	}
+	if j.audience != "" && !containsString(claims.Audience, j.audience) {
+		return fmt.Errorf("%w: audience is not accepted", ErrInvalidToken)
+	}
+	return nil
+}
+
+// getKey returns the key with the given id, fetching the key set again when the id is unknown,
+// as the issuer may have rotated its keys. The key set is fetched outside the lock, so the known
+// keys are still served meanwhile, and the requests with an unknown key wait for the fetch in
+// flight instead of starting their own.
+func (j *jwksTokenValidator) getKey(keyId string) (*rsa.PublicKey, error) {
+	j.mu.Lock()
+	if key, ok := j.keys[keyId]; ok {
+		j.mu.Unlock()
+		return key, nil
+	}
+	if refreshing := j.refreshing; refreshing != nil {
+		j.mu.Unlock()
+		<-refreshing
+		return j.cachedKey(keyId)
+	}
+	if time.Since(j.refreshedAt) < jwksRefreshInterval {
+		j.mu.Unlock()
+		return nil, fmt.Errorf("%w: key [%s] is unknown", ErrInvalidToken, keyId)
+	}
+
+	refreshing := make(chan struct{})
+	j.refreshing = refreshing
+	j.refreshedAt = time.Now()
+	j.mu.Unlock()
+
+	keys, err := j.fetchKeys()
+
+	j.mu.Lock()
+	if err == nil {
+		j.keys = keys
+	}
+	j.refreshing = nil
+	close(refreshing)
+	j.mu.Unlock()
+
+	if err != nil {
+		return nil, err
+	}
+	return j.cachedKey(keyId)
+}
+
+func (j *jwksTokenValidator) cachedKey(keyId string) (*rsa.PublicKey, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	key, ok := j.keys[keyId]
+	if !ok {
+		return nil, fmt.Errorf("%w: key [%s] is unknown", ErrInvalidToken, keyId)
+	}
+	return key, nil
+}
+
+func (j *jwksTokenValidator) fetchKeys() (map[string]*rsa.PublicKey, error) {
+	response, err := j.httpClient.DoGet(j.jwksUrl)
+	if err != nil {
+		return nil, coreErrors.Wrap(coreErrors.KindUpstreamUnavailable, fmt.Errorf("failed to get jwks, error: %v", err))
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode > 299 || response.StatusCode < 200 {
+		return nil, coreErrors.Wrap(coreErrors.KindUpstreamUnavailable, fmt.Errorf("failed to get jwks, status [%d] non-2xx", response.StatusCode))
+	}
+
+	var keySet jsonWebKeySet
+	err = json.NewDecoder(response.Body).Decode(&keySet)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode jwks, error: %v", err)
+	}
+
+	keys := map[string]*rsa.PublicKey{}
+	for _, jwk := range keySet.Keys {
+		if jwk.KeyType != "RSA" {
+			continue
+		}
+		n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+		if err != nil {
+			continue
+		}
+		e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+		if err != nil {
+			continue
+		}
+		keys[jwk.KeyId] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+
+	return keys, nil
+}
+
+func decodeSegment(segment string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
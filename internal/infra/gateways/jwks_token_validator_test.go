@@ -0,0 +1,166 @@
+package gateways
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/entities"
+	mock_http "github.com/IgorRamosBR/g73-techchallenge-payment/internal/infra/drivers/http/mocks"
+	"github.com/go-playground/assert/v2"
+	"go.uber.org/mock/gomock"
+)
+
+func TestJWKSTokenValidator_ValidateToken(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	httpClient := mock_http.NewMockHttpClient(ctrl)
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now().Unix()
+
+	type want struct {
+		principal entities.Principal
+		err       error
+	}
+	tests := []struct {
+		name  string
+		token string
+		want
+	}{
+		{
+			name:  "should refuse token when it is not a jwt",
+			token: "token",
+			want:  want{err: fmt.Errorf("%w: token is not a jwt", ErrInvalidToken)},
+		},
+		{
+			name:  "should refuse token when the algorithm is not supported",
+			token: signToken(t, key, map[string]any{"alg": "HS256", "kid": "key-1"}, map[string]any{"sub": "client-1", "exp": now + 60}),
+			want:  want{err: fmt.Errorf("%w: algorithm [HS256] is not supported", ErrInvalidToken)},
+		},
+		{
+			name:  "should refuse token when the key is unknown",
+			token: signToken(t, key, map[string]any{"alg": "RS256", "kid": "key-2"}, map[string]any{"sub": "client-1", "exp": now + 60}),
+			want:  want{err: fmt.Errorf("%w: key [key-2] is unknown", ErrInvalidToken)},
+		},
+		{
+			name:  "should refuse token when the signature does not match",
+			token: signToken(t, otherKey, map[string]any{"alg": "RS256", "kid": "key-1"}, map[string]any{"sub": "client-1", "exp": now + 60}),
+			want:  want{err: fmt.Errorf("%w: signature does not match", ErrInvalidToken)},
+		},
+		{
+			name:  "should refuse token when it is expired",
+			token: signToken(t, key, map[string]any{"alg": "RS256", "kid": "key-1"}, map[string]any{"sub": "client-1", "exp": now - 60}),
+			want:  want{err: fmt.Errorf("%w: token expired", ErrInvalidToken)},
+		},
+		{
+			name:  "should refuse token when the issuer is not accepted",
+			token: signToken(t, key, map[string]any{"alg": "RS256", "kid": "key-1"}, map[string]any{"sub": "client-1", "exp": now + 60, "iss": "other", "aud": "payments"}),
+			want:  want{err: fmt.Errorf("%w: issuer [other] is not accepted", ErrInvalidToken)},
+		},
+		{
+			name:  "should refuse token when the audience is not accepted",
+			token: signToken(t, key, map[string]any{"alg": "RS256", "kid": "key-1"}, map[string]any{"sub": "client-1", "exp": now + 60, "iss": "issuer", "aud": []string{"orders"}}),
+			want:  want{err: fmt.Errorf("%w: audience is not accepted", ErrInvalidToken)},
+		},
+		{
+			name:  "should return the principal when the token is valid",
+			token: signToken(t, key, map[string]any{"alg": "RS256", "kid": "key-1"}, map[string]any{"sub": "client-1", "exp": now + 60, "iss": "issuer", "aud": []string{"orders", "payments"}, "scope": "payments:read"}),
+			want:  want{principal: entities.Principal{Subject: "client-1", Scopes: []string{"payments:read"}}},
+		},
+	}
+
+	// the key set is fetched once, the unknown key does not refresh it again within the interval
+	httpClient.EXPECT().DoGet(gomock.Eq("/jwks")).
+		Times(1).
+		Return(&http.Response{StatusCode: 200, Body: io.NopCloser(strings.NewReader(jwks(t, "key-1", &key.PublicKey)))}, nil)
+
+	tokenValidator := NewJWKSTokenValidator(JWKSTokenValidatorConfig{
+		HttpClient: httpClient,
+		JWKSUrl:    "/jwks",
+		Issuer:     "issuer",
+		Audience:   "payments",
+	})
+	for _, tt := range tests {
+		principal, err := tokenValidator.ValidateToken(tt.token)
+
+		assert.Equal(t, tt.want.principal, principal)
+		assert.Equal(t, tt.want.err, err)
+		assert.Equal(t, err == nil || errors.Is(err, ErrInvalidToken), true)
+	}
+}
+
+func TestJWKSTokenValidator_ValidateToken_FailedRefresh(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	httpClient := mock_http.NewMockHttpClient(ctrl)
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := signToken(t, key, map[string]any{"alg": "RS256", "kid": "key-1"}, map[string]any{"sub": "client-1", "exp": time.Now().Unix() + 60})
+
+	// a failed fetch counts as a refresh, so the next tokens do not fetch the key set again
+	httpClient.EXPECT().DoGet(gomock.Eq("/jwks")).
+		Times(1).
+		Return(nil, errors.New("timeout"))
+
+	tokenValidator := NewJWKSTokenValidator(JWKSTokenValidatorConfig{
+		HttpClient: httpClient,
+		JWKSUrl:    "/jwks",
+	})
+
+	_, err = tokenValidator.ValidateToken(token)
+	assert.Equal(t, errors.Is(err, ErrInvalidToken), false)
+
+	_, err = tokenValidator.ValidateToken(token)
+	assert.Equal(t, fmt.Errorf("%w: key [key-1] is unknown", ErrInvalidToken), err)
+}
+
+func signToken(t *testing.T, key *rsa.PrivateKey, header map[string]any, claims map[string]any) string {
+	encode := func(v any) string {
+		data, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+
+	signingInput := encode(header) + "." + encode(claims)
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func jwks(t *testing.T, keyId string, key *rsa.PublicKey) string {
+	data, err := json.Marshal(jsonWebKeySet{Keys: []jsonWebKey{{
+		KeyType: "RSA",
+		KeyId:   keyId,
+		N:       base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		E:       base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
@@ -0,0 +1,55 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: authorizer_token_validator.go
+//
+// Generated by this command:
+//
+//	mockgen -source=authorizer_token_validator.go -destination=mocks/authorizer_token_validator.go
+//
+
+// Package mock_gateways is a generated GoMock package.
+package mock_gateways
+
+import (
+	reflect "reflect"
+
+	entities "github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/entities"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockTokenValidator is a mock of TokenValidator interface.
+type MockTokenValidator struct {
+	ctrl     *gomock.Controller
+	recorder *MockTokenValidatorMockRecorder
+}
+
+// MockTokenValidatorMockRecorder is the mock recorder for MockTokenValidator.
+type MockTokenValidatorMockRecorder struct {
+	mock *MockTokenValidator
+}
+
+// NewMockTokenValidator creates a new mock instance.
+func NewMockTokenValidator(ctrl *gomock.Controller) *MockTokenValidator {
+	mock := &MockTokenValidator{ctrl: ctrl}
+	mock.recorder = &MockTokenValidatorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTokenValidator) EXPECT() *MockTokenValidatorMockRecorder {
+	return m.recorder
+}
+
+// ValidateToken mocks base method.
+func (m *MockTokenValidator) ValidateToken(token string) (entities.Principal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ValidateToken", token)
+	ret0, _ := ret[0].(entities.Principal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ValidateToken indicates an expected call of ValidateToken.
+func (mr *MockTokenValidatorMockRecorder) ValidateToken(token any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ValidateToken", reflect.TypeOf((*MockTokenValidator)(nil).ValidateToken), token)
+}
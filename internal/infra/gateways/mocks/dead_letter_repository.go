@@ -0,0 +1,98 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: dead_letter_repository.go
+//
+// Generated by this command:
+//
+//	mockgen -source=dead_letter_repository.go -destination=mocks/dead_letter_repository.go
+//
+
+// Package mock_gateways is a generated GoMock package.
+package mock_gateways
+
+import (
+	reflect "reflect"
+
+	entities "github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/entities"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockDeadLetterRepositoryGateway is a mock of DeadLetterRepositoryGateway interface.
+type MockDeadLetterRepositoryGateway struct {
+	ctrl     *gomock.Controller
+	recorder *MockDeadLetterRepositoryGatewayMockRecorder
+}
+
+// MockDeadLetterRepositoryGatewayMockRecorder is the mock recorder for MockDeadLetterRepositoryGateway.
+type MockDeadLetterRepositoryGatewayMockRecorder struct {
+	mock *MockDeadLetterRepositoryGateway
+}
+
+// NewMockDeadLetterRepositoryGateway creates a new mock instance.
+func NewMockDeadLetterRepositoryGateway(ctrl *gomock.Controller) *MockDeadLetterRepositoryGateway {
+	mock := &MockDeadLetterRepositoryGateway{ctrl: ctrl}
+	mock.recorder = &MockDeadLetterRepositoryGatewayMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDeadLetterRepositoryGateway) EXPECT() *MockDeadLetterRepositoryGatewayMockRecorder {
+	return m.recorder
+}
+
+// DeleteDeadLetter mocks base method.
+func (m *MockDeadLetterRepositoryGateway) DeleteDeadLetter(messageId string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteDeadLetter", messageId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteDeadLetter indicates an expected call of DeleteDeadLetter.
+func (mr *MockDeadLetterRepositoryGatewayMockRecorder) DeleteDeadLetter(messageId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteDeadLetter", reflect.TypeOf((*MockDeadLetterRepositoryGateway)(nil).DeleteDeadLetter), messageId)
+}
+
+// GetDeadLetter mocks base method.
+func (m *MockDeadLetterRepositoryGateway) GetDeadLetter(messageId string) (entities.DeadLetter, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeadLetter", messageId)
+	ret0, _ := ret[0].(entities.DeadLetter)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeadLetter indicates an expected call of GetDeadLetter.
+func (mr *MockDeadLetterRepositoryGatewayMockRecorder) GetDeadLetter(messageId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeadLetter", reflect.TypeOf((*MockDeadLetterRepositoryGateway)(nil).GetDeadLetter), messageId)
+}
+
+// GetDeadLetters mocks base method.
+func (m *MockDeadLetterRepositoryGateway) GetDeadLetters(deadLetterType entities.DeadLetterType) ([]entities.DeadLetter, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeadLetters", deadLetterType)
+	ret0, _ := ret[0].([]entities.DeadLetter)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeadLetters indicates an expected call of GetDeadLetters.
+func (mr *MockDeadLetterRepositoryGatewayMockRecorder) GetDeadLetters(deadLetterType any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeadLetters", reflect.TypeOf((*MockDeadLetterRepositoryGateway)(nil).GetDeadLetters), deadLetterType)
+}
+
+// SaveDeadLetter mocks base method.
+func (m *MockDeadLetterRepositoryGateway) SaveDeadLetter(deadLetter entities.DeadLetter) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveDeadLetter", deadLetter)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveDeadLetter indicates an expected call of SaveDeadLetter.
+func (mr *MockDeadLetterRepositoryGatewayMockRecorder) SaveDeadLetter(deadLetter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveDeadLetter", reflect.TypeOf((*MockDeadLetterRepositoryGateway)(nil).SaveDeadLetter), deadLetter)
+}
@@ -0,0 +1,97 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: notification_queue.go
+//
+// Generated by this command:
+//
+//	mockgen -source=notification_queue.go -destination=mocks/notification_queue.go
+//
+
+// Package mock_gateways is a generated GoMock package.
+package mock_gateways
+
+import (
+	reflect "reflect"
+
+	entities "github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/entities"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockNotificationQueue is a mock of NotificationQueue interface.
+type MockNotificationQueue struct {
+	ctrl     *gomock.Controller
+	recorder *MockNotificationQueueMockRecorder
+}
+
+// MockNotificationQueueMockRecorder is the mock recorder for MockNotificationQueue.
+type MockNotificationQueueMockRecorder struct {
+	mock *MockNotificationQueue
+}
+
+// NewMockNotificationQueue creates a new mock instance.
+func NewMockNotificationQueue(ctrl *gomock.Controller) *MockNotificationQueue {
+	mock := &MockNotificationQueue{ctrl: ctrl}
+	mock.recorder = &MockNotificationQueueMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockNotificationQueue) EXPECT() *MockNotificationQueueMockRecorder {
+	return m.recorder
+}
+
+// Delete mocks base method.
+func (m *MockNotificationQueue) Delete(message entities.NotificationMessage) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", message)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockNotificationQueueMockRecorder) Delete(message any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockNotificationQueue)(nil).Delete), message)
+}
+
+// Enqueue mocks base method.
+func (m *MockNotificationQueue) Enqueue(message entities.NotificationMessage) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Enqueue", message)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Enqueue indicates an expected call of Enqueue.
+func (mr *MockNotificationQueueMockRecorder) Enqueue(message any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Enqueue", reflect.TypeOf((*MockNotificationQueue)(nil).Enqueue), message)
+}
+
+// Receive mocks base method.
+func (m *MockNotificationQueue) Receive(maxMessages int) ([]entities.NotificationMessage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Receive", maxMessages)
+	ret0, _ := ret[0].([]entities.NotificationMessage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Receive indicates an expected call of Receive.
+func (mr *MockNotificationQueueMockRecorder) Receive(maxMessages any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Receive", reflect.TypeOf((*MockNotificationQueue)(nil).Receive), maxMessages)
+}
+
+// Retry mocks base method.
+func (m *MockNotificationQueue) Retry(message entities.NotificationMessage) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Retry", message)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Retry indicates an expected call of Retry.
+func (mr *MockNotificationQueueMockRecorder) Retry(message any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Retry", reflect.TypeOf((*MockNotificationQueue)(nil).Retry), message)
+}
@@ -13,6 +13,7 @@ import (
 	reflect "reflect"
 
 	entities "github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/entities"
+	dto "github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/usecases/dto"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -39,6 +40,21 @@ func (m *MockOrderClient) EXPECT() *MockOrderClientMockRecorder {
 	return m.recorder
 }
 
+// GetOrder mocks base method.
+func (m *MockOrderClient) GetOrder(orderId int) (dto.OrderDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrder", orderId)
+	ret0, _ := ret[0].(dto.OrderDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrder indicates an expected call of GetOrder.
+func (mr *MockOrderClientMockRecorder) GetOrder(orderId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrder", reflect.TypeOf((*MockOrderClient)(nil).GetOrder), orderId)
+}
+
 // NotifyPaymentOrder mocks base method.
 func (m *MockOrderClient) NotifyPaymentOrder(orderId int, status entities.PaymentStatus) error {
 	m.ctrl.T.Helper()
@@ -0,0 +1,55 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: payment_event_repository.go
+//
+// Generated by this command:
+//
+//	mockgen -source=payment_event_repository.go -destination=mocks/payment_event_repository.go
+//
+
+// Package mock_gateways is a generated GoMock package.
+package mock_gateways
+
+import (
+	reflect "reflect"
+
+	entities "github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/entities"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockPaymentEventRepositoryGateway is a mock of PaymentEventRepositoryGateway interface.
+type MockPaymentEventRepositoryGateway struct {
+	ctrl     *gomock.Controller
+	recorder *MockPaymentEventRepositoryGatewayMockRecorder
+}
+
+// MockPaymentEventRepositoryGatewayMockRecorder is the mock recorder for MockPaymentEventRepositoryGateway.
+type MockPaymentEventRepositoryGatewayMockRecorder struct {
+	mock *MockPaymentEventRepositoryGateway
+}
+
+// NewMockPaymentEventRepositoryGateway creates a new mock instance.
+func NewMockPaymentEventRepositoryGateway(ctrl *gomock.Controller) *MockPaymentEventRepositoryGateway {
+	mock := &MockPaymentEventRepositoryGateway{ctrl: ctrl}
+	mock.recorder = &MockPaymentEventRepositoryGatewayMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPaymentEventRepositoryGateway) EXPECT() *MockPaymentEventRepositoryGatewayMockRecorder {
+	return m.recorder
+}
+
+// GetPaymentEvents mocks base method.
+func (m *MockPaymentEventRepositoryGateway) GetPaymentEvents(orderId int) ([]entities.PaymentEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPaymentEvents", orderId)
+	ret0, _ := ret[0].([]entities.PaymentEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPaymentEvents indicates an expected call of GetPaymentEvents.
+func (mr *MockPaymentEventRepositoryGatewayMockRecorder) GetPaymentEvents(orderId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPaymentEvents", reflect.TypeOf((*MockPaymentEventRepositoryGateway)(nil).GetPaymentEvents), orderId)
+}
@@ -11,9 +11,9 @@ package mock_gateways
 
 import (
 	reflect "reflect"
+	time "time"
 
 	entities "github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/entities"
-	dto "github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/usecases/dto"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -40,30 +40,147 @@ func (m *MockPaymentRepositoryGateway) EXPECT() *MockPaymentRepositoryGatewayMoc
 	return m.recorder
 }
 
+// FlagPaymentOrderForRefund mocks base method.
+func (m *MockPaymentRepositoryGateway) FlagPaymentOrderForRefund(orderId, paymentId int, paymentEvent entities.PaymentEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FlagPaymentOrderForRefund", orderId, paymentId, paymentEvent)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FlagPaymentOrderForRefund indicates an expected call of FlagPaymentOrderForRefund.
+func (mr *MockPaymentRepositoryGatewayMockRecorder) FlagPaymentOrderForRefund(orderId, paymentId, paymentEvent any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FlagPaymentOrderForRefund", reflect.TypeOf((*MockPaymentRepositoryGateway)(nil).FlagPaymentOrderForRefund), orderId, paymentId, paymentEvent)
+}
+
+// GetExpiredPaymentOrders mocks base method.
+func (m *MockPaymentRepositoryGateway) GetExpiredPaymentOrders(now time.Time) ([]entities.PaymentOrder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetExpiredPaymentOrders", now)
+	ret0, _ := ret[0].([]entities.PaymentOrder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetExpiredPaymentOrders indicates an expected call of GetExpiredPaymentOrders.
+func (mr *MockPaymentRepositoryGatewayMockRecorder) GetExpiredPaymentOrders(now any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetExpiredPaymentOrders", reflect.TypeOf((*MockPaymentRepositoryGateway)(nil).GetExpiredPaymentOrders), now)
+}
+
+// GetPaymentOrder mocks base method.
+func (m *MockPaymentRepositoryGateway) GetPaymentOrder(orderId int) (entities.PaymentOrder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPaymentOrder", orderId)
+	ret0, _ := ret[0].(entities.PaymentOrder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPaymentOrder indicates an expected call of GetPaymentOrder.
+func (mr *MockPaymentRepositoryGatewayMockRecorder) GetPaymentOrder(orderId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPaymentOrder", reflect.TypeOf((*MockPaymentRepositoryGateway)(nil).GetPaymentOrder), orderId)
+}
+
+// GetPaymentOrdersByStatus mocks base method.
+func (m *MockPaymentRepositoryGateway) GetPaymentOrdersByStatus(statuses []entities.PaymentStatus, createdBefore time.Time) ([]entities.PaymentOrder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPaymentOrdersByStatus", statuses, createdBefore)
+	ret0, _ := ret[0].([]entities.PaymentOrder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPaymentOrdersByStatus indicates an expected call of GetPaymentOrdersByStatus.
+func (mr *MockPaymentRepositoryGatewayMockRecorder) GetPaymentOrdersByStatus(statuses, createdBefore any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPaymentOrdersByStatus", reflect.TypeOf((*MockPaymentRepositoryGateway)(nil).GetPaymentOrdersByStatus), statuses, createdBefore)
+}
+
+// ReleaseRefund mocks base method.
+func (m *MockPaymentRepositoryGateway) ReleaseRefund(paymentOrder entities.PaymentOrder, index int, status entities.PaymentStatus, paymentEvent entities.PaymentEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseRefund", paymentOrder, index, status, paymentEvent)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReleaseRefund indicates an expected call of ReleaseRefund.
+func (mr *MockPaymentRepositoryGatewayMockRecorder) ReleaseRefund(paymentOrder, index, status, paymentEvent any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseRefund", reflect.TypeOf((*MockPaymentRepositoryGateway)(nil).ReleaseRefund), paymentOrder, index, status, paymentEvent)
+}
+
 // SavePaymentOrder mocks base method.
-func (m *MockPaymentRepositoryGateway) SavePaymentOrder(paymentOrderDTO dto.PaymentOrderDTO, qrCode string) error {
+func (m *MockPaymentRepositoryGateway) SavePaymentOrder(paymentOrder entities.PaymentOrder, paymentEvent entities.PaymentEvent) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SavePaymentOrder", paymentOrderDTO, qrCode)
+	ret := m.ctrl.Call(m, "SavePaymentOrder", paymentOrder, paymentEvent)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // SavePaymentOrder indicates an expected call of SavePaymentOrder.
-func (mr *MockPaymentRepositoryGatewayMockRecorder) SavePaymentOrder(paymentOrderDTO, qrCode any) *gomock.Call {
+func (mr *MockPaymentRepositoryGatewayMockRecorder) SavePaymentOrder(paymentOrder, paymentEvent any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SavePaymentOrder", reflect.TypeOf((*MockPaymentRepositoryGateway)(nil).SavePaymentOrder), paymentOrder, paymentEvent)
+}
+
+// SaveRefund mocks base method.
+func (m *MockPaymentRepositoryGateway) SaveRefund(paymentOrder entities.PaymentOrder, refund entities.Refund, status entities.PaymentStatus, paymentEvent entities.PaymentEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveRefund", paymentOrder, refund, status, paymentEvent)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveRefund indicates an expected call of SaveRefund.
+func (mr *MockPaymentRepositoryGatewayMockRecorder) SaveRefund(paymentOrder, refund, status, paymentEvent any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveRefund", reflect.TypeOf((*MockPaymentRepositoryGateway)(nil).SaveRefund), paymentOrder, refund, status, paymentEvent)
+}
+
+// SearchPaymentOrders mocks base method.
+func (m *MockPaymentRepositoryGateway) SearchPaymentOrders(filter entities.PaymentOrderFilter, limit int, cursor string) ([]entities.PaymentOrder, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchPaymentOrders", filter, limit, cursor)
+	ret0, _ := ret[0].([]entities.PaymentOrder)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// SearchPaymentOrders indicates an expected call of SearchPaymentOrders.
+func (mr *MockPaymentRepositoryGatewayMockRecorder) SearchPaymentOrders(filter, limit, cursor any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchPaymentOrders", reflect.TypeOf((*MockPaymentRepositoryGateway)(nil).SearchPaymentOrders), filter, limit, cursor)
+}
+
+// SettleRefund mocks base method.
+func (m *MockPaymentRepositoryGateway) SettleRefund(orderId, index int, refund entities.Refund, paymentEvent entities.PaymentEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SettleRefund", orderId, index, refund, paymentEvent)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SettleRefund indicates an expected call of SettleRefund.
+func (mr *MockPaymentRepositoryGatewayMockRecorder) SettleRefund(orderId, index, refund, paymentEvent any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SavePaymentOrder", reflect.TypeOf((*MockPaymentRepositoryGateway)(nil).SavePaymentOrder), paymentOrderDTO, qrCode)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SettleRefund", reflect.TypeOf((*MockPaymentRepositoryGateway)(nil).SettleRefund), orderId, index, refund, paymentEvent)
 }
 
-// UpdatePaymentOrderStatus mocks base method.
-func (m *MockPaymentRepositoryGateway) UpdatePaymentOrderStatus(orderId, paymentId int, status entities.PaymentStatus) error {
+// TransitionPaymentOrderStatus mocks base method.
+func (m *MockPaymentRepositoryGateway) TransitionPaymentOrderStatus(orderId, paymentId, merchantOrderId int, from, to entities.PaymentStatus, paymentEvent entities.PaymentEvent) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdatePaymentOrderStatus", orderId, paymentId, status)
+	ret := m.ctrl.Call(m, "TransitionPaymentOrderStatus", orderId, paymentId, merchantOrderId, from, to, paymentEvent)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// UpdatePaymentOrderStatus indicates an expected call of UpdatePaymentOrderStatus.
-func (mr *MockPaymentRepositoryGatewayMockRecorder) UpdatePaymentOrderStatus(orderId, paymentId, status any) *gomock.Call {
+// TransitionPaymentOrderStatus indicates an expected call of TransitionPaymentOrderStatus.
+func (mr *MockPaymentRepositoryGatewayMockRecorder) TransitionPaymentOrderStatus(orderId, paymentId, merchantOrderId, from, to, paymentEvent any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePaymentOrderStatus", reflect.TypeOf((*MockPaymentRepositoryGateway)(nil).UpdatePaymentOrderStatus), orderId, paymentId, status)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TransitionPaymentOrderStatus", reflect.TypeOf((*MockPaymentRepositoryGateway)(nil).TransitionPaymentOrderStatus), orderId, paymentId, merchantOrderId, from, to, paymentEvent)
 }
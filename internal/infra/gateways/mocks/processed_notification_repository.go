@@ -0,0 +1,83 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: processed_notification_repository.go
+//
+// Generated by this command:
+//
+//	mockgen -source=processed_notification_repository.go -destination=mocks/processed_notification_repository.go
+//
+
+// Package mock_gateways is a generated GoMock package.
+package mock_gateways
+
+import (
+	reflect "reflect"
+	time "time"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockProcessedNotificationRepositoryGateway is a mock of ProcessedNotificationRepositoryGateway interface.
+type MockProcessedNotificationRepositoryGateway struct {
+	ctrl     *gomock.Controller
+	recorder *MockProcessedNotificationRepositoryGatewayMockRecorder
+}
+
+// MockProcessedNotificationRepositoryGatewayMockRecorder is the mock recorder for MockProcessedNotificationRepositoryGateway.
+type MockProcessedNotificationRepositoryGatewayMockRecorder struct {
+	mock *MockProcessedNotificationRepositoryGateway
+}
+
+// NewMockProcessedNotificationRepositoryGateway creates a new mock instance.
+func NewMockProcessedNotificationRepositoryGateway(ctrl *gomock.Controller) *MockProcessedNotificationRepositoryGateway {
+	mock := &MockProcessedNotificationRepositoryGateway{ctrl: ctrl}
+	mock.recorder = &MockProcessedNotificationRepositoryGatewayMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockProcessedNotificationRepositoryGateway) EXPECT() *MockProcessedNotificationRepositoryGatewayMockRecorder {
+	return m.recorder
+}
+
+// CompleteNotification mocks base method.
+func (m *MockProcessedNotificationRepositoryGateway) CompleteNotification(notificationId string, expiresAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteNotification", notificationId, expiresAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CompleteNotification indicates an expected call of CompleteNotification.
+func (mr *MockProcessedNotificationRepositoryGatewayMockRecorder) CompleteNotification(notificationId, expiresAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteNotification", reflect.TypeOf((*MockProcessedNotificationRepositoryGateway)(nil).CompleteNotification), notificationId, expiresAt)
+}
+
+// RegisterNotification mocks base method.
+func (m *MockProcessedNotificationRepositoryGateway) RegisterNotification(notificationId string, expiresAt time.Time) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RegisterNotification", notificationId, expiresAt)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RegisterNotification indicates an expected call of RegisterNotification.
+func (mr *MockProcessedNotificationRepositoryGatewayMockRecorder) RegisterNotification(notificationId, expiresAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegisterNotification", reflect.TypeOf((*MockProcessedNotificationRepositoryGateway)(nil).RegisterNotification), notificationId, expiresAt)
+}
+
+// ReleaseNotification mocks base method.
+func (m *MockProcessedNotificationRepositoryGateway) ReleaseNotification(notificationId string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseNotification", notificationId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReleaseNotification indicates an expected call of ReleaseNotification.
+func (mr *MockProcessedNotificationRepositoryGatewayMockRecorder) ReleaseNotification(notificationId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseNotification", reflect.TypeOf((*MockProcessedNotificationRepositoryGateway)(nil).ReleaseNotification), notificationId)
+}
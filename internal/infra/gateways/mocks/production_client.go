@@ -0,0 +1,54 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: production_client.go
+//
+// Generated by this command:
+//
+//	mockgen -source=production_client.go -destination=mocks/production_client.go
+//
+
+// Package mock_gateways is a generated GoMock package.
+package mock_gateways
+
+import (
+	reflect "reflect"
+
+	entities "github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/entities"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockProductionClient is a mock of ProductionClient interface.
+type MockProductionClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockProductionClientMockRecorder
+}
+
+// MockProductionClientMockRecorder is the mock recorder for MockProductionClient.
+type MockProductionClientMockRecorder struct {
+	mock *MockProductionClient
+}
+
+// NewMockProductionClient creates a new mock instance.
+func NewMockProductionClient(ctrl *gomock.Controller) *MockProductionClient {
+	mock := &MockProductionClient{ctrl: ctrl}
+	mock.recorder = &MockProductionClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockProductionClient) EXPECT() *MockProductionClientMockRecorder {
+	return m.recorder
+}
+
+// SubmitProductionOrder mocks base method.
+func (m *MockProductionClient) SubmitProductionOrder(productionOrder entities.ProductionOrder) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SubmitProductionOrder", productionOrder)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SubmitProductionOrder indicates an expected call of SubmitProductionOrder.
+func (mr *MockProductionClientMockRecorder) SubmitProductionOrder(productionOrder any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubmitProductionOrder", reflect.TypeOf((*MockProductionClient)(nil).SubmitProductionOrder), productionOrder)
+}
@@ -0,0 +1,166 @@
+package gateways
+
+import (
+	"errors"
+	"time"
+
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/entities"
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/infra/drivers/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// NotificationQueue holds the accepted broker notifications until they are processed. A received
+// message is hidden from other receivers for the visibility timeout, and becomes visible again
+// unless it is deleted or scheduled for a retry.
+type NotificationQueue interface {
+	Enqueue(message entities.NotificationMessage) error
+	Receive(maxMessages int) ([]entities.NotificationMessage, error)
+	Delete(message entities.NotificationMessage) error
+	Retry(message entities.NotificationMessage) error
+}
+
+const (
+	// notificationQueueIndex sorts the messages of the queue by VisibleAt, all under the same
+	// Queue partition, so the visible ones are read without scanning the table.
+	notificationQueueIndex     = "Queue-VisibleAt-index"
+	notificationQueuePartition = "notifications"
+)
+
+type dynamoDBNotificationQueue struct {
+	notificationQueueTable string
+	visibilityTimeout      time.Duration
+	dynamodbClient         dynamodb.DynamoDBClient
+}
+
+func NewDynamoDBNotificationQueue(dynamodbClient dynamodb.DynamoDBClient, notificationQueueTable string, visibilityTimeout time.Duration) NotificationQueue {
+	return dynamoDBNotificationQueue{
+		dynamodbClient:         dynamodbClient,
+		notificationQueueTable: notificationQueueTable,
+		visibilityTimeout:      visibilityTimeout,
+	}
+}
+
+func (q dynamoDBNotificationQueue) Enqueue(message entities.NotificationMessage) error {
+	message, err := prepareNotificationMessage(message)
+	if err != nil {
+		return err
+	}
+
+	av, err := attributevalue.MarshalMap(message)
+	if err != nil {
+		return err
+	}
+	av["Queue"] = &types.AttributeValueMemberS{Value: notificationQueuePartition}
+
+	return q.dynamodbClient.PutItem(q.notificationQueueTable, av)
+}
+
+// Receive claims up to maxMessages visible messages, the ones visible the longest first. Each
+// message is claimed with a conditional update, so a message received by another replica in the
+// meantime is skipped.
+func (q dynamoDBNotificationQueue) Receive(maxMessages int) ([]entities.NotificationMessage, error) {
+	now := time.Now()
+	keyCondition := expression.Key("Queue").Equal(expression.Value(notificationQueuePartition)).
+		And(expression.Key("VisibleAt").LessThanEqual(expression.Value(now.Unix())))
+	expr, err := expression.NewBuilder().WithKeyCondition(keyCondition).Build()
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := q.dynamodbClient.QueryPage(dynamodb.QueryInput{
+		TableName:  q.notificationQueueTable,
+		IndexName:  notificationQueueIndex,
+		Expression: expr,
+		Limit:      int32(maxMessages),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var visibleMessages []entities.NotificationMessage
+	err = attributevalue.UnmarshalListOfMaps(page.Items, &visibleMessages)
+	if err != nil {
+		return nil, err
+	}
+
+	messages := []entities.NotificationMessage{}
+	for _, message := range visibleMessages {
+		if len(messages) == maxMessages {
+			break
+		}
+
+		claimed, err := q.claim(message, now.Add(q.visibilityTimeout))
+		if err != nil {
+			return messages, err
+		}
+		if claimed {
+			messages = append(messages, message)
+		}
+	}
+
+	return messages, nil
+}
+
+func (q dynamoDBNotificationQueue) claim(message entities.NotificationMessage, visibleAt time.Time) (bool, error) {
+	update := expression.Set(expression.Name("VisibleAt"), expression.Value(visibleAt.Unix()))
+	condition := expression.Name("VisibleAt").Equal(expression.Value(message.VisibleAt.Unix()))
+	expr, err := expression.NewBuilder().WithUpdate(update).WithCondition(condition).Build()
+	if err != nil {
+		return false, err
+	}
+
+	err = q.dynamodbClient.UpdateItem(q.notificationQueueTable, q.messageKey(message.MessageId), expr)
+	if err != nil {
+		var conditionalCheckFailed *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionalCheckFailed) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (q dynamoDBNotificationQueue) Delete(message entities.NotificationMessage) error {
+	return q.dynamodbClient.DeleteItem(q.notificationQueueTable, q.messageKey(message.MessageId))
+}
+
+// Retry stores the attempt count and last error of the message, which becomes visible again at
+// message.VisibleAt.
+func (q dynamoDBNotificationQueue) Retry(message entities.NotificationMessage) error {
+	update := expression.Set(expression.Name("Attempts"), expression.Value(message.Attempts)).
+		Set(expression.Name("LastError"), expression.Value(message.LastError)).
+		Set(expression.Name("VisibleAt"), expression.Value(message.VisibleAt.Unix()))
+	expr, err := expression.NewBuilder().WithUpdate(update).Build()
+	if err != nil {
+		return err
+	}
+
+	return q.dynamodbClient.UpdateItem(q.notificationQueueTable, q.messageKey(message.MessageId), expr)
+}
+
+func (q dynamoDBNotificationQueue) messageKey(messageId string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"MessageId": &types.AttributeValueMemberS{Value: messageId},
+	}
+}
+
+func prepareNotificationMessage(message entities.NotificationMessage) (entities.NotificationMessage, error) {
+	if message.CreatedAt.IsZero() {
+		message.CreatedAt = time.Now()
+	}
+	if message.VisibleAt.IsZero() {
+		message.VisibleAt = message.CreatedAt
+	}
+	if message.MessageId == "" {
+		messageId, err := newSortableId(message.CreatedAt)
+		if err != nil {
+			return entities.NotificationMessage{}, err
+		}
+		message.MessageId = messageId
+	}
+
+	return message, nil
+}
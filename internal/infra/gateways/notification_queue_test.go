@@ -0,0 +1,170 @@
+package gateways
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/entities"
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/infra/drivers/dynamodb"
+	mock_dynamodb "github.com/IgorRamosBR/g73-techchallenge-payment/internal/infra/drivers/dynamodb/mocks"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/go-playground/assert/v2"
+	"go.uber.org/mock/gomock"
+)
+
+func TestDynamoDBNotificationQueue_Receive(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	dynamodbClient := mock_dynamodb.NewMockDynamoDBClient(ctrl)
+
+	visibleAt := time.Unix(1714564800, 0)
+	firstMessage := entities.NotificationMessage{MessageId: "1", Topic: "payment", ResourceId: 7890, CreatedAt: visibleAt, VisibleAt: visibleAt}
+	secondMessage := entities.NotificationMessage{MessageId: "2", Topic: "payment", ResourceId: 7891, CreatedAt: visibleAt, VisibleAt: visibleAt}
+	firstItem, _ := attributevalue.MarshalMap(firstMessage)
+	secondItem, _ := attributevalue.MarshalMap(secondMessage)
+
+	type args struct {
+		maxMessages int
+	}
+	type want struct {
+		messages []entities.NotificationMessage
+		err      error
+	}
+	type queryCall struct {
+		times int
+		items []map[string]types.AttributeValue
+		err   error
+	}
+	type claimCall struct {
+		errs []error
+	}
+	tests := []struct {
+		name string
+		args
+		want
+		queryCall
+		claimCall
+	}{
+		{
+			name: "should fail to receive messages when dynamodb client returns error",
+			args: args{
+				maxMessages: 2,
+			},
+			want: want{
+				err: errors.New("internal error"),
+			},
+			queryCall: queryCall{
+				times: 1,
+				err:   errors.New("internal error"),
+			},
+		},
+		{
+			name: "should skip messages claimed by another receiver",
+			args: args{
+				maxMessages: 2,
+			},
+			want: want{
+				messages: []entities.NotificationMessage{secondMessage},
+			},
+			queryCall: queryCall{
+				times: 1,
+				items: []map[string]types.AttributeValue{firstItem, secondItem},
+			},
+			claimCall: claimCall{
+				errs: []error{&types.ConditionalCheckFailedException{}, nil},
+			},
+		},
+		{
+			name: "should receive up to the maximum number of messages",
+			args: args{
+				maxMessages: 1,
+			},
+			want: want{
+				messages: []entities.NotificationMessage{firstMessage},
+			},
+			queryCall: queryCall{
+				times: 1,
+				items: []map[string]types.AttributeValue{firstItem, secondItem},
+			},
+			claimCall: claimCall{
+				errs: []error{nil},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		dynamodbClient.EXPECT().
+			QueryPage(gomock.Cond(func(x any) bool {
+				input := x.(dynamodb.QueryInput)
+				return input.TableName == "NotificationQueue" && input.IndexName == notificationQueueIndex && input.Limit == int32(tt.args.maxMessages)
+			})).
+			Times(tt.queryCall.times).
+			Return(dynamodb.Page{Items: tt.queryCall.items}, tt.queryCall.err)
+
+		for _, err := range tt.claimCall.errs {
+			dynamodbClient.EXPECT().
+				UpdateItem(gomock.Eq("NotificationQueue"), gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(err)
+		}
+
+		notificationQueue := NewDynamoDBNotificationQueue(dynamodbClient, "NotificationQueue", time.Minute)
+		messages, err := notificationQueue.Receive(tt.args.maxMessages)
+
+		assert.Equal(t, tt.want.messages, messages)
+		assert.Equal(t, tt.want.err, err)
+	}
+}
+
+func TestDynamoDBNotificationQueue_Enqueue(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	dynamodbClient := mock_dynamodb.NewMockDynamoDBClient(ctrl)
+
+	dynamodbClient.EXPECT().
+		PutItem(gomock.Eq("NotificationQueue"), gomock.Cond(func(x any) bool {
+			item := x.(map[string]types.AttributeValue)
+			return assert.IsEqual(item["Queue"], &types.AttributeValueMemberS{Value: notificationQueuePartition}) && item["VisibleAt"] != nil
+		})).
+		Times(1).
+		Return(nil)
+
+	notificationQueue := NewDynamoDBNotificationQueue(dynamodbClient, "NotificationQueue", time.Minute)
+	err := notificationQueue.Enqueue(entities.NotificationMessage{Topic: "payment", ResourceId: 7890})
+
+	assert.Equal(t, nil, err)
+}
+
+func TestInMemoryNotificationQueue(t *testing.T) {
+	notificationQueue := NewInMemoryNotificationQueue(time.Minute)
+
+	err := notificationQueue.Enqueue(entities.NotificationMessage{Topic: "payment", ResourceId: 7890})
+	assert.Equal(t, nil, err)
+
+	messages, err := notificationQueue.Receive(10)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 1, len(messages))
+	assert.NotEqual(t, "", messages[0].MessageId)
+
+	// a received message stays hidden until the visibility timeout
+	hiddenMessages, _ := notificationQueue.Receive(10)
+	assert.Equal(t, 0, len(hiddenMessages))
+
+	message := messages[0]
+	message.Attempts = 1
+	message.VisibleAt = time.Now()
+	err = notificationQueue.Retry(message)
+	assert.Equal(t, nil, err)
+
+	retriedMessages, _ := notificationQueue.Receive(10)
+	assert.Equal(t, 1, len(retriedMessages))
+	assert.Equal(t, 1, retriedMessages[0].Attempts)
+
+	err = notificationQueue.Delete(message)
+	assert.Equal(t, nil, err)
+
+	message.VisibleAt = time.Now()
+	_ = notificationQueue.Retry(message)
+	deletedMessages, _ := notificationQueue.Receive(10)
+	assert.Equal(t, 0, len(deletedMessages))
+}
@@ -2,23 +2,60 @@ package gateways
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
 
 	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/entities"
+	coreErrors "github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/errors"
 	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/usecases/dto"
-	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/infra/drivers/http"
+	httpDriver "github.com/IgorRamosBR/g73-techchallenge-payment/internal/infra/drivers/http"
 )
 
 type OrderClient interface {
 	NotifyPaymentOrder(orderId int, status entities.PaymentStatus) error
+	GetOrder(orderId int) (dto.OrderDTO, error)
+}
+
+var ErrOrderNotFound = coreErrors.New(coreErrors.KindNotFound, "order not found")
+
+// OrderApiError is returned when the order api answers with a non-2xx status.
+type OrderApiError struct {
+	StatusCode int
+}
+
+func (e *OrderApiError) Error() string {
+	return fmt.Sprintf("failed to call order api, status [%d] non-2xx", e.StatusCode)
+}
+
+func (e *OrderApiError) Kind() coreErrors.Kind {
+	return coreErrors.UpstreamStatusKind(e.StatusCode)
+}
+
+// IsPermanent tells whether the order api refused the payment of the order, on 409, 410 or 422,
+// so sending it again cannot succeed. Any other status may be a fault of the call itself.
+func (e *OrderApiError) IsPermanent() bool {
+	switch e.StatusCode {
+	case http.StatusConflict, http.StatusGone, http.StatusUnprocessableEntity:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsPermanentOrderApiError tells whether err is an order api answer that must not be retried.
+// Any other error, such as a network failure, is considered transient.
+func IsPermanentOrderApiError(err error) bool {
+	var orderApiError *OrderApiError
+	return errors.As(err, &orderApiError) && orderApiError.IsPermanent()
 }
 
 type orderClient struct {
-	httpClient  http.HttpClient
+	httpClient  httpDriver.HttpClient
 	orderApiUrl string
 }
 
-func NewOrderClient(httpClient http.HttpClient, orderApiUrl string) OrderClient {
+func NewOrderClient(httpClient httpDriver.HttpClient, orderApiUrl string) OrderClient {
 	return orderClient{
 		httpClient:  httpClient,
 		orderApiUrl: orderApiUrl,
@@ -35,12 +72,35 @@ func (o orderClient) NotifyPaymentOrder(orderId int, status entities.PaymentStat
 
 	response, err := o.httpClient.DoPut(fmt.Sprintf("%s/%d/status", o.orderApiUrl, orderId), reqBody)
 	if err != nil {
-		return fmt.Errorf("failed to call order api, error: %v", err)
+		return coreErrors.Wrap(coreErrors.KindUpstreamUnavailable, fmt.Errorf("failed to call order api, error: %v", err))
 	}
 
 	if response.StatusCode > 299 || response.StatusCode < 200 {
-		return fmt.Errorf("failed to call order api, status [%d] non-2xx", response.StatusCode)
+		return &OrderApiError{StatusCode: response.StatusCode}
 	}
 
 	return nil
 }
+
+func (o orderClient) GetOrder(orderId int) (dto.OrderDTO, error) {
+	response, err := o.httpClient.DoGet(fmt.Sprintf("%s/%d", o.orderApiUrl, orderId))
+	if err != nil {
+		return dto.OrderDTO{}, coreErrors.Wrap(coreErrors.KindUpstreamUnavailable, fmt.Errorf("failed to call order api, error: %v", err))
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusNotFound {
+		return dto.OrderDTO{}, ErrOrderNotFound
+	}
+	if response.StatusCode > 299 || response.StatusCode < 200 {
+		return dto.OrderDTO{}, &OrderApiError{StatusCode: response.StatusCode}
+	}
+
+	var order dto.OrderDTO
+	err = json.NewDecoder(response.Body).Decode(&order)
+	if err != nil {
+		return dto.OrderDTO{}, fmt.Errorf("failed to decode order response, error: %v", err)
+	}
+
+	return order, nil
+}
@@ -2,10 +2,14 @@ package gateways
 
 import (
 	"errors"
+	"io"
 	"net/http"
+	"strings"
 	"testing"
 
 	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/entities"
+	coreErrors "github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/errors"
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/usecases/dto"
 	mock_http "github.com/IgorRamosBR/g73-techchallenge-payment/internal/infra/drivers/http/mocks"
 	"github.com/go-playground/assert/v2"
 	"go.uber.org/mock/gomock"
@@ -41,7 +45,7 @@ func TestOrderClient_NotifyPaymentOrder(t *testing.T) {
 				status:  entities.PaymentStatusPending,
 			},
 			want: want{
-				errors.New("failed to call order api, error: internal error"),
+				coreErrors.Wrap(coreErrors.KindUpstreamUnavailable, errors.New("failed to call order api, error: internal error")),
 			},
 			clientCall: clientCall{
 				orderApiUrl: "/order/123/status",
@@ -57,7 +61,7 @@ func TestOrderClient_NotifyPaymentOrder(t *testing.T) {
 				status:  entities.PaymentStatusPending,
 			},
 			want: want{
-				errors.New("failed to call order api, status [500] non-2xx"),
+				&OrderApiError{StatusCode: 500},
 			},
 			clientCall: clientCall{
 				orderApiUrl: "/order/123/status",
@@ -99,3 +103,105 @@ func TestOrderClient_NotifyPaymentOrder(t *testing.T) {
 		assert.Equal(t, tt.want.err, err)
 	}
 }
+
+func TestIsPermanentOrderApiError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "should be permanent when the order api rejects the request", err: &OrderApiError{StatusCode: 409}, want: true},
+		{name: "should be permanent when the order is gone", err: &OrderApiError{StatusCode: 410}, want: true},
+		{name: "should be permanent when the order api refuses the status", err: &OrderApiError{StatusCode: 422}, want: true},
+		{name: "should be transient when the order is not found", err: &OrderApiError{StatusCode: 404}, want: false},
+		{name: "should be transient when the request is malformed", err: &OrderApiError{StatusCode: 400}, want: false},
+		{name: "should be transient when the order api times out", err: &OrderApiError{StatusCode: 408}, want: false},
+		{name: "should be transient when the order api throttles the request", err: &OrderApiError{StatusCode: 429}, want: false},
+		{name: "should be transient when the order api fails", err: &OrderApiError{StatusCode: 503}, want: false},
+		{name: "should be transient when the order api cannot be reached", err: errors.New("failed to call order api, error: timeout"), want: false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, IsPermanentOrderApiError(tt.err))
+	}
+}
+
+func TestOrderClient_GetOrder(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	httpClient := mock_http.NewMockHttpClient(ctrl)
+
+	type want struct {
+		order dto.OrderDTO
+		err   error
+	}
+	type clientCall struct {
+		response *http.Response
+		err      error
+	}
+	tests := []struct {
+		name string
+		want
+		clientCall
+	}{
+		{
+			name: "should fail to get order when http client returns error",
+			want: want{
+				err: coreErrors.Wrap(coreErrors.KindUpstreamUnavailable, errors.New("failed to call order api, error: internal error")),
+			},
+			clientCall: clientCall{
+				response: &http.Response{},
+				err:      errors.New("internal error"),
+			},
+		},
+		{
+			name: "should return not found when the order does not exist",
+			want: want{
+				err: ErrOrderNotFound,
+			},
+			clientCall: clientCall{
+				response: &http.Response{StatusCode: 404, Body: io.NopCloser(strings.NewReader(""))},
+			},
+		},
+		{
+			name: "should fail to get order when response is non-2xx",
+			want: want{
+				err: &OrderApiError{StatusCode: 500},
+			},
+			clientCall: clientCall{
+				response: &http.Response{StatusCode: 500, Body: io.NopCloser(strings.NewReader(""))},
+			},
+		},
+		{
+			name: "should get the order",
+			want: want{
+				order: dto.OrderDTO{
+					Id:          123,
+					CustomerCPF: "111222333444",
+					Items: []dto.PaymentOrderItem{
+						{Quantity: 1, Product: dto.OrderItemProduct{Name: "Batata frita", SkuId: "333", Price: 9.99}},
+					},
+					TotalAmount: 9.99,
+					Status:      "CREATED",
+				},
+			},
+			clientCall: clientCall{
+				response: &http.Response{
+					StatusCode: 200,
+					Body:       io.NopCloser(strings.NewReader(`{"id":123,"customerCpf":"111222333444","items":[{"quantity":1,"product":{"name":"Batata frita","skuId":"333","price":9.99}}],"totalAmount":9.99,"status":"CREATED"}`)),
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		httpClient.EXPECT().DoGet(gomock.Eq("/order/123")).
+			Times(1).
+			Return(tt.clientCall.response, tt.clientCall.err)
+
+		orderClient := NewOrderClient(httpClient, "/order")
+		order, err := orderClient.GetOrder(123)
+
+		assert.Equal(t, tt.want.order, order)
+		assert.Equal(t, tt.want.err, err)
+	}
+}
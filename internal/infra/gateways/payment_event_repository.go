@@ -0,0 +1,79 @@
+package gateways
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/entities"
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/infra/drivers/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// PaymentEventRepositoryGateway reads the payment order history. The events are written by the
+// payment repository, along with the changes they record.
+type PaymentEventRepositoryGateway interface {
+	GetPaymentEvents(orderId int) ([]entities.PaymentEvent, error)
+}
+
+type paymentEventRepositoryGateway struct {
+	paymentEventTable string
+	dynamodbClient    dynamodb.DynamoDBClient
+}
+
+func NewPaymentEventRepositoryGateway(dynamodbClient dynamodb.DynamoDBClient, paymentEventTable string) PaymentEventRepositoryGateway {
+	return paymentEventRepositoryGateway{
+		dynamodbClient:    dynamodbClient,
+		paymentEventTable: paymentEventTable,
+	}
+}
+
+func (p paymentEventRepositoryGateway) GetPaymentEvents(orderId int) ([]entities.PaymentEvent, error) {
+	keyCondition := expression.Key("OrderId").Equal(expression.Value(orderId))
+	expr, err := expression.NewBuilder().WithKeyCondition(keyCondition).Build()
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := p.dynamodbClient.Query(p.paymentEventTable, expr)
+	if err != nil {
+		return nil, err
+	}
+
+	paymentEvents := []entities.PaymentEvent{}
+	err = attributevalue.UnmarshalListOfMaps(items, &paymentEvents)
+	if err != nil {
+		return nil, err
+	}
+
+	return paymentEvents, nil
+}
+
+// newPaymentEventItem is the item of the event in the payment event table. Events are keyed by
+// OrderId and by an EventId that sorts in creation order.
+func newPaymentEventItem(paymentEvent entities.PaymentEvent) (map[string]types.AttributeValue, error) {
+	if paymentEvent.EventId == "" {
+		eventId, err := newSortableId(paymentEvent.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		paymentEvent.EventId = eventId
+	}
+
+	return attributevalue.MarshalMap(paymentEvent)
+}
+
+// newSortableId returns an id that sorts by the given time, with a random suffix to tell apart
+// ids created at the same instant.
+func newSortableId(createdAt time.Time) (string, error) {
+	suffix := make([]byte, 4)
+	_, err := rand.Read(suffix)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%019d-%s", createdAt.UnixNano(), hex.EncodeToString(suffix)), nil
+}
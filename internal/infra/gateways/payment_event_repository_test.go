@@ -0,0 +1,79 @@
+package gateways
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/entities"
+	mock_dynamodb "github.com/IgorRamosBR/g73-techchallenge-payment/internal/infra/drivers/dynamodb/mocks"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/go-playground/assert/v2"
+	"go.uber.org/mock/gomock"
+)
+
+func TestPaymentEventRepository_GetPaymentEvents(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	dynamodbClient := mock_dynamodb.NewMockDynamoDBClient(ctrl)
+
+	createdAt := time.Unix(1714564800, 0)
+	paymentEvent := entities.PaymentEvent{
+		OrderId:   123,
+		EventId:   "1714564800000000000-a1b2c3d4",
+		Type:      entities.PaymentEventTypeCreated,
+		ToStatus:  entities.PaymentStatusPending,
+		Source:    entities.PaymentEventSourceApi,
+		CreatedAt: createdAt,
+	}
+	item, _ := attributevalue.MarshalMap(paymentEvent)
+
+	type want struct {
+		paymentEvents []entities.PaymentEvent
+		err           error
+	}
+	type dynamodbCall struct {
+		times int
+		items []map[string]types.AttributeValue
+		err   error
+	}
+	tests := []struct {
+		name string
+		want
+		dynamodbCall
+	}{
+		{
+			name: "should fail to get payment events when dynamodb client returns error",
+			want: want{
+				err: errors.New("internal error"),
+			},
+			dynamodbCall: dynamodbCall{
+				times: 1,
+				err:   errors.New("internal error"),
+			},
+		},
+		{
+			name: "should get payment events",
+			want: want{
+				paymentEvents: []entities.PaymentEvent{paymentEvent},
+			},
+			dynamodbCall: dynamodbCall{
+				times: 1,
+				items: []map[string]types.AttributeValue{item},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		dynamodbClient.EXPECT().
+			Query(gomock.Eq("PaymentEvent"), gomock.Any()).
+			Times(tt.dynamodbCall.times).
+			Return(tt.dynamodbCall.items, tt.dynamodbCall.err)
+
+		paymentEventRepository := NewPaymentEventRepositoryGateway(dynamodbClient, "PaymentEvent")
+		paymentEvents, err := paymentEventRepository.GetPaymentEvents(123)
+
+		assert.Equal(t, tt.want.paymentEvents, paymentEvents)
+		assert.Equal(t, tt.want.err, err)
+	}
+}
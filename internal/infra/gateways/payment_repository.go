@@ -1,64 +1,501 @@
 package gateways
 
 import (
+	"errors"
+	"fmt"
+	"math"
 	"strconv"
+	"time"
 
 	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/entities"
-	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/usecases/dto"
+	coreErrors "github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/errors"
 	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/infra/drivers/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 )
 
+var (
+	ErrPaymentOrderNotFound       = coreErrors.New(coreErrors.KindNotFound, "payment order not found")
+	ErrPaymentOrderStatusConflict = coreErrors.New(coreErrors.KindConflict, "payment order is not in the expected status")
+	ErrInvalidPaymentOrderCursor  = coreErrors.New(coreErrors.KindValidation, "payment order cursor is invalid")
+)
+
+// the payment table indexes, both sorted by the creation time
+const (
+	paymentStatusIndex      = "Status-CreatedAt-index"
+	paymentCustomerCPFIndex = "CustomerCPF-CreatedAt-index"
+)
+
+// paymentSearchMaxReads bounds the requests made to fill a page of search results, so a filter
+// matching few payment orders does not read the whole table at once. The page is then shorter,
+// and the cursor resumes from where the reading stopped.
+const paymentSearchMaxReads = 10
+
+// PaymentRepositoryGateway writes every change of a payment order in a single transaction with the
+// event that records it in the payment order history, so no change is left out of the history.
 type PaymentRepositoryGateway interface {
-	SavePaymentOrder(paymentOrderDTO dto.PaymentOrderDTO, qrCode string) error
-	UpdatePaymentOrderStatus(orderId, paymentId int, status entities.PaymentStatus) error
+	GetPaymentOrder(orderId int) (entities.PaymentOrder, error)
+	SavePaymentOrder(paymentOrder entities.PaymentOrder, paymentEvent entities.PaymentEvent) error
+	TransitionPaymentOrderStatus(orderId, paymentId, merchantOrderId int, from, to entities.PaymentStatus, paymentEvent entities.PaymentEvent) error
+	GetExpiredPaymentOrders(now time.Time) ([]entities.PaymentOrder, error)
+	GetPaymentOrdersByStatus(statuses []entities.PaymentStatus, createdBefore time.Time) ([]entities.PaymentOrder, error)
+	SaveRefund(paymentOrder entities.PaymentOrder, refund entities.Refund, status entities.PaymentStatus, paymentEvent entities.PaymentEvent) error
+	SettleRefund(orderId, index int, refund entities.Refund, paymentEvent entities.PaymentEvent) error
+	ReleaseRefund(paymentOrder entities.PaymentOrder, index int, status entities.PaymentStatus, paymentEvent entities.PaymentEvent) error
+	FlagPaymentOrderForRefund(orderId, paymentId int, paymentEvent entities.PaymentEvent) error
+	// SearchPaymentOrders lists a page of the payment orders matching the filter, the most recent
+	// first when filtering by status or customer, resuming from the cursor of the previous page.
+	// The cursor of the next page is empty on the last one.
+	SearchPaymentOrders(filter entities.PaymentOrderFilter, limit int, cursor string) ([]entities.PaymentOrder, string, error)
 }
 
 type paymentRepositoryGateway struct {
-	paymentTable   string
-	dynamodbClient dynamodb.DynamoDBClient
+	paymentTable      string
+	paymentEventTable string
+	dynamodbClient    dynamodb.DynamoDBClient
 }
 
-func NewPaymentRepositoryGateway(dynamodbClient dynamodb.DynamoDBClient, paymentTable string) PaymentRepositoryGateway {
+func NewPaymentRepositoryGateway(dynamodbClient dynamodb.DynamoDBClient, paymentTable, paymentEventTable string) PaymentRepositoryGateway {
 	return paymentRepositoryGateway{
-		dynamodbClient: dynamodbClient,
-		paymentTable:   paymentTable,
+		dynamodbClient:    dynamodbClient,
+		paymentTable:      paymentTable,
+		paymentEventTable: paymentEventTable,
 	}
 }
 
-func (p paymentRepositoryGateway) SavePaymentOrder(paymentOrderDTO dto.PaymentOrderDTO, qrCode string) error {
-	paymentOrder := paymentOrderDTO.ToPaymentOrder(qrCode)
+func (p paymentRepositoryGateway) GetPaymentOrder(orderId int) (entities.PaymentOrder, error) {
+	item, err := p.dynamodbClient.GetItem(p.paymentTable, p.paymentOrderKey(orderId))
+	if err != nil {
+		return entities.PaymentOrder{}, err
+	}
+	if len(item) == 0 {
+		return entities.PaymentOrder{}, ErrPaymentOrderNotFound
+	}
 
+	var paymentOrder entities.PaymentOrder
+	err = attributevalue.UnmarshalMap(item, &paymentOrder)
+	if err != nil {
+		return entities.PaymentOrder{}, err
+	}
+
+	return paymentOrder, nil
+}
+
+func (p paymentRepositoryGateway) SavePaymentOrder(paymentOrder entities.PaymentOrder, paymentEvent entities.PaymentEvent) error {
 	av, err := attributevalue.MarshalMap(paymentOrder)
 	if err != nil {
 		return err
 	}
 
-	err = p.dynamodbClient.PutItem(p.paymentTable, av)
+	return p.writePaymentOrderChange(dynamodb.TransactWriteItem{
+		Action:    dynamodb.TransactPut,
+		TableName: p.paymentTable,
+		Item:      av,
+	}, paymentEvent)
+}
+
+// TransitionPaymentOrderStatus only updates the status when the payment order is still in the
+// [from] status, returning ErrPaymentOrderStatusConflict otherwise. The paymentId and the
+// merchantOrderId are stored when they are known.
+func (p paymentRepositoryGateway) TransitionPaymentOrderStatus(orderId, paymentId, merchantOrderId int, from, to entities.PaymentStatus, paymentEvent entities.PaymentEvent) error {
+	key := p.paymentOrderKey(orderId)
+	update := statusUpdate(to, time.Now())
+	if paymentId != 0 {
+		update.Set(expression.Name("PaymentId"), expression.Value(paymentId))
+	}
+	if merchantOrderId != 0 {
+		update.Set(expression.Name("MerchantOrderId"), expression.Value(merchantOrderId))
+	}
+	condition := expression.Name("Status").Equal(expression.Value(from))
+	expr, err := expression.NewBuilder().WithUpdate(update).WithCondition(condition).Build()
+	if err != nil {
+		return err
+	}
+
+	return p.writePaymentOrderUpdate(key, expr, paymentEvent)
+}
+
+// SaveRefund appends the refund to the payment order and moves it to the status, as long as no
+// other refund was saved since the payment order was read, so the same amount is not refunded twice.
+func (p paymentRepositoryGateway) SaveRefund(paymentOrder entities.PaymentOrder, refund entities.Refund, status entities.PaymentStatus, paymentEvent entities.PaymentEvent) error {
+	refundedAmount := math.Round((paymentOrder.RefundedAmount+refund.Amount)*100) / 100
+
+	update := statusUpdate(status, time.Now()).
+		Set(expression.Name("RefundedAmount"), expression.Value(refundedAmount)).
+		Set(expression.Name("Refunds"), expression.ListAppend(
+			expression.IfNotExists(expression.Name("Refunds"), expression.Value([]entities.Refund{})),
+			expression.Value([]entities.Refund{refund}),
+		))
+	condition := expression.Name("Status").Equal(expression.Value(paymentOrder.Status)).
+		And(expression.Or(
+			expression.Name("RefundedAmount").AttributeNotExists(),
+			expression.Name("RefundedAmount").Equal(expression.Value(paymentOrder.RefundedAmount)),
+		))
+	expr, err := expression.NewBuilder().WithUpdate(update).WithCondition(condition).Build()
 	if err != nil {
 		return err
 	}
 
-	return nil
+	return p.writePaymentOrderUpdate(p.paymentOrderKey(paymentOrder.OrderId), expr, paymentEvent)
 }
 
-func (p paymentRepositoryGateway) UpdatePaymentOrderStatus(orderId, paymentId int, status entities.PaymentStatus) error {
-	key := map[string]types.AttributeValue{
-		"OrderId": &types.AttributeValueMemberN{Value: strconv.Itoa(orderId)},
+// SettleRefund replaces the refund reserved at the index with the one answered by the broker.
+func (p paymentRepositoryGateway) SettleRefund(orderId, index int, refund entities.Refund, paymentEvent entities.PaymentEvent) error {
+	refundPath := fmt.Sprintf("Refunds[%d]", index)
+	update := expression.Set(expression.Name(refundPath), expression.Value(refund)).
+		Set(expression.Name("UpdatedAt"), expression.Value(time.Now().Unix()))
+	condition := expression.Name(refundPath + ".ReferenceId").Equal(expression.Value(refund.ReferenceId))
+	expr, err := expression.NewBuilder().WithUpdate(update).WithCondition(condition).Build()
+	if err != nil {
+		return err
+	}
+
+	return p.writePaymentOrderUpdate(p.paymentOrderKey(orderId), expr, paymentEvent)
+}
+
+// ReleaseRefund removes the refund reserved at the index, giving its amount back, as long as no
+// other refund was reserved since. The payment order is the one stored by the reservation.
+func (p paymentRepositoryGateway) ReleaseRefund(paymentOrder entities.PaymentOrder, index int, status entities.PaymentStatus, paymentEvent entities.PaymentEvent) error {
+	if index < 0 || index >= len(paymentOrder.Refunds) {
+		return fmt.Errorf("refund [%d] of the payment order [%d] not found", index, paymentOrder.OrderId)
+	}
+	refund := paymentOrder.Refunds[index]
+	refundPath := fmt.Sprintf("Refunds[%d]", index)
+	refundedAmount := math.Round((paymentOrder.RefundedAmount-refund.Amount)*100) / 100
+
+	update := statusUpdate(status, time.Now()).
+		Set(expression.Name("RefundedAmount"), expression.Value(refundedAmount)).
+		Remove(expression.Name(refundPath))
+	condition := expression.Name(refundPath + ".ReferenceId").Equal(expression.Value(refund.ReferenceId)).
+		And(expression.Name("RefundedAmount").Equal(expression.Value(paymentOrder.RefundedAmount)))
+	expr, err := expression.NewBuilder().WithUpdate(update).WithCondition(condition).Build()
+	if err != nil {
+		return err
 	}
-	update := expression.Set(expression.Name("Status"), expression.Value(status))
-	update.Set(expression.Name("PaymentId"), expression.Value(paymentId))
+
+	return p.writePaymentOrderUpdate(p.paymentOrderKey(paymentOrder.OrderId), expr, paymentEvent)
+}
+
+// FlagPaymentOrderForRefund records a payment received for a payment order that can no longer be
+// paid, so that the money can be returned to the customer.
+func (p paymentRepositoryGateway) FlagPaymentOrderForRefund(orderId, paymentId int, paymentEvent entities.PaymentEvent) error {
+	update := expression.Set(expression.Name("RefundRequired"), expression.Value(true)).
+		Set(expression.Name("PaymentId"), expression.Value(paymentId)).
+		Set(expression.Name("UpdatedAt"), expression.Value(time.Now().Unix()))
 	expr, err := expression.NewBuilder().WithUpdate(update).Build()
 	if err != nil {
 		return err
 	}
 
-	err = p.dynamodbClient.UpdateItem(p.paymentTable, key, expr)
+	return p.writePaymentOrderUpdate(p.paymentOrderKey(orderId), expr, paymentEvent)
+}
+
+func (p paymentRepositoryGateway) writePaymentOrderUpdate(key map[string]types.AttributeValue, expr expression.Expression, paymentEvent entities.PaymentEvent) error {
+	return p.writePaymentOrderChange(dynamodb.TransactWriteItem{
+		Action:     dynamodb.TransactUpdate,
+		TableName:  p.paymentTable,
+		Key:        key,
+		Expression: expr,
+	}, paymentEvent)
+}
+
+// writePaymentOrderChange writes the change and its event in a single transaction, returning
+// ErrPaymentOrderStatusConflict when the condition of the change fails.
+func (p paymentRepositoryGateway) writePaymentOrderChange(change dynamodb.TransactWriteItem, paymentEvent entities.PaymentEvent) error {
+	eventItem, err := newPaymentEventItem(paymentEvent)
 	if err != nil {
 		return err
 	}
 
+	err = p.dynamodbClient.TransactWriteItems([]dynamodb.TransactWriteItem{
+		change,
+		{Action: dynamodb.TransactPut, TableName: p.paymentEventTable, Item: eventItem},
+	})
+	if err != nil {
+		var transactionCanceled *types.TransactionCanceledException
+		if errors.As(err, &transactionCanceled) && hasConditionalCheckFailed(transactionCanceled) {
+			return ErrPaymentOrderStatusConflict
+		}
+		return err
+	}
+
 	return nil
 }
+
+func hasConditionalCheckFailed(transactionCanceled *types.TransactionCanceledException) bool {
+	for _, reason := range transactionCanceled.CancellationReasons {
+		if aws.ToString(reason.Code) == "ConditionalCheckFailed" {
+			return true
+		}
+	}
+	return false
+}
+
+// statusUpdate sets the status along with the timestamps that depend on it.
+func statusUpdate(status entities.PaymentStatus, now time.Time) expression.UpdateBuilder {
+	update := expression.Set(expression.Name("Status"), expression.Value(status)).
+		Set(expression.Name("UpdatedAt"), expression.Value(now.Unix()))
+	if status == entities.PaymentStatusPaid {
+		update.Set(expression.Name("PaidAt"), expression.Value(now.Unix()))
+	}
+	return update
+}
+
+func (p paymentRepositoryGateway) paymentOrderKey(orderId int) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"OrderId": &types.AttributeValueMemberN{Value: strconv.Itoa(orderId)},
+	}
+}
+
+func (p paymentRepositoryGateway) GetExpiredPaymentOrders(now time.Time) ([]entities.PaymentOrder, error) {
+	filter := expression.Name("Status").Equal(expression.Value(entities.PaymentStatusPending)).
+		And(expression.Name("ExpiresAt").LessThanEqual(expression.Value(now.Unix())))
+	expr, err := expression.NewBuilder().WithFilter(filter).Build()
+	if err != nil {
+		return nil, err
+	}
+
+	return p.scanPaymentOrders(expr)
+}
+
+func (p paymentRepositoryGateway) GetPaymentOrdersByStatus(statuses []entities.PaymentStatus, createdBefore time.Time) ([]entities.PaymentOrder, error) {
+	var operands []expression.OperandBuilder
+	for _, status := range statuses {
+		operands = append(operands, expression.Value(status))
+	}
+	if len(operands) == 0 {
+		return nil, nil
+	}
+
+	filter := expression.Name("Status").In(operands[0], operands[1:]...).
+		And(expression.Name("CreatedAt").LessThanEqual(expression.Value(createdBefore.Unix())))
+	expr, err := expression.NewBuilder().WithFilter(filter).Build()
+	if err != nil {
+		return nil, err
+	}
+
+	return p.scanPaymentOrders(expr)
+}
+
+func (p paymentRepositoryGateway) scanPaymentOrders(expr expression.Expression) ([]entities.PaymentOrder, error) {
+	items, err := p.dynamodbClient.Scan(p.paymentTable, expr)
+	if err != nil {
+		return nil, err
+	}
+
+	var paymentOrders []entities.PaymentOrder
+	err = attributevalue.UnmarshalListOfMaps(items, &paymentOrders)
+	if err != nil {
+		return nil, err
+	}
+
+	return paymentOrders, nil
+}
+
+// paymentSearch is how a filter is read: the index queried by the filter and the conditions on its
+// keys, or a scan of the table when the filter matches no index.
+type paymentSearch struct {
+	indexName     string
+	keyAttributes []string
+	keyCondition  *expression.KeyConditionBuilder
+	conditions    []expression.ConditionBuilder
+
+	// the partition and the creation range read from the index, which a cursor must lie in
+	partitionKeyName string
+	partitionValue   string
+	createdFrom      time.Time
+	createdTo        time.Time
+}
+
+func (p paymentRepositoryGateway) SearchPaymentOrders(filter entities.PaymentOrderFilter, limit int, cursor string) ([]entities.PaymentOrder, string, error) {
+	search := newPaymentSearch(filter)
+
+	var startKey map[string]types.AttributeValue
+	if cursor != "" {
+		var err error
+		startKey, err = decodeCursor(cursor, search.indexName, search.keyAttributes)
+		if err != nil || !search.contains(startKey) {
+			return nil, "", ErrInvalidPaymentOrderCursor
+		}
+	}
+
+	expr, err := search.expression()
+	if err != nil {
+		return nil, "", err
+	}
+
+	items := []map[string]types.AttributeValue{}
+	for reads := 0; reads < paymentSearchMaxReads; reads++ {
+		page, err := p.readPaymentOrders(search, expr, int32(limit), startKey)
+		if err != nil {
+			return nil, "", err
+		}
+
+		for i, item := range page.Items {
+			items = append(items, item)
+			if len(items) == limit {
+				if i == len(page.Items)-1 && page.LastEvaluatedKey == nil {
+					return p.toPaymentOrderPage(search, items, nil)
+				}
+				return p.toPaymentOrderPage(search, items, search.keyOf(item))
+			}
+		}
+
+		if page.LastEvaluatedKey == nil {
+			return p.toPaymentOrderPage(search, items, nil)
+		}
+		startKey = page.LastEvaluatedKey
+	}
+
+	return p.toPaymentOrderPage(search, items, startKey)
+}
+
+func (p paymentRepositoryGateway) readPaymentOrders(search paymentSearch, expr expression.Expression, limit int32, startKey map[string]types.AttributeValue) (dynamodb.Page, error) {
+	if search.keyCondition == nil {
+		return p.dynamodbClient.ScanPage(dynamodb.ScanInput{
+			TableName:         p.paymentTable,
+			Expression:        expr,
+			Limit:             limit,
+			ExclusiveStartKey: startKey,
+		})
+	}
+
+	return p.dynamodbClient.QueryPage(dynamodb.QueryInput{
+		TableName:         p.paymentTable,
+		IndexName:         search.indexName,
+		Expression:        expr,
+		Descending:        true,
+		Limit:             limit,
+		ExclusiveStartKey: startKey,
+	})
+}
+
+func (p paymentRepositoryGateway) toPaymentOrderPage(search paymentSearch, items []map[string]types.AttributeValue, nextKey map[string]types.AttributeValue) ([]entities.PaymentOrder, string, error) {
+	paymentOrders := []entities.PaymentOrder{}
+	err := attributevalue.UnmarshalListOfMaps(items, &paymentOrders)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if nextKey == nil {
+		return paymentOrders, "", nil
+	}
+	cursor, err := encodeCursor(search.indexName, nextKey)
+	if err != nil {
+		return nil, "", err
+	}
+	return paymentOrders, cursor, nil
+}
+
+// newPaymentSearch queries the customer index, which matches fewer payment orders, when the
+// filter has the customer, else the status index when it has the status. Any other filter scans
+// the table, in no particular order and at most paymentSearchMaxReads pages at a time, so a search
+// by creation range alone may return short or empty pages before the last one.
+func newPaymentSearch(filter entities.PaymentOrderFilter) paymentSearch {
+	search := paymentSearch{keyAttributes: []string{"OrderId"}}
+
+	var partitionKey expression.KeyConditionBuilder
+	switch {
+	case filter.CustomerCPF != "":
+		search.indexName = paymentCustomerCPFIndex
+		search.partitionKeyName, search.partitionValue = "CustomerCPF", filter.CustomerCPF
+		partitionKey = expression.Key(search.partitionKeyName).Equal(expression.Value(filter.CustomerCPF))
+		if filter.Status != "" {
+			search.conditions = append(search.conditions, expression.Name("Status").Equal(expression.Value(filter.Status)))
+		}
+	case filter.Status != "":
+		search.indexName = paymentStatusIndex
+		search.partitionKeyName, search.partitionValue = "Status", string(filter.Status)
+		partitionKey = expression.Key(search.partitionKeyName).Equal(expression.Value(filter.Status))
+	}
+
+	if search.indexName != "" {
+		search.keyAttributes = append(search.keyAttributes, search.partitionKeyName, "CreatedAt")
+		search.createdFrom, search.createdTo = filter.CreatedFrom, filter.CreatedTo
+		keyCondition := partitionKey
+		switch {
+		case !filter.CreatedFrom.IsZero() && !filter.CreatedTo.IsZero():
+			keyCondition = keyCondition.And(expression.Key("CreatedAt").Between(expression.Value(filter.CreatedFrom.Unix()), expression.Value(filter.CreatedTo.Unix())))
+		case !filter.CreatedFrom.IsZero():
+			keyCondition = keyCondition.And(expression.Key("CreatedAt").GreaterThanEqual(expression.Value(filter.CreatedFrom.Unix())))
+		case !filter.CreatedTo.IsZero():
+			keyCondition = keyCondition.And(expression.Key("CreatedAt").LessThanEqual(expression.Value(filter.CreatedTo.Unix())))
+		}
+		search.keyCondition = &keyCondition
+	} else {
+		if !filter.CreatedFrom.IsZero() {
+			search.conditions = append(search.conditions, expression.Name("CreatedAt").GreaterThanEqual(expression.Value(filter.CreatedFrom.Unix())))
+		}
+		if !filter.CreatedTo.IsZero() {
+			search.conditions = append(search.conditions, expression.Name("CreatedAt").LessThanEqual(expression.Value(filter.CreatedTo.Unix())))
+		}
+	}
+
+	if filter.Broker != "" {
+		search.conditions = append(search.conditions, expression.Name("Broker").Equal(expression.Value(filter.Broker)))
+	}
+	if filter.MinAmount > 0 {
+		search.conditions = append(search.conditions, expression.Name("TotalAmount").GreaterThanEqual(expression.Value(filter.MinAmount)))
+	}
+	if filter.MaxAmount > 0 {
+		search.conditions = append(search.conditions, expression.Name("TotalAmount").LessThanEqual(expression.Value(filter.MaxAmount)))
+	}
+
+	return search
+}
+
+func (s paymentSearch) expression() (expression.Expression, error) {
+	if s.keyCondition == nil && len(s.conditions) == 0 {
+		return expression.Expression{}, nil
+	}
+
+	builder := expression.NewBuilder()
+	if s.keyCondition != nil {
+		builder = builder.WithKeyCondition(*s.keyCondition)
+	}
+
+	switch len(s.conditions) {
+	case 0:
+	case 1:
+		builder = builder.WithFilter(s.conditions[0])
+	default:
+		builder = builder.WithFilter(expression.And(s.conditions[0], s.conditions[1], s.conditions[2:]...))
+	}
+
+	return builder.Build()
+}
+
+// contains reports whether the key to resume from lies in the partition and the creation range the
+// search reads, which the index refuses to resume from otherwise.
+func (s paymentSearch) contains(key map[string]types.AttributeValue) bool {
+	if s.indexName == "" {
+		return true
+	}
+
+	partitionValue, ok := key[s.partitionKeyName].(*types.AttributeValueMemberS)
+	if !ok || partitionValue.Value != s.partitionValue {
+		return false
+	}
+
+	createdAtValue, ok := key["CreatedAt"].(*types.AttributeValueMemberN)
+	if !ok {
+		return false
+	}
+	createdAt, err := strconv.ParseInt(createdAtValue.Value, 10, 64)
+	if err != nil {
+		return false
+	}
+	return (s.createdFrom.IsZero() || createdAt >= s.createdFrom.Unix()) &&
+		(s.createdTo.IsZero() || createdAt <= s.createdTo.Unix())
+}
+
+// keyOf is the key of the item in the table or the index read, to resume the search after it.
+func (s paymentSearch) keyOf(item map[string]types.AttributeValue) map[string]types.AttributeValue {
+	key := map[string]types.AttributeValue{}
+	for _, name := range s.keyAttributes {
+		key[name] = item[name]
+	}
+	return key
+}
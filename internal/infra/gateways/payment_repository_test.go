@@ -3,10 +3,14 @@ package gateways
 import (
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/entities"
 	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/usecases/dto"
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/infra/drivers/dynamodb"
 	mock_dynamodb "github.com/IgorRamosBR/g73-techchallenge-payment/internal/infra/drivers/dynamodb/mocks"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 	"github.com/go-playground/assert/v2"
 	"go.uber.org/mock/gomock"
 )
@@ -100,26 +104,26 @@ func TestPaymentRepository_SavePaymentOrder(t *testing.T) {
 	}
 
 	for _, tt := range tests {
-		dynamodbClient.EXPECT().PutItem(gomock.Eq(tt.dynamodbCall.table), gomock.Any()).
+		dynamodbClient.EXPECT().TransactWriteItems(gomock.Cond(func(x any) bool {
+			items := x.([]dynamodb.TransactWriteItem)
+			return len(items) == 2 &&
+				items[0].Action == dynamodb.TransactPut && items[0].TableName == tt.dynamodbCall.table &&
+				isPaymentEventPut(items[1])
+		})).
 			Times(tt.dynamodbCall.times).
 			Return(tt.dynamodbCall.err)
 
-		paymentRepository := NewPaymentRepositoryGateway(dynamodbClient, "Payment")
-		err := paymentRepository.SavePaymentOrder(tt.args.paymentDto, tt.args.qrCode)
+		paymentRepository := NewPaymentRepositoryGateway(dynamodbClient, "Payment", "PaymentEvent")
+		err := paymentRepository.SavePaymentOrder(tt.args.paymentDto.ToPaymentOrder(tt.args.qrCode, time.Now(), time.Now()), testPaymentEvent())
 
 		assert.Equal(t, tt.want.err, err)
 	}
 }
 
-func TestPaymentRepository_UpdatePaymentOrderStatus(t *testing.T) {
+func TestPaymentRepository_TransitionPaymentOrderStatus(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	dynamodbClient := mock_dynamodb.NewMockDynamoDBClient(ctrl)
 
-	type args struct {
-		orderId   int
-		paymentId int
-		status    entities.PaymentStatus
-	}
 	type want struct {
 		err error
 	}
@@ -130,17 +134,11 @@ func TestPaymentRepository_UpdatePaymentOrderStatus(t *testing.T) {
 	}
 	tests := []struct {
 		name string
-		args
 		want
 		dynamodbCall
 	}{
 		{
-			name: "should fail to update payment order status when dynamodb client returns error",
-			args: args{
-				orderId:   123,
-				paymentId: 999,
-				status:    entities.PaymentStatusPaid,
-			},
+			name: "should fail to transition payment order status when dynamodb client returns error",
 			want: want{
 				errors.New("internal error"),
 			},
@@ -151,12 +149,18 @@ func TestPaymentRepository_UpdatePaymentOrderStatus(t *testing.T) {
 			},
 		},
 		{
-			name: "should update payment order when dynamodb client does not return error",
-			args: args{
-				orderId:   123,
-				paymentId: 999,
-				status:    entities.PaymentStatusPaid,
+			name: "should return conflict when payment order is not in the expected status",
+			want: want{
+				ErrPaymentOrderStatusConflict,
+			},
+			dynamodbCall: dynamodbCall{
+				table: "Payment",
+				times: 1,
+				err:   &types.TransactionCanceledException{CancellationReasons: []types.CancellationReason{{Code: aws.String("ConditionalCheckFailed")}}},
 			},
+		},
+		{
+			name: "should transition payment order status when dynamodb client does not return error",
 			want: want{
 				nil,
 			},
@@ -169,13 +173,586 @@ func TestPaymentRepository_UpdatePaymentOrderStatus(t *testing.T) {
 	}
 
 	for _, tt := range tests {
-		dynamodbClient.EXPECT().UpdateItem(gomock.Eq(tt.dynamodbCall.table), gomock.Any(), gomock.Any()).
+		dynamodbClient.EXPECT().TransactWriteItems(gomock.Cond(func(x any) bool {
+			items := x.([]dynamodb.TransactWriteItem)
+			if len(items) != 2 || items[0].TableName != tt.dynamodbCall.table || !isPaymentEventPut(items[1]) {
+				return false
+			}
+			updated := map[string]bool{}
+			for _, name := range items[0].Expression.Names() {
+				updated[name] = true
+			}
+			return updated["Status"] && updated["PaymentId"] && updated["MerchantOrderId"]
+		})).
+			Times(tt.dynamodbCall.times).
+			Return(tt.dynamodbCall.err)
+
+		paymentRepository := NewPaymentRepositoryGateway(dynamodbClient, "Payment", "PaymentEvent")
+		err := paymentRepository.TransitionPaymentOrderStatus(123, 999, 222, entities.PaymentStatusPending, entities.PaymentStatusPaid, testPaymentEvent())
+
+		assert.Equal(t, tt.want.err, err)
+	}
+}
+
+func TestPaymentRepository_GetExpiredPaymentOrders(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	dynamodbClient := mock_dynamodb.NewMockDynamoDBClient(ctrl)
+
+	type want struct {
+		paymentOrders []entities.PaymentOrder
+		err           error
+	}
+	type dynamodbCall struct {
+		table string
+		times int
+		items []map[string]types.AttributeValue
+		err   error
+	}
+	tests := []struct {
+		name string
+		want
+		dynamodbCall
+	}{
+		{
+			name: "should fail to get expired payment orders when dynamodb client returns error",
+			want: want{
+				err: errors.New("internal error"),
+			},
+			dynamodbCall: dynamodbCall{
+				table: "Payment",
+				times: 1,
+				err:   errors.New("internal error"),
+			},
+		},
+		{
+			name: "should get expired payment orders",
+			want: want{
+				paymentOrders: []entities.PaymentOrder{
+					{
+						OrderId: 123,
+						Status:  entities.PaymentStatusPending,
+					},
+				},
+			},
+			dynamodbCall: dynamodbCall{
+				table: "Payment",
+				times: 1,
+				items: []map[string]types.AttributeValue{
+					{
+						"OrderId": &types.AttributeValueMemberN{Value: "123"},
+						"Status":  &types.AttributeValueMemberS{Value: "PENDING"},
+					},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		dynamodbClient.EXPECT().Scan(gomock.Eq(tt.dynamodbCall.table), gomock.Any()).
+			Times(tt.dynamodbCall.times).
+			Return(tt.dynamodbCall.items, tt.dynamodbCall.err)
+
+		paymentRepository := NewPaymentRepositoryGateway(dynamodbClient, "Payment", "PaymentEvent")
+		paymentOrders, err := paymentRepository.GetExpiredPaymentOrders(time.Now())
+
+		assert.Equal(t, tt.want.paymentOrders, paymentOrders)
+		assert.Equal(t, tt.want.err, err)
+	}
+}
+
+func TestPaymentRepository_GetPaymentOrder(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	dynamodbClient := mock_dynamodb.NewMockDynamoDBClient(ctrl)
+
+	type want struct {
+		paymentOrder entities.PaymentOrder
+		err          error
+	}
+	type dynamodbCall struct {
+		table string
+		times int
+		item  map[string]types.AttributeValue
+		err   error
+	}
+	tests := []struct {
+		name string
+		want
+		dynamodbCall
+	}{
+		{
+			name: "should fail to get payment order when dynamodb client returns error",
+			want: want{
+				err: errors.New("internal error"),
+			},
+			dynamodbCall: dynamodbCall{
+				table: "Payment",
+				times: 1,
+				err:   errors.New("internal error"),
+			},
+		},
+		{
+			name: "should return not found when payment order does not exist",
+			want: want{
+				err: ErrPaymentOrderNotFound,
+			},
+			dynamodbCall: dynamodbCall{
+				table: "Payment",
+				times: 1,
+				item:  map[string]types.AttributeValue{},
+			},
+		},
+		{
+			name: "should get payment order",
+			want: want{
+				paymentOrder: entities.PaymentOrder{
+					OrderId:    123,
+					PaymentId:  111,
+					TotalAmout: 9.99,
+					Status:     entities.PaymentStatusPaid,
+				},
+			},
+			dynamodbCall: dynamodbCall{
+				table: "Payment",
+				times: 1,
+				item: map[string]types.AttributeValue{
+					"OrderId":     &types.AttributeValueMemberN{Value: "123"},
+					"PaymentId":   &types.AttributeValueMemberN{Value: "111"},
+					"TotalAmount": &types.AttributeValueMemberN{Value: "9.99"},
+					"Status":      &types.AttributeValueMemberS{Value: "PAID"},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		dynamodbClient.EXPECT().GetItem(gomock.Eq(tt.dynamodbCall.table), gomock.Any()).
+			Times(tt.dynamodbCall.times).
+			Return(tt.dynamodbCall.item, tt.dynamodbCall.err)
+
+		paymentRepository := NewPaymentRepositoryGateway(dynamodbClient, "Payment", "PaymentEvent")
+		paymentOrder, err := paymentRepository.GetPaymentOrder(123)
+
+		assert.Equal(t, tt.want.paymentOrder, paymentOrder)
+		assert.Equal(t, tt.want.err, err)
+	}
+}
+
+func TestPaymentRepository_SaveRefund(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	dynamodbClient := mock_dynamodb.NewMockDynamoDBClient(ctrl)
+
+	type want struct {
+		err error
+	}
+	type dynamodbCall struct {
+		table string
+		times int
+		err   error
+	}
+	tests := []struct {
+		name string
+		want
+		dynamodbCall
+	}{
+		{
+			name: "should fail to save refund when dynamodb client returns error",
+			want: want{
+				errors.New("internal error"),
+			},
+			dynamodbCall: dynamodbCall{
+				table: "Payment",
+				times: 1,
+				err:   errors.New("internal error"),
+			},
+		},
+		{
+			name: "should return conflict when payment order changed since it was read",
+			want: want{
+				ErrPaymentOrderStatusConflict,
+			},
+			dynamodbCall: dynamodbCall{
+				table: "Payment",
+				times: 1,
+				err:   &types.TransactionCanceledException{CancellationReasons: []types.CancellationReason{{Code: aws.String("ConditionalCheckFailed")}}},
+			},
+		},
+		{
+			name: "should save refund",
+			want: want{
+				nil,
+			},
+			dynamodbCall: dynamodbCall{
+				table: "Payment",
+				times: 1,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		dynamodbClient.EXPECT().TransactWriteItems(gomock.Cond(func(x any) bool {
+			items := x.([]dynamodb.TransactWriteItem)
+			return len(items) == 2 && items[0].TableName == tt.dynamodbCall.table && isPaymentEventPut(items[1])
+		})).
 			Times(tt.dynamodbCall.times).
 			Return(tt.dynamodbCall.err)
 
-		paymentRepository := NewPaymentRepositoryGateway(dynamodbClient, "Payment")
-		err := paymentRepository.UpdatePaymentOrderStatus(tt.args.orderId, tt.args.paymentId, tt.args.status)
+		paymentOrder := entities.PaymentOrder{OrderId: 123, TotalAmout: 20, Status: entities.PaymentStatusPaid}
+		refund := entities.Refund{RefundId: 999, Amount: 5, Status: "approved", CreatedAt: time.Now()}
+
+		paymentRepository := NewPaymentRepositoryGateway(dynamodbClient, "Payment", "PaymentEvent")
+		err := paymentRepository.SaveRefund(paymentOrder, refund, entities.PaymentStatusPartiallyRefunded, testPaymentEvent())
 
 		assert.Equal(t, tt.want.err, err)
 	}
 }
+
+func TestPaymentRepository_SettleRefund(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	dynamodbClient := mock_dynamodb.NewMockDynamoDBClient(ctrl)
+
+	type want struct {
+		err error
+	}
+	type dynamodbCall struct {
+		err error
+	}
+	tests := []struct {
+		name string
+		want
+		dynamodbCall
+	}{
+		{
+			name: "should return conflict when the reserved refund is not found",
+			want: want{
+				ErrPaymentOrderStatusConflict,
+			},
+			dynamodbCall: dynamodbCall{
+				err: &types.TransactionCanceledException{CancellationReasons: []types.CancellationReason{{Code: aws.String("ConditionalCheckFailed")}}},
+			},
+		},
+		{
+			name: "should settle refund",
+			want: want{
+				nil,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		dynamodbClient.EXPECT().TransactWriteItems(gomock.Cond(func(x any) bool {
+			items := x.([]dynamodb.TransactWriteItem)
+			return len(items) == 2 && *items[0].Expression.Condition() != "" && isPaymentEventPut(items[1])
+		})).
+			Times(1).
+			Return(tt.dynamodbCall.err)
+
+		refund := entities.Refund{RefundId: 999, ReferenceId: "123-refund-2", Amount: 5, Status: "approved", CreatedAt: time.Now()}
+
+		paymentRepository := NewPaymentRepositoryGateway(dynamodbClient, "Payment", "PaymentEvent")
+		err := paymentRepository.SettleRefund(123, 1, refund, testPaymentEvent())
+
+		assert.Equal(t, tt.want.err, err)
+	}
+}
+
+func TestPaymentRepository_ReleaseRefund(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	dynamodbClient := mock_dynamodb.NewMockDynamoDBClient(ctrl)
+
+	paymentOrder := entities.PaymentOrder{
+		OrderId:        123,
+		TotalAmout:     20,
+		RefundedAmount: 5,
+		Status:         entities.PaymentStatusPartiallyRefunded,
+		Refunds:        []entities.Refund{{ReferenceId: "123-refund-1", Amount: 5, Status: entities.RefundStatusPending}},
+	}
+
+	t.Run("should release the reserved refund", func(t *testing.T) {
+		dynamodbClient.EXPECT().TransactWriteItems(gomock.Cond(func(x any) bool {
+			items := x.([]dynamodb.TransactWriteItem)
+			if len(items) != 2 || !isPaymentEventPut(items[1]) {
+				return false
+			}
+			updated := map[string]bool{}
+			for _, name := range items[0].Expression.Names() {
+				updated[name] = true
+			}
+			return updated["Refunds"] && updated["RefundedAmount"] && updated["Status"]
+		})).
+			Times(1).
+			Return(nil)
+
+		paymentRepository := NewPaymentRepositoryGateway(dynamodbClient, "Payment", "PaymentEvent")
+		err := paymentRepository.ReleaseRefund(paymentOrder, 0, entities.PaymentStatusPaid, testPaymentEvent())
+
+		assert.Equal(t, nil, err)
+	})
+
+	t.Run("should fail when the refund is not in the payment order", func(t *testing.T) {
+		paymentRepository := NewPaymentRepositoryGateway(dynamodbClient, "Payment", "PaymentEvent")
+		err := paymentRepository.ReleaseRefund(paymentOrder, 1, entities.PaymentStatusPaid, testPaymentEvent())
+
+		assert.NotEqual(t, nil, err)
+	})
+}
+
+func TestPaymentRepository_FlagPaymentOrderForRefund(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	dynamodbClient := mock_dynamodb.NewMockDynamoDBClient(ctrl)
+
+	type want struct {
+		err error
+	}
+	type dynamodbCall struct {
+		table string
+		times int
+		err   error
+	}
+	tests := []struct {
+		name string
+		want
+		dynamodbCall
+	}{
+		{
+			name: "should fail to flag payment order when dynamodb client returns error",
+			want: want{
+				errors.New("internal error"),
+			},
+			dynamodbCall: dynamodbCall{
+				table: "Payment",
+				times: 1,
+				err:   errors.New("internal error"),
+			},
+		},
+		{
+			name: "should flag payment order for refund",
+			want: want{
+				nil,
+			},
+			dynamodbCall: dynamodbCall{
+				table: "Payment",
+				times: 1,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		dynamodbClient.EXPECT().TransactWriteItems(gomock.Cond(func(x any) bool {
+			items := x.([]dynamodb.TransactWriteItem)
+			return len(items) == 2 && items[0].TableName == tt.dynamodbCall.table && isPaymentEventPut(items[1])
+		})).
+			Times(tt.dynamodbCall.times).
+			Return(tt.dynamodbCall.err)
+
+		paymentRepository := NewPaymentRepositoryGateway(dynamodbClient, "Payment", "PaymentEvent")
+		err := paymentRepository.FlagPaymentOrderForRefund(123, 111, testPaymentEvent())
+
+		assert.Equal(t, tt.want.err, err)
+	}
+}
+
+func TestPaymentRepository_SearchPaymentOrders(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	dynamodbClient := mock_dynamodb.NewMockDynamoDBClient(ctrl)
+
+	paymentItem := func(orderId string) map[string]types.AttributeValue {
+		return map[string]types.AttributeValue{
+			"OrderId":     &types.AttributeValueMemberN{Value: orderId},
+			"CustomerCPF": &types.AttributeValueMemberS{Value: "123.456.789-00"},
+			"Status":      &types.AttributeValueMemberS{Value: "PAID"},
+			"CreatedAt":   &types.AttributeValueMemberN{Value: "1714564800"},
+		}
+	}
+	customerCursor, _ := encodeCursor(paymentCustomerCPFIndex, map[string]types.AttributeValue{
+		"OrderId":     &types.AttributeValueMemberN{Value: "123"},
+		"CustomerCPF": &types.AttributeValueMemberS{Value: "123.456.789-00"},
+		"CreatedAt":   &types.AttributeValueMemberN{Value: "1714564800"},
+	})
+	scanKey := map[string]types.AttributeValue{"OrderId": &types.AttributeValueMemberN{Value: "122"}}
+
+	type args struct {
+		filter entities.PaymentOrderFilter
+		limit  int
+		cursor string
+	}
+	type want struct {
+		orderIds []int
+		cursor   string
+		err      error
+	}
+	type queryCall struct {
+		times     int
+		indexName string
+		startKey  map[string]types.AttributeValue
+		page      dynamodb.Page
+		err       error
+	}
+	type scanCall struct {
+		times int
+		pages []dynamodb.Page
+	}
+	tests := []struct {
+		name string
+		args
+		want
+		queryCall
+		scanCall
+	}{
+		{
+			name: "should fail to search when the cursor is of another index",
+			args: args{
+				filter: entities.PaymentOrderFilter{Status: entities.PaymentStatusPaid},
+				limit:  20,
+				cursor: customerCursor,
+			},
+			want: want{
+				err: ErrInvalidPaymentOrderCursor,
+			},
+		},
+		{
+			name: "should fail to search when the cursor is of another customer",
+			args: args{
+				filter: entities.PaymentOrderFilter{CustomerCPF: "987.654.321-00"},
+				limit:  20,
+				cursor: customerCursor,
+			},
+			want: want{
+				err: ErrInvalidPaymentOrderCursor,
+			},
+		},
+		{
+			name: "should fail to search when the cursor is out of the creation range",
+			args: args{
+				filter: entities.PaymentOrderFilter{CustomerCPF: "123.456.789-00", CreatedTo: time.Unix(1714521600, 0)},
+				limit:  20,
+				cursor: customerCursor,
+			},
+			want: want{
+				err: ErrInvalidPaymentOrderCursor,
+			},
+		},
+		{
+			name: "should fail to search when dynamodb client returns error",
+			args: args{
+				filter: entities.PaymentOrderFilter{Status: entities.PaymentStatusPaid},
+				limit:  20,
+			},
+			want: want{
+				err: errors.New("internal error"),
+			},
+			queryCall: queryCall{
+				times:     1,
+				indexName: paymentStatusIndex,
+				err:       errors.New("internal error"),
+			},
+		},
+		{
+			name: "should search the payment orders by status",
+			args: args{
+				filter: entities.PaymentOrderFilter{Status: entities.PaymentStatusPaid, CreatedFrom: time.Unix(1714521600, 0)},
+				limit:  2,
+			},
+			want: want{
+				orderIds: []int{124, 123},
+			},
+			queryCall: queryCall{
+				times:     1,
+				indexName: paymentStatusIndex,
+				page:      dynamodb.Page{Items: []map[string]types.AttributeValue{paymentItem("124"), paymentItem("123")}},
+			},
+		},
+		{
+			name: "should search the payment orders by customer, resuming from the cursor",
+			args: args{
+				filter: entities.PaymentOrderFilter{CustomerCPF: "123.456.789-00", Status: entities.PaymentStatusPaid},
+				limit:  1,
+				cursor: customerCursor,
+			},
+			want: want{
+				orderIds: []int{122},
+				cursor:   "eyJpbmRleCI6IkN1c3RvbWVyQ1BGLUNyZWF0ZWRBdC1pbmRleCIsImtleSI6eyJDcmVhdGVkQXQiOnsiTiI6IjE3MTQ1NjQ4MDAifSwiQ3VzdG9tZXJDUEYiOnsiUyI6IjEyMy40NTYuNzg5LTAwIn0sIk9yZGVySWQiOnsiTiI6IjEyMiJ9fX0",
+			},
+			queryCall: queryCall{
+				times:     1,
+				indexName: paymentCustomerCPFIndex,
+				startKey: map[string]types.AttributeValue{
+					"OrderId":     &types.AttributeValueMemberN{Value: "123"},
+					"CustomerCPF": &types.AttributeValueMemberS{Value: "123.456.789-00"},
+					"CreatedAt":   &types.AttributeValueMemberN{Value: "1714564800"},
+				},
+				page: dynamodb.Page{Items: []map[string]types.AttributeValue{paymentItem("122"), paymentItem("121")}},
+			},
+		},
+		{
+			name: "should scan the payment orders when neither status nor customer is given",
+			args: args{
+				filter: entities.PaymentOrderFilter{Broker: "mercado-pago", MinAmount: 10},
+				limit:  20,
+			},
+			want: want{
+				orderIds: []int{121},
+			},
+			scanCall: scanCall{
+				times: 2,
+				pages: []dynamodb.Page{
+					{Items: []map[string]types.AttributeValue{}, LastEvaluatedKey: scanKey},
+					{Items: []map[string]types.AttributeValue{paymentItem("121")}},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		dynamodbClient.EXPECT().
+			QueryPage(gomock.Any()).
+			Times(tt.queryCall.times).
+			DoAndReturn(func(input dynamodb.QueryInput) (dynamodb.Page, error) {
+				assert.Equal(t, "Payment", input.TableName)
+				assert.Equal(t, tt.queryCall.indexName, input.IndexName)
+				assert.Equal(t, true, input.Descending)
+				assert.Equal(t, int32(tt.args.limit), input.Limit)
+				assert.Equal(t, tt.queryCall.startKey, input.ExclusiveStartKey)
+				return tt.queryCall.page, tt.queryCall.err
+			})
+
+		scans := 0
+		dynamodbClient.EXPECT().
+			ScanPage(gomock.Any()).
+			Times(tt.scanCall.times).
+			DoAndReturn(func(input dynamodb.ScanInput) (dynamodb.Page, error) {
+				if scans > 0 {
+					assert.Equal(t, scanKey, input.ExclusiveStartKey)
+				}
+				scans++
+				return tt.scanCall.pages[scans-1], nil
+			})
+
+		paymentRepository := NewPaymentRepositoryGateway(dynamodbClient, "Payment", "PaymentEvent")
+		paymentOrders, cursor, err := paymentRepository.SearchPaymentOrders(tt.args.filter, tt.args.limit, tt.args.cursor)
+
+		orderIds := []int{}
+		for _, paymentOrder := range paymentOrders {
+			orderIds = append(orderIds, paymentOrder.OrderId)
+		}
+		if tt.want.err == nil {
+			assert.Equal(t, tt.want.orderIds, orderIds)
+		}
+		assert.Equal(t, tt.want.cursor, cursor)
+		assert.Equal(t, tt.want.err, err)
+	}
+}
+
+func testPaymentEvent() entities.PaymentEvent {
+	return entities.PaymentEvent{
+		OrderId:   123,
+		Type:      entities.PaymentEventTypeStatusChanged,
+		ToStatus:  entities.PaymentStatusPaid,
+		Source:    entities.PaymentEventSourceWebhook,
+		CreatedAt: time.Now(),
+	}
+}
+
+func isPaymentEventPut(item dynamodb.TransactWriteItem) bool {
+	eventId, ok := item.Item["EventId"].(*types.AttributeValueMemberS)
+	return item.Action == dynamodb.TransactPut && item.TableName == "PaymentEvent" && ok && eventId.Value != ""
+}
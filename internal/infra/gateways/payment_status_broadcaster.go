@@ -0,0 +1,232 @@
+package gateways
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/entities"
+	drivers "github.com/IgorRamosBR/g73-techchallenge-payment/internal/infra/drivers/dynamodbstreams"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodbstreams/types"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// defaultStreamPollInterval applies when no stream poll interval is configured.
+const defaultStreamPollInterval = time.Second
+
+type PaymentStatusHandler func(change entities.PaymentStatusChange)
+
+// PaymentStatusBroadcaster carries the payment status changes to the listeners of every replica.
+type PaymentStatusBroadcaster interface {
+	Broadcast(change entities.PaymentStatusChange) error
+	// Listen calls the handler with every change broadcast from now on. Handlers must not block.
+	Listen(handler PaymentStatusHandler)
+}
+
+type dynamoDBStreamPaymentStatusBroadcaster struct {
+	client       drivers.DynamoDBStreamsClient
+	streamArn    string
+	pollInterval time.Duration
+
+	mutex    sync.RWMutex
+	handlers []PaymentStatusHandler
+	start    sync.Once
+
+	// iterators holds the position read so far in each open shard, positions where to acquire it
+	// again from when its iterator is lost, and closedShards the shards already read to the end,
+	// so they are not read again.
+	iterators    map[string]string
+	positions    map[string]shardPosition
+	closedShards map[string]bool
+	synced       bool
+}
+
+// shardPosition is where the reading of a shard resumes: after the last record read, or else from
+// where the reading started.
+type shardPosition struct {
+	iteratorType   types.ShardIteratorType
+	sequenceNumber string
+}
+
+// NewDynamoDBStreamPaymentStatusBroadcaster reads the changes from the stream of the payment
+// table, so every replica sees the changes committed by any of them. Broadcast does nothing, as
+// committing the change is what puts it in the stream. The stream must carry the new and the old
+// images.
+func NewDynamoDBStreamPaymentStatusBroadcaster(client drivers.DynamoDBStreamsClient, streamArn string, pollInterval time.Duration) PaymentStatusBroadcaster {
+	if pollInterval <= 0 {
+		pollInterval = defaultStreamPollInterval
+	}
+	return &dynamoDBStreamPaymentStatusBroadcaster{
+		client:       client,
+		streamArn:    streamArn,
+		pollInterval: pollInterval,
+		iterators:    map[string]string{},
+		positions:    map[string]shardPosition{},
+		closedShards: map[string]bool{},
+	}
+}
+
+func (b *dynamoDBStreamPaymentStatusBroadcaster) Broadcast(change entities.PaymentStatusChange) error {
+	return nil
+}
+
+// Listen starts reading the stream on the first call.
+func (b *dynamoDBStreamPaymentStatusBroadcaster) Listen(handler PaymentStatusHandler) {
+	b.mutex.Lock()
+	b.handlers = append(b.handlers, handler)
+	b.mutex.Unlock()
+
+	b.start.Do(func() {
+		go b.run()
+	})
+}
+
+func (b *dynamoDBStreamPaymentStatusBroadcaster) run() {
+	ticker := time.NewTicker(b.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		b.poll()
+		<-ticker.C
+	}
+}
+
+// poll reads the new records of every open shard. The shards are listed again when one of them
+// is closed, so its children are read from their beginning, and when one of them fails to be
+// read, as its iterator may have expired, so it is acquired again from the last record read.
+func (b *dynamoDBStreamPaymentStatusBroadcaster) poll() {
+	if !b.synced {
+		err := b.syncShards()
+		if err != nil {
+			log.Errorf("failed to list the shards of the stream [%s], error: %v", b.streamArn, err)
+			return
+		}
+	}
+
+	for shardId, iterator := range b.iterators {
+		records, next, err := b.client.GetRecords(iterator)
+		if err != nil {
+			log.Errorf("failed to read the shard [%s] of the stream [%s], error: %v", shardId, b.streamArn, err)
+			delete(b.iterators, shardId)
+			b.synced = false
+			continue
+		}
+
+		for _, record := range records {
+			b.handleRecord(record)
+			if record.Dynamodb != nil && record.Dynamodb.SequenceNumber != nil {
+				b.positions[shardId] = shardPosition{
+					iteratorType:   types.ShardIteratorTypeAfterSequenceNumber,
+					sequenceNumber: *record.Dynamodb.SequenceNumber,
+				}
+			}
+		}
+
+		if next == nil {
+			delete(b.iterators, shardId)
+			delete(b.positions, shardId)
+			b.closedShards[shardId] = true
+			b.synced = false
+			continue
+		}
+		b.iterators[shardId] = *next
+	}
+}
+
+// syncShards starts reading the shards not read yet. The shards open when the broadcaster starts
+// are read from their tip, as the changes before it are of no interest to anyone, and the shards
+// opened later from their beginning. The shards whose iterator was lost resume from their position.
+func (b *dynamoDBStreamPaymentStatusBroadcaster) syncShards() error {
+	shardIds, err := b.client.ListShards(b.streamArn)
+	if err != nil {
+		return err
+	}
+
+	iteratorType := types.ShardIteratorTypeTrimHorizon
+	if len(b.positions) == 0 && len(b.closedShards) == 0 {
+		iteratorType = types.ShardIteratorTypeLatest
+	}
+
+	for _, shardId := range shardIds {
+		_, reading := b.iterators[shardId]
+		if reading || b.closedShards[shardId] {
+			continue
+		}
+
+		position, known := b.positions[shardId]
+		if !known {
+			position = shardPosition{iteratorType: iteratorType}
+		}
+
+		iterator, err := b.client.GetShardIterator(b.streamArn, shardId, position.iteratorType, position.sequenceNumber)
+		var trimmed *types.TrimmedDataAccessException
+		if errors.As(err, &trimmed) {
+			// the records after the last one read are gone, so the reading resumes from the oldest left
+			position = shardPosition{iteratorType: types.ShardIteratorTypeTrimHorizon}
+			iterator, err = b.client.GetShardIterator(b.streamArn, shardId, position.iteratorType, "")
+		}
+		if err != nil {
+			return err
+		}
+
+		b.positions[shardId] = position
+		if iterator != nil {
+			b.iterators[shardId] = *iterator
+		}
+	}
+
+	b.synced = true
+	return nil
+}
+
+func (b *dynamoDBStreamPaymentStatusBroadcaster) handleRecord(record types.Record) {
+	if record.Dynamodb == nil || record.Dynamodb.NewImage == nil {
+		return
+	}
+
+	newImage, err := b.toPaymentOrder(record.Dynamodb.NewImage)
+	if err != nil {
+		log.Errorf("failed to read the stream record [%v], error: %v", record.EventID, err)
+		return
+	}
+	oldImage, err := b.toPaymentOrder(record.Dynamodb.OldImage)
+	if err != nil {
+		log.Errorf("failed to read the stream record [%v], error: %v", record.EventID, err)
+		return
+	}
+	if newImage.Status == oldImage.Status {
+		return
+	}
+
+	change := entities.PaymentStatusChange{
+		OrderId:   newImage.OrderId,
+		Status:    newImage.Status,
+		UpdatedAt: newImage.UpdatedAt,
+	}
+
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+	for _, handler := range b.handlers {
+		handler(change)
+	}
+}
+
+func (b *dynamoDBStreamPaymentStatusBroadcaster) toPaymentOrder(image map[string]types.AttributeValue) (entities.PaymentOrder, error) {
+	paymentOrder := entities.PaymentOrder{}
+	if image == nil {
+		return paymentOrder, nil
+	}
+
+	item, err := attributevalue.FromDynamoDBStreamsMap(image)
+	if err != nil {
+		return paymentOrder, err
+	}
+
+	err = attributevalue.UnmarshalMap(item, &paymentOrder)
+	if err != nil {
+		return paymentOrder, err
+	}
+	return paymentOrder, nil
+}
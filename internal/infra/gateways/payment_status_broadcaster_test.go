@@ -0,0 +1,206 @@
+package gateways
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/entities"
+	mock_dynamodbstreams "github.com/IgorRamosBR/g73-techchallenge-payment/internal/infra/drivers/dynamodbstreams/mocks"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodbstreams/types"
+	"github.com/go-playground/assert/v2"
+	"go.uber.org/mock/gomock"
+)
+
+const paymentTableStreamArn = "arn:aws:dynamodb:us-east-1:000000000000:table/Payment/stream/2024-05-01T12:00:00.000"
+
+func TestDynamoDBStreamPaymentStatusBroadcaster_Poll(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	streamsClient := mock_dynamodbstreams.NewMockDynamoDBStreamsClient(ctrl)
+
+	type want struct {
+		changes []entities.PaymentStatusChange
+	}
+	type getRecordsCall struct {
+		records []types.Record
+		next    *string
+		err     error
+	}
+	tests := []struct {
+		name string
+		want
+		getRecordsCall
+	}{
+		{
+			name: "should broadcast the status change",
+			want: want{
+				changes: []entities.PaymentStatusChange{
+					{OrderId: 123, Status: entities.PaymentStatusPaid, UpdatedAt: time.Unix(1714564800, 0)},
+				},
+			},
+			getRecordsCall: getRecordsCall{
+				records: []types.Record{
+					createPaymentStreamRecord(types.OperationTypeModify, entities.PaymentStatusPending, entities.PaymentStatusPaid),
+				},
+				next: aws.String("iterator-2"),
+			},
+		},
+		{
+			name: "should broadcast the status of a new payment order",
+			want: want{
+				changes: []entities.PaymentStatusChange{
+					{OrderId: 123, Status: entities.PaymentStatusPending, UpdatedAt: time.Unix(1714564800, 0)},
+				},
+			},
+			getRecordsCall: getRecordsCall{
+				records: []types.Record{
+					createPaymentStreamRecord(types.OperationTypeInsert, "", entities.PaymentStatusPending),
+				},
+				next: aws.String("iterator-2"),
+			},
+		},
+		{
+			name: "should not broadcast changes that keep the status",
+			want: want{
+				changes: []entities.PaymentStatusChange{},
+			},
+			getRecordsCall: getRecordsCall{
+				records: []types.Record{
+					createPaymentStreamRecord(types.OperationTypeModify, entities.PaymentStatusPaid, entities.PaymentStatusPaid),
+					createPaymentStreamRecord(types.OperationTypeRemove, entities.PaymentStatusPaid, ""),
+				},
+				next: aws.String("iterator-2"),
+			},
+		},
+		{
+			name: "should not broadcast when the shard fails to be read",
+			want: want{
+				changes: []entities.PaymentStatusChange{},
+			},
+			getRecordsCall: getRecordsCall{
+				err: errors.New("internal server error"),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		streamsClient.EXPECT().
+			ListShards(gomock.Eq(paymentTableStreamArn)).
+			Times(1).
+			Return([]string{"shard-1"}, nil)
+
+		streamsClient.EXPECT().
+			GetShardIterator(gomock.Eq(paymentTableStreamArn), gomock.Eq("shard-1"), gomock.Eq(types.ShardIteratorTypeLatest), gomock.Eq("")).
+			Times(1).
+			Return(aws.String("iterator-1"), nil)
+
+		streamsClient.EXPECT().
+			GetRecords(gomock.Eq("iterator-1")).
+			Times(1).
+			Return(tt.getRecordsCall.records, tt.getRecordsCall.next, tt.getRecordsCall.err)
+
+		broadcaster := NewDynamoDBStreamPaymentStatusBroadcaster(streamsClient, paymentTableStreamArn, time.Second).(*dynamoDBStreamPaymentStatusBroadcaster)
+		changes := []entities.PaymentStatusChange{}
+		broadcaster.handlers = []PaymentStatusHandler{func(change entities.PaymentStatusChange) {
+			changes = append(changes, change)
+		}}
+
+		broadcaster.poll()
+
+		assert.Equal(t, tt.want.changes, changes)
+	}
+}
+
+func TestDynamoDBStreamPaymentStatusBroadcaster_ClosedShard(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	streamsClient := mock_dynamodbstreams.NewMockDynamoDBStreamsClient(ctrl)
+
+	gomock.InOrder(
+		streamsClient.EXPECT().ListShards(gomock.Eq(paymentTableStreamArn)).Times(1).Return([]string{"shard-1"}, nil),
+		streamsClient.EXPECT().GetShardIterator(gomock.Eq(paymentTableStreamArn), gomock.Eq("shard-1"), gomock.Eq(types.ShardIteratorTypeLatest), gomock.Eq("")).Times(1).Return(aws.String("iterator-1"), nil),
+		streamsClient.EXPECT().GetRecords(gomock.Eq("iterator-1")).Times(1).Return([]types.Record{}, nil, nil),
+		streamsClient.EXPECT().ListShards(gomock.Eq(paymentTableStreamArn)).Times(1).Return([]string{"shard-1", "shard-2"}, nil),
+		streamsClient.EXPECT().GetShardIterator(gomock.Eq(paymentTableStreamArn), gomock.Eq("shard-2"), gomock.Eq(types.ShardIteratorTypeTrimHorizon), gomock.Eq("")).Times(1).Return(aws.String("iterator-2"), nil),
+		streamsClient.EXPECT().GetRecords(gomock.Eq("iterator-2")).Times(1).Return([]types.Record{}, aws.String("iterator-3"), nil),
+	)
+
+	broadcaster := NewDynamoDBStreamPaymentStatusBroadcaster(streamsClient, paymentTableStreamArn, time.Second).(*dynamoDBStreamPaymentStatusBroadcaster)
+	broadcaster.poll()
+	broadcaster.poll()
+
+	assert.Equal(t, map[string]string{"shard-2": "iterator-3"}, broadcaster.iterators)
+}
+
+func TestDynamoDBStreamPaymentStatusBroadcaster_FailedShard(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	streamsClient := mock_dynamodbstreams.NewMockDynamoDBStreamsClient(ctrl)
+
+	record := createPaymentStreamRecord(types.OperationTypeModify, entities.PaymentStatusPending, entities.PaymentStatusPaid)
+	gomock.InOrder(
+		streamsClient.EXPECT().ListShards(gomock.Eq(paymentTableStreamArn)).Times(1).Return([]string{"shard-1"}, nil),
+		streamsClient.EXPECT().GetShardIterator(gomock.Eq(paymentTableStreamArn), gomock.Eq("shard-1"), gomock.Eq(types.ShardIteratorTypeLatest), gomock.Eq("")).Times(1).Return(aws.String("iterator-1"), nil),
+		streamsClient.EXPECT().GetRecords(gomock.Eq("iterator-1")).Times(1).Return([]types.Record{record}, aws.String("iterator-2"), nil),
+		streamsClient.EXPECT().GetRecords(gomock.Eq("iterator-2")).Times(1).Return(nil, nil, &types.ExpiredIteratorException{}),
+		streamsClient.EXPECT().ListShards(gomock.Eq(paymentTableStreamArn)).Times(1).Return([]string{"shard-1"}, nil),
+		streamsClient.EXPECT().GetShardIterator(gomock.Eq(paymentTableStreamArn), gomock.Eq("shard-1"), gomock.Eq(types.ShardIteratorTypeAfterSequenceNumber), gomock.Eq("100")).Times(1).Return(aws.String("iterator-3"), nil),
+		streamsClient.EXPECT().GetRecords(gomock.Eq("iterator-3")).Times(1).Return([]types.Record{}, aws.String("iterator-4"), nil),
+	)
+
+	broadcaster := NewDynamoDBStreamPaymentStatusBroadcaster(streamsClient, paymentTableStreamArn, time.Second).(*dynamoDBStreamPaymentStatusBroadcaster)
+	broadcaster.poll()
+	broadcaster.poll()
+	broadcaster.poll()
+
+	assert.Equal(t, map[string]string{"shard-1": "iterator-4"}, broadcaster.iterators)
+}
+
+func TestDynamoDBStreamPaymentStatusBroadcaster_TrimmedShard(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	streamsClient := mock_dynamodbstreams.NewMockDynamoDBStreamsClient(ctrl)
+
+	gomock.InOrder(
+		streamsClient.EXPECT().ListShards(gomock.Eq(paymentTableStreamArn)).Times(1).Return([]string{"shard-1"}, nil),
+		streamsClient.EXPECT().GetShardIterator(gomock.Eq(paymentTableStreamArn), gomock.Eq("shard-1"), gomock.Eq(types.ShardIteratorTypeAfterSequenceNumber), gomock.Eq("100")).Times(1).Return(nil, &types.TrimmedDataAccessException{}),
+		streamsClient.EXPECT().GetShardIterator(gomock.Eq(paymentTableStreamArn), gomock.Eq("shard-1"), gomock.Eq(types.ShardIteratorTypeTrimHorizon), gomock.Eq("")).Times(1).Return(aws.String("iterator-1"), nil),
+		streamsClient.EXPECT().GetRecords(gomock.Eq("iterator-1")).Times(1).Return([]types.Record{}, aws.String("iterator-2"), nil),
+	)
+
+	broadcaster := NewDynamoDBStreamPaymentStatusBroadcaster(streamsClient, paymentTableStreamArn, time.Second).(*dynamoDBStreamPaymentStatusBroadcaster)
+	broadcaster.positions["shard-1"] = shardPosition{iteratorType: types.ShardIteratorTypeAfterSequenceNumber, sequenceNumber: "100"}
+	broadcaster.poll()
+
+	assert.Equal(t, map[string]string{"shard-1": "iterator-2"}, broadcaster.iterators)
+}
+
+func createPaymentStreamRecord(operation types.OperationType, from, to entities.PaymentStatus) types.Record {
+	image := func(status entities.PaymentStatus) map[string]types.AttributeValue {
+		if status == "" {
+			return nil
+		}
+		return map[string]types.AttributeValue{
+			"OrderId":   &types.AttributeValueMemberN{Value: "123"},
+			"Status":    &types.AttributeValueMemberS{Value: string(status)},
+			"UpdatedAt": &types.AttributeValueMemberN{Value: "1714564800"},
+		}
+	}
+
+	return types.Record{
+		EventID:   aws.String("event-1"),
+		EventName: operation,
+		Dynamodb: &types.StreamRecord{
+			SequenceNumber: aws.String("100"),
+			OldImage:       image(from),
+			NewImage:       image(to),
+		},
+	}
+}
+
+func TestNewDynamoDBStreamPaymentStatusBroadcaster_DefaultPollInterval(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	streamsClient := mock_dynamodbstreams.NewMockDynamoDBStreamsClient(ctrl)
+
+	broadcaster := NewDynamoDBStreamPaymentStatusBroadcaster(streamsClient, paymentTableStreamArn, 0).(*dynamoDBStreamPaymentStatusBroadcaster)
+
+	assert.Equal(t, defaultStreamPollInterval, broadcaster.pollInterval)
+}
@@ -0,0 +1,81 @@
+package gateways
+
+import (
+	"sync"
+
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/entities"
+)
+
+// paymentStatusSubscriptionBuffer is how many changes a slow subscriber may fall behind before
+// changes are dropped for it.
+const paymentStatusSubscriptionBuffer = 8
+
+// PaymentStatusHub dispatches the payment status changes, received from the broadcaster, to the
+// subscribers of each payment order.
+type PaymentStatusHub interface {
+	Publish(change entities.PaymentStatusChange) error
+	// Subscribe returns the changes of the payment order, until unsubscribe is called. A
+	// subscriber falling behind misses changes, so it must read the payment order again on
+	// every change rather than rely on receiving each one.
+	Subscribe(orderId int) (changes <-chan entities.PaymentStatusChange, unsubscribe func())
+}
+
+type paymentStatusHub struct {
+	broadcaster PaymentStatusBroadcaster
+
+	mutex       sync.Mutex
+	subscribers map[int]map[int]chan entities.PaymentStatusChange
+	nextId      int
+}
+
+func NewPaymentStatusHub(broadcaster PaymentStatusBroadcaster) PaymentStatusHub {
+	hub := &paymentStatusHub{
+		broadcaster: broadcaster,
+		subscribers: map[int]map[int]chan entities.PaymentStatusChange{},
+	}
+	broadcaster.Listen(hub.dispatch)
+	return hub
+}
+
+func (h *paymentStatusHub) Publish(change entities.PaymentStatusChange) error {
+	return h.broadcaster.Broadcast(change)
+}
+
+func (h *paymentStatusHub) Subscribe(orderId int) (<-chan entities.PaymentStatusChange, func()) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	id := h.nextId
+	h.nextId++
+	changes := make(chan entities.PaymentStatusChange, paymentStatusSubscriptionBuffer)
+	if h.subscribers[orderId] == nil {
+		h.subscribers[orderId] = map[int]chan entities.PaymentStatusChange{}
+	}
+	h.subscribers[orderId][id] = changes
+
+	var once sync.Once
+	return changes, func() {
+		once.Do(func() {
+			h.mutex.Lock()
+			defer h.mutex.Unlock()
+
+			delete(h.subscribers[orderId], id)
+			if len(h.subscribers[orderId]) == 0 {
+				delete(h.subscribers, orderId)
+			}
+			close(changes)
+		})
+	}
+}
+
+func (h *paymentStatusHub) dispatch(change entities.PaymentStatusChange) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	for _, changes := range h.subscribers[change.OrderId] {
+		select {
+		case changes <- change:
+		default:
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package gateways
+
+import (
+	"testing"
+	"time"
+
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/entities"
+	"github.com/go-playground/assert/v2"
+)
+
+func TestPaymentStatusHub_Subscribe(t *testing.T) {
+	hub := NewPaymentStatusHub(NewInMemoryPaymentStatusBroadcaster())
+	updatedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	changes, unsubscribe := hub.Subscribe(123)
+	otherChanges, unsubscribeOther := hub.Subscribe(456)
+	defer unsubscribeOther()
+
+	err := hub.Publish(entities.PaymentStatusChange{OrderId: 123, Status: entities.PaymentStatusPaid, UpdatedAt: updatedAt})
+	assert.Equal(t, nil, err)
+
+	change := <-changes
+	assert.Equal(t, entities.PaymentStatusChange{OrderId: 123, Status: entities.PaymentStatusPaid, UpdatedAt: updatedAt}, change)
+	assert.Equal(t, 0, len(otherChanges))
+
+	unsubscribe()
+	unsubscribe()
+	err = hub.Publish(entities.PaymentStatusChange{OrderId: 123, Status: entities.PaymentStatusRefunded})
+	assert.Equal(t, nil, err)
+
+	_, open := <-changes
+	assert.Equal(t, false, open)
+}
+
+func TestPaymentStatusHub_SlowSubscriber(t *testing.T) {
+	hub := NewPaymentStatusHub(NewInMemoryPaymentStatusBroadcaster())
+
+	changes, unsubscribe := hub.Subscribe(123)
+	defer unsubscribe()
+
+	for i := 0; i < paymentStatusSubscriptionBuffer+2; i++ {
+		err := hub.Publish(entities.PaymentStatusChange{OrderId: 123, Status: entities.PaymentStatusPaid})
+		assert.Equal(t, nil, err)
+	}
+
+	assert.Equal(t, paymentStatusSubscriptionBuffer, len(changes))
+}
@@ -0,0 +1,93 @@
+package gateways
+
+import (
+	"errors"
+	"time"
+
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/infra/drivers/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+type ProcessedNotificationRepositoryGateway interface {
+	RegisterNotification(notificationId string, expiresAt time.Time) (bool, error)
+	CompleteNotification(notificationId string, expiresAt time.Time) error
+	ReleaseNotification(notificationId string) error
+}
+
+type processedNotificationRepositoryGateway struct {
+	processedNotificationTable string
+	dynamodbClient             dynamodb.DynamoDBClient
+}
+
+// processedNotification marks a broker notification as handled. ExpiresAt is the table TTL
+// attribute, so the markers are removed by DynamoDB once retries are no longer expected.
+type processedNotification struct {
+	NotificationId string
+	ProcessedAt    time.Time `dynamodbav:"ProcessedAt,unixtime"`
+	ExpiresAt      time.Time `dynamodbav:"ExpiresAt,unixtime"`
+}
+
+func NewProcessedNotificationRepositoryGateway(dynamodbClient dynamodb.DynamoDBClient, processedNotificationTable string) ProcessedNotificationRepositoryGateway {
+	return processedNotificationRepositoryGateway{
+		dynamodbClient:             dynamodbClient,
+		processedNotificationTable: processedNotificationTable,
+	}
+}
+
+// RegisterNotification stores the notification id unless it is already there and not yet
+// expired, returning false when the notification was registered before. The expired markers
+// DynamoDB has not removed yet are taken over, so a notification whose processing stopped midway
+// is processed again once its lease is over.
+func (p processedNotificationRepositoryGateway) RegisterNotification(notificationId string, expiresAt time.Time) (bool, error) {
+	av, err := attributevalue.MarshalMap(processedNotification{
+		NotificationId: notificationId,
+		ProcessedAt:    time.Now(),
+		ExpiresAt:      expiresAt,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	condition := expression.AttributeNotExists(expression.Name("NotificationId")).
+		Or(expression.Name("ExpiresAt").LessThan(expression.Value(time.Now().Unix())))
+	expr, err := expression.NewBuilder().WithCondition(condition).Build()
+	if err != nil {
+		return false, err
+	}
+
+	err = p.dynamodbClient.PutItemWithCondition(p.processedNotificationTable, av, expr)
+	if err != nil {
+		var conditionalCheckFailed *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionalCheckFailed) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
+// CompleteNotification keeps the notification id until expiresAt, once it was processed.
+func (p processedNotificationRepositoryGateway) CompleteNotification(notificationId string, expiresAt time.Time) error {
+	update := expression.Set(expression.Name("ProcessedAt"), expression.Value(time.Now().Unix())).
+		Set(expression.Name("ExpiresAt"), expression.Value(expiresAt.Unix()))
+	expr, err := expression.NewBuilder().WithUpdate(update).Build()
+	if err != nil {
+		return err
+	}
+
+	return p.dynamodbClient.UpdateItem(p.processedNotificationTable, p.notificationKey(notificationId), expr)
+}
+
+// ReleaseNotification removes the notification id, so the next delivery is processed again.
+func (p processedNotificationRepositoryGateway) ReleaseNotification(notificationId string) error {
+	return p.dynamodbClient.DeleteItem(p.processedNotificationTable, p.notificationKey(notificationId))
+}
+
+func (p processedNotificationRepositoryGateway) notificationKey(notificationId string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"NotificationId": &types.AttributeValueMemberS{Value: notificationId},
+	}
+}
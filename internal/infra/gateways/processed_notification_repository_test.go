@@ -0,0 +1,108 @@
+package gateways
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	mock_dynamodb "github.com/IgorRamosBR/g73-techchallenge-payment/internal/infra/drivers/dynamodb/mocks"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/go-playground/assert/v2"
+	"go.uber.org/mock/gomock"
+)
+
+func TestProcessedNotificationRepository_RegisterNotification(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	dynamodbClient := mock_dynamodb.NewMockDynamoDBClient(ctrl)
+
+	type want struct {
+		registered bool
+		err        error
+	}
+	type dynamodbCall struct {
+		times int
+		err   error
+	}
+	tests := []struct {
+		name string
+		want
+		dynamodbCall
+	}{
+		{
+			name: "should fail to register notification when dynamodb client returns error",
+			want: want{
+				err: errors.New("internal error"),
+			},
+			dynamodbCall: dynamodbCall{
+				times: 1,
+				err:   errors.New("internal error"),
+			},
+		},
+		{
+			name: "should not register notification when it was already registered",
+			want: want{
+				registered: false,
+			},
+			dynamodbCall: dynamodbCall{
+				times: 1,
+				err:   &types.ConditionalCheckFailedException{},
+			},
+		},
+		{
+			name: "should register notification",
+			want: want{
+				registered: true,
+			},
+			dynamodbCall: dynamodbCall{
+				times: 1,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		dynamodbClient.EXPECT().
+			PutItemWithCondition(gomock.Eq("ProcessedNotification"), gomock.Any(), gomock.Any()).
+			Times(tt.dynamodbCall.times).
+			Return(tt.dynamodbCall.err)
+
+		processedNotificationRepository := NewProcessedNotificationRepositoryGateway(dynamodbClient, "ProcessedNotification")
+		registered, err := processedNotificationRepository.RegisterNotification("7890:payment", time.Now().Add(24*time.Hour))
+
+		assert.Equal(t, tt.want.registered, registered)
+		assert.Equal(t, tt.want.err, err)
+	}
+}
+
+func TestProcessedNotificationRepository_CompleteNotification(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	dynamodbClient := mock_dynamodb.NewMockDynamoDBClient(ctrl)
+
+	dynamodbClient.EXPECT().
+		UpdateItem(gomock.Eq("ProcessedNotification"), gomock.Eq(map[string]types.AttributeValue{
+			"NotificationId": &types.AttributeValueMemberS{Value: "7890:payment"},
+		}), gomock.Any()).
+		Times(1).
+		Return(nil)
+
+	processedNotificationRepository := NewProcessedNotificationRepositoryGateway(dynamodbClient, "ProcessedNotification")
+	err := processedNotificationRepository.CompleteNotification("7890:payment", time.Now().Add(72*time.Hour))
+
+	assert.Equal(t, nil, err)
+}
+
+func TestProcessedNotificationRepository_ReleaseNotification(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	dynamodbClient := mock_dynamodb.NewMockDynamoDBClient(ctrl)
+
+	dynamodbClient.EXPECT().
+		DeleteItem(gomock.Eq("ProcessedNotification"), gomock.Eq(map[string]types.AttributeValue{
+			"NotificationId": &types.AttributeValueMemberS{Value: "7890:payment"},
+		})).
+		Times(1).
+		Return(nil)
+
+	processedNotificationRepository := NewProcessedNotificationRepositoryGateway(dynamodbClient, "ProcessedNotification")
+	err := processedNotificationRepository.ReleaseNotification("7890:payment")
+
+	assert.Equal(t, nil, err)
+}
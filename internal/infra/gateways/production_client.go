@@ -0,0 +1,45 @@
+package gateways
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/entities"
+	coreErrors "github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/errors"
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/infra/drivers/http"
+)
+
+type ProductionClient interface {
+	SubmitProductionOrder(productionOrder entities.ProductionOrder) error
+}
+
+type productionClient struct {
+	httpClient       http.HttpClient
+	productionApiUrl string
+}
+
+func NewProductionClient(httpClient http.HttpClient, productionApiUrl string) ProductionClient {
+	return productionClient{
+		httpClient:       httpClient,
+		productionApiUrl: productionApiUrl,
+	}
+}
+
+func (p productionClient) SubmitProductionOrder(productionOrder entities.ProductionOrder) error {
+	reqBody, err := json.Marshal(productionOrder)
+	if err != nil {
+		return fmt.Errorf("failed to marshal production order request, error: %v", err)
+	}
+
+	response, err := p.httpClient.DoPost(p.productionApiUrl, reqBody)
+	if err != nil {
+		return coreErrors.Wrap(coreErrors.KindUpstreamUnavailable, fmt.Errorf("failed to call production api, error: %v", err))
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode > 299 || response.StatusCode < 200 {
+		return coreErrors.Wrap(coreErrors.UpstreamStatusKind(response.StatusCode), fmt.Errorf("failed to call production api, status [%d] non-2xx", response.StatusCode))
+	}
+
+	return nil
+}
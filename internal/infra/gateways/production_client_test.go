@@ -0,0 +1,86 @@
+package gateways
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/entities"
+	coreErrors "github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/errors"
+	mock_http "github.com/IgorRamosBR/g73-techchallenge-payment/internal/infra/drivers/http/mocks"
+	"github.com/go-playground/assert/v2"
+	"go.uber.org/mock/gomock"
+)
+
+func TestProductionClient_SubmitProductionOrder(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	httpClient := mock_http.NewMockHttpClient(ctrl)
+
+	type want struct {
+		err error
+	}
+	type clientCall struct {
+		response *http.Response
+		err      error
+	}
+	tests := []struct {
+		name string
+		want
+		clientCall
+	}{
+		{
+			name: "should fail to submit production order when http client returns error",
+			want: want{
+				coreErrors.Wrap(coreErrors.KindUpstreamUnavailable, errors.New("failed to call production api, error: internal error")),
+			},
+			clientCall: clientCall{
+				response: &http.Response{},
+				err:      errors.New("internal error"),
+			},
+		},
+		{
+			name: "should fail to submit production order when response is non-2xx",
+			want: want{
+				coreErrors.Wrap(coreErrors.UpstreamStatusKind(503), errors.New("failed to call production api, status [503] non-2xx")),
+			},
+			clientCall: clientCall{
+				response: &http.Response{
+					StatusCode: 503,
+					Body:       io.NopCloser(strings.NewReader("")),
+				},
+			},
+		},
+		{
+			name: "should submit production order when response is 2xx",
+			want: want{
+				nil,
+			},
+			clientCall: clientCall{
+				response: &http.Response{
+					StatusCode: 201,
+					Body:       io.NopCloser(strings.NewReader("")),
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		httpClient.EXPECT().
+			DoPost(gomock.Eq("/production"), gomock.Eq([]byte(`{"orderId":123,"customerCpf":"12345678900","items":[{"skuId":"1","name":"X-Burger","category":"Lanche","type":"UNIT","quantity":2}]}`))).
+			Times(1).
+			Return(tt.clientCall.response, tt.clientCall.err)
+
+		productionClient := NewProductionClient(httpClient, "/production")
+		err := productionClient.SubmitProductionOrder(entities.ProductionOrder{
+			OrderId:     123,
+			CustomerCPF: "12345678900",
+			Items: []entities.ProductionOrderItem{
+				{SkuId: "1", Name: "X-Burger", Category: "Lanche", Type: "UNIT", Quantity: 2},
+			},
+		})
+
+		assert.Equal(t, tt.want.err, err)
+	}
+}
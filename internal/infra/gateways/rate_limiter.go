@@ -0,0 +1,141 @@
+package gateways
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/infra/drivers/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// rateLimiterMaxAttempts bounds the retries when other replicas update the same bucket.
+const rateLimiterMaxAttempts = 3
+
+// RateLimit is a token bucket refilled with Rate tokens per second, holding up to Burst tokens,
+// at least one.
+type RateLimit struct {
+	Rate  float64
+	Burst int
+}
+
+// RateLimiter takes a token from the bucket of the key. When the bucket is empty, it returns
+// false and how long until a token is available.
+type RateLimiter interface {
+	Allow(key string, limit RateLimit) (bool, time.Duration, error)
+}
+
+type tokenBucket struct {
+	BucketKey string
+	Tokens    float64
+	UpdatedAt int64
+	ExpiresAt time.Time `dynamodbav:"ExpiresAt,unixtime"`
+}
+
+// take refills the bucket for the time elapsed since its last update and takes a token from it.
+func (b tokenBucket) take(limit RateLimit, now time.Time) (tokenBucket, bool, time.Duration) {
+	burst := math.Max(1, float64(limit.Burst))
+	tokens := burst
+	if b.UpdatedAt != 0 {
+		elapsed := now.Sub(time.Unix(0, b.UpdatedAt)).Seconds()
+		tokens = math.Min(burst, b.Tokens+math.Max(elapsed, 0)*limit.Rate)
+	}
+
+	if tokens < 1 {
+		retryAfter := time.Duration((1 - tokens) / limit.Rate * float64(time.Second))
+		return b, false, retryAfter
+	}
+
+	// the bucket is full again once the burst is refilled, so it may be dropped after that
+	fullIn := time.Duration(burst / limit.Rate * float64(time.Second))
+	return tokenBucket{
+		BucketKey: b.BucketKey,
+		Tokens:    tokens - 1,
+		UpdatedAt: now.UnixNano(),
+		ExpiresAt: now.Add(fullIn),
+	}, true, 0
+}
+
+type dynamoDBRateLimiter struct {
+	rateLimitTable string
+	dynamodbClient dynamodb.DynamoDBClient
+}
+
+// NewDynamoDBRateLimiter keeps the buckets in DynamoDB, so the limits are shared by the replicas.
+// ExpiresAt is the table TTL attribute, removing the buckets once they are full again.
+func NewDynamoDBRateLimiter(dynamodbClient dynamodb.DynamoDBClient, rateLimitTable string) RateLimiter {
+	return dynamoDBRateLimiter{
+		dynamodbClient: dynamodbClient,
+		rateLimitTable: rateLimitTable,
+	}
+}
+
+// Allow updates the bucket with a condition on its previous update time, trying again with the
+// new state when another replica changed it in the meantime.
+func (r dynamoDBRateLimiter) Allow(key string, limit RateLimit) (bool, time.Duration, error) {
+	for attempt := 0; attempt < rateLimiterMaxAttempts; attempt++ {
+		bucket, err := r.getBucket(key)
+		if err != nil {
+			return false, 0, err
+		}
+
+		updated, allowed, retryAfter := bucket.take(limit, time.Now())
+		if !allowed {
+			return false, retryAfter, nil
+		}
+
+		err = r.putBucket(updated, bucket.UpdatedAt)
+		if err != nil {
+			var conditionalCheckFailed *types.ConditionalCheckFailedException
+			if errors.As(err, &conditionalCheckFailed) {
+				continue
+			}
+			return false, 0, err
+		}
+
+		return true, 0, nil
+	}
+
+	return false, 0, fmt.Errorf("failed to take token from bucket [%s], bucket kept changing", key)
+}
+
+func (r dynamoDBRateLimiter) getBucket(key string) (tokenBucket, error) {
+	item, err := r.dynamodbClient.GetItem(r.rateLimitTable, map[string]types.AttributeValue{
+		"BucketKey": &types.AttributeValueMemberS{Value: key},
+	})
+	if err != nil {
+		return tokenBucket{}, err
+	}
+
+	bucket := tokenBucket{BucketKey: key}
+	if item == nil {
+		return bucket, nil
+	}
+
+	err = attributevalue.UnmarshalMap(item, &bucket)
+	if err != nil {
+		return tokenBucket{}, err
+	}
+	return bucket, nil
+}
+
+func (r dynamoDBRateLimiter) putBucket(bucket tokenBucket, previousUpdatedAt int64) error {
+	av, err := attributevalue.MarshalMap(bucket)
+	if err != nil {
+		return err
+	}
+
+	condition := expression.AttributeNotExists(expression.Name("BucketKey"))
+	if previousUpdatedAt != 0 {
+		condition = expression.Name("UpdatedAt").Equal(expression.Value(previousUpdatedAt))
+	}
+	expr, err := expression.NewBuilder().WithCondition(condition).Build()
+	if err != nil {
+		return err
+	}
+
+	return r.dynamodbClient.PutItemWithCondition(r.rateLimitTable, av, expr)
+}
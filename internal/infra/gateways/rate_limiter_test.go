@@ -0,0 +1,154 @@
+package gateways
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	mock_dynamodb "github.com/IgorRamosBR/g73-techchallenge-payment/internal/infra/drivers/dynamodb/mocks"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/go-playground/assert/v2"
+	"go.uber.org/mock/gomock"
+)
+
+func TestTokenBucket_Take(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	limit := RateLimit{Rate: 2, Burst: 4}
+
+	type want struct {
+		tokens     float64
+		allowed    bool
+		retryAfter time.Duration
+	}
+	tests := []struct {
+		name   string
+		bucket tokenBucket
+		want
+	}{
+		{
+			name:   "should start a new bucket full",
+			bucket: tokenBucket{BucketKey: "key"},
+			want:   want{tokens: 3, allowed: true},
+		},
+		{
+			name:   "should refill the bucket for the elapsed time",
+			bucket: tokenBucket{BucketKey: "key", Tokens: 0, UpdatedAt: now.Add(-time.Second).UnixNano()},
+			want:   want{tokens: 1, allowed: true},
+		},
+		{
+			name:   "should not refill the bucket over the burst",
+			bucket: tokenBucket{BucketKey: "key", Tokens: 1, UpdatedAt: now.Add(-time.Hour).UnixNano()},
+			want:   want{tokens: 3, allowed: true},
+		},
+		{
+			name:   "should refuse when the bucket is empty",
+			bucket: tokenBucket{BucketKey: "key", Tokens: 0.5, UpdatedAt: now.UnixNano()},
+			want:   want{tokens: 0.5, allowed: false, retryAfter: 250 * time.Millisecond},
+		},
+	}
+
+	for _, tt := range tests {
+		bucket, allowed, retryAfter := tt.bucket.take(limit, now)
+
+		assert.Equal(t, tt.want.tokens, bucket.Tokens)
+		assert.Equal(t, tt.want.allowed, allowed)
+		assert.Equal(t, tt.want.retryAfter, retryAfter)
+		if allowed {
+			assert.Equal(t, now.UnixNano(), bucket.UpdatedAt)
+			assert.Equal(t, now.Add(2*time.Second), bucket.ExpiresAt)
+		}
+	}
+}
+
+func TestDynamoDBRateLimiter_Allow(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	dynamodbClient := mock_dynamodb.NewMockDynamoDBClient(ctrl)
+
+	type want struct {
+		allowed bool
+		err     error
+	}
+	type getItemCall struct {
+		times int
+		item  map[string]types.AttributeValue
+		err   error
+	}
+	type putItemCall struct {
+		times int
+		err   error
+	}
+	tests := []struct {
+		name string
+		want
+		getItemCall
+		putItemCall
+	}{
+		{
+			name:        "should fail when dynamodb client fails to get the bucket",
+			want:        want{err: errors.New("internal error")},
+			getItemCall: getItemCall{times: 1, err: errors.New("internal error")},
+		},
+		{
+			name:        "should allow and create the bucket when it does not exist",
+			want:        want{allowed: true},
+			getItemCall: getItemCall{times: 1},
+			putItemCall: putItemCall{times: 1},
+		},
+		{
+			name: "should refuse without updating the bucket when it is empty",
+			want: want{allowed: false},
+			getItemCall: getItemCall{times: 1, item: map[string]types.AttributeValue{
+				"BucketKey": &types.AttributeValueMemberS{Value: "ip:10.0.0.1"},
+				"Tokens":    &types.AttributeValueMemberN{Value: "0"},
+				"UpdatedAt": &types.AttributeValueMemberN{Value: fmt.Sprint(time.Now().UnixNano())},
+			}},
+		},
+		{
+			name:        "should fail to update dynamodb when dynamodb client returns error",
+			want:        want{err: errors.New("internal error")},
+			getItemCall: getItemCall{times: 1},
+			putItemCall: putItemCall{times: 1, err: errors.New("internal error")},
+		},
+		{
+			name:        "should give up when other replicas keep changing the bucket",
+			want:        want{err: errors.New("failed to take token from bucket [ip:10.0.0.1], bucket kept changing")},
+			getItemCall: getItemCall{times: 3},
+			putItemCall: putItemCall{times: 3, err: &types.ConditionalCheckFailedException{}},
+		},
+	}
+
+	for _, tt := range tests {
+		dynamodbClient.EXPECT().GetItem(gomock.Eq("RateLimit"), gomock.Eq(map[string]types.AttributeValue{"BucketKey": &types.AttributeValueMemberS{Value: "ip:10.0.0.1"}})).
+			Times(tt.getItemCall.times).
+			Return(tt.getItemCall.item, tt.getItemCall.err)
+		dynamodbClient.EXPECT().PutItemWithCondition(gomock.Eq("RateLimit"), gomock.Any(), gomock.Any()).
+			Times(tt.putItemCall.times).
+			Return(tt.putItemCall.err)
+
+		rateLimiter := NewDynamoDBRateLimiter(dynamodbClient, "RateLimit")
+		allowed, _, err := rateLimiter.Allow("ip:10.0.0.1", RateLimit{Rate: 1, Burst: 1})
+
+		assert.Equal(t, tt.want.allowed, allowed)
+		assert.Equal(t, tt.want.err, err)
+	}
+}
+
+func TestInMemoryRateLimiter_Allow(t *testing.T) {
+	rateLimiter := NewInMemoryRateLimiter()
+	limit := RateLimit{Rate: 1.0 / 3600, Burst: 2}
+
+	for i := 0; i < 2; i++ {
+		allowed, _, err := rateLimiter.Allow("client:kiosk-1", limit)
+		assert.Equal(t, true, allowed)
+		assert.Equal(t, nil, err)
+	}
+
+	allowed, retryAfter, err := rateLimiter.Allow("client:kiosk-1", limit)
+	assert.Equal(t, false, allowed)
+	assert.Equal(t, retryAfter > 59*time.Minute, true)
+	assert.Equal(t, nil, err)
+
+	allowed, _, _ = rateLimiter.Allow("client:kiosk-2", limit)
+	assert.Equal(t, true, allowed)
+}
@@ -0,0 +1,91 @@
+package workers
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/entities"
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/usecases"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// defaultPoolSize and defaultPollInterval apply when the pool size or the poll interval is not
+// configured.
+const (
+	defaultPoolSize     = 1
+	defaultPollInterval = time.Second
+)
+
+type NotificationWorker interface {
+	Start(ctx context.Context)
+}
+
+type notificationWorker struct {
+	notificationUseCase usecases.NotificationUseCase
+	poolSize            int
+	interval            time.Duration
+}
+
+func NewNotificationWorker(notificationUseCase usecases.NotificationUseCase, poolSize int, interval time.Duration) NotificationWorker {
+	if poolSize <= 0 {
+		poolSize = defaultPoolSize
+	}
+	if interval <= 0 {
+		interval = defaultPollInterval
+	}
+
+	return notificationWorker{
+		notificationUseCase: notificationUseCase,
+		poolSize:            poolSize,
+		interval:            interval,
+	}
+}
+
+// Start polls the notification queue on every tick until the context is cancelled. At most
+// poolSize messages are processed at the same time.
+func (w notificationWorker) Start(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	log.Infof("notification worker started, pool size: %d, interval: %s", w.poolSize, w.interval)
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info("notification worker stopped")
+			return
+		case <-ticker.C:
+			w.drain(ctx)
+		}
+	}
+}
+
+// drain keeps receiving batches while the queue returns full batches, so a backlog does not wait
+// for the next tick.
+func (w notificationWorker) drain(ctx context.Context) {
+	for ctx.Err() == nil {
+		messages, err := w.notificationUseCase.ReceiveNotificationMessages(w.poolSize)
+		if err != nil {
+			log.Errorf("failed to receive notification messages, error: %v", err)
+			return
+		}
+
+		var wg sync.WaitGroup
+		for _, message := range messages {
+			wg.Add(1)
+			go func(message entities.NotificationMessage) {
+				defer wg.Done()
+				err := w.notificationUseCase.ProcessNotificationMessage(message)
+				if err != nil {
+					log.Errorf("failed to process notification message [%s], error: %v", message.MessageId, err)
+				}
+			}(message)
+		}
+		wg.Wait()
+
+		if len(messages) < w.poolSize {
+			return
+		}
+	}
+}
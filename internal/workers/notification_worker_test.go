@@ -0,0 +1,118 @@
+package workers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/entities"
+	mock_usecases "github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/usecases/mocks"
+	"go.uber.org/mock/gomock"
+)
+
+func TestNotificationWorker_Start(t *testing.T) {
+	firstMessage := entities.NotificationMessage{MessageId: "1", Topic: "payment", ResourceId: 7890}
+	secondMessage := entities.NotificationMessage{MessageId: "2", Topic: "payment", ResourceId: 7891}
+	thirdMessage := entities.NotificationMessage{MessageId: "3", Topic: "payment", ResourceId: 7892}
+
+	type receiveCall struct {
+		messages []entities.NotificationMessage
+		err      error
+	}
+	type processCall struct {
+		message entities.NotificationMessage
+		err     error
+	}
+	tests := []struct {
+		name         string
+		cancelled    bool
+		receiveCalls []receiveCall
+		processCalls []processCall
+	}{
+		{
+			name: "should process the messages received on a tick",
+			receiveCalls: []receiveCall{
+				{messages: []entities.NotificationMessage{firstMessage}},
+				{messages: []entities.NotificationMessage{}},
+			},
+			processCalls: []processCall{
+				{message: firstMessage},
+			},
+		},
+		{
+			name: "should keep receiving while the batches are full",
+			receiveCalls: []receiveCall{
+				{messages: []entities.NotificationMessage{firstMessage, secondMessage}},
+				{messages: []entities.NotificationMessage{thirdMessage}},
+				{messages: []entities.NotificationMessage{}},
+			},
+			processCalls: []processCall{
+				{message: firstMessage},
+				{message: secondMessage},
+				{message: thirdMessage},
+			},
+		},
+		{
+			name: "should wait for the next tick when the messages fail to be received",
+			receiveCalls: []receiveCall{
+				{err: errors.New("internal error")},
+				{messages: []entities.NotificationMessage{}},
+			},
+		},
+		{
+			name: "should go on when a message fails to be processed",
+			receiveCalls: []receiveCall{
+				{messages: []entities.NotificationMessage{firstMessage, secondMessage}},
+				{messages: []entities.NotificationMessage{}},
+			},
+			processCalls: []processCall{
+				{message: firstMessage, err: errors.New("internal error")},
+				{message: secondMessage},
+			},
+		},
+		{
+			name:      "should stop without receiving when the context is cancelled",
+			cancelled: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			notificationUseCase := mock_usecases.NewMockNotificationUseCase(ctrl)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tt.cancelled {
+				cancel()
+			}
+
+			// the last receive stops the worker
+			calls := []any{}
+			for i, call := range tt.receiveCalls {
+				call := call
+				last := i == len(tt.receiveCalls)-1
+				calls = append(calls, notificationUseCase.EXPECT().
+					ReceiveNotificationMessages(gomock.Eq(2)).
+					Times(1).
+					DoAndReturn(func(int) ([]entities.NotificationMessage, error) {
+						if last {
+							cancel()
+						}
+						return call.messages, call.err
+					}))
+			}
+			gomock.InOrder(calls...)
+
+			for _, call := range tt.processCalls {
+				notificationUseCase.EXPECT().
+					ProcessNotificationMessage(gomock.Eq(call.message)).
+					Times(1).
+					Return(call.err)
+			}
+
+			worker := NewNotificationWorker(notificationUseCase, 2, testInterval)
+			runWorker(t, ctx, worker.Start)
+		})
+	}
+}
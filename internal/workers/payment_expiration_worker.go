@@ -0,0 +1,53 @@
+package workers
+
+import (
+	"context"
+	"time"
+
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/usecases"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// defaultSweepInterval applies when no sweep interval is configured.
+const defaultSweepInterval = time.Minute
+
+type PaymentExpirationWorker interface {
+	Start(ctx context.Context)
+}
+
+type paymentExpirationWorker struct {
+	paymentUseCase usecases.PaymentUseCase
+	interval       time.Duration
+}
+
+func NewPaymentExpirationWorker(paymentUseCase usecases.PaymentUseCase, interval time.Duration) PaymentExpirationWorker {
+	if interval <= 0 {
+		interval = defaultSweepInterval
+	}
+
+	return paymentExpirationWorker{
+		paymentUseCase: paymentUseCase,
+		interval:       interval,
+	}
+}
+
+// Start sweeps overdue payment orders on every tick until the context is cancelled.
+func (w paymentExpirationWorker) Start(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	log.Infof("payment expiration worker started, interval: %s", w.interval)
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info("payment expiration worker stopped")
+			return
+		case <-ticker.C:
+			err := w.paymentUseCase.ExpirePaymentOrders()
+			if err != nil {
+				log.Errorf("failed to sweep expired payment orders, error: %v", err)
+			}
+		}
+	}
+}
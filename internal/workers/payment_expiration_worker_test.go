@@ -0,0 +1,83 @@
+package workers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	mock_usecases "github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/usecases/mocks"
+	"go.uber.org/mock/gomock"
+)
+
+const testInterval = 10 * time.Millisecond
+
+// runWorker runs start until it returns, failing the test when it does not stop once the context
+// is cancelled.
+func runWorker(t *testing.T, ctx context.Context, start func(ctx context.Context)) {
+	done := make(chan struct{})
+	go func() {
+		start(ctx)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("worker did not stop")
+	}
+}
+
+func TestPaymentExpirationWorker_Start(t *testing.T) {
+	tests := []struct {
+		name      string
+		cancelled bool
+		sweepErrs []error
+	}{
+		{
+			name:      "should sweep the expired payment orders on every tick",
+			sweepErrs: []error{nil, nil},
+		},
+		{
+			name:      "should keep sweeping after a failed sweep",
+			sweepErrs: []error{errors.New("internal error"), nil},
+		},
+		{
+			name:      "should stop without sweeping when the context is cancelled",
+			cancelled: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			paymentUseCase := mock_usecases.NewMockPaymentUseCase(ctrl)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tt.cancelled {
+				cancel()
+			}
+
+			// the last sweep stops the worker
+			calls := []any{}
+			for i, err := range tt.sweepErrs {
+				err := err
+				last := i == len(tt.sweepErrs)-1
+				calls = append(calls, paymentUseCase.EXPECT().
+					ExpirePaymentOrders().
+					Times(1).
+					DoAndReturn(func() error {
+						if last {
+							cancel()
+						}
+						return err
+					}))
+			}
+			gomock.InOrder(calls...)
+
+			worker := NewPaymentExpirationWorker(paymentUseCase, testInterval)
+			runWorker(t, ctx, worker.Start)
+		})
+	}
+}
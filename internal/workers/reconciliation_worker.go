@@ -0,0 +1,64 @@
+package workers
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/usecases"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// defaultReconciliationInterval applies when no reconciliation interval is configured.
+const defaultReconciliationInterval = 10 * time.Minute
+
+type ReconciliationWorker interface {
+	Start(ctx context.Context)
+}
+
+type reconciliationWorker struct {
+	paymentUseCase usecases.PaymentUseCase
+	interval       time.Duration
+	threshold      time.Duration
+}
+
+func NewReconciliationWorker(paymentUseCase usecases.PaymentUseCase, interval, threshold time.Duration) ReconciliationWorker {
+	if interval <= 0 {
+		interval = defaultReconciliationInterval
+	}
+
+	return reconciliationWorker{
+		paymentUseCase: paymentUseCase,
+		interval:       interval,
+		threshold:      threshold,
+	}
+}
+
+// Start reconciles the payment orders with the broker on every tick until the context is cancelled.
+func (w reconciliationWorker) Start(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	log.Infof("reconciliation worker started, interval: %s, threshold: %s", w.interval, w.threshold)
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info("reconciliation worker stopped")
+			return
+		case <-ticker.C:
+			report, err := w.paymentUseCase.ReconcilePayments(w.threshold)
+			if err != nil {
+				log.Errorf("failed to reconcile payments, error: %v", err)
+				continue
+			}
+
+			reportJson, err := json.Marshal(report)
+			if err != nil {
+				log.Errorf("failed to marshal reconciliation report, error: %v", err)
+				continue
+			}
+			log.Infof("reconciliation report: %s", reportJson)
+		}
+	}
+}
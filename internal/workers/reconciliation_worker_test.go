@@ -0,0 +1,76 @@
+package workers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/usecases/dto"
+	mock_usecases "github.com/IgorRamosBR/g73-techchallenge-payment/internal/core/usecases/mocks"
+	"go.uber.org/mock/gomock"
+)
+
+func TestReconciliationWorker_Start(t *testing.T) {
+	type reconcileCall struct {
+		report dto.ReconciliationReport
+		err    error
+	}
+	tests := []struct {
+		name           string
+		cancelled      bool
+		reconcileCalls []reconcileCall
+	}{
+		{
+			name: "should reconcile the payment orders on every tick",
+			reconcileCalls: []reconcileCall{
+				{report: dto.ReconciliationReport{}},
+				{report: dto.ReconciliationReport{}},
+			},
+		},
+		{
+			name: "should keep reconciling after a failed reconciliation",
+			reconcileCalls: []reconcileCall{
+				{err: errors.New("internal error")},
+				{report: dto.ReconciliationReport{}},
+			},
+		},
+		{
+			name:      "should stop without reconciling when the context is cancelled",
+			cancelled: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			paymentUseCase := mock_usecases.NewMockPaymentUseCase(ctrl)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tt.cancelled {
+				cancel()
+			}
+
+			// the last reconciliation stops the worker
+			calls := []any{}
+			for i, call := range tt.reconcileCalls {
+				call := call
+				last := i == len(tt.reconcileCalls)-1
+				calls = append(calls, paymentUseCase.EXPECT().
+					ReconcilePayments(gomock.Eq(30*time.Minute)).
+					Times(1).
+					DoAndReturn(func(time.Duration) (dto.ReconciliationReport, error) {
+						if last {
+							cancel()
+						}
+						return call.report, call.err
+					}))
+			}
+			gomock.InOrder(calls...)
+
+			worker := NewReconciliationWorker(paymentUseCase, testInterval, 30*time.Minute)
+			runWorker(t, ctx, worker.Start)
+		})
+	}
+}